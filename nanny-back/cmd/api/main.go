@@ -4,20 +4,32 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // sitter time zones must resolve even in images without zoneinfo
 
 	"github.com/gorilla/mux"
 
 	"nanny-backend/internal/admin"
+	"nanny-backend/internal/audit"
 	"nanny-backend/internal/auth"
+	"nanny-backend/internal/availability"
 	"nanny-backend/internal/bookings"
+	"nanny-backend/internal/chat"
+	"nanny-backend/internal/common/authz"
 	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/geo"
+	"nanny-backend/internal/common/mailer"
 	"nanny-backend/internal/common/middleware"
+	"nanny-backend/internal/common/storage"
+	"nanny-backend/internal/common/token"
+	"nanny-backend/internal/locations"
+	"nanny-backend/internal/payments"
 	"nanny-backend/internal/pets"
 	"nanny-backend/internal/reviews"
 	"nanny-backend/internal/services"
@@ -27,20 +39,56 @@ import (
 func main() {
 	cfg := config.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, os.Args[2:]))
+	}
+
 	db, err := connectWithRetry(cfg.Database.ConnectionString(), 10, 3*time.Second)
 	if err != nil {
 		log.Fatal("❌ Failed to connect to database:", err)
 	}
 	defer db.Close()
+	database.SetQueryTimeout(cfg.Database.QueryTimeout)
+
+	if cfg.Database.AutoMigrate {
+		if err := applyMigrations(context.Background(), db); err != nil {
+			log.Fatal("❌ Failed to apply migrations:", err)
+		}
+	}
+
+	tokens, err := token.FromConfig(cfg)
+	if err != nil {
+		log.Fatal("❌ Failed to load token keys:", err)
+	}
+
+	mail, err := mailer.FromConfig(cfg.Mail)
+	if err != nil {
+		log.Fatal("❌ Failed to configure mailer:", err)
+	}
+
+	provider, err := payments.NewProvider(cfg.Payments.Provider)
+	if err != nil {
+		log.Fatal("❌ Failed to configure payment provider:", err)
+	}
+
+	geocoder, err := geo.FromConfig(cfg.Geo)
+	if err != nil {
+		log.Fatal("❌ Failed to configure geocoder:", err)
+	}
 
 	r := mux.NewRouter()
 
-	setupAuthModule(r, db)
+	setupAuditModule(r, db)
+	setupAuthModule(r, db, tokens, mail)
 	setupPetsModule(r, db)
-	setupBookingsModule(r, db)
+	schedule := setupAvailabilityModule(r, db)
+	places := setupLocationsModule(r, db, geocoder)
+	billing := setupPaymentsModule(r, db, provider)
+	chats := setupChatModule(r, db)
+	bookingService := setupBookingsModule(r, db, schedule, places, billing, chats)
 	setupReviewsModule(r, db)
-	setupServicesModule(r, db)
-	setupAdminModule(r, db)
+	setupServicesModule(r, db, places)
+	setupAdminModule(r, db, mail, storage.NewDiskStore(cfg.Storage.Dir))
 
 	frontendDir := "../nanny-front"
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(frontendDir)))
@@ -53,9 +101,15 @@ func main() {
 
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
 
+	// Every request context derives from requestCtx, so cancelling it stops
+	// the queries of requests still running when shutdown gives up.
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	srv := &http.Server{
 		Addr:              addr,
 		Handler:           handler,
+		BaseContext:       func(net.Listener) context.Context { return requestCtx },
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       15 * time.Second,
 		WriteTimeout:      15 * time.Second,
@@ -69,7 +123,7 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		startBookingExpirationWorker(ctx, db)
+		startBookingExpirationWorker(ctx, bookingService)
 	}()
 
 	go func() {
@@ -91,6 +145,7 @@ func main() {
 	defer shutdownCancel()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
+		cancelRequests()
 		log.Printf("❌ Forced shutdown: %v", err)
 	} else {
 		log.Println("✅ HTTP server stopped gracefully")
@@ -118,7 +173,7 @@ func connectWithRetry(dsn string, attempts int, delay time.Duration) (*database.
 	return nil, err
 }
 
-func startBookingExpirationWorker(ctx context.Context, db *database.Database) {
+func startBookingExpirationWorker(ctx context.Context, service bookings.Service) {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
 
@@ -131,39 +186,60 @@ func startBookingExpirationWorker(ctx context.Context, db *database.Database) {
 			return
 
 		case <-ticker.C:
-			checkExpiredBookings(ctx, db)
+			checkExpiredBookings(ctx, service)
 		}
 	}
 }
 
-func checkExpiredBookings(ctx context.Context, db *database.Database) {
-	query := `
-		UPDATE bookings
-		SET status = 'cancelled'
-		WHERE status = 'pending'
-		  AND start_time < NOW() - INTERVAL '24 hours'
-	`
-
-	res, err := db.DB.ExecContext(ctx, query)
+func checkExpiredBookings(ctx context.Context, service bookings.Service) {
+	affected, err := service.ExpireOverdueBookings(ctx)
 	if err != nil {
 		log.Printf("❌ Worker error updating expired bookings: %v", err)
 		return
 	}
 
-	affected, _ := res.RowsAffected()
 	if affected > 0 {
-		log.Printf("✅ Worker cancelled %d expired booking(s)", affected)
+		log.Printf("✅ Worker expired %d booking(s)", affected)
 	}
 }
 
-func setupAuthModule(r *mux.Router, db *database.Database) {
+func setupAuditModule(r *mux.Router, db *database.Database) {
+	repo := audit.NewRepository(db.DB)
+	service := audit.NewService(repo)
+	handler := audit.NewHandler(service)
+
+	r.Use(audit.Middleware(service))
+
+	r.Handle("/api/admin/audit",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleAdmin)(http.HandlerFunc(handler.GetEntries))),
+	).Methods("GET")
+}
+
+func setupAuthModule(r *mux.Router, db *database.Database, tokens *token.Manager, mail mailer.Mailer) {
 	repo := auth.NewRepository(db.DB)
-	service := auth.NewService(repo)
+	service := auth.NewService(repo, tokens, mail)
 	handler := auth.NewHandler(service)
 
 	r.HandleFunc("/api/auth/register/owner", handler.RegisterOwner).Methods("POST")
 	r.HandleFunc("/api/auth/register/sitter", handler.RegisterSitter).Methods("POST")
 	r.HandleFunc("/api/auth/login", handler.Login).Methods("POST")
+	r.HandleFunc("/api/auth/refresh", handler.Refresh).Methods("POST")
+	r.HandleFunc("/api/auth/logout", handler.Logout).Methods("POST")
+	r.HandleFunc("/api/auth/logout/all", handler.LogoutAll).Methods("POST")
+	r.HandleFunc("/api/auth/password/forgot", handler.ForgotPassword).Methods("POST")
+	r.HandleFunc("/api/auth/password/reset", handler.ResetPassword).Methods("POST")
+	r.HandleFunc("/api/auth/verify", handler.VerifyEmail).Methods("GET", "POST")
+	r.HandleFunc("/.well-known/jwks.json", tokens.JWKSHandler).Methods("GET")
+
+	r.Handle("/api/admin/users/{user_id:[0-9]+}/impersonate",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleAdmin)(http.HandlerFunc(handler.Impersonate))),
+	).Methods("POST")
+
+	middleware.SetTokenVerifier(tokens)
+	middleware.SetSessionChecker(service)
+	middleware.SetAccountChecker(service)
+
+	r.Use(middleware.GuardImpersonation)
 }
 
 func setupPetsModule(r *mux.Router, db *database.Database) {
@@ -172,45 +248,155 @@ func setupPetsModule(r *mux.Router, db *database.Database) {
 	handler := pets.NewHandler(service)
 
 	r.Handle("/api/pets",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.CreatePet)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner)(http.HandlerFunc(handler.CreatePet))),
 	).Methods("POST")
 
 	r.Handle("/api/pets/{id:[0-9]+}",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.UpdatePet)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner)(http.HandlerFunc(handler.UpdatePet))),
 	).Methods("PUT")
 
 	r.Handle("/api/pets/{id:[0-9]+}",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.DeletePet)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner)(http.HandlerFunc(handler.DeletePet))),
 	).Methods("DELETE")
 
 	r.HandleFunc("/api/pets/{id:[0-9]+}", handler.GetPet).Methods("GET")
 	r.HandleFunc("/api/owners/{owner_id:[0-9]+}/pets", handler.GetOwnerPets).Methods("GET")
 }
 
-func setupBookingsModule(r *mux.Router, db *database.Database) {
+func setupAvailabilityModule(r *mux.Router, db *database.Database) availability.Service {
+	repo := availability.NewRepository(db.DB)
+	service := availability.NewService(repo)
+	handler := availability.NewHandler(service)
+
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/availability",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.SetSchedule))),
+	).Methods("PUT")
+
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/availability/blackouts",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.AddBlackout))),
+	).Methods("POST")
+
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/availability/blackouts/{blackout_id:[0-9]+}",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.DeleteBlackout))),
+	).Methods("DELETE")
+
+	r.HandleFunc("/api/sitters/{sitter_id:[0-9]+}/availability", handler.GetSchedule).Methods("GET")
+	r.HandleFunc("/api/sitters/{sitter_id:[0-9]+}/availability/free", handler.GetFreeSlots).Methods("GET")
+
+	return service
+}
+
+func setupLocationsModule(r *mux.Router, db *database.Database, geocoder geo.Geocoder) locations.Service {
+	repo := locations.NewRepository(db.DB)
+	service := locations.NewService(repo, geocoder)
+	handler := locations.NewHandler(service)
+
+	sitters := middleware.RequireRole(authz.RoleSitter, authz.RoleAdmin)
+	owners := middleware.RequireRole(authz.RoleOwner, authz.RoleAdmin)
+
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/location",
+		middleware.AuthMiddleware(sitters(http.HandlerFunc(handler.GetSitterLocation))),
+	).Methods("GET")
+
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/location",
+		middleware.AuthMiddleware(sitters(http.HandlerFunc(handler.SetSitterLocation))),
+	).Methods("PUT")
+
+	r.Handle("/api/owners/{owner_id:[0-9]+}/location",
+		middleware.AuthMiddleware(owners(http.HandlerFunc(handler.GetOwnerLocation))),
+	).Methods("GET")
+
+	r.Handle("/api/owners/{owner_id:[0-9]+}/location",
+		middleware.AuthMiddleware(owners(http.HandlerFunc(handler.SetOwnerLocation))),
+	).Methods("PUT")
+
+	return service
+}
+
+func setupPaymentsModule(r *mux.Router, db *database.Database, provider payments.PaymentProvider) payments.Service {
+	repo := payments.NewRepository(db.DB)
+	service := payments.NewService(repo, provider)
+	handler := payments.NewHandler(service)
+
+	r.Handle("/api/owners/{owner_id:[0-9]+}/payments",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner, authz.RoleAdmin)(http.HandlerFunc(handler.GetOwnerPayments))),
+	).Methods("GET")
+
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/payments",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter, authz.RoleAdmin)(http.HandlerFunc(handler.GetSitterPayments))),
+	).Methods("GET")
+
+	r.Handle("/api/admin/payments",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleAdmin)(http.HandlerFunc(handler.GetAllPayments))),
+	).Methods("GET")
+
+	return service
+}
+
+func setupChatModule(r *mux.Router, db *database.Database) chat.Service {
+	repo := chat.NewRepository(db.DB)
+	service := chat.NewService(repo, chat.NewBroker())
+	handler := chat.NewHandler(service)
+
+	participants := middleware.RequireRole(authz.RoleOwner, authz.RoleSitter)
+
+	r.Handle("/api/bookings/{id:[0-9]+}/chat",
+		middleware.AuthMiddleware(participants(http.HandlerFunc(handler.GetChat))),
+	).Methods("GET")
+
+	r.Handle("/api/bookings/{id:[0-9]+}/chat/messages",
+		middleware.AuthMiddleware(participants(http.HandlerFunc(handler.ListMessages))),
+	).Methods("GET")
+
+	r.Handle("/api/bookings/{id:[0-9]+}/chat/messages",
+		middleware.AuthMiddleware(participants(http.HandlerFunc(handler.PostMessage))),
+	).Methods("POST")
+
+	r.Handle("/api/bookings/{id:[0-9]+}/chat/stream",
+		middleware.QueryToken(middleware.AuthMiddleware(participants(http.HandlerFunc(handler.Stream)))),
+	).Methods("GET")
+
+	return service
+}
+
+func setupBookingsModule(r *mux.Router, db *database.Database, schedule availability.Service, places locations.Service, billing payments.Service, chats chat.Service) bookings.Service {
 	repo := bookings.NewRepository(db.DB)
-	service := bookings.NewService(repo)
+	service := bookings.NewService(repo, schedule, places, billing, chats)
 	handler := bookings.NewHandler(service)
 
 	r.Handle("/api/bookings",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.CreateBooking)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner)(http.HandlerFunc(handler.CreateBooking))),
 	).Methods("POST")
 
 	r.Handle("/api/bookings/{id:[0-9]+}/confirm",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.ConfirmBooking)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.ConfirmBooking))),
 	).Methods("POST")
 
 	r.Handle("/api/bookings/{id:[0-9]+}/cancel",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.CancelBooking)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner, authz.RoleSitter)(http.HandlerFunc(handler.CancelBooking))),
+	).Methods("POST")
+
+	r.Handle("/api/bookings/{id:[0-9]+}/start",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.StartBooking))),
 	).Methods("POST")
 
 	r.Handle("/api/bookings/{id:[0-9]+}/complete",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.CompleteBooking)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.CompleteBooking))),
+	).Methods("POST")
+
+	r.Handle("/api/bookings/{id:[0-9]+}/no-show",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner, authz.RoleSitter)(http.HandlerFunc(handler.ReportNoShow))),
 	).Methods("POST")
 
+	r.Handle("/api/bookings/{id:[0-9]+}/history",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner, authz.RoleSitter, authz.RoleAdmin)(http.HandlerFunc(handler.GetBookingHistory))),
+	).Methods("GET")
+
 	r.HandleFunc("/api/bookings/{id:[0-9]+}", handler.GetBooking).Methods("GET")
 	r.HandleFunc("/api/owners/{owner_id:[0-9]+}/bookings", handler.GetOwnerBookings).Methods("GET")
 	r.HandleFunc("/api/sitters/{sitter_id:[0-9]+}/bookings", handler.GetSitterBookings).Methods("GET")
+
+	return service
 }
 
 func setupReviewsModule(r *mux.Router, db *database.Database) {
@@ -219,75 +405,100 @@ func setupReviewsModule(r *mux.Router, db *database.Database) {
 	handler := reviews.NewHandler(service)
 
 	r.Handle("/api/reviews",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.CreateReview)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner)(http.HandlerFunc(handler.CreateReview))),
 	).Methods("POST")
 
 	r.Handle("/api/reviews/{id:[0-9]+}",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.UpdateReview)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner)(http.HandlerFunc(handler.UpdateReview))),
 	).Methods("PUT")
 
 	r.Handle("/api/reviews/{id:[0-9]+}",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.DeleteReview)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleOwner)(http.HandlerFunc(handler.DeleteReview))),
 	).Methods("DELETE")
 
+	r.Handle("/api/reviews/{id:[0-9]+}/reply",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.ReplyToReview))),
+	).Methods("POST")
+
+	r.Handle("/api/reviews/{id:[0-9]+}/flag",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.FlagReview))),
+	).Methods("POST")
+
+	ar := r.PathPrefix("/api/admin/reviews").Subrouter()
+	ar.Use(middleware.AuthMiddleware, middleware.RequireRole(authz.RoleAdmin))
+
+	ar.HandleFunc("/flagged", handler.GetModerationQueue).Methods("GET")
+	ar.HandleFunc("/{id:[0-9]+}/moderate", handler.ModerateReview).Methods("POST")
+	ar.HandleFunc("/{id:[0-9]+}/moderation", handler.GetModerationHistory).Methods("GET")
+
 	r.HandleFunc("/api/reviews/{id:[0-9]+}", handler.GetReview).Methods("GET")
 	r.HandleFunc("/api/sitters/{sitter_id:[0-9]+}/reviews", handler.GetSitterReviews).Methods("GET")
 	r.HandleFunc("/api/sitters/{sitter_id:[0-9]+}/rating", handler.GetSitterRating).Methods("GET")
+	r.HandleFunc("/api/sitters/{sitter_id:[0-9]+}/rating/summary", handler.GetRatingSummary).Methods("GET")
 	r.HandleFunc("/api/bookings/{booking_id:[0-9]+}/review", handler.GetBookingReview).Methods("GET")
 }
 
-func setupServicesModule(r *mux.Router, db *database.Database) {
+func setupServicesModule(r *mux.Router, db *database.Database, places locations.Service) {
 	repo := services.NewRepository(db.DB)
-	service := services.NewService(repo)
+	service := services.NewService(repo, places)
 	handler := services.NewHandler(service)
 
-	r.HandleFunc("/api/services/search", handler.SearchServices).Methods("GET")
+	r.Handle("/api/services/search", middleware.OptionalAuth(http.HandlerFunc(handler.SearchServices))).Methods("GET")
 	r.HandleFunc("/api/sitters/{sitter_id:[0-9]+}/services", handler.GetSitterServices).Methods("GET")
 	r.HandleFunc("/api/services/{id:[0-9]+}", handler.GetService).Methods("GET")
 
 	r.Handle("/api/services",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.CreateService)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.CreateService))),
 	).Methods("POST")
 
 	r.Handle("/api/services/{id:[0-9]+}",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.UpdateService)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.UpdateService))),
 	).Methods("PUT")
 
 	r.Handle("/api/services/{id:[0-9]+}",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.DeleteService)),
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.DeleteService))),
 	).Methods("DELETE")
 }
 
-func setupAdminModule(r *mux.Router, db *database.Database) {
+func setupAdminModule(r *mux.Router, db *database.Database, mail mailer.Mailer, store storage.Store) {
 	repo := admin.NewRepository(db.DB)
-	service := admin.NewService(repo)
+	service := admin.NewService(repo, mail, store)
 	handler := admin.NewHandler(service)
 
-	r.Handle("/api/admin/sitters/pending",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.GetPendingSitters)),
-	).Methods("GET")
-
-	r.Handle("/api/admin/sitters/{sitter_id:[0-9]+}/approve",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.ApproveSitter)),
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/documents",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.UploadSitterDocument))),
 	).Methods("POST")
 
-	r.Handle("/api/admin/sitters/{sitter_id:[0-9]+}/reject",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.RejectSitter)),
-	).Methods("POST")
-
-	r.Handle("/api/admin/sitters/{sitter_id:[0-9]+}",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.GetSitterDetails)),
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/documents",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter, authz.RoleAdmin)(http.HandlerFunc(handler.GetSitterDocuments))),
 	).Methods("GET")
 
-	r.Handle("/api/admin/users",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.GetAllUsers)),
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/documents/{document_id:[0-9]+}/file",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter, authz.RoleAdmin)(http.HandlerFunc(handler.DownloadSitterDocument))),
 	).Methods("GET")
 
-	r.Handle("/api/admin/users/{user_id:[0-9]+}",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.GetUser)),
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/status-history",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter, authz.RoleAdmin)(http.HandlerFunc(handler.GetSitterStatusHistory))),
 	).Methods("GET")
 
-	r.Handle("/api/admin/users/{user_id:[0-9]+}",
-		middleware.AuthMiddleware(http.HandlerFunc(handler.DeleteUser)),
-	).Methods("DELETE")
+	r.Handle("/api/sitters/{sitter_id:[0-9]+}/resubmit",
+		middleware.AuthMiddleware(middleware.RequireRole(authz.RoleSitter)(http.HandlerFunc(handler.ResubmitSitter))),
+	).Methods("POST")
+
+	ar := r.PathPrefix("/api/admin").Subrouter()
+	ar.Use(middleware.AuthMiddleware, middleware.RequireRole(authz.RoleAdmin))
+
+	ar.HandleFunc("/sitters/pending", handler.GetPendingSitters).Methods("GET")
+	ar.HandleFunc("/sitters/{sitter_id:[0-9]+}/approve", handler.ApproveSitter).Methods("POST")
+	ar.HandleFunc("/sitters/{sitter_id:[0-9]+}/reject", handler.RejectSitter).Methods("POST")
+	ar.HandleFunc("/sitters/{sitter_id:[0-9]+}/request-changes", handler.RequestSitterChanges).Methods("POST")
+	ar.HandleFunc("/sitters/{sitter_id:[0-9]+}/notes", handler.AddSitterNote).Methods("POST")
+	ar.HandleFunc("/sitters/{sitter_id:[0-9]+}", handler.GetSitterDetails).Methods("GET")
+	ar.HandleFunc("/users", handler.GetAllUsers).Methods("GET")
+	ar.HandleFunc("/users/{user_id:[0-9]+}", handler.GetUser).Methods("GET")
+	ar.HandleFunc("/users/{user_id:[0-9]+}", handler.DeleteUser).Methods("DELETE")
+	ar.HandleFunc("/users/{user_id:[0-9]+}/suspend", handler.SuspendUser).Methods("POST")
+	ar.HandleFunc("/users/{user_id:[0-9]+}/ban", handler.BanUser).Methods("POST")
+	ar.HandleFunc("/users/{user_id:[0-9]+}/reinstate", handler.ReinstateUser).Methods("POST")
+	ar.HandleFunc("/stats", handler.GetStats).Methods("GET")
 }
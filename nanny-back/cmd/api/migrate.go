@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/migrate"
+	"nanny-backend/migrations"
+	"nanny-backend/pkg/config"
+)
+
+const migrateUsage = `usage: nanny-backend migrate <command>
+
+commands:
+  up                 apply all pending migrations
+  down [N]           roll back the last N migrations (default 1)
+  status             show the current and pending versions
+  create NAME        add empty up/down files to the migrations directory
+                     (-dir DIR, default "migrations")
+  force VERSION      set the version and clear the dirty flag without running SQL`
+
+// runMigrate implements the migrate subcommand and returns the exit code.
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	if args[0] == "create" {
+		return createMigration(args[1:])
+	}
+
+	db, err := connectWithRetry(cfg.Database.ConnectionString(), 3, time.Second)
+	if err != nil {
+		log.Printf("❌ Failed to connect to database: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db.DB, migrations.FS)
+	if err != nil {
+		log.Printf("❌ Failed to load migrations: %v", err)
+		return 1
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Printf("❌ Migration failed: %v", err)
+			return 1
+		}
+		log.Printf("✅ Applied %d migration(s)", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				log.Printf("❌ Invalid number of steps: %s", args[1])
+				return 2
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		if errors.Is(err, migrate.ErrNoChange) {
+			log.Println("Nothing to roll back")
+			return 0
+		}
+		if err != nil {
+			log.Printf("❌ Rollback failed: %v", err)
+			return 1
+		}
+		log.Printf("✅ Rolled back %d migration(s)", reverted)
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			log.Printf("❌ Failed to read status: %v", err)
+			return 1
+		}
+
+		fmt.Printf("current version: %d", status.Current)
+		if status.Dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Printf("\nlatest version:  %d\n", status.Latest)
+		for _, m := range status.Pending {
+			fmt.Printf("pending: %06d_%s\n", m.Version, m.Name)
+		}
+
+	case "force":
+		if len(args) < 2 {
+			fmt.Println(migrateUsage)
+			return 2
+		}
+
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			log.Printf("❌ Invalid version: %s", args[1])
+			return 2
+		}
+
+		if err := migrator.Force(ctx, version); err != nil {
+			log.Printf("❌ Force failed: %v", err)
+			return 1
+		}
+		log.Printf("✅ Schema version set to %d", version)
+
+	default:
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	return 0
+}
+
+func createMigration(args []string) int {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	dir := flags.String("dir", "migrations", "migrations directory")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	up, down, err := migrate.Create(*dir, flags.Arg(0))
+	if err != nil {
+		log.Printf("❌ Failed to create migration: %v", err)
+		return 1
+	}
+
+	fmt.Println(up)
+	fmt.Println(down)
+	return 0
+}
+
+// applyMigrations brings the schema up to date before the server starts.
+func applyMigrations(ctx context.Context, db *database.Database) error {
+	migrator, err := migrate.New(db.DB, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+
+	if applied > 0 {
+		log.Printf("✅ Applied %d migration(s)", applied)
+	}
+	return nil
+}
@@ -1,69 +1,154 @@
 package admin
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 
 	"github.com/gorilla/mux"
 )
 
 type mockAdminServiceForHandler struct {
-	getPendingSittersFunc func() ([]models.Sitter, error)
-	approveSitterFunc     func(int) error
-	rejectSitterFunc      func(int) error
-	getAllUsersFunc       func() ([]models.User, error)
+	getPendingSittersFunc func(listing.Query) (*listing.Page[models.Sitter], error)
+	approveSitterFunc     func(int, string) error
+	rejectSitterFunc      func(int, string) error
+	requestChangesFunc    func(int, string) error
+	resubmitSitterFunc    func(int) error
+	addSitterNoteFunc     func(int, string) (*SitterNote, error)
+	uploadDocumentFunc    func(int, string, string, io.Reader) (*SitterDocument, error)
+	openDocumentFunc      func(int, int) (*SitterDocument, io.ReadCloser, error)
+	getAllUsersFunc       func(listing.Query) (*listing.Page[models.User], error)
 	getUserFunc           func(int) (*models.User, error)
 	deleteUserFunc        func(int) error
+	suspendUserFunc       func(int, time.Time, string) error
+	banUserFunc           func(int, string) error
 	getSitterDetailsFunc  func(int) (*SitterDetails, error)
+	getStatsFunc          func(string, string) (*Stats, error)
 }
 
-func (m *mockAdminServiceForHandler) GetPendingSitters() ([]models.Sitter, error) {
+func (m *mockAdminServiceForHandler) GetStats(ctx context.Context, from, to string) (*Stats, error) {
+	if m.getStatsFunc != nil {
+		return m.getStatsFunc(from, to)
+	}
+	return &Stats{From: from, To: to}, nil
+}
+
+func (m *mockAdminServiceForHandler) GetPendingSitters(ctx context.Context, q listing.Query) (*listing.Page[models.Sitter], error) {
 	if m.getPendingSittersFunc != nil {
-		return m.getPendingSittersFunc()
+		return m.getPendingSittersFunc(q)
 	}
-	return []models.Sitter{{SitterID: 1}}, nil
+	return SitterListSpec.Page([]models.Sitter{{SitterID: 1}}, q), nil
 }
 
-func (m *mockAdminServiceForHandler) ApproveSitter(id int) error {
+func (m *mockAdminServiceForHandler) ApproveSitter(ctx context.Context, actor authz.Actor, id int, reason string) error {
 	if m.approveSitterFunc != nil {
-		return m.approveSitterFunc(id)
+		return m.approveSitterFunc(id, reason)
 	}
 	return nil
 }
 
-func (m *mockAdminServiceForHandler) RejectSitter(id int) error {
+func (m *mockAdminServiceForHandler) RejectSitter(ctx context.Context, actor authz.Actor, id int, reason string) error {
 	if m.rejectSitterFunc != nil {
-		return m.rejectSitterFunc(id)
+		return m.rejectSitterFunc(id, reason)
+	}
+	return nil
+}
+
+func (m *mockAdminServiceForHandler) RequestSitterChanges(ctx context.Context, actor authz.Actor, id int, reason string) error {
+	if m.requestChangesFunc != nil {
+		return m.requestChangesFunc(id, reason)
+	}
+	return nil
+}
+
+func (m *mockAdminServiceForHandler) ResubmitSitter(ctx context.Context, actor authz.Actor, id int) error {
+	if m.resubmitSitterFunc != nil {
+		return m.resubmitSitterFunc(id)
 	}
 	return nil
 }
 
-func (m *mockAdminServiceForHandler) GetAllUsers() ([]models.User, error) {
+func (m *mockAdminServiceForHandler) GetSitterStatusHistory(ctx context.Context, actor authz.Actor, id int) ([]StatusChange, error) {
+	return []StatusChange{}, nil
+}
+
+func (m *mockAdminServiceForHandler) AddSitterNote(ctx context.Context, actor authz.Actor, id int, body string) (*SitterNote, error) {
+	if m.addSitterNoteFunc != nil {
+		return m.addSitterNoteFunc(id, body)
+	}
+	return &SitterNote{NoteID: 1, SitterID: id, Body: body}, nil
+}
+
+func (m *mockAdminServiceForHandler) UploadSitterDocument(ctx context.Context, actor authz.Actor, id int, kind, fileName string, content io.Reader) (*SitterDocument, error) {
+	if m.uploadDocumentFunc != nil {
+		return m.uploadDocumentFunc(id, kind, fileName, content)
+	}
+	return &SitterDocument{DocumentID: 1, SitterID: id, Kind: kind, FileName: fileName}, nil
+}
+
+func (m *mockAdminServiceForHandler) GetSitterDocuments(ctx context.Context, actor authz.Actor, id int) ([]SitterDocument, error) {
+	return []SitterDocument{}, nil
+}
+
+func (m *mockAdminServiceForHandler) OpenSitterDocument(ctx context.Context, actor authz.Actor, id, documentID int) (*SitterDocument, io.ReadCloser, error) {
+	if m.openDocumentFunc != nil {
+		return m.openDocumentFunc(id, documentID)
+	}
+	return nil, nil, ErrDocumentNotFound
+}
+
+func (m *mockAdminServiceForHandler) GetAllUsers(ctx context.Context, q listing.Query) (*listing.Page[models.User], error) {
 	if m.getAllUsersFunc != nil {
-		return m.getAllUsersFunc()
+		return m.getAllUsersFunc(q)
 	}
-	return []models.User{{UserID: 1}}, nil
+	return UserListSpec.Page([]models.User{{UserID: 1}}, q), nil
 }
 
-func (m *mockAdminServiceForHandler) GetUser(id int) (*models.User, error) {
+func (m *mockAdminServiceForHandler) GetUser(ctx context.Context, id int) (*models.User, error) {
 	if m.getUserFunc != nil {
 		return m.getUserFunc(id)
 	}
 	return &models.User{UserID: id}, nil
 }
 
-func (m *mockAdminServiceForHandler) DeleteUser(id int) error {
+func (m *mockAdminServiceForHandler) DeleteUser(ctx context.Context, actor authz.Actor, id int) error {
 	if m.deleteUserFunc != nil {
 		return m.deleteUserFunc(id)
 	}
 	return nil
 }
 
-func (m *mockAdminServiceForHandler) GetSitterDetails(id int) (*SitterDetails, error) {
+func (m *mockAdminServiceForHandler) SuspendUser(ctx context.Context, actor authz.Actor, id int, until time.Time, reason string) error {
+	if m.suspendUserFunc != nil {
+		return m.suspendUserFunc(id, until, reason)
+	}
+	return nil
+}
+
+func (m *mockAdminServiceForHandler) BanUser(ctx context.Context, actor authz.Actor, id int, reason string) error {
+	if m.banUserFunc != nil {
+		return m.banUserFunc(id, reason)
+	}
+	return nil
+}
+
+func (m *mockAdminServiceForHandler) ReinstateUser(ctx context.Context, actor authz.Actor, id int) error {
+	return nil
+}
+
+func (m *mockAdminServiceForHandler) GetSitterDetails(ctx context.Context, id int) (*SitterDetails, error) {
 	if m.getSitterDetailsFunc != nil {
 		return m.getSitterDetailsFunc(id)
 	}
@@ -72,8 +157,8 @@ func (m *mockAdminServiceForHandler) GetSitterDetails(id int) (*SitterDetails, e
 
 func TestGetPendingSittersHandler(t *testing.T) {
 	mockSvc := &mockAdminServiceForHandler{
-		getPendingSittersFunc: func() ([]models.Sitter, error) {
-			return []models.Sitter{{SitterID: 1}, {SitterID: 2}}, nil
+		getPendingSittersFunc: func(q listing.Query) (*listing.Page[models.Sitter], error) {
+			return SitterListSpec.Page([]models.Sitter{{SitterID: 1}, {SitterID: 2}}, q), nil
 		},
 	}
 
@@ -90,7 +175,7 @@ func TestGetPendingSittersHandler(t *testing.T) {
 
 func TestGetPendingSittersHandler_Error(t *testing.T) {
 	mockSvc := &mockAdminServiceForHandler{
-		getPendingSittersFunc: func() ([]models.Sitter, error) {
+		getPendingSittersFunc: func(q listing.Query) (*listing.Page[models.Sitter], error) {
 			return nil, errors.New("database error")
 		},
 	}
@@ -108,7 +193,7 @@ func TestGetPendingSittersHandler_Error(t *testing.T) {
 
 func TestApproveSitterHandler(t *testing.T) {
 	mockSvc := &mockAdminServiceForHandler{
-		approveSitterFunc: func(id int) error {
+		approveSitterFunc: func(id int, reason string) error {
 			return nil
 		},
 	}
@@ -142,8 +227,8 @@ func TestApproveSitterHandler_InvalidID(t *testing.T) {
 
 func TestApproveSitterHandler_ServiceError(t *testing.T) {
 	mockSvc := &mockAdminServiceForHandler{
-		approveSitterFunc: func(id int) error {
-			return errors.New("already approved")
+		approveSitterFunc: func(id int, reason string) error {
+			return ErrSitterNotPending
 		},
 	}
 
@@ -154,20 +239,22 @@ func TestApproveSitterHandler_ServiceError(t *testing.T) {
 
 	handler.ApproveSitter(rr, req)
 
-	if rr.Code != http.StatusBadRequest {
-		t.Errorf("expected status 400, got %d", rr.Code)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rr.Code)
 	}
 }
 
 func TestRejectSitterHandler(t *testing.T) {
+	var gotReason string
 	mockSvc := &mockAdminServiceForHandler{
-		rejectSitterFunc: func(id int) error {
+		rejectSitterFunc: func(id int, reason string) error {
+			gotReason = reason
 			return nil
 		},
 	}
 
 	handler := NewHandler(mockSvc)
-	req := httptest.NewRequest(http.MethodPut, "/api/admin/sitters/1/reject", nil)
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/sitters/1/reject", strings.NewReader(`{"reason":" no certificate "}`))
 	req = mux.SetURLVars(req, map[string]string{"sitter_id": "1"})
 	rr := httptest.NewRecorder()
 
@@ -176,12 +263,15 @@ func TestRejectSitterHandler(t *testing.T) {
 	if rr.Code != http.StatusOK {
 		t.Errorf("expected status 200, got %d", rr.Code)
 	}
+	if gotReason != "no certificate" {
+		t.Errorf("expected reason 'no certificate', got %q", gotReason)
+	}
 }
 
 func TestGetAllUsersHandler(t *testing.T) {
 	mockSvc := &mockAdminServiceForHandler{
-		getAllUsersFunc: func() ([]models.User, error) {
-			return []models.User{{UserID: 1}, {UserID: 2}}, nil
+		getAllUsersFunc: func(q listing.Query) (*listing.Page[models.User], error) {
+			return UserListSpec.Page([]models.User{{UserID: 1}, {UserID: 2}}, q), nil
 		},
 	}
 
@@ -218,7 +308,7 @@ func TestGetUserHandler(t *testing.T) {
 func TestGetUserHandler_NotFound(t *testing.T) {
 	mockSvc := &mockAdminServiceForHandler{
 		getUserFunc: func(id int) (*models.User, error) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		},
 	}
 
@@ -292,8 +382,8 @@ func TestRejectSitterHandler_InvalidID(t *testing.T) {
 
 func TestRejectSitterHandler_ServiceError(t *testing.T) {
 	mockSvc := &mockAdminServiceForHandler{
-		rejectSitterFunc: func(id int) error {
-			return errors.New("rejection failed")
+		rejectSitterFunc: func(id int, reason string) error {
+			return ErrSitterNotPending
 		},
 	}
 
@@ -304,14 +394,14 @@ func TestRejectSitterHandler_ServiceError(t *testing.T) {
 
 	handler.RejectSitter(rr, req)
 
-	if rr.Code != http.StatusBadRequest {
-		t.Errorf("expected status 400, got %d", rr.Code)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rr.Code)
 	}
 }
 
 func TestGetAllUsersHandler_Error(t *testing.T) {
 	mockSvc := &mockAdminServiceForHandler{
-		getAllUsersFunc: func() ([]models.User, error) {
+		getAllUsersFunc: func(q listing.Query) (*listing.Page[models.User], error) {
 			return nil, errors.New("database error")
 		},
 	}
@@ -394,7 +484,7 @@ func TestGetSitterDetailsHandler_InvalidID(t *testing.T) {
 func TestGetSitterDetailsHandler_NotFound(t *testing.T) {
 	mockSvc := &mockAdminServiceForHandler{
 		getSitterDetailsFunc: func(id int) (*SitterDetails, error) {
-			return nil, errors.New("sitter not found")
+			return nil, ErrSitterNotFound
 		},
 	}
 
@@ -409,3 +499,229 @@ func TestGetSitterDetailsHandler_NotFound(t *testing.T) {
 		t.Errorf("expected status 404, got %d", rr.Code)
 	}
 }
+
+func TestRequestSitterChangesHandler(t *testing.T) {
+	var gotReason string
+	mockSvc := &mockAdminServiceForHandler{
+		requestChangesFunc: func(id int, reason string) error {
+			gotReason = reason
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockSvc)
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/sitters/1/request-changes", strings.NewReader(`{"reason":"upload your ID"}`))
+	req = mux.SetURLVars(req, map[string]string{"sitter_id": "1"})
+	rr := httptest.NewRecorder()
+
+	handler.RequestSitterChanges(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rr.Code)
+	}
+	if gotReason != "upload your ID" {
+		t.Errorf("expected reason 'upload your ID', got %q", gotReason)
+	}
+}
+
+func TestRequestSitterChangesHandler_ReasonRequired(t *testing.T) {
+	mockSvc := &mockAdminServiceForHandler{
+		requestChangesFunc: func(id int, reason string) error {
+			return ErrReasonRequired
+		},
+	}
+
+	handler := NewHandler(mockSvc)
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/sitters/1/request-changes", nil)
+	req = mux.SetURLVars(req, map[string]string{"sitter_id": "1"})
+	rr := httptest.NewRecorder()
+
+	handler.RequestSitterChanges(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestAddSitterNoteHandler_EmptyBody(t *testing.T) {
+	handler := NewHandler(&mockAdminServiceForHandler{})
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/sitters/1/notes", strings.NewReader(`{"body":""}`))
+	req = mux.SetURLVars(req, map[string]string{"sitter_id": "1"})
+	rr := httptest.NewRecorder()
+
+	handler.AddSitterNote(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func newUploadRequest(t *testing.T, kind, fileName string, content []byte) *http.Request {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField("kind", kind)
+	if fileName != "" {
+		part, err := form.CreateFormFile("file", fileName)
+		if err != nil {
+			t.Fatalf("failed to build form: %v", err)
+		}
+		part.Write(content)
+	}
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sitters/1/documents", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return mux.SetURLVars(req, map[string]string{"sitter_id": "1"})
+}
+
+func TestUploadSitterDocumentHandler(t *testing.T) {
+	var gotKind, gotName string
+	mockSvc := &mockAdminServiceForHandler{
+		uploadDocumentFunc: func(id int, kind, fileName string, content io.Reader) (*SitterDocument, error) {
+			gotKind, gotName = kind, fileName
+			return &SitterDocument{DocumentID: 1, SitterID: id}, nil
+		},
+	}
+
+	handler := NewHandler(mockSvc)
+	rr := httptest.NewRecorder()
+
+	handler.UploadSitterDocument(rr, newUploadRequest(t, DocumentCertificate, "first-aid.pdf", []byte("%PDF-1.4")))
+
+	if rr.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", rr.Code)
+	}
+	if gotKind != DocumentCertificate || gotName != "first-aid.pdf" {
+		t.Errorf("unexpected upload: kind %q, name %q", gotKind, gotName)
+	}
+}
+
+func TestUploadSitterDocumentHandler_MissingFile(t *testing.T) {
+	handler := NewHandler(&mockAdminServiceForHandler{})
+	rr := httptest.NewRecorder()
+
+	handler.UploadSitterDocument(rr, newUploadRequest(t, DocumentID, "", nil))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestDownloadSitterDocumentHandler(t *testing.T) {
+	mockSvc := &mockAdminServiceForHandler{
+		openDocumentFunc: func(id, documentID int) (*SitterDocument, io.ReadCloser, error) {
+			doc := &SitterDocument{DocumentID: documentID, ContentType: "application/pdf", FileName: "id.pdf", SizeBytes: 8}
+			return doc, io.NopCloser(strings.NewReader("%PDF-1.4")), nil
+		},
+	}
+
+	handler := NewHandler(mockSvc)
+	req := httptest.NewRequest(http.MethodGet, "/api/sitters/1/documents/2/file", nil)
+	req = mux.SetURLVars(req, map[string]string{"sitter_id": "1", "document_id": "2"})
+	rr := httptest.NewRecorder()
+
+	handler.DownloadSitterDocument(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename=id.pdf` {
+		t.Errorf("unexpected Content-Disposition %q", got)
+	}
+	if rr.Body.String() != "%PDF-1.4" {
+		t.Errorf("unexpected body %q", rr.Body.String())
+	}
+}
+
+func TestSuspendUserHandler(t *testing.T) {
+	var gotUntil time.Time
+	var gotReason string
+	mockSvc := &mockAdminServiceForHandler{
+		suspendUserFunc: func(id int, until time.Time, reason string) error {
+			gotUntil, gotReason = until, reason
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockSvc)
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users/5/suspend",
+		strings.NewReader(`{"until":"2030-01-02T15:04:05Z","reason":"spam in chats"}`))
+	req = mux.SetURLVars(req, map[string]string{"user_id": "5"})
+	rr := httptest.NewRecorder()
+
+	handler.SuspendUser(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rr.Code)
+	}
+	if !gotUntil.Equal(time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)) || gotReason != "spam in chats" {
+		t.Errorf("unexpected suspension: until %v, reason %q", gotUntil, gotReason)
+	}
+}
+
+func TestBanUserHandler_ReasonRequired(t *testing.T) {
+	mockSvc := &mockAdminServiceForHandler{
+		banUserFunc: func(id int, reason string) error {
+			t.Error("service should not be called")
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockSvc)
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users/5/ban", strings.NewReader(`{}`))
+	req = mux.SetURLVars(req, map[string]string{"user_id": "5"})
+	rr := httptest.NewRecorder()
+
+	handler.BanUser(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestGetStatsHandler(t *testing.T) {
+	mockSvc := &mockAdminServiceForHandler{
+		getStatsFunc: func(from, to string) (*Stats, error) {
+			if from != "2026-01-01" || to != "2026-01-31" {
+				t.Errorf("expected range 2026-01-01..2026-01-31, got %s..%s", from, to)
+			}
+			return &Stats{From: from, To: to, BookingTotals: BookingTotals{Bookings: 4, Cancelled: 1, CancellationRate: 0.25}}, nil
+		},
+	}
+
+	handler := NewHandler(mockSvc)
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/stats?from=2026-01-01&to=2026-01-31", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetStats(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+
+	var stats Stats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if stats.BookingTotals.CancellationRate != 0.25 {
+		t.Errorf("expected cancellation rate 0.25, got %v", stats.BookingTotals.CancellationRate)
+	}
+}
+
+func TestGetStatsHandler_InvalidRange(t *testing.T) {
+	mockSvc := &mockAdminServiceForHandler{
+		getStatsFunc: func(from, to string) (*Stats, error) {
+			return nil, ErrInvalidStatsRange
+		},
+	}
+
+	handler := NewHandler(mockSvc)
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/stats?from=yesterday", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetStats(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
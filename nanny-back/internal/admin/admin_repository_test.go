@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
@@ -9,6 +10,16 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 )
 
+var (
+	sitterQuery, _ = SitterListSpec.Parse(nil)
+	userQuery, _   = UserListSpec.Parse(nil)
+)
+
+var userRowColumns = []string{
+	"user_id", "full_name", "email", "phone", "role", "created_at",
+	"suspended_until", "banned_at", "restriction_reason", "deleted_at",
+}
+
 func TestGetPendingSittersRepository(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -25,12 +36,12 @@ func TestGetPendingSittersRepository(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM sitters WHERE status").
 		WillReturnRows(rows)
 
-	sitters, err := repo.GetPendingSitters()
+	page, err := repo.GetPendingSitters(context.Background(), sitterQuery)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	if len(sitters) != 2 {
-		t.Errorf("expected 2 sitters, got %d", len(sitters))
+	if len(page.Items) != 2 {
+		t.Errorf("expected 2 sitters, got %d", len(page.Items))
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -50,7 +61,7 @@ func TestGetPendingSittersRepository_Error(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM sitters WHERE status").
 		WillReturnError(errors.New("database error"))
 
-	_, err = repo.GetPendingSitters()
+	_, err = repo.GetPendingSitters(context.Background(), sitterQuery)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -75,7 +86,7 @@ func TestGetPendingSittersRepository_ScanError(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM sitters WHERE status").
 		WillReturnRows(rows)
 
-	_, err = repo.GetPendingSitters()
+	_, err = repo.GetPendingSitters(context.Background(), sitterQuery)
 	if err == nil {
 		t.Error("expected scan error, got nil")
 	}
@@ -85,7 +96,7 @@ func TestGetPendingSittersRepository_ScanError(t *testing.T) {
 	}
 }
 
-func TestApproveSitterRepository(t *testing.T) {
+func TestChangeSitterStatusRepository(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
@@ -94,21 +105,35 @@ func TestApproveSitterRepository(t *testing.T) {
 
 	repo := NewRepository(db)
 
-	mock.ExpectExec("UPDATE sitters SET status").
-		WithArgs(1).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	adminID := 100
+	now := time.Now()
+	change := &StatusChange{
+		SitterID:   1,
+		FromStatus: StatusPending,
+		ToStatus:   StatusRejected,
+		Decision:   DecisionReject,
+		Reason:     "missing certificate",
+		ActorID:    &adminID,
+	}
 
-	err = repo.ApproveSitter(1)
+	mock.ExpectQuery("UPDATE sitters(.|\\n)*INSERT INTO sitter_status_history").
+		WithArgs(1, StatusPending, StatusRejected, DecisionReject, "missing certificate", &adminID).
+		WillReturnRows(sqlmock.NewRows([]string{"history_id", "created_at"}).AddRow(7, now))
+
+	err = repo.ChangeSitterStatus(context.Background(), change)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
+	if change.HistoryID != 7 {
+		t.Errorf("expected history ID 7, got %d", change.HistoryID)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unmet expectations: %v", err)
 	}
 }
 
-func TestApproveSitterRepository_Error(t *testing.T) {
+func TestChangeSitterStatusRepository_StatusChanged(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
@@ -117,13 +142,12 @@ func TestApproveSitterRepository_Error(t *testing.T) {
 
 	repo := NewRepository(db)
 
-	mock.ExpectExec("UPDATE sitters SET status").
-		WithArgs(1).
-		WillReturnError(errors.New("db error"))
+	mock.ExpectQuery("UPDATE sitters").
+		WillReturnRows(sqlmock.NewRows([]string{"history_id", "created_at"}))
 
-	err = repo.ApproveSitter(1)
-	if err == nil {
-		t.Error("expected error, got nil")
+	err = repo.ChangeSitterStatus(context.Background(), &StatusChange{SitterID: 1, FromStatus: StatusPending, ToStatus: StatusApproved})
+	if !errors.Is(err, ErrSitterStatusChanged) {
+		t.Errorf("expected ErrSitterStatusChanged, got %v", err)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -131,7 +155,7 @@ func TestApproveSitterRepository_Error(t *testing.T) {
 	}
 }
 
-func TestRejectSitterRepository(t *testing.T) {
+func TestGetDocumentsRepository(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
@@ -140,21 +164,27 @@ func TestRejectSitterRepository(t *testing.T) {
 
 	repo := NewRepository(db)
 
-	mock.ExpectExec("UPDATE sitters SET status").
+	rows := sqlmock.NewRows([]string{"document_id", "sitter_id", "kind", "file_name", "content_type", "size_bytes", "storage_key", "uploaded_at"}).
+		AddRow(1, 1, DocumentCertificate, "first-aid.pdf", "application/pdf", 2048, "sitters/1/a.pdf", time.Now())
+
+	mock.ExpectQuery("SELECT (.+) FROM sitter_documents").
 		WithArgs(1).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+		WillReturnRows(rows)
 
-	err = repo.RejectSitter(1)
+	docs, err := repo.GetDocuments(context.Background(), 1)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
+	if len(docs) != 1 || docs[0].StorageKey != "sitters/1/a.pdf" {
+		t.Errorf("unexpected documents: %+v", docs)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unmet expectations: %v", err)
 	}
 }
 
-func TestRejectSitterRepository_Error(t *testing.T) {
+func TestGetDocumentRepository_NotFound(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
@@ -163,13 +193,13 @@ func TestRejectSitterRepository_Error(t *testing.T) {
 
 	repo := NewRepository(db)
 
-	mock.ExpectExec("UPDATE sitters SET status").
-		WithArgs(1).
-		WillReturnError(errors.New("db error"))
+	mock.ExpectQuery("SELECT (.+) FROM sitter_documents").
+		WithArgs(1, 5).
+		WillReturnError(sql.ErrNoRows)
 
-	err = repo.RejectSitter(1)
-	if err == nil {
-		t.Error("expected error, got nil")
+	_, err = repo.GetDocument(context.Background(), 1, 5)
+	if !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("expected ErrDocumentNotFound, got %v", err)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -188,19 +218,19 @@ func TestGetAllUsersRepository(t *testing.T) {
 
 	// ВАЖНО: используем time.Time вместо строки
 	now := time.Now()
-	rows := sqlmock.NewRows([]string{"user_id", "full_name", "email", "phone", "role", "created_at"}).
-		AddRow(1, "User One", "user1@test.com", "123456", "owner", now).
-		AddRow(2, "User Two", "user2@test.com", "654321", "sitter", now)
+	rows := sqlmock.NewRows(userRowColumns).
+		AddRow(1, "User One", "user1@test.com", "123456", "owner", now, nil, nil, "", nil).
+		AddRow(2, "User Two", "user2@test.com", "654321", "sitter", now, nil, nil, "", nil)
 
 	mock.ExpectQuery("SELECT (.+) FROM users").
 		WillReturnRows(rows)
 
-	users, err := repo.GetAllUsers()
+	page, err := repo.GetAllUsers(context.Background(), userQuery)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	if len(users) != 2 {
-		t.Errorf("expected 2 users, got %d", len(users))
+	if len(page.Items) != 2 {
+		t.Errorf("expected 2 users, got %d", len(page.Items))
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -220,7 +250,7 @@ func TestGetAllUsersRepository_Error(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM users").
 		WillReturnError(errors.New("database error"))
 
-	_, err = repo.GetAllUsers()
+	_, err = repo.GetAllUsers(context.Background(), userQuery)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -239,13 +269,13 @@ func TestGetAllUsersRepository_ScanError(t *testing.T) {
 
 	repo := NewRepository(db)
 
-	rows := sqlmock.NewRows([]string{"user_id", "full_name", "email", "phone", "role", "created_at"}).
-		AddRow("invalid", "User One", "user1@test.com", "123456", "owner", time.Now())
+	rows := sqlmock.NewRows(userRowColumns).
+		AddRow("invalid", "User One", "user1@test.com", "123456", "owner", time.Now(), nil, nil, "", nil)
 
 	mock.ExpectQuery("SELECT (.+) FROM users").
 		WillReturnRows(rows)
 
-	_, err = repo.GetAllUsers()
+	_, err = repo.GetAllUsers(context.Background(), userQuery)
 	if err == nil {
 		t.Error("expected scan error, got nil")
 	}
@@ -266,14 +296,14 @@ func TestGetUserByIDRepository(t *testing.T) {
 
 	// ВАЖНО: используем time.Time вместо строки
 	now := time.Now()
-	rows := sqlmock.NewRows([]string{"user_id", "full_name", "email", "phone", "role", "created_at"}).
-		AddRow(1, "Test User", "test@example.com", "1234567890", "owner", now)
+	rows := sqlmock.NewRows(userRowColumns).
+		AddRow(1, "Test User", "test@example.com", "1234567890", "owner", now, nil, nil, "", nil)
 
 	mock.ExpectQuery("SELECT (.+) FROM users WHERE user_id").
 		WithArgs(1).
 		WillReturnRows(rows)
 
-	user, err := repo.GetUserByID(1)
+	user, err := repo.GetUserByID(context.Background(), 1)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -302,7 +332,7 @@ func TestGetUserByIDNotFound(t *testing.T) {
 		WithArgs(999).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err = repo.GetUserByID(999)
+	_, err = repo.GetUserByID(context.Background(), 999)
 	if err == nil {
 		t.Error("expected error for non-existent user")
 	}
@@ -325,7 +355,7 @@ func TestGetUserByIDRepository_Error(t *testing.T) {
 		WithArgs(1).
 		WillReturnError(errors.New("database error"))
 
-	_, err = repo.GetUserByID(1)
+	_, err = repo.GetUserByID(context.Background(), 1)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -344,11 +374,18 @@ func TestDeleteUserRepository(t *testing.T) {
 
 	repo := NewRepository(db)
 
-	mock.ExpectExec("DELETE FROM users WHERE user_id").
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users(.|\\n)*deleted_at = NOW\\(\\)").
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	err = repo.DeleteUser(1)
+	mock.ExpectExec("UPDATE sessions SET revoked_at").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM user_tokens").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM owner_locations").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM sitter_documents").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE sitters").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = repo.DeleteUser(context.Background(), 1)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -367,11 +404,13 @@ func TestDeleteUserRepository_Error(t *testing.T) {
 
 	repo := NewRepository(db)
 
-	mock.ExpectExec("DELETE FROM users WHERE user_id").
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").
 		WithArgs(1).
 		WillReturnError(errors.New("db error"))
+	mock.ExpectRollback()
 
-	err = repo.DeleteUser(1)
+	err = repo.DeleteUser(context.Background(), 1)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -381,6 +420,63 @@ func TestDeleteUserRepository_Error(t *testing.T) {
 	}
 }
 
+func TestDeleteUserRepository_AlreadyDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.DeleteUser(context.Background(), 1)
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSuspendUserRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	until := time.Now().Add(24 * time.Hour)
+
+	mock.ExpectQuery("UPDATE users(.|\\n)*suspended_until = \\$2(.|\\n)*UPDATE sessions").
+		WithArgs(5, until, "spam").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	if err := repo.SuspendUser(context.Background(), 5, until, "spam"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mock.ExpectQuery("UPDATE users(.|\\n)*banned_at = NOW\\(\\)").
+		WithArgs(6, "fraud").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	if err := repo.BanUser(context.Background(), 6, "fraud"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 func TestGetSitterDetailsRepository(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -393,13 +489,14 @@ func TestGetSitterDetailsRepository(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"sitter_id", "experience_years", "certificates", "preferences", "location", "status",
 		"full_name", "email", "phone", "rating", "reviews",
-	}).AddRow(1, 5, "cert", "dogs", "Almaty", "approved", "John Doe", "john@test.com", "123", 4.5, 10)
+		"verified_reviews", "punctuality", "communication", "pet_care",
+	}).AddRow(1, 5, "cert", "dogs", "Almaty", "approved", "John Doe", "john@test.com", "123", 4.5, 10, 8, 4.8, 0.0, 5.0)
 
-	mock.ExpectQuery("SELECT (.+) FROM sitters").
+	mock.ExpectQuery("SELECT (.+) FROM sitters (.+) LEFT JOIN sitter_stats").
 		WithArgs(1).
 		WillReturnRows(rows)
 
-	details, err := repo.GetSitterDetails(1)
+	details, err := repo.GetSitterDetails(context.Background(), 1)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -409,6 +506,9 @@ func TestGetSitterDetailsRepository(t *testing.T) {
 	if details.FullName != "John Doe" {
 		t.Errorf("expected name 'John Doe', got '%s'", details.FullName)
 	}
+	if details.VerifiedReviews != 8 || details.Punctuality != 4.8 || details.PetCare != 5.0 {
+		t.Errorf("unexpected review averages: %+v", details)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unmet expectations: %v", err)
@@ -428,7 +528,7 @@ func TestGetSitterDetailsRepository_NotFound(t *testing.T) {
 		WithArgs(999).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err = repo.GetSitterDetails(999)
+	_, err = repo.GetSitterDetails(context.Background(), 999)
 	if err == nil {
 		t.Error("expected error for non-existent sitter")
 	}
@@ -451,7 +551,7 @@ func TestGetSitterDetailsRepository_Error(t *testing.T) {
 		WithArgs(1).
 		WillReturnError(errors.New("database error"))
 
-	_, err = repo.GetSitterDetails(1)
+	_, err = repo.GetSitterDetails(context.Background(), 1)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -474,7 +574,7 @@ func TestUpdateSitterStatusRepository(t *testing.T) {
 		WithArgs("approved", 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.UpdateSitterStatus(1, "approved")
+	err = repo.UpdateSitterStatus(context.Background(), 1, "approved")
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -497,7 +597,7 @@ func TestUpdateSitterStatusRepository_Error(t *testing.T) {
 		WithArgs("approved", 1).
 		WillReturnError(errors.New("db error"))
 
-	err = repo.UpdateSitterStatus(1, "approved")
+	err = repo.UpdateSitterStatus(context.Background(), 1, "approved")
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -506,3 +606,109 @@ func TestUpdateSitterStatusRepository_Error(t *testing.T) {
 		t.Errorf("unmet expectations: %v", err)
 	}
 }
+
+func TestGetStatsRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 31)
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE created_at >= (.+) GROUP BY day, role").
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "role", "count"}).
+			AddRow("2026-01-02", "owner", 3).
+			AddRow("2026-01-02", "sitter", 1))
+	mock.ExpectQuery("SELECT (.+) FROM bookings WHERE start_time >= (.+) GROUP BY day, status").
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "status", "count"}).
+			AddRow("2026-01-05", "completed", 3).
+			AddRow("2026-01-05", "cancelled_by_owner", 1))
+	mock.ExpectQuery("SELECT (.+) FROM bookings b LEFT JOIN services s").
+		WithArgs(from, to, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "cancelled", "gbv"}).AddRow(4, 1, 15000.0))
+	mock.ExpectQuery("SELECT status, COUNT(.+) FROM payments").
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count", "amount"}).
+			AddRow("paid", 2, 10000.0).
+			AddRow("refunded", 1, 5000.0))
+	mock.ExpectQuery("SELECT (.+) FROM payments p JOIN bookings b (.+) ORDER BY revenue DESC").
+		WithArgs(from, to, "paid", TopSittersLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"sitter_id", "full_name", "count", "revenue"}).
+			AddRow(7, "Aigerim", 2, 10000.0))
+	mock.ExpectQuery("SELECT (.+) FROM reviews r (.+) ORDER BY score DESC").
+		WithArgs(from, to, TopSittersLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"sitter_id", "full_name", "avg", "count", "score"}).
+			AddRow(7, "Aigerim", 5.0, 2, 4.29))
+	mock.ExpectQuery("SELECT (.+) AS city, s.type, COUNT(.+) FROM bookings b").
+		WithArgs(from, to, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"city", "type", "bookings"}).
+			AddRow("Almaty", "walking", 3))
+
+	stats, err := repo.GetStats(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(stats.Registrations) != 2 || stats.Registrations[0].Role != "owner" || stats.Registrations[0].Count != 3 {
+		t.Errorf("unexpected registrations: %+v", stats.Registrations)
+	}
+	if len(stats.Bookings) != 2 {
+		t.Errorf("expected 2 booking counts, got %d", len(stats.Bookings))
+	}
+	if stats.BookingTotals.CancellationRate != 0.25 || stats.BookingTotals.GrossBookingValue != 15000 {
+		t.Errorf("unexpected booking totals: %+v", stats.BookingTotals)
+	}
+	if len(stats.Payments) != 2 || stats.Payments[1].Status != "refunded" {
+		t.Errorf("unexpected payments: %+v", stats.Payments)
+	}
+	if len(stats.TopSittersByRevenue) != 1 || stats.TopSittersByRevenue[0].Revenue != 10000 {
+		t.Errorf("unexpected top sitters by revenue: %+v", stats.TopSittersByRevenue)
+	}
+	if len(stats.TopSittersByRating) != 1 || stats.TopSittersByRating[0].ReviewCount != 2 {
+		t.Errorf("unexpected top sitters by rating: %+v", stats.TopSittersByRating)
+	}
+	if len(stats.BusiestServices) != 1 || stats.BusiestServices[0].City != "Almaty" {
+		t.Errorf("unexpected busiest services: %+v", stats.BusiestServices)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGetStatsRepository_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	for i := 0; i < 7; i++ {
+		if i == 2 {
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"count", "cancelled", "gbv"}).AddRow(0, 0, 0.0))
+			continue
+		}
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"a"}))
+	}
+
+	stats, err := repo.GetStats(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.BookingTotals.CancellationRate != 0 {
+		t.Errorf("expected no cancellation rate without bookings, got %v", stats.BookingTotals.CancellationRate)
+	}
+	if stats.Registrations == nil || stats.TopSittersByRating == nil || stats.BusiestServices == nil {
+		t.Error("expected empty lists rather than nil")
+	}
+}
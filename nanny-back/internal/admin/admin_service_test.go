@@ -1,66 +1,91 @@
 package admin
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/mailer"
 	"nanny-backend/internal/common/models"
+	"nanny-backend/internal/common/storage"
 )
 
 type mockAdminRepository struct {
-	getPendingSittersFunc  func() ([]models.Sitter, error)
-	approveSitterFunc      func(int) error
-	rejectSitterFunc       func(int) error
-	getAllUsersFunc        func() ([]models.User, error)
+	getPendingSittersFunc  func(listing.Query) (*listing.Page[models.Sitter], error)
+	getAllUsersFunc        func(listing.Query) (*listing.Page[models.User], error)
 	getUserByIDFunc        func(int) (*models.User, error)
 	deleteUserFunc         func(int) error
+	suspendUserFunc        func(int, time.Time, string) error
+	banUserFunc            func(int, string) error
+	reinstateUserFunc      func(int) error
 	getSitterDetailsFunc   func(int) (*SitterDetails, error)
 	updateSitterStatusFunc func(int, string) error
+	changeSitterStatusFunc func(*StatusChange) error
+	getStatusHistoryFunc   func(int) ([]StatusChange, error)
+	addNoteFunc            func(*SitterNote) error
+	getNotesFunc           func(int) ([]SitterNote, error)
+	addDocumentFunc        func(*SitterDocument) error
+	getDocumentsFunc       func(int) ([]SitterDocument, error)
+	getDocumentFunc        func(int, int) (*SitterDocument, error)
+	getStatsFunc           func(time.Time, time.Time) (*Stats, error)
 }
 
-func (m *mockAdminRepository) GetPendingSitters() ([]models.Sitter, error) {
+func (m *mockAdminRepository) GetPendingSitters(ctx context.Context, q listing.Query) (*listing.Page[models.Sitter], error) {
 	if m.getPendingSittersFunc != nil {
-		return m.getPendingSittersFunc()
+		return m.getPendingSittersFunc(q)
 	}
-	return []models.Sitter{{SitterID: 1, Status: "pending"}}, nil
+	return SitterListSpec.Page([]models.Sitter{{SitterID: 1, Status: "pending"}}, q), nil
 }
 
-func (m *mockAdminRepository) ApproveSitter(sitterID int) error {
-	if m.approveSitterFunc != nil {
-		return m.approveSitterFunc(sitterID)
+func (m *mockAdminRepository) GetAllUsers(ctx context.Context, q listing.Query) (*listing.Page[models.User], error) {
+	if m.getAllUsersFunc != nil {
+		return m.getAllUsersFunc(q)
 	}
-	return nil
+	return UserListSpec.Page([]models.User{{UserID: 1, Email: "test@example.com"}}, q), nil
 }
 
-func (m *mockAdminRepository) RejectSitter(sitterID int) error {
-	if m.rejectSitterFunc != nil {
-		return m.rejectSitterFunc(sitterID)
+func (m *mockAdminRepository) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
+	if m.getUserByIDFunc != nil {
+		return m.getUserByIDFunc(userID)
+	}
+	return &models.User{UserID: userID, Email: "test@example.com"}, nil
+}
+
+func (m *mockAdminRepository) DeleteUser(ctx context.Context, userID int) error {
+	if m.deleteUserFunc != nil {
+		return m.deleteUserFunc(userID)
 	}
 	return nil
 }
 
-func (m *mockAdminRepository) GetAllUsers() ([]models.User, error) {
-	if m.getAllUsersFunc != nil {
-		return m.getAllUsersFunc()
+func (m *mockAdminRepository) SuspendUser(ctx context.Context, userID int, until time.Time, reason string) error {
+	if m.suspendUserFunc != nil {
+		return m.suspendUserFunc(userID, until, reason)
 	}
-	return []models.User{{UserID: 1, Email: "test@example.com"}}, nil
+	return nil
 }
 
-func (m *mockAdminRepository) GetUserByID(userID int) (*models.User, error) {
-	if m.getUserByIDFunc != nil {
-		return m.getUserByIDFunc(userID)
+func (m *mockAdminRepository) BanUser(ctx context.Context, userID int, reason string) error {
+	if m.banUserFunc != nil {
+		return m.banUserFunc(userID, reason)
 	}
-	return &models.User{UserID: userID, Email: "test@example.com"}, nil
+	return nil
 }
 
-func (m *mockAdminRepository) DeleteUser(userID int) error {
-	if m.deleteUserFunc != nil {
-		return m.deleteUserFunc(userID)
+func (m *mockAdminRepository) ReinstateUser(ctx context.Context, userID int) error {
+	if m.reinstateUserFunc != nil {
+		return m.reinstateUserFunc(userID)
 	}
 	return nil
 }
 
-func (m *mockAdminRepository) GetSitterDetails(sitterID int) (*SitterDetails, error) {
+func (m *mockAdminRepository) GetSitterDetails(ctx context.Context, sitterID int) (*SitterDetails, error) {
 	if m.getSitterDetailsFunc != nil {
 		return m.getSitterDetailsFunc(sitterID)
 	}
@@ -71,40 +96,114 @@ func (m *mockAdminRepository) GetSitterDetails(sitterID int) (*SitterDetails, er
 	}, nil
 }
 
-func (m *mockAdminRepository) UpdateSitterStatus(sitterID int, status string) error {
+func (m *mockAdminRepository) UpdateSitterStatus(ctx context.Context, sitterID int, status string) error {
 	if m.updateSitterStatusFunc != nil {
 		return m.updateSitterStatusFunc(sitterID, status)
 	}
 	return nil
 }
 
+func (m *mockAdminRepository) ChangeSitterStatus(ctx context.Context, change *StatusChange) error {
+	if m.changeSitterStatusFunc != nil {
+		return m.changeSitterStatusFunc(change)
+	}
+	return nil
+}
+
+func (m *mockAdminRepository) GetStatusHistory(ctx context.Context, sitterID int) ([]StatusChange, error) {
+	if m.getStatusHistoryFunc != nil {
+		return m.getStatusHistoryFunc(sitterID)
+	}
+	return []StatusChange{}, nil
+}
+
+func (m *mockAdminRepository) AddNote(ctx context.Context, note *SitterNote) error {
+	if m.addNoteFunc != nil {
+		return m.addNoteFunc(note)
+	}
+	return nil
+}
+
+func (m *mockAdminRepository) GetNotes(ctx context.Context, sitterID int) ([]SitterNote, error) {
+	if m.getNotesFunc != nil {
+		return m.getNotesFunc(sitterID)
+	}
+	return []SitterNote{}, nil
+}
+
+func (m *mockAdminRepository) AddDocument(ctx context.Context, doc *SitterDocument) error {
+	if m.addDocumentFunc != nil {
+		return m.addDocumentFunc(doc)
+	}
+	return nil
+}
+
+func (m *mockAdminRepository) GetDocuments(ctx context.Context, sitterID int) ([]SitterDocument, error) {
+	if m.getDocumentsFunc != nil {
+		return m.getDocumentsFunc(sitterID)
+	}
+	return []SitterDocument{}, nil
+}
+
+func (m *mockAdminRepository) GetDocument(ctx context.Context, sitterID, documentID int) (*SitterDocument, error) {
+	if m.getDocumentFunc != nil {
+		return m.getDocumentFunc(sitterID, documentID)
+	}
+	return nil, ErrDocumentNotFound
+}
+
+func (m *mockAdminRepository) GetStats(ctx context.Context, from, to time.Time) (*Stats, error) {
+	if m.getStatsFunc != nil {
+		return m.getStatsFunc(from, to)
+	}
+	return &Stats{}, nil
+}
+
+type recordingMailer struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return m.err
+}
+
+var adminActor = authz.Actor{UserID: 100, Role: authz.RoleAdmin}
+
+func newTestService(t *testing.T, repo Repository) Service {
+	return NewService(repo, &recordingMailer{}, storage.NewDiskStore(t.TempDir()))
+}
+
 func TestGetPendingSitters(t *testing.T) {
 	repo := &mockAdminRepository{
-		getPendingSittersFunc: func() ([]models.Sitter, error) {
-			return []models.Sitter{
+		getPendingSittersFunc: func(q listing.Query) (*listing.Page[models.Sitter], error) {
+			return SitterListSpec.Page([]models.Sitter{
 				{SitterID: 1, Status: "pending"},
 				{SitterID: 2, Status: "pending"},
-			}, nil
+			}, q), nil
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
+
+	q, _ := SitterListSpec.Parse(nil)
 
-	sitters, err := svc.GetPendingSitters()
+	page, err := svc.GetPendingSitters(context.Background(), q)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	if len(sitters) != 2 {
-		t.Errorf("expected 2 sitters, got %d", len(sitters))
+	if len(page.Items) != 2 {
+		t.Errorf("expected 2 sitters, got %d", len(page.Items))
 	}
 }
 
 func TestApproveSitter(t *testing.T) {
 	tests := []struct {
-		name              string
-		sitterID          int
-		mockGetDetails    func(int) (*SitterDetails, error)
-		mockApproveSitter func(int) error
-		expectError       bool
+		name             string
+		sitterID         int
+		mockGetDetails   func(int) (*SitterDetails, error)
+		mockChangeStatus func(*StatusChange) error
+		expectError      bool
 	}{
 		{
 			name:     "successful approval",
@@ -114,7 +213,7 @@ func TestApproveSitter(t *testing.T) {
 					Sitter: models.Sitter{SitterID: id, Status: "pending"},
 				}, nil
 			},
-			mockApproveSitter: func(id int) error {
+			mockChangeStatus: func(change *StatusChange) error {
 				return nil
 			},
 			expectError: false,
@@ -142,12 +241,12 @@ func TestApproveSitter(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockAdminRepository{
-				getSitterDetailsFunc: tt.mockGetDetails,
-				approveSitterFunc:    tt.mockApproveSitter,
+				getSitterDetailsFunc:   tt.mockGetDetails,
+				changeSitterStatusFunc: tt.mockChangeStatus,
 			}
-			svc := NewService(repo)
+			svc := newTestService(t, repo)
 
-			err := svc.ApproveSitter(tt.sitterID)
+			err := svc.ApproveSitter(context.Background(), adminActor, tt.sitterID, "")
 
 			if tt.expectError && err == nil {
 				t.Error("expected error, got nil")
@@ -164,7 +263,7 @@ func TestRejectSitter(t *testing.T) {
 		name             string
 		sitterID         int
 		mockGetDetails   func(int) (*SitterDetails, error)
-		mockRejectSitter func(int) error
+		mockChangeStatus func(*StatusChange) error
 		expectError      bool
 	}{
 		{
@@ -175,7 +274,7 @@ func TestRejectSitter(t *testing.T) {
 					Sitter: models.Sitter{SitterID: id, Status: "pending"},
 				}, nil
 			},
-			mockRejectSitter: func(id int) error {
+			mockChangeStatus: func(change *StatusChange) error {
 				return nil
 			},
 			expectError: false,
@@ -195,12 +294,12 @@ func TestRejectSitter(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockAdminRepository{
-				getSitterDetailsFunc: tt.mockGetDetails,
-				rejectSitterFunc:     tt.mockRejectSitter,
+				getSitterDetailsFunc:   tt.mockGetDetails,
+				changeSitterStatusFunc: tt.mockChangeStatus,
 			}
-			svc := NewService(repo)
+			svc := newTestService(t, repo)
 
-			err := svc.RejectSitter(tt.sitterID)
+			err := svc.RejectSitter(context.Background(), adminActor, tt.sitterID, "missing certificate")
 
 			if tt.expectError && err == nil {
 				t.Error("expected error, got nil")
@@ -214,21 +313,23 @@ func TestRejectSitter(t *testing.T) {
 
 func TestGetAllUsers(t *testing.T) {
 	repo := &mockAdminRepository{
-		getAllUsersFunc: func() ([]models.User, error) {
-			return []models.User{
+		getAllUsersFunc: func(q listing.Query) (*listing.Page[models.User], error) {
+			return UserListSpec.Page([]models.User{
 				{UserID: 1, Email: "user1@example.com", Role: "owner"},
 				{UserID: 2, Email: "user2@example.com", Role: "sitter"},
-			}, nil
+			}, q), nil
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
+
+	q, _ := UserListSpec.Parse(nil)
 
-	users, err := svc.GetAllUsers()
+	page, err := svc.GetAllUsers(context.Background(), q)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	if len(users) != 2 {
-		t.Errorf("expected 2 users, got %d", len(users))
+	if len(page.Items) != 2 {
+		t.Errorf("expected 2 users, got %d", len(page.Items))
 	}
 }
 
@@ -238,9 +339,9 @@ func TestGetUser(t *testing.T) {
 			return &models.User{UserID: userID, Email: "test@example.com"}, nil
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	user, err := svc.GetUser(1)
+	user, err := svc.GetUser(context.Background(), 1)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -284,9 +385,9 @@ func TestDeleteUser(t *testing.T) {
 				getUserByIDFunc: tt.mockGetUser,
 				deleteUserFunc:  tt.mockDeleteUser,
 			}
-			svc := NewService(repo)
+			svc := newTestService(t, repo)
 
-			err := svc.DeleteUser(tt.userID)
+			err := svc.DeleteUser(context.Background(), adminActor, tt.userID)
 
 			if tt.expectError && err == nil {
 				t.Error("expected error, got nil")
@@ -312,9 +413,9 @@ func TestGetSitterDetails(t *testing.T) {
 			}, nil
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	details, err := svc.GetSitterDetails(1)
+	details, err := svc.GetSitterDetails(context.Background(), 1)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -328,13 +429,13 @@ func TestGetSitterDetails(t *testing.T) {
 
 func TestGetPendingSitters_Error(t *testing.T) {
 	repo := &mockAdminRepository{
-		getPendingSittersFunc: func() ([]models.Sitter, error) {
+		getPendingSittersFunc: func(q listing.Query) (*listing.Page[models.Sitter], error) {
 			return nil, errors.New("database error")
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	_, err := svc.GetPendingSitters()
+	_, err := svc.GetPendingSitters(context.Background(), listing.Query{})
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -342,13 +443,13 @@ func TestGetPendingSitters_Error(t *testing.T) {
 
 func TestGetAllUsers_Error(t *testing.T) {
 	repo := &mockAdminRepository{
-		getAllUsersFunc: func() ([]models.User, error) {
+		getAllUsersFunc: func(q listing.Query) (*listing.Page[models.User], error) {
 			return nil, errors.New("database error")
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	_, err := svc.GetAllUsers()
+	_, err := svc.GetAllUsers(context.Background(), listing.Query{})
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -360,9 +461,9 @@ func TestGetUser_Error(t *testing.T) {
 			return nil, errors.New("user not found")
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	_, err := svc.GetUser(999)
+	_, err := svc.GetUser(context.Background(), 999)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -374,9 +475,9 @@ func TestGetSitterDetails_Error(t *testing.T) {
 			return nil, errors.New("sitter not found")
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	_, err := svc.GetSitterDetails(999)
+	_, err := svc.GetSitterDetails(context.Background(), 999)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -389,13 +490,13 @@ func TestApproveSitter_RepositoryError(t *testing.T) {
 				Sitter: models.Sitter{SitterID: id, Status: "pending"},
 			}, nil
 		},
-		approveSitterFunc: func(id int) error {
+		changeSitterStatusFunc: func(change *StatusChange) error {
 			return errors.New("repository error")
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	err := svc.ApproveSitter(1)
+	err := svc.ApproveSitter(context.Background(), adminActor, 1, "")
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -408,13 +509,13 @@ func TestRejectSitter_RepositoryError(t *testing.T) {
 				Sitter: models.Sitter{SitterID: id, Status: "pending"},
 			}, nil
 		},
-		rejectSitterFunc: func(id int) error {
+		changeSitterStatusFunc: func(change *StatusChange) error {
 			return errors.New("repository error")
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	err := svc.RejectSitter(1)
+	err := svc.RejectSitter(context.Background(), adminActor, 1, "missing certificate")
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -426,9 +527,9 @@ func TestRejectSitter_GetDetailsError(t *testing.T) {
 			return nil, errors.New("sitter not found")
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	err := svc.RejectSitter(1)
+	err := svc.RejectSitter(context.Background(), adminActor, 1, "missing certificate")
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -443,10 +544,503 @@ func TestDeleteUser_DeleteError(t *testing.T) {
 			return errors.New("delete failed")
 		},
 	}
-	svc := NewService(repo)
+	svc := newTestService(t, repo)
 
-	err := svc.DeleteUser(1)
+	err := svc.DeleteUser(context.Background(), adminActor, 1)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
 }
+
+func TestDecideSitter(t *testing.T) {
+	tests := []struct {
+		name       string
+		decide     func(Service) error
+		wantStatus string
+		wantErr    error
+		wantNotice bool
+		wantInMail string
+	}{
+		{
+			name: "approve without reason",
+			decide: func(svc Service) error {
+				return svc.ApproveSitter(context.Background(), adminActor, 1, "")
+			},
+			wantStatus: StatusApproved,
+			wantNotice: true,
+		},
+		{
+			name: "reject with reason",
+			decide: func(svc Service) error {
+				return svc.RejectSitter(context.Background(), adminActor, 1, "certificate expired")
+			},
+			wantStatus: StatusRejected,
+			wantNotice: true,
+			wantInMail: "certificate expired",
+		},
+		{
+			name: "request changes with reason",
+			decide: func(svc Service) error {
+				return svc.RequestSitterChanges(context.Background(), adminActor, 1, "upload your ID")
+			},
+			wantStatus: StatusChangesRequested,
+			wantNotice: true,
+			wantInMail: "upload your ID",
+		},
+		{
+			name: "reject without reason",
+			decide: func(svc Service) error {
+				return svc.RejectSitter(context.Background(), adminActor, 1, "")
+			},
+			wantErr: ErrReasonRequired,
+		},
+		{
+			name: "request changes without reason",
+			decide: func(svc Service) error {
+				return svc.RequestSitterChanges(context.Background(), adminActor, 1, "")
+			},
+			wantErr: ErrReasonRequired,
+		},
+		{
+			name: "not an admin",
+			decide: func(svc Service) error {
+				return svc.ApproveSitter(context.Background(), authz.Actor{UserID: 1, Role: authz.RoleSitter}, 1, "")
+			},
+			wantErr: authz.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorded *StatusChange
+			repo := &mockAdminRepository{
+				changeSitterStatusFunc: func(change *StatusChange) error {
+					recorded = change
+					return nil
+				},
+			}
+			mail := &recordingMailer{}
+			svc := NewService(repo, mail, storage.NewDiskStore(t.TempDir()))
+
+			err := tt.decide(svc)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if recorded != nil || len(mail.sent) != 0 {
+					t.Error("expected nothing to be recorded or sent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if recorded == nil || recorded.ToStatus != tt.wantStatus || recorded.FromStatus != StatusPending {
+				t.Fatalf("unexpected status change: %+v", recorded)
+			}
+			if recorded.ActorID == nil || *recorded.ActorID != adminActor.UserID {
+				t.Errorf("expected the admin to be recorded as actor")
+			}
+			if len(mail.sent) != 1 || mail.sent[0].To != "test@example.com" {
+				t.Fatalf("expected one mail to the sitter, got %+v", mail.sent)
+			}
+			if !strings.Contains(mail.sent[0].Body, tt.wantInMail) {
+				t.Errorf("expected mail to contain %q, got %q", tt.wantInMail, mail.sent[0].Body)
+			}
+		})
+	}
+}
+
+func TestDecideSitter_MailFailureIsNotAnError(t *testing.T) {
+	svc := NewService(&mockAdminRepository{}, &recordingMailer{err: errors.New("smtp down")}, storage.NewDiskStore(t.TempDir()))
+
+	if err := svc.ApproveSitter(context.Background(), adminActor, 1, ""); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestResubmitSitter(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   authz.Actor
+		status  string
+		wantErr error
+	}{
+		{name: "after changes requested", actor: authz.Actor{UserID: 1, Role: authz.RoleSitter}, status: StatusChangesRequested},
+		{name: "after rejection", actor: authz.Actor{UserID: 1, Role: authz.RoleSitter}, status: StatusRejected},
+		{name: "still pending", actor: authz.Actor{UserID: 1, Role: authz.RoleSitter}, status: StatusPending, wantErr: ErrNotResubmittable},
+		{name: "another sitter", actor: authz.Actor{UserID: 2, Role: authz.RoleSitter}, status: StatusRejected, wantErr: authz.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorded *StatusChange
+			repo := &mockAdminRepository{
+				getSitterDetailsFunc: func(id int) (*SitterDetails, error) {
+					return &SitterDetails{Sitter: models.Sitter{SitterID: id, Status: tt.status}}, nil
+				},
+				changeSitterStatusFunc: func(change *StatusChange) error {
+					recorded = change
+					return nil
+				},
+			}
+			svc := newTestService(t, repo)
+
+			err := svc.ResubmitSitter(context.Background(), tt.actor, 1)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if recorded.Decision != DecisionResubmit || recorded.FromStatus != tt.status || recorded.ToStatus != StatusPending {
+				t.Errorf("unexpected status change: %+v", recorded)
+			}
+		})
+	}
+}
+
+var pdfContent = []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n")
+
+func TestUploadSitterDocument(t *testing.T) {
+	sitter := authz.Actor{UserID: 1, Role: authz.RoleSitter}
+
+	var saved *SitterDocument
+	repo := &mockAdminRepository{
+		addDocumentFunc: func(doc *SitterDocument) error {
+			doc.DocumentID = 3
+			saved = doc
+			return nil
+		},
+		getDocumentFunc: func(sitterID, documentID int) (*SitterDocument, error) {
+			return saved, nil
+		},
+	}
+	svc := newTestService(t, repo)
+
+	doc, err := svc.UploadSitterDocument(context.Background(), sitter, 1, DocumentCertificate, "../../first-aid.pdf", bytes.NewReader(pdfContent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.ContentType != "application/pdf" || doc.FileName != "first-aid.pdf" || doc.SizeBytes != int64(len(pdfContent)) {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+	if !strings.HasPrefix(doc.StorageKey, "sitters/1/") || !strings.HasSuffix(doc.StorageKey, ".pdf") {
+		t.Errorf("unexpected storage key %q", doc.StorageKey)
+	}
+
+	_, content, err := svc.OpenSitterDocument(context.Background(), adminActor, 1, 3)
+	if err != nil {
+		t.Fatalf("unexpected error opening document: %v", err)
+	}
+	defer content.Close()
+
+	data, _ := io.ReadAll(content)
+	if !bytes.Equal(data, pdfContent) {
+		t.Error("stored document differs from the upload")
+	}
+}
+
+func TestUploadSitterDocument_Rejected(t *testing.T) {
+	sitter := authz.Actor{UserID: 1, Role: authz.RoleSitter}
+
+	tests := []struct {
+		name    string
+		actor   authz.Actor
+		kind    string
+		content []byte
+		wantErr error
+	}{
+		{name: "another sitter", actor: authz.Actor{UserID: 2, Role: authz.RoleSitter}, kind: DocumentID, content: pdfContent, wantErr: authz.ErrForbidden},
+		{name: "unknown kind", actor: sitter, kind: "selfie", content: pdfContent, wantErr: ErrInvalidDocumentKind},
+		{name: "not a document", actor: sitter, kind: DocumentID, content: []byte("#!/bin/sh\necho hi\n"), wantErr: ErrInvalidDocument},
+		{name: "too large", actor: sitter, kind: DocumentID, content: append(pdfContent, make([]byte, MaxDocumentSize)...), wantErr: ErrDocumentTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockAdminRepository{
+				addDocumentFunc: func(doc *SitterDocument) error {
+					t.Error("document should not be recorded")
+					return nil
+				},
+			}
+			svc := newTestService(t, repo)
+
+			_, err := svc.UploadSitterDocument(context.Background(), tt.actor, 1, tt.kind, "doc.pdf", bytes.NewReader(tt.content))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUploadSitterDocument_RemovesFileWhenNotRecorded(t *testing.T) {
+	store := storage.NewDiskStore(t.TempDir())
+
+	var key string
+	repo := &mockAdminRepository{
+		addDocumentFunc: func(doc *SitterDocument) error {
+			key = doc.StorageKey
+			return errors.New("db error")
+		},
+	}
+	svc := NewService(repo, &recordingMailer{}, store)
+
+	_, err := svc.UploadSitterDocument(context.Background(), authz.Actor{UserID: 1, Role: authz.RoleSitter}, 1, DocumentID, "id.pdf", bytes.NewReader(pdfContent))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if _, err := store.Open(context.Background(), key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected stored file to be removed, got %v", err)
+	}
+}
+
+func TestAddSitterNote(t *testing.T) {
+	var saved *SitterNote
+	repo := &mockAdminRepository{
+		addNoteFunc: func(note *SitterNote) error {
+			saved = note
+			return nil
+		},
+	}
+	svc := newTestService(t, repo)
+
+	if _, err := svc.AddSitterNote(context.Background(), authz.Actor{UserID: 1, Role: authz.RoleSitter}, 1, "note"); !errors.Is(err, authz.ErrForbidden) {
+		t.Errorf("expected ErrForbidden for a sitter, got %v", err)
+	}
+
+	note, err := svc.AddSitterNote(context.Background(), adminActor, 1, "called references")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved != note || note.AdminID == nil || *note.AdminID != adminActor.UserID {
+		t.Errorf("unexpected note: %+v", note)
+	}
+}
+
+func TestGetSitterDetails_IncludesVetting(t *testing.T) {
+	repo := &mockAdminRepository{
+		getDocumentsFunc: func(int) ([]SitterDocument, error) {
+			return []SitterDocument{{DocumentID: 1}}, nil
+		},
+		getNotesFunc: func(int) ([]SitterNote, error) {
+			return []SitterNote{{NoteID: 1}, {NoteID: 2}}, nil
+		},
+		getStatusHistoryFunc: func(int) ([]StatusChange, error) {
+			return []StatusChange{{HistoryID: 1, Decision: DecisionRequestChanges}}, nil
+		},
+	}
+	svc := newTestService(t, repo)
+
+	details, err := svc.GetSitterDetails(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(details.Documents) != 1 || len(details.Notes) != 2 || len(details.StatusHistory) != 1 {
+		t.Errorf("unexpected details: %+v", details)
+	}
+}
+
+func TestSuspendUser(t *testing.T) {
+	future := time.Now().Add(72 * time.Hour)
+	deleted := time.Now()
+
+	tests := []struct {
+		name    string
+		actor   authz.Actor
+		user    *models.User
+		until   time.Time
+		reason  string
+		wantErr error
+	}{
+		{name: "success", actor: adminActor, user: &models.User{UserID: 5, Role: "owner"}, until: future, reason: "spam"},
+		{name: "no reason", actor: adminActor, user: &models.User{UserID: 5, Role: "owner"}, until: future, wantErr: ErrReasonRequired},
+		{name: "in the past", actor: adminActor, user: &models.User{UserID: 5, Role: "owner"}, until: time.Now().Add(-time.Hour), reason: "spam", wantErr: ErrInvalidSuspension},
+		{name: "admin", actor: adminActor, user: &models.User{UserID: 5, Role: "admin"}, until: future, reason: "spam", wantErr: ErrCannotRestrictAdmin},
+		{name: "deleted", actor: adminActor, user: &models.User{UserID: 5, Role: "owner", DeletedAt: &deleted}, until: future, reason: "spam", wantErr: ErrUserNotFound},
+		{name: "not an admin", actor: authz.Actor{UserID: 1, Role: authz.RoleOwner}, user: &models.User{UserID: 5, Role: "owner"}, until: future, reason: "spam", wantErr: authz.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suspended := false
+			repo := &mockAdminRepository{
+				getUserByIDFunc: func(int) (*models.User, error) { return tt.user, nil },
+				suspendUserFunc: func(id int, until time.Time, reason string) error {
+					suspended = true
+					return nil
+				},
+			}
+			svc := newTestService(t, repo)
+
+			err := svc.SuspendUser(context.Background(), tt.actor, 5, tt.until, tt.reason)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if suspended != (tt.wantErr == nil) {
+				t.Errorf("expected suspended=%v", tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestBanUser(t *testing.T) {
+	var gotReason string
+	repo := &mockAdminRepository{
+		banUserFunc: func(id int, reason string) error {
+			gotReason = reason
+			return nil
+		},
+	}
+	svc := newTestService(t, repo)
+
+	if err := svc.BanUser(context.Background(), adminActor, 5, "fraud"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotReason != "fraud" {
+		t.Errorf("expected reason 'fraud', got %q", gotReason)
+	}
+}
+
+func TestDeleteUser_RemovesDocuments(t *testing.T) {
+	store := storage.NewDiskStore(t.TempDir())
+	if err := store.Put(context.Background(), "sitters/5/a.pdf", bytes.NewReader(pdfContent)); err != nil {
+		t.Fatalf("failed to store document: %v", err)
+	}
+
+	repo := &mockAdminRepository{
+		getDocumentsFunc: func(int) ([]SitterDocument, error) {
+			return []SitterDocument{{DocumentID: 1, StorageKey: "sitters/5/a.pdf"}}, nil
+		},
+	}
+	svc := NewService(repo, &recordingMailer{}, store)
+
+	if err := svc.DeleteUser(context.Background(), adminActor, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := store.Open(context.Background(), "sitters/5/a.pdf"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected document to be removed, got %v", err)
+	}
+}
+
+func TestGetStats_DefaultRange(t *testing.T) {
+	var gotFrom, gotTo time.Time
+	repo := &mockAdminRepository{
+		getStatsFunc: func(from, to time.Time) (*Stats, error) {
+			gotFrom, gotTo = from, to
+			return &Stats{}, nil
+		},
+	}
+
+	stats, err := newTestService(t, repo).GetStats(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if !gotTo.Equal(today.AddDate(0, 0, 1)) {
+		t.Errorf("expected the range to end after today, got %v", gotTo)
+	}
+	if !gotFrom.Equal(today.AddDate(0, 0, 1-DefaultStatsDays)) {
+		t.Errorf("expected the range to cover %d days, got from %v", DefaultStatsDays, gotFrom)
+	}
+	if stats.To != today.Format(dateLayout) || stats.GeneratedAt.IsZero() {
+		t.Errorf("expected the range and generation time to be filled in, got %+v", stats)
+	}
+}
+
+func TestGetStats_InvalidRange(t *testing.T) {
+	repo := &mockAdminRepository{
+		getStatsFunc: func(from, to time.Time) (*Stats, error) {
+			t.Error("repository should not be called")
+			return nil, nil
+		},
+	}
+	service := newTestService(t, repo)
+
+	for _, tc := range []struct{ from, to string }{
+		{"01.02.2026", ""},
+		{"", "tomorrow"},
+		{"2026-02-10", "2026-02-01"},
+		{"2024-12-31", "2026-01-01"},
+	} {
+		if _, err := service.GetStats(context.Background(), tc.from, tc.to); !errors.Is(err, ErrInvalidStatsRange) {
+			t.Errorf("%s..%s: expected ErrInvalidStatsRange, got %v", tc.from, tc.to, err)
+		}
+	}
+}
+
+func TestGetStats_Cached(t *testing.T) {
+	calls := 0
+	repo := &mockAdminRepository{
+		getStatsFunc: func(from, to time.Time) (*Stats, error) {
+			calls++
+			return &Stats{}, nil
+		},
+	}
+	svc := newTestService(t, repo)
+	ctx := context.Background()
+
+	if _, err := svc.GetStats(ctx, "2026-01-01", "2026-01-31"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := svc.GetStats(ctx, "2026-01-01", "2026-01-31"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the second call to be cached, got %d queries", calls)
+	}
+
+	if _, err := svc.GetStats(ctx, "2026-01-01", "2026-01-30"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected another range to be queried, got %d queries", calls)
+	}
+
+	cache := svc.(*service).stats
+	for key, entry := range cache.entries {
+		entry.expires = time.Now().Add(-time.Second)
+		cache.entries[key] = entry
+	}
+	if _, err := svc.GetStats(ctx, "2026-01-01", "2026-01-31"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected expired stats to be queried again, got %d queries", calls)
+	}
+	if len(cache.entries) != 1 {
+		t.Errorf("expected expired entries to be dropped, got %d", len(cache.entries))
+	}
+}
+
+func TestGetStats_CacheDisabled(t *testing.T) {
+	calls := 0
+	repo := &mockAdminRepository{
+		getStatsFunc: func(from, to time.Time) (*Stats, error) {
+			calls++
+			return &Stats{}, nil
+		},
+	}
+	svc := newTestService(t, repo)
+	svc.(*service).stats = newStatsCache(0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.GetStats(context.Background(), "2026-01-01", "2026-01-31"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected every call to query with the cache off, got %d queries", calls)
+	}
+}
@@ -1,11 +1,19 @@
 package admin
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/gorilla/mux"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
 )
 
 type Handler struct {
@@ -17,122 +25,367 @@ func NewHandler(service Service) *Handler {
 }
 
 func (h *Handler) GetPendingSitters(w http.ResponseWriter, r *http.Request) {
-	sitters, err := h.service.GetPendingSitters()
+	q, err := SitterListSpec.Parse(r.URL.Query())
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, sitters)
+	page, err := h.service.GetPendingSitters(r.Context(), q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, page)
+}
+
+// DecisionRequest carries the reason shown to the sitter. It is optional
+// when approving, so approve accepts an empty body.
+type DecisionRequest struct {
+	Reason string `json:"reason" validate:"max=1000"`
+}
+
+type NoteRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
 }
 
 func (h *Handler) ApproveSitter(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	sitterID, err := strconv.Atoi(vars["sitter_id"])
+	h.decide(w, r, h.service.ApproveSitter, "nanny approved successfully")
+}
+
+func (h *Handler) RejectSitter(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.RejectSitter, "nanny rejected")
+}
+
+func (h *Handler) RequestSitterChanges(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.RequestSitterChanges, "changes requested")
+}
+
+type decisionFunc func(ctx context.Context, actor authz.Actor, sitterID int, reason string) error
+
+func (h *Handler) decide(w http.ResponseWriter, r *http.Request, decide decisionFunc, message string) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID of a nanny")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err = h.service.ApproveSitter(sitterID)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+	var req DecisionRequest
+	if r.ContentLength != 0 {
+		if err := httpx.Decode(r, &req); err != nil {
+			httpx.Error(w, r, err)
+			return
+		}
+	}
+
+	actor := middleware.ActorFromContext(r.Context())
+
+	if err := decide(r.Context(), actor, sitterID, strings.TrimSpace(req.Reason)); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "nanny approved successfully",
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": message,
 	})
 }
 
-func (h *Handler) RejectSitter(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	sitterID, err := strconv.Atoi(vars["sitter_id"])
+func (h *Handler) ResubmitSitter(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID of a nanny")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err = h.service.RejectSitter(sitterID)
+	err = h.service.ResubmitSitter(r.Context(), middleware.ActorFromContext(r.Context()), sitterID)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "nanny rejected",
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "application resubmitted",
 	})
 }
 
+func (h *Handler) GetSitterStatusHistory(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	history, err := h.service.GetSitterStatusHistory(r.Context(), middleware.ActorFromContext(r.Context()), sitterID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, history)
+}
+
+func (h *Handler) AddSitterNote(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	var req NoteRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	note, err := h.service.AddSitterNote(r.Context(), middleware.ActorFromContext(r.Context()), sitterID, req.Body)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusCreated, note)
+}
+
+// UploadSitterDocument takes a multipart form with the file in "file" and
+// its kind (certificate or id) in "kind".
+func (h *Handler) UploadSitterDocument(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxDocumentSize+1<<20)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpx.Error(w, r, ErrDocumentTooLarge)
+			return
+		}
+		httpx.Error(w, r, ErrMissingDocument)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		httpx.Error(w, r, ErrMissingDocument)
+		return
+	}
+	defer file.Close()
+
+	doc, err := h.service.UploadSitterDocument(r.Context(),
+		middleware.ActorFromContext(r.Context()),
+		sitterID,
+		r.FormValue("kind"),
+		header.Filename,
+		file,
+	)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusCreated, doc)
+}
+
+func (h *Handler) GetSitterDocuments(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	docs, err := h.service.GetSitterDocuments(r.Context(), middleware.ActorFromContext(r.Context()), sitterID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, docs)
+}
+
+func (h *Handler) DownloadSitterDocument(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	documentID, err := httpx.PathID(r, "document_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	doc, content, err := h.service.OpenSitterDocument(r.Context(), middleware.ActorFromContext(r.Context()), sitterID, documentID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(doc.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("⚠️ could not send document %d: %v", documentID, err)
+	}
+}
+
 func (h *Handler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.service.GetAllUsers()
+	q, err := UserListSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.GetAllUsers(r.Context(), q)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, users)
+	httpx.JSON(w, http.StatusOK, page)
 }
 
 func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	userID, err := strconv.Atoi(vars["user_id"])
+	userID, err := httpx.PathID(r, "user_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID of a user")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	user, err := h.service.GetUser(userID)
+	user, err := h.service.GetUser(r.Context(), userID)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, user)
+	httpx.JSON(w, http.StatusOK, user)
 }
 
 func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	userID, err := strconv.Atoi(vars["user_id"])
+	userID, err := httpx.PathID(r, "user_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID of a user")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err = h.service.DeleteUser(userID)
+	err = h.service.DeleteUser(r.Context(), middleware.ActorFromContext(r.Context()), userID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "user found succesfully",
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "user deleted",
 	})
 }
 
-func (h *Handler) GetSitterDetails(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	sitterID, err := strconv.Atoi(vars["sitter_id"])
+type SuspendRequest struct {
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason" validate:"required,max=1000"`
+}
+
+type BanRequest struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+func (h *Handler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := httpx.PathID(r, "user_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	var req SuspendRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	actor := middleware.ActorFromContext(r.Context())
+
+	if err := h.service.SuspendUser(r.Context(), actor, userID, req.Until, strings.TrimSpace(req.Reason)); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "user suspended",
+	})
+}
+
+func (h *Handler) BanUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := httpx.PathID(r, "user_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID of a user")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	details, err := h.service.GetSitterDetails(sitterID)
+	var req BanRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	actor := middleware.ActorFromContext(r.Context())
+
+	if err := h.service.BanUser(r.Context(), actor, userID, strings.TrimSpace(req.Reason)); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "user banned",
+	})
+}
+
+func (h *Handler) ReinstateUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := httpx.PathID(r, "user_id")
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, details)
+	if err := h.service.ReinstateUser(r.Context(), middleware.ActorFromContext(r.Context()), userID); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "user reinstated",
+	})
 }
 
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
+func (h *Handler) GetSitterDetails(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	details, err := h.service.GetSitterDetails(r.Context(), sitterID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, details)
 }
 
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(payload)
+// GetStats: GET /api/admin/stats?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.GetStats(r.Context(), r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, stats)
 }
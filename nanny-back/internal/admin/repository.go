@@ -1,30 +1,96 @@
 package admin
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
+	"nanny-backend/internal/bookings"
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
+	"nanny-backend/internal/payments"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUserNotFound   = apperr.NotFound("user_not_found", "user not found")
+	ErrSitterNotFound = apperr.NotFound("sitter_not_found", "nanny not found")
 )
 
 type Repository interface {
-	GetPendingSitters() ([]models.Sitter, error)
-	ApproveSitter(sitterID int) error
-	RejectSitter(sitterID int) error
-	GetAllUsers() ([]models.User, error)
-	GetUserByID(userID int) (*models.User, error)
-	DeleteUser(userID int) error
-	GetSitterDetails(sitterID int) (*SitterDetails, error)
-	UpdateSitterStatus(sitterID int, status string) error
+	GetPendingSitters(ctx context.Context, q listing.Query) (*listing.Page[models.Sitter], error)
+	GetAllUsers(ctx context.Context, q listing.Query) (*listing.Page[models.User], error)
+	GetUserByID(ctx context.Context, userID int) (*models.User, error)
+	DeleteUser(ctx context.Context, userID int) error
+	SuspendUser(ctx context.Context, userID int, until time.Time, reason string) error
+	BanUser(ctx context.Context, userID int, reason string) error
+	ReinstateUser(ctx context.Context, userID int) error
+	GetSitterDetails(ctx context.Context, sitterID int) (*SitterDetails, error)
+	UpdateSitterStatus(ctx context.Context, sitterID int, status string) error
+
+	ChangeSitterStatus(ctx context.Context, change *StatusChange) error
+	GetStatusHistory(ctx context.Context, sitterID int) ([]StatusChange, error)
+	AddNote(ctx context.Context, note *SitterNote) error
+	GetNotes(ctx context.Context, sitterID int) ([]SitterNote, error)
+	AddDocument(ctx context.Context, doc *SitterDocument) error
+	GetDocuments(ctx context.Context, sitterID int) ([]SitterDocument, error)
+	GetDocument(ctx context.Context, sitterID, documentID int) (*SitterDocument, error)
+
+	GetStats(ctx context.Context, from, to time.Time) (*Stats, error)
 }
 
+// SitterDetails is the sitter's profile for review. Punctuality,
+// Communication and PetCare average the reviews that scored them and are
+// 0 when none did. Documents, Notes and StatusHistory are filled in by
+// the service.
 type SitterDetails struct {
 	models.Sitter
-	FullName string  `json:"full_name"`
-	Email    string  `json:"email"`
-	Phone    string  `json:"phone"`
-	Rating   float64 `json:"rating"`
-	Reviews  int     `json:"reviews"`
+	FullName        string  `json:"full_name"`
+	Email           string  `json:"email"`
+	Phone           string  `json:"phone"`
+	Rating          float64 `json:"rating"`
+	Reviews         int     `json:"reviews"`
+	VerifiedReviews int     `json:"verified_reviews"`
+	Punctuality     float64 `json:"punctuality"`
+	Communication   float64 `json:"communication"`
+	PetCare         float64 `json:"pet_care"`
+
+	Documents     []SitterDocument `json:"documents"`
+	Notes         []SitterNote     `json:"notes"`
+	StatusHistory []StatusChange   `json:"status_history"`
+}
+
+// SitterListSpec is how the pending sitter queue can be sorted and filtered.
+var SitterListSpec = listing.Spec[models.Sitter]{
+	IDColumn: "sitter_id",
+	ID:       func(s models.Sitter) int { return s.SitterID },
+	Sorts: map[string]listing.Sort[models.Sitter]{
+		"sitter_id":        {Column: "sitter_id", Value: func(s models.Sitter) interface{} { return s.SitterID }},
+		"experience_years": {Column: "experience_years", Value: func(s models.Sitter) interface{} { return s.ExperienceYears }},
+	},
+	DefaultSort: "-sitter_id",
+	Filters: map[string]listing.Filter{
+		"location": {Column: "location", Type: listing.Text},
+	},
+}
+
+// UserListSpec is how the user list can be sorted and filtered.
+var UserListSpec = listing.Spec[models.User]{
+	IDColumn: "user_id",
+	ID:       func(u models.User) int { return u.UserID },
+	Sorts: map[string]listing.Sort[models.User]{
+		"created_at": {Column: "created_at", Value: func(u models.User) interface{} { return u.CreatedAt }},
+		"full_name":  {Column: "full_name", Value: func(u models.User) interface{} { return u.FullName }},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"role":       {Column: "role", Type: listing.Text},
+		"created_at": {Column: "created_at", Type: listing.Time},
+	},
 }
 
 type repository struct {
@@ -35,13 +101,16 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) GetPendingSitters() ([]models.Sitter, error) {
-	rows, err := r.db.Query(`
+func (r *repository) GetPendingSitters(ctx context.Context, q listing.Query) (*listing.Page[models.Sitter], error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query, args := q.Apply(`
 		SELECT sitter_id, experience_years, certificates, preferences, location, status
 		FROM sitters
-		WHERE status = 'pending'
-		ORDER BY sitter_id DESC
-	`)
+		WHERE status = 'pending'`, nil)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения заявок: %w", err)
@@ -64,44 +133,41 @@ func (r *repository) GetPendingSitters() ([]models.Sitter, error) {
 		}
 		sitters = append(sitters, sitter)
 	}
-
-	return sitters, nil
-}
-
-func (r *repository) ApproveSitter(sitterID int) error {
-	_, err := r.db.Exec(`
-		UPDATE sitters
-		SET status = 'approved'
-		WHERE sitter_id = $1
-	`, sitterID)
-
-	if err != nil {
-		return fmt.Errorf("could not approve a nanny: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка получения заявок: %w", err)
 	}
 
-	return nil
+	return SitterListSpec.Page(sitters, q), nil
 }
 
-func (r *repository) RejectSitter(sitterID int) error {
-	_, err := r.db.Exec(`
-		UPDATE sitters
-		SET status = 'rejected'
-		WHERE sitter_id = $1
-	`, sitterID)
-
-	if err != nil {
-		return fmt.Errorf("could not decline a nanny: %w", err)
-	}
+const userColumns = `user_id, full_name, email, phone, role, created_at,
+		suspended_until, banned_at, restriction_reason, deleted_at`
 
-	return nil
+func scanUser(row interface{ Scan(...interface{}) error }, user *models.User) error {
+	return row.Scan(
+		&user.UserID,
+		&user.FullName,
+		&user.Email,
+		&user.Phone,
+		&user.Role,
+		&user.CreatedAt,
+		&user.SuspendedUntil,
+		&user.BannedAt,
+		&user.RestrictionReason,
+		&user.DeletedAt,
+	)
 }
 
-func (r *repository) GetAllUsers() ([]models.User, error) {
-	rows, err := r.db.Query(`
-		SELECT user_id, full_name, email, phone, role, created_at
+func (r *repository) GetAllUsers(ctx context.Context, q listing.Query) (*listing.Page[models.User], error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query, args := q.Apply(`
+		SELECT `+userColumns+`
 		FROM users
-		ORDER BY created_at DESC
-	`)
+		WHERE TRUE`, nil)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting users: %w", err)
@@ -111,40 +177,31 @@ func (r *repository) GetAllUsers() ([]models.User, error) {
 	var users []models.User
 	for rows.Next() {
 		var user models.User
-		err := rows.Scan(
-			&user.UserID,
-			&user.FullName,
-			&user.Email,
-			&user.Phone,
-			&user.Role,
-			&user.CreatedAt,
-		)
-		if err != nil {
+		if err := scanUser(rows, &user); err != nil {
 			return nil, fmt.Errorf("error scanning users: %w", err)
 		}
 		users = append(users, user)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting users: %w", err)
+	}
 
-	return users, nil
+	return UserListSpec.Page(users, q), nil
 }
 
-func (r *repository) GetUserByID(userID int) (*models.User, error) {
+func (r *repository) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	user := &models.User{}
-	err := r.db.QueryRow(`
-		SELECT user_id, full_name, email, phone, role, created_at
+	err := scanUser(r.db.QueryRowContext(ctx, `
+		SELECT `+userColumns+`
 		FROM users
 		WHERE user_id = $1
-	`, userID).Scan(
-		&user.UserID,
-		&user.FullName,
-		&user.Email,
-		&user.Phone,
-		&user.Role,
-		&user.CreatedAt,
-	)
+	`, userID), user)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting users: %w", err)
@@ -153,28 +210,133 @@ func (r *repository) GetUserByID(userID int) (*models.User, error) {
 	return user, nil
 }
 
-func (r *repository) DeleteUser(userID int) error {
-	_, err := r.db.Exec(`DELETE FROM users WHERE user_id = $1`, userID)
+// DeleteUser keeps the row so bookings, payments, reviews and messages
+// still point somewhere, but wipes what identifies the person and signs
+// them out everywhere.
+func (r *repository) DeleteUser(ctx context.Context, userID int) error {
+	return database.WithTx(ctx, r.db, func(ctx context.Context) error {
+		ctx, cancel := database.WithTimeout(ctx)
+		defer cancel()
+
+		conn := database.Conn(ctx, r.db)
+
+		result, err := conn.ExecContext(ctx, `
+			UPDATE users
+			SET full_name = 'Deleted user',
+				email = 'deleted-' || user_id || '@deleted.invalid',
+				phone = '',
+				password_hash = '',
+				email_verified_at = NULL,
+				suspended_until = NULL,
+				banned_at = NULL,
+				restriction_reason = '',
+				deleted_at = NOW()
+			WHERE user_id = $1 AND deleted_at IS NULL
+		`, userID)
+		if err != nil {
+			return fmt.Errorf("could not delete user: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return ErrUserNotFound
+		}
+
+		cleanup := []string{
+			`UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`,
+			`DELETE FROM user_tokens WHERE user_id = $1`,
+			`DELETE FROM owner_locations WHERE owner_id = $1`,
+			`DELETE FROM sitter_documents WHERE sitter_id = $1`,
+			`UPDATE sitters
+			SET certificates = '', preferences = '', address_line = NULL, latitude = NULL, longitude = NULL
+			WHERE sitter_id = $1`,
+		}
+		for _, query := range cleanup {
+			if _, err := conn.ExecContext(ctx, query, userID); err != nil {
+				return fmt.Errorf("could not delete user: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// restrictUser applies set to a user who is not deleted and revokes their
+// sessions in the same statement.
+func (r *repository) restrictUser(ctx context.Context, userID int, set string, args ...interface{}) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var restricted int
+	err := r.db.QueryRowContext(ctx, `
+		WITH restricted AS (
+			UPDATE users
+			SET `+set+`
+			WHERE user_id = $1 AND deleted_at IS NULL
+			RETURNING user_id
+		), revoked AS (
+			UPDATE sessions
+			SET revoked_at = NOW()
+			WHERE user_id IN (SELECT user_id FROM restricted) AND revoked_at IS NULL
+		)
+		SELECT COUNT(*) FROM restricted
+	`, append([]interface{}{userID}, args...)...).Scan(&restricted)
+
+	if err != nil {
+		return fmt.Errorf("could not restrict user: %w", err)
+	}
+	if restricted == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) SuspendUser(ctx context.Context, userID int, until time.Time, reason string) error {
+	return r.restrictUser(ctx, userID, `suspended_until = $2, restriction_reason = $3`, until, reason)
+}
+
+func (r *repository) BanUser(ctx context.Context, userID int, reason string) error {
+	return r.restrictUser(ctx, userID, `banned_at = NOW(), restriction_reason = $2`, reason)
+}
+
+func (r *repository) ReinstateUser(ctx context.Context, userID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET suspended_until = NULL, banned_at = NULL, restriction_reason = ''
+		WHERE user_id = $1 AND deleted_at IS NULL
+	`, userID)
 	if err != nil {
-		return fmt.Errorf("coould not try to delete user: %w", err)
+		return fmt.Errorf("could not reinstate user: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrUserNotFound
 	}
+
 	return nil
 }
 
-func (r *repository) GetSitterDetails(sitterID int) (*SitterDetails, error) {
+func (r *repository) GetSitterDetails(ctx context.Context, sitterID int) (*SitterDetails, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	details := &SitterDetails{}
 
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT 
 			s.sitter_id, s.experience_years, s.certificates, s.preferences, s.location, s.status,
 			u.full_name, u.email, u.phone,
-			COALESCE(AVG(r.rating), 0) as rating,
-			COUNT(r.review_id) as reviews
+			COALESCE(ss.rating_sum::float8 / NULLIF(ss.review_count, 0), 0) AS rating,
+			COALESCE(ss.review_count, 0) AS reviews,
+			COALESCE(ss.verified_count, 0) AS verified_reviews,
+			COALESCE(ss.punctuality_sum::float8 / NULLIF(ss.punctuality_count, 0), 0) AS punctuality,
+			COALESCE(ss.communication_sum::float8 / NULLIF(ss.communication_count, 0), 0) AS communication,
+			COALESCE(ss.pet_care_sum::float8 / NULLIF(ss.pet_care_count, 0), 0) AS pet_care
 		FROM sitters s
 		JOIN users u ON s.sitter_id = u.user_id
-		LEFT JOIN reviews r ON s.sitter_id = r.sitter_id
+		LEFT JOIN sitter_stats ss ON ss.sitter_id = s.sitter_id
 		WHERE s.sitter_id = $1
-		GROUP BY s.sitter_id, u.full_name, u.email, u.phone
 	`, sitterID).Scan(
 		&details.SitterID,
 		&details.ExperienceYears,
@@ -187,10 +349,14 @@ func (r *repository) GetSitterDetails(sitterID int) (*SitterDetails, error) {
 		&details.Phone,
 		&details.Rating,
 		&details.Reviews,
+		&details.VerifiedReviews,
+		&details.Punctuality,
+		&details.Communication,
+		&details.PetCare,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("nanny not found")
+		return nil, ErrSitterNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting details of a nanny: %w", err)
@@ -199,8 +365,11 @@ func (r *repository) GetSitterDetails(sitterID int) (*SitterDetails, error) {
 	return details, nil
 }
 
-func (r *repository) UpdateSitterStatus(sitterID int, status string) error {
-	_, err := r.db.Exec(`
+func (r *repository) UpdateSitterStatus(ctx context.Context, sitterID int, status string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE sitters
 		SET status = $1
 		WHERE sitter_id = $2
@@ -212,3 +381,396 @@ func (r *repository) UpdateSitterStatus(sitterID int, status string) error {
 
 	return nil
 }
+
+// ChangeSitterStatus moves the sitter from change.FromStatus to
+// change.ToStatus and records it in one statement, so two admins deciding
+// at once cannot both succeed.
+func (r *repository) ChangeSitterStatus(ctx context.Context, change *StatusChange) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.QueryRowContext(ctx, `
+		WITH changed AS (
+			UPDATE sitters
+			SET status = $3
+			WHERE sitter_id = $1 AND status = $2
+			RETURNING sitter_id
+		)
+		INSERT INTO sitter_status_history (sitter_id, from_status, to_status, decision, reason, actor_id)
+		SELECT sitter_id, $2, $3, $4, $5, $6 FROM changed
+		RETURNING history_id, created_at
+	`, change.SitterID, change.FromStatus, change.ToStatus, change.Decision, change.Reason, change.ActorID,
+	).Scan(&change.HistoryID, &change.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrSitterStatusChanged
+	}
+	if err != nil {
+		return fmt.Errorf("could not change the status of a nanny: %w", err)
+	}
+
+	return nil
+}
+
+// GetStatusHistory lists the sitter's status changes, oldest first.
+func (r *repository) GetStatusHistory(ctx context.Context, sitterID int) ([]StatusChange, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT history_id, sitter_id, from_status, to_status, decision, reason, actor_id, created_at
+		FROM sitter_status_history
+		WHERE sitter_id = $1
+		ORDER BY history_id
+	`, sitterID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []StatusChange{}
+	for rows.Next() {
+		var change StatusChange
+		err := rows.Scan(
+			&change.HistoryID,
+			&change.SitterID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.Decision,
+			&change.Reason,
+			&change.ActorID,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning status change: %w", err)
+		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting status history: %w", err)
+	}
+
+	return history, nil
+}
+
+func (r *repository) AddNote(ctx context.Context, note *SitterNote) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO sitter_notes (sitter_id, admin_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING note_id, created_at
+	`, note.SitterID, note.AdminID, note.Body).Scan(&note.NoteID, &note.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("could not add a note: %w", err)
+	}
+
+	return nil
+}
+
+// GetNotes lists the notes on the sitter's application, oldest first.
+func (r *repository) GetNotes(ctx context.Context, sitterID int) ([]SitterNote, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT note_id, sitter_id, admin_id, body, created_at
+		FROM sitter_notes
+		WHERE sitter_id = $1
+		ORDER BY note_id
+	`, sitterID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []SitterNote{}
+	for rows.Next() {
+		var note SitterNote
+		if err := rows.Scan(&note.NoteID, &note.SitterID, &note.AdminID, &note.Body, &note.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning note: %w", err)
+		}
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+func (r *repository) AddDocument(ctx context.Context, doc *SitterDocument) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO sitter_documents (sitter_id, kind, file_name, content_type, size_bytes, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING document_id, uploaded_at
+	`, doc.SitterID, doc.Kind, doc.FileName, doc.ContentType, doc.SizeBytes, doc.StorageKey,
+	).Scan(&doc.DocumentID, &doc.UploadedAt)
+
+	if err != nil {
+		return fmt.Errorf("could not save a document: %w", err)
+	}
+
+	return nil
+}
+
+const documentColumns = `document_id, sitter_id, kind, file_name, content_type, size_bytes, storage_key, uploaded_at`
+
+func scanDocument(row interface{ Scan(...interface{}) error }, doc *SitterDocument) error {
+	return row.Scan(
+		&doc.DocumentID,
+		&doc.SitterID,
+		&doc.Kind,
+		&doc.FileName,
+		&doc.ContentType,
+		&doc.SizeBytes,
+		&doc.StorageKey,
+		&doc.UploadedAt,
+	)
+}
+
+// GetDocuments lists the sitter's documents, oldest first.
+func (r *repository) GetDocuments(ctx context.Context, sitterID int) ([]SitterDocument, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+documentColumns+`
+		FROM sitter_documents
+		WHERE sitter_id = $1
+		ORDER BY document_id
+	`, sitterID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []SitterDocument{}
+	for rows.Next() {
+		var doc SitterDocument
+		if err := scanDocument(rows, &doc); err != nil {
+			return nil, fmt.Errorf("error scanning document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting documents: %w", err)
+	}
+
+	return docs, nil
+}
+
+func (r *repository) GetDocument(ctx context.Context, sitterID, documentID int) (*SitterDocument, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	doc := &SitterDocument{}
+	err := scanDocument(r.db.QueryRowContext(ctx, `
+		SELECT `+documentColumns+`
+		FROM sitter_documents
+		WHERE sitter_id = $1 AND document_id = $2
+	`, sitterID, documentID), doc)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// cancelledStatuses are the bookings that count as cancelled; "cancelled"
+// is what rows from before the owner/sitter split still hold.
+var cancelledStatuses = pq.Array([]string{bookings.StatusCancelledByOwner, bookings.StatusCancelledBySitter, "cancelled"})
+
+// deadStatuses are the bookings that never turned into a sale.
+var deadStatuses = pq.Array([]string{bookings.StatusCancelledByOwner, bookings.StatusCancelledBySitter, "cancelled", bookings.StatusExpired})
+
+// GetStats aggregates the dashboard for from (inclusive) to to
+// (exclusive). Days are bucketed in UTC.
+func (r *repository) GetStats(ctx context.Context, from, to time.Time) (*Stats, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	stats := &Stats{
+		Registrations:       []RoleCount{},
+		Bookings:            []StatusCount{},
+		Payments:            []PaymentTotal{},
+		TopSittersByRevenue: []SitterRevenue{},
+		TopSittersByRating:  []SitterRating{},
+		BusiestServices:     []CityServiceCount{},
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT to_char(created_at, 'YYYY-MM-DD') AS day, role, COUNT(*)
+		FROM users
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY day, role
+		ORDER BY day, role
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error counting registrations: %w", err)
+	}
+	for rows.Next() {
+		var c RoleCount
+		if err := rows.Scan(&c.Date, &c.Role, &c.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning registrations: %w", err)
+		}
+		stats.Registrations = append(stats.Registrations, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error counting registrations: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT to_char(start_time AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, status, COUNT(*)
+		FROM bookings
+		WHERE start_time >= $1 AND start_time < $2
+		GROUP BY day, status
+		ORDER BY day, status
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error counting bookings: %w", err)
+	}
+	for rows.Next() {
+		var c StatusCount
+		if err := rows.Scan(&c.Date, &c.Status, &c.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning bookings: %w", err)
+		}
+		stats.Bookings = append(stats.Bookings, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error counting bookings: %w", err)
+	}
+
+	totals := &stats.BookingTotals
+	err = r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE b.status = ANY($3)),
+			COALESCE(SUM(ROUND(s.price_per_hour * EXTRACT(EPOCH FROM b.end_time - b.start_time)::numeric / 3600, 2))
+				FILTER (WHERE b.status <> ALL($4)), 0)::float8
+		FROM bookings b
+		LEFT JOIN services s ON s.service_id = b.service_id
+		WHERE b.start_time >= $1 AND b.start_time < $2
+	`, from, to, cancelledStatuses, deadStatuses).Scan(&totals.Bookings, &totals.Cancelled, &totals.GrossBookingValue)
+	if err != nil {
+		return nil, fmt.Errorf("error totalling bookings: %w", err)
+	}
+	if totals.Bookings > 0 {
+		totals.CancellationRate = float64(totals.Cancelled) / float64(totals.Bookings)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT status, COUNT(*), COALESCE(SUM(amount), 0)::float8
+		FROM payments
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY status
+		ORDER BY status
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error totalling payments: %w", err)
+	}
+	for rows.Next() {
+		var p PaymentTotal
+		if err := rows.Scan(&p.Status, &p.Count, &p.Amount); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning payments: %w", err)
+		}
+		stats.Payments = append(stats.Payments, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error totalling payments: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT b.sitter_id, u.full_name, COUNT(*), SUM(p.amount)::float8 AS revenue
+		FROM payments p
+		JOIN bookings b ON b.booking_id = p.booking_id
+		JOIN users u ON u.user_id = b.sitter_id
+		WHERE p.status = $3 AND p.created_at >= $1 AND p.created_at < $2
+		GROUP BY b.sitter_id, u.full_name
+		ORDER BY revenue DESC, b.sitter_id
+		LIMIT $4
+	`, from, to, payments.StatusPaid, TopSittersLimit)
+	if err != nil {
+		return nil, fmt.Errorf("error ranking nannies by revenue: %w", err)
+	}
+	for rows.Next() {
+		var s SitterRevenue
+		if err := rows.Scan(&s.SitterID, &s.FullName, &s.Payments, &s.Revenue); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning nanny revenue: %w", err)
+		}
+		stats.TopSittersByRevenue = append(stats.TopSittersByRevenue, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error ranking nannies by revenue: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT r.sitter_id, u.full_name, AVG(r.rating)::float8, COUNT(*),
+			sitter_score(COUNT(*), SUM(r.rating)) AS score
+		FROM reviews r
+		JOIN users u ON u.user_id = r.sitter_id
+		WHERE r.hidden_at IS NULL AND r.created_at >= $1 AND r.created_at < $2
+		GROUP BY r.sitter_id, u.full_name
+		ORDER BY score DESC, r.sitter_id
+		LIMIT $3
+	`, from, to, TopSittersLimit)
+	if err != nil {
+		return nil, fmt.Errorf("error ranking nannies by rating: %w", err)
+	}
+	for rows.Next() {
+		var s SitterRating
+		if err := rows.Scan(&s.SitterID, &s.FullName, &s.AverageRating, &s.ReviewCount, &s.Score); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning nanny rating: %w", err)
+		}
+		stats.TopSittersByRating = append(stats.TopSittersByRating, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error ranking nannies by rating: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT COALESCE(st.location, '') AS city, s.type, COUNT(*) AS bookings
+		FROM bookings b
+		JOIN services s ON s.service_id = b.service_id
+		JOIN sitters st ON st.sitter_id = b.sitter_id
+		WHERE b.start_time >= $1 AND b.start_time < $2 AND b.status <> ALL($3)
+		GROUP BY city, s.type
+		ORDER BY city, bookings DESC, s.type
+	`, from, to, deadStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("error counting bookings per city: %w", err)
+	}
+	for rows.Next() {
+		var c CityServiceCount
+		if err := rows.Scan(&c.City, &c.ServiceType, &c.Bookings); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning bookings per city: %w", err)
+		}
+		stats.BusiestServices = append(stats.BusiestServices, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error counting bookings per city: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"nanny-backend/internal/audit"
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/models"
+)
+
+var (
+	ErrCannotRestrictAdmin = apperr.Forbidden("cannot_restrict_admin", "admins cannot be suspended, banned or deleted")
+	ErrInvalidSuspension   = apperr.Validation("invalid_suspension", "a suspension must end in the future")
+)
+
+// SuspendUser locks the user out until the given time.
+func (s *service) SuspendUser(ctx context.Context, actor authz.Actor, userID int, until time.Time, reason string) error {
+	if reason == "" {
+		return ErrReasonRequired
+	}
+
+	user, err := s.checkRestrictable(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+
+	if !until.After(time.Now()) {
+		return ErrInvalidSuspension
+	}
+
+	if err := s.repo.SuspendUser(ctx, userID, until, reason); err != nil {
+		return err
+	}
+
+	after := restrictionOf(user)
+	after.SuspendedUntil, after.Reason = &until, reason
+	audit.Describe(ctx, "user.suspend", "user", userID, restrictionOf(user), after)
+	return nil
+}
+
+// BanUser locks the user out until an admin reinstates them.
+func (s *service) BanUser(ctx context.Context, actor authz.Actor, userID int, reason string) error {
+	if reason == "" {
+		return ErrReasonRequired
+	}
+
+	user, err := s.checkRestrictable(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.BanUser(ctx, userID, reason); err != nil {
+		return err
+	}
+
+	after := restrictionOf(user)
+	after.Banned, after.Reason = true, reason
+	audit.Describe(ctx, "user.ban", "user", userID, restrictionOf(user), after)
+	return nil
+}
+
+// ReinstateUser lifts a suspension or a ban.
+func (s *service) ReinstateUser(ctx context.Context, actor authz.Actor, userID int) error {
+	if !actor.IsAdmin() {
+		return authz.ErrForbidden
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.ReinstateUser(ctx, userID); err != nil {
+		return err
+	}
+
+	audit.Describe(ctx, "user.reinstate", "user", userID, restrictionOf(user), restriction{})
+	return nil
+}
+
+// DeleteUser anonymizes the user and removes their uploaded documents.
+func (s *service) DeleteUser(ctx context.Context, actor authz.Actor, userID int) error {
+	user, err := s.checkRestrictable(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+
+	docs, err := s.repo.GetDocuments(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	after := restrictionOf(user)
+	after.Deleted = true
+	audit.Describe(ctx, "user.delete", "user", userID, restrictionOf(user), after)
+
+	for _, doc := range docs {
+		if err := s.store.Delete(ctx, doc.StorageKey); err != nil {
+			log.Printf("⚠️ could not remove document %s of deleted user %d: %v", doc.StorageKey, userID, err)
+		}
+	}
+
+	return nil
+}
+
+// checkRestrictable lets admins act on any user who is not an admin and
+// not already deleted.
+func (s *service) checkRestrictable(ctx context.Context, actor authz.Actor, userID int) (*models.User, error) {
+	if !actor.IsAdmin() {
+		return nil, authz.ErrForbidden
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletedAt != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if user.Role == authz.RoleAdmin {
+		return nil, ErrCannotRestrictAdmin
+	}
+
+	return user, nil
+}
+
+// restriction is what the audit log shows of a user's restrictions. The
+// user's personal data stays out of the log, so deleting them erases it.
+type restriction struct {
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	Banned         bool       `json:"banned"`
+	Reason         string     `json:"restriction_reason"`
+	Deleted        bool       `json:"deleted"`
+}
+
+func restrictionOf(user *models.User) restriction {
+	return restriction{
+		SuspendedUntil: user.SuspendedUntil,
+		Banned:         user.BannedAt != nil,
+		Reason:         user.RestrictionReason,
+		Deleted:        user.DeletedAt != nil,
+	}
+}
@@ -1,76 +1,91 @@
 package admin
 
 import (
-	"fmt"
-
+	"context"
+	"io"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/mailer"
 	"nanny-backend/internal/common/models"
+	"nanny-backend/internal/common/storage"
+	"nanny-backend/pkg/config"
 )
 
 type Service interface {
-	GetPendingSitters() ([]models.Sitter, error)
-	ApproveSitter(sitterID int) error
-	RejectSitter(sitterID int) error
-	GetAllUsers() ([]models.User, error)
-	GetUser(userID int) (*models.User, error)
-	DeleteUser(userID int) error
-	GetSitterDetails(sitterID int) (*SitterDetails, error)
+	GetPendingSitters(ctx context.Context, q listing.Query) (*listing.Page[models.Sitter], error)
+	ApproveSitter(ctx context.Context, actor authz.Actor, sitterID int, reason string) error
+	RejectSitter(ctx context.Context, actor authz.Actor, sitterID int, reason string) error
+	RequestSitterChanges(ctx context.Context, actor authz.Actor, sitterID int, reason string) error
+	GetAllUsers(ctx context.Context, q listing.Query) (*listing.Page[models.User], error)
+	GetUser(ctx context.Context, userID int) (*models.User, error)
+	DeleteUser(ctx context.Context, actor authz.Actor, userID int) error
+	SuspendUser(ctx context.Context, actor authz.Actor, userID int, until time.Time, reason string) error
+	BanUser(ctx context.Context, actor authz.Actor, userID int, reason string) error
+	ReinstateUser(ctx context.Context, actor authz.Actor, userID int) error
+	GetSitterDetails(ctx context.Context, sitterID int) (*SitterDetails, error)
+
+	ResubmitSitter(ctx context.Context, actor authz.Actor, sitterID int) error
+	GetSitterStatusHistory(ctx context.Context, actor authz.Actor, sitterID int) ([]StatusChange, error)
+	AddSitterNote(ctx context.Context, actor authz.Actor, sitterID int, body string) (*SitterNote, error)
+	UploadSitterDocument(ctx context.Context, actor authz.Actor, sitterID int, kind, fileName string, content io.Reader) (*SitterDocument, error)
+	GetSitterDocuments(ctx context.Context, actor authz.Actor, sitterID int) ([]SitterDocument, error)
+	OpenSitterDocument(ctx context.Context, actor authz.Actor, sitterID, documentID int) (*SitterDocument, io.ReadCloser, error)
+
+	GetStats(ctx context.Context, from, to string) (*Stats, error)
 }
 
-type service struct {
-	repo Repository
-}
-
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
-}
+var ErrSitterNotPending = apperr.Conflict("sitter_not_pending", "nanny application is not pending")
 
-func (s *service) GetPendingSitters() ([]models.Sitter, error) {
-	return s.repo.GetPendingSitters()
+type service struct {
+	repo  Repository
+	mail  mailer.Mailer
+	store storage.Store
+	stats *statsCache
 }
 
-func (s *service) ApproveSitter(sitterID int) error {
-	details, err := s.repo.GetSitterDetails(sitterID)
-	if err != nil {
-		return err
-	}
+func NewService(repo Repository, mail mailer.Mailer, store storage.Store) Service {
+	cfg := config.Load().Admin
 
-	if details.Status != "pending" {
-		return fmt.Errorf("you can approve only request in status 'pending'")
+	return &service{
+		repo:  repo,
+		mail:  mail,
+		store: store,
+		stats: newStatsCache(cfg.StatsCacheTTL),
 	}
-
-	return s.repo.ApproveSitter(sitterID)
 }
 
-func (s *service) RejectSitter(sitterID int) error {
-	details, err := s.repo.GetSitterDetails(sitterID)
-	if err != nil {
-		return err
-	}
-
-	if details.Status != "pending" {
-		return fmt.Errorf("you can reject only request in status 'pending'")
-	}
-
-	return s.repo.RejectSitter(sitterID)
+func (s *service) GetPendingSitters(ctx context.Context, q listing.Query) (*listing.Page[models.Sitter], error) {
+	return s.repo.GetPendingSitters(ctx, q)
 }
 
-func (s *service) GetAllUsers() ([]models.User, error) {
-	return s.repo.GetAllUsers()
+func (s *service) GetAllUsers(ctx context.Context, q listing.Query) (*listing.Page[models.User], error) {
+	return s.repo.GetAllUsers(ctx, q)
 }
 
-func (s *service) GetUser(userID int) (*models.User, error) {
-	return s.repo.GetUserByID(userID)
+func (s *service) GetUser(ctx context.Context, userID int) (*models.User, error) {
+	return s.repo.GetUserByID(ctx, userID)
 }
 
-func (s *service) DeleteUser(userID int) error {
-	_, err := s.repo.GetUserByID(userID)
+// GetSitterDetails is everything an admin needs to vet the sitter: the
+// profile, the uploaded documents, the notes and the status history.
+func (s *service) GetSitterDetails(ctx context.Context, sitterID int) (*SitterDetails, error) {
+	details, err := s.repo.GetSitterDetails(ctx, sitterID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return s.repo.DeleteUser(userID)
-}
+	if details.Documents, err = s.repo.GetDocuments(ctx, sitterID); err != nil {
+		return nil, err
+	}
+	if details.Notes, err = s.repo.GetNotes(ctx, sitterID); err != nil {
+		return nil, err
+	}
+	if details.StatusHistory, err = s.repo.GetStatusHistory(ctx, sitterID); err != nil {
+		return nil, err
+	}
 
-func (s *service) GetSitterDetails(sitterID int) (*SitterDetails, error) {
-	return s.repo.GetSitterDetails(sitterID)
+	return details, nil
 }
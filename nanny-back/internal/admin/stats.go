@@ -0,0 +1,200 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+)
+
+const dateLayout = "2006-01-02"
+
+const (
+	// DefaultStatsDays is how many days, today included, the stats cover
+	// when no range is given.
+	DefaultStatsDays = 30
+	// MaxStatsDays is the longest range the stats can be asked for.
+	MaxStatsDays = 366
+	// TopSittersLimit is how many sitters each top list holds.
+	TopSittersLimit = 10
+)
+
+var ErrInvalidStatsRange = apperr.Validation("invalid_stats_range", "from and to must be dates (YYYY-MM-DD), from not after to, at most 366 days apart")
+
+// Stats is the operations dashboard for the days From to To, both
+// included, in UTC. Bookings are counted on the day they start, users on
+// the day they registered and payments on the day they were made.
+type Stats struct {
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Registrations       []RoleCount        `json:"registrations"`
+	Bookings            []StatusCount      `json:"bookings"`
+	BookingTotals       BookingTotals      `json:"booking_totals"`
+	Payments            []PaymentTotal     `json:"payments"`
+	TopSittersByRevenue []SitterRevenue    `json:"top_sitters_by_revenue"`
+	TopSittersByRating  []SitterRating     `json:"top_sitters_by_rating"`
+	BusiestServices     []CityServiceCount `json:"busiest_services"`
+}
+
+// RoleCount is how many users of a role registered on Date.
+type RoleCount struct {
+	Date  string `json:"date"`
+	Role  string `json:"role"`
+	Count int    `json:"count"`
+}
+
+// StatusCount is how many bookings starting on Date are in Status now.
+type StatusCount struct {
+	Date   string `json:"date"`
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+// BookingTotals sums up the bookings in the range. GrossBookingValue is
+// what the bookings that were not cancelled or left to expire are worth,
+// priced the same way as their payments.
+type BookingTotals struct {
+	Bookings          int     `json:"bookings"`
+	Cancelled         int     `json:"cancelled"`
+	CancellationRate  float64 `json:"cancellation_rate"`
+	GrossBookingValue float64 `json:"gross_booking_value"`
+}
+
+// PaymentTotal is the number and amount of payments in one status.
+type PaymentTotal struct {
+	Status string  `json:"status"`
+	Count  int     `json:"count"`
+	Amount float64 `json:"amount"`
+}
+
+// SitterRevenue is what a sitter was paid in the range; refunded payments
+// do not count.
+type SitterRevenue struct {
+	SitterID int     `json:"sitter_id"`
+	FullName string  `json:"full_name"`
+	Payments int     `json:"payments"`
+	Revenue  float64 `json:"revenue"`
+}
+
+// SitterRating rates a sitter on the visible reviews posted in the range.
+// Score is the same Bayesian average search ranks by.
+type SitterRating struct {
+	SitterID      int     `json:"sitter_id"`
+	FullName      string  `json:"full_name"`
+	AverageRating float64 `json:"average_rating"`
+	ReviewCount   int     `json:"review_count"`
+	Score         float64 `json:"score"`
+}
+
+// CityServiceCount is how many live bookings a service type got in a
+// city, the sitter's location.
+type CityServiceCount struct {
+	City        string `json:"city"`
+	ServiceType string `json:"service_type"`
+	Bookings    int    `json:"bookings"`
+}
+
+// GetStats computes the stats for the dates from..to, the last
+// DefaultStatsDays days when both are empty. The same range is answered
+// from the cache until it is older than the configured TTL.
+func (s *service) GetStats(ctx context.Context, from, to string) (*Stats, error) {
+	start, end, err := statsRange(from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	key := start.Format(dateLayout) + "/" + end.Format(dateLayout)
+	if stats, ok := s.stats.get(key, time.Now()); ok {
+		return stats, nil
+	}
+
+	stats, err := s.repo.GetStats(ctx, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	stats.From = start.Format(dateLayout)
+	stats.To = end.Format(dateLayout)
+	stats.GeneratedAt = time.Now().UTC()
+
+	s.stats.put(key, stats, stats.GeneratedAt)
+	return stats, nil
+}
+
+// statsRange parses the inclusive from..to dates. Either may be left out:
+// to defaults to today and from to DefaultStatsDays days before to.
+func statsRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	end := now.UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		t, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid to date %q", ErrInvalidStatsRange, to)
+		}
+		end = t
+	}
+
+	start := end.AddDate(0, 0, 1-DefaultStatsDays)
+	if from != "" {
+		t, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid from date %q", ErrInvalidStatsRange, from)
+		}
+		start = t
+	}
+
+	if start.After(end) || end.Sub(start) >= MaxStatsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrInvalidStatsRange
+	}
+
+	return start, end, nil
+}
+
+// statsCache keeps computed stats per date range for ttl. Two requests
+// missing at once both query; the later one wins the entry.
+type statsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedStats
+}
+
+type cachedStats struct {
+	stats   *Stats
+	expires time.Time
+}
+
+func newStatsCache(ttl time.Duration) *statsCache {
+	return &statsCache{ttl: ttl, entries: map[string]cachedStats{}}
+}
+
+func (c *statsCache) get(key string, now time.Time) (*Stats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		return nil, false
+	}
+	return entry.stats, true
+}
+
+// put stores the stats and drops expired entries, so ranges nobody asks
+// for again do not pile up.
+func (c *statsCache) put(key string, stats *Stats, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedStats{stats: stats, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,340 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"nanny-backend/internal/audit"
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/mailer"
+)
+
+// Sitter application statuses.
+const (
+	StatusPending          = "pending"
+	StatusChangesRequested = "changes_requested"
+	StatusApproved         = "approved"
+	StatusRejected         = "rejected"
+)
+
+// Decisions recorded in the status history. Admins approve, reject or
+// request changes; the sitter resubmits.
+const (
+	DecisionApprove        = "approve"
+	DecisionReject         = "reject"
+	DecisionRequestChanges = "request_changes"
+	DecisionResubmit       = "resubmit"
+)
+
+// Kinds of documents a sitter uploads for vetting.
+const (
+	DocumentCertificate = "certificate"
+	DocumentID          = "id"
+)
+
+// MaxDocumentSize is the largest document a sitter may upload.
+const MaxDocumentSize = 10 << 20
+
+// documentTypes maps the accepted content types to the extension the file
+// is stored with.
+var documentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+var (
+	ErrReasonRequired      = apperr.Validation("reason_required", "a reason is required for this decision")
+	ErrSitterStatusChanged = apperr.Conflict("sitter_status_changed", "the nanny application was changed in the meantime")
+	ErrNotResubmittable    = apperr.Conflict("sitter_not_resubmittable", "only applications sent back or rejected can be resubmitted")
+	ErrInvalidDocumentKind = apperr.Validation("invalid_document_kind", "kind must be certificate or id")
+	ErrMissingDocument     = apperr.Validation("missing_document", "upload the document as multipart form field 'file'")
+	ErrInvalidDocument     = apperr.Validation("invalid_document", "documents must be PDF, JPEG or PNG files")
+	ErrDocumentTooLarge    = apperr.Validation("document_too_large", "documents can be at most 10 MB")
+	ErrDocumentNotFound    = apperr.NotFound("document_not_found", "document not found")
+)
+
+// StatusChange is one entry of a sitter's status history. ActorID is nil
+// once that account is gone.
+type StatusChange struct {
+	HistoryID  int       `json:"history_id"`
+	SitterID   int       `json:"sitter_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Decision   string    `json:"decision"`
+	Reason     string    `json:"reason,omitempty"`
+	ActorID    *int      `json:"actor_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SitterNote is an admin's internal note on an application.
+type SitterNote struct {
+	NoteID    int       `json:"note_id"`
+	SitterID  int       `json:"sitter_id"`
+	AdminID   *int      `json:"admin_id,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SitterDocument describes an uploaded file; the file itself is in the
+// upload store under StorageKey.
+type SitterDocument struct {
+	DocumentID  int       `json:"document_id"`
+	SitterID    int       `json:"sitter_id"`
+	Kind        string    `json:"kind"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	StorageKey  string    `json:"-"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+var decisionStatus = map[string]string{
+	DecisionApprove:        StatusApproved,
+	DecisionReject:         StatusRejected,
+	DecisionRequestChanges: StatusChangesRequested,
+}
+
+func (s *service) ApproveSitter(ctx context.Context, actor authz.Actor, sitterID int, reason string) error {
+	return s.decide(ctx, actor, sitterID, DecisionApprove, reason)
+}
+
+func (s *service) RejectSitter(ctx context.Context, actor authz.Actor, sitterID int, reason string) error {
+	return s.decide(ctx, actor, sitterID, DecisionReject, reason)
+}
+
+// RequestSitterChanges sends the application back to the sitter, who can
+// fix it and resubmit.
+func (s *service) RequestSitterChanges(ctx context.Context, actor authz.Actor, sitterID int, reason string) error {
+	return s.decide(ctx, actor, sitterID, DecisionRequestChanges, reason)
+}
+
+// decide moves a pending application on, records why and tells the sitter.
+func (s *service) decide(ctx context.Context, actor authz.Actor, sitterID int, decision, reason string) error {
+	if !actor.IsAdmin() {
+		return authz.ErrForbidden
+	}
+
+	if reason == "" && decision != DecisionApprove {
+		return ErrReasonRequired
+	}
+
+	details, err := s.repo.GetSitterDetails(ctx, sitterID)
+	if err != nil {
+		return err
+	}
+
+	if details.Status != StatusPending {
+		return fmt.Errorf("%w: only applications in status 'pending' can be decided", ErrSitterNotPending)
+	}
+
+	adminID := actor.UserID
+	change := &StatusChange{
+		SitterID:   sitterID,
+		FromStatus: details.Status,
+		ToStatus:   decisionStatus[decision],
+		Decision:   decision,
+		Reason:     reason,
+		ActorID:    &adminID,
+	}
+
+	if err := s.repo.ChangeSitterStatus(ctx, change); err != nil {
+		return err
+	}
+
+	describeStatusChange(ctx, change)
+	s.notifySitter(ctx, details, change)
+	return nil
+}
+
+// ResubmitSitter puts an application that was sent back or rejected into
+// the pending queue again.
+func (s *service) ResubmitSitter(ctx context.Context, actor authz.Actor, sitterID int) error {
+	if !actor.CanActAs(sitterID) {
+		return authz.ErrForbidden
+	}
+
+	details, err := s.repo.GetSitterDetails(ctx, sitterID)
+	if err != nil {
+		return err
+	}
+
+	if details.Status != StatusChangesRequested && details.Status != StatusRejected {
+		return ErrNotResubmittable
+	}
+
+	actorID := actor.UserID
+	change := &StatusChange{
+		SitterID:   sitterID,
+		FromStatus: details.Status,
+		ToStatus:   StatusPending,
+		Decision:   DecisionResubmit,
+		ActorID:    &actorID,
+	}
+
+	if err := s.repo.ChangeSitterStatus(ctx, change); err != nil {
+		return err
+	}
+
+	describeStatusChange(ctx, change)
+	return nil
+}
+
+func describeStatusChange(ctx context.Context, change *StatusChange) {
+	audit.Describe(ctx, "sitter."+change.Decision, "sitter", change.SitterID,
+		map[string]string{"status": change.FromStatus},
+		map[string]string{"status": change.ToStatus, "reason": change.Reason},
+	)
+}
+
+func (s *service) GetSitterStatusHistory(ctx context.Context, actor authz.Actor, sitterID int) ([]StatusChange, error) {
+	if !actor.CanActAs(sitterID) {
+		return nil, authz.ErrForbidden
+	}
+
+	return s.repo.GetStatusHistory(ctx, sitterID)
+}
+
+func (s *service) AddSitterNote(ctx context.Context, actor authz.Actor, sitterID int, body string) (*SitterNote, error) {
+	if !actor.IsAdmin() {
+		return nil, authz.ErrForbidden
+	}
+
+	if _, err := s.repo.GetSitterDetails(ctx, sitterID); err != nil {
+		return nil, err
+	}
+
+	adminID := actor.UserID
+	note := &SitterNote{SitterID: sitterID, AdminID: &adminID, Body: body}
+	if err := s.repo.AddNote(ctx, note); err != nil {
+		return nil, err
+	}
+
+	return note, nil
+}
+
+// UploadSitterDocument stores the file and then records it. The file type
+// is taken from its content, not from the name or the client's header.
+func (s *service) UploadSitterDocument(ctx context.Context, actor authz.Actor, sitterID int, kind, fileName string, content io.Reader) (*SitterDocument, error) {
+	if !actor.CanActAs(sitterID) {
+		return nil, authz.ErrForbidden
+	}
+
+	if kind != DocumentCertificate && kind != DocumentID {
+		return nil, ErrInvalidDocumentKind
+	}
+
+	if _, err := s.repo.GetSitterDetails(ctx, sitterID); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, MaxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading document: %w", err)
+	}
+	if len(data) > MaxDocumentSize {
+		return nil, ErrDocumentTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := documentTypes[contentType]
+	if !ok {
+		return nil, ErrInvalidDocument
+	}
+
+	fileName = filepath.Base(fileName)
+	if fileName == "." || fileName == string(filepath.Separator) || len(fileName) > 255 {
+		fileName = kind + ext
+	}
+
+	doc := &SitterDocument{
+		SitterID:    sitterID,
+		Kind:        kind,
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		StorageKey:  fmt.Sprintf("sitters/%d/%s%s", sitterID, rand.Text(), ext),
+	}
+
+	if err := s.store.Put(ctx, doc.StorageKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddDocument(ctx, doc); err != nil {
+		_ = s.store.Delete(ctx, doc.StorageKey)
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (s *service) GetSitterDocuments(ctx context.Context, actor authz.Actor, sitterID int) ([]SitterDocument, error) {
+	if !actor.CanActAs(sitterID) {
+		return nil, authz.ErrForbidden
+	}
+
+	return s.repo.GetDocuments(ctx, sitterID)
+}
+
+// OpenSitterDocument returns the document and its content; the caller
+// closes the reader.
+func (s *service) OpenSitterDocument(ctx context.Context, actor authz.Actor, sitterID, documentID int) (*SitterDocument, io.ReadCloser, error) {
+	if !actor.CanActAs(sitterID) {
+		return nil, nil, authz.ErrForbidden
+	}
+
+	doc, err := s.repo.GetDocument(ctx, sitterID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.store.Open(ctx, doc.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, content, nil
+}
+
+var decisionMail = map[string]struct{ subject, text string }{
+	DecisionApprove: {
+		"Your nanny application was approved",
+		"Your application has been approved. Owners can now find and book your services.",
+	},
+	DecisionReject: {
+		"Your nanny application was rejected",
+		"Unfortunately your application has been rejected. You can update your profile and documents and apply again.",
+	},
+	DecisionRequestChanges: {
+		"Your nanny application needs changes",
+		"We need a bit more before we can approve your application. Please update your profile or upload the missing documents and resubmit it.",
+	},
+}
+
+// notifySitter emails the decision. The decision is already stored, so a
+// mail failure is only logged.
+func (s *service) notifySitter(ctx context.Context, details *SitterDetails, change *StatusChange) {
+	mail := decisionMail[change.Decision]
+
+	body := fmt.Sprintf("Hello, %s!\n\n%s", details.FullName, mail.text)
+	if change.Reason != "" {
+		body += "\n\nReason: " + change.Reason
+	}
+
+	err := s.mail.Send(ctx, mailer.Message{
+		To:      details.Email,
+		Subject: mail.subject,
+		Body:    body,
+	})
+	if err != nil {
+		log.Printf("⚠️ could not notify nanny %d about decision %s: %v", details.SitterID, change.Decision, err)
+	}
+}
@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"net/http"
+
+	"nanny-backend/internal/common/httpx"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	q, err := ListSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.GetEntries(r.Context(), q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, page)
+}
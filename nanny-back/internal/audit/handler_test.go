@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nanny-backend/internal/common/listing"
+)
+
+type stubService struct {
+	recordingRecorder
+	query listing.Query
+}
+
+func (s *stubService) GetEntries(ctx context.Context, q listing.Query) (*listing.Page[Entry], error) {
+	s.query = q
+	return ListSpec.Page([]Entry{{EntryID: 1, Action: "user.ban"}}, q), nil
+}
+
+func TestHandler_GetEntries(t *testing.T) {
+	service := &stubService{}
+	handler := NewHandler(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/audit?actor_id=1&action=user.ban&created_at_from=2026-01-01", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetEntries(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if service.query.Limit != listing.DefaultLimit {
+		t.Errorf("expected the default limit, got %d", service.query.Limit)
+	}
+}
+
+func TestHandler_GetEntries_InvalidFilter(t *testing.T) {
+	handler := NewHandler(&stubService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/audit?actor_id=me", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetEntries(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,225 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nanny-backend/internal/common/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+type contextKey string
+
+const changeKey contextKey = "audit_change"
+
+// change is what a service said the current request did.
+type change struct {
+	action     string
+	entityType string
+	entityID   *int
+	before     json.RawMessage
+	after      json.RawMessage
+}
+
+// Middleware writes an entry for every request that can change state,
+// that is anything but GET, HEAD and OPTIONS, once it has been answered.
+// Reads are recorded too when made with an impersonation token, which is
+// only known once AuthMiddleware has run. Failed requests are recorded
+// with their status. Register it with Router.Use so the matched route is
+// known.
+func Middleware(recorder Recorder) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, identity := middleware.TrackIdentity(r.Context())
+			described := &change{}
+			ctx = context.WithValue(ctx, changeKey, described)
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			if !changesState(r.Method) && identity.ImpersonatorID == 0 {
+				return
+			}
+
+			entry := newEntry(r, identity, described, sw.status)
+			// The answer is out; the entry is written even if the client
+			// has gone away.
+			if err := recorder.Record(context.WithoutCancel(ctx), entry); err != nil {
+				log.Printf("⚠️ could not write audit entry for %s %s: %v", r.Method, r.URL.Path, err)
+			}
+		})
+	}
+}
+
+// Describe says what the current request did: the action, the entity it
+// did it to and that entity before and after. Objects are cut down to
+// the fields that differ; before is nil for a creation and after for a
+// deletion. Outside an audited request it does nothing, so services can
+// call it unconditionally.
+func Describe(ctx context.Context, action, entityType string, entityID int, before, after interface{}) {
+	described, ok := ctx.Value(changeKey).(*change)
+	if !ok {
+		return
+	}
+
+	described.action = action
+	described.entityType = entityType
+	described.entityID = &entityID
+	described.before, described.after = diff(before, after)
+}
+
+func newEntry(r *http.Request, identity *middleware.Identity, described *change, status int) *Entry {
+	entry := &Entry{
+		ActorRole:  identity.Role,
+		Action:     described.action,
+		EntityType: described.entityType,
+		EntityID:   described.entityID,
+		Before:     described.before,
+		After:      described.after,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Status:     status,
+		IP:         clientIP(r),
+	}
+
+	if identity.UserID > 0 {
+		userID := identity.UserID
+		entry.ActorID = &userID
+	}
+
+	if identity.ImpersonatorID > 0 {
+		adminID := identity.ImpersonatorID
+		entry.ImpersonatorID = &adminID
+	}
+
+	if entry.Action == "" {
+		entry.Action, entry.EntityType, entry.EntityID = routeAction(r)
+	}
+
+	return entry
+}
+
+func changesState(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// routeAction names an action nothing described after its route, e.g.
+// "POST /api/pets/{id}", and takes the target from the last id variable:
+// {sitter_id} is a sitter, {id} is named after the path segment before it.
+func routeAction(r *http.Request) (string, string, *int) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return r.Method + " " + r.URL.Path, "", nil
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return r.Method + " " + r.URL.Path, "", nil
+	}
+
+	segments := strings.Split(template, "/")
+	vars := mux.Vars(r)
+	for i := len(segments) - 1; i >= 0; i-- {
+		name, ok := strings.CutPrefix(segments[i], "{")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(strings.TrimSuffix(name, "}"), ":")
+
+		id, err := strconv.Atoi(vars[name])
+		if err != nil {
+			continue
+		}
+
+		entityType := strings.TrimSuffix(name, "_id")
+		if name == "id" && i > 0 {
+			entityType = strings.TrimSuffix(segments[i-1], "s")
+		}
+		return r.Method + " " + template, entityType, &id
+	}
+
+	return r.Method + " " + template, "", nil
+}
+
+// diff marshals before and after and, when both are objects, drops the
+// fields they have in common.
+func diff(before, after interface{}) (json.RawMessage, json.RawMessage) {
+	b, errB := marshal(before)
+	a, errA := marshal(after)
+	if errB != nil || errA != nil {
+		log.Printf("⚠️ could not encode audit change: %v", errors.Join(errB, errA))
+		return nil, nil
+	}
+
+	var beforeFields, afterFields map[string]json.RawMessage
+	if json.Unmarshal(b, &beforeFields) != nil || json.Unmarshal(a, &afterFields) != nil ||
+		beforeFields == nil || afterFields == nil {
+		return b, a
+	}
+
+	for field, value := range beforeFields {
+		if other, ok := afterFields[field]; ok && bytes.Equal(value, other) {
+			delete(beforeFields, field)
+			delete(afterFields, field)
+		}
+	}
+
+	b, _ = json.Marshal(beforeFields)
+	a, _ = json.Marshal(afterFields)
+	return b, a
+}
+
+// marshal leaves nil as nil rather than encoding it as null.
+func marshal(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// clientIP is the address the request came from; like the rate limiter it
+// does not trust forwarding headers.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusWriter remembers the status code the handler answered with.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
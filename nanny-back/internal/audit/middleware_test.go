@@ -0,0 +1,208 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/middleware"
+	"nanny-backend/internal/common/token"
+
+	"github.com/gorilla/mux"
+)
+
+type recordingRecorder struct {
+	entries []*Entry
+	err     error
+}
+
+func (r *recordingRecorder) Record(ctx context.Context, entry *Entry) error {
+	r.entries = append(r.entries, entry)
+	return r.err
+}
+
+// newRouter serves handler at route behind the audit middleware and
+// AuthMiddleware, the way main wires them.
+func newRouter(recorder Recorder, method, route string, handler http.HandlerFunc) *mux.Router {
+	r := mux.NewRouter()
+	r.Use(Middleware(recorder))
+	r.Handle(route, middleware.AuthMiddleware(handler)).Methods(method)
+	return r
+}
+
+func bearer(t *testing.T, userID int, role string) string {
+	t.Helper()
+	return issue(t, token.Claims{UserID: userID, Role: role})
+}
+
+func issue(t *testing.T, claims token.Claims) string {
+	t.Helper()
+	tokens := token.NewManager(token.NewHMACKey("test", []byte("test_jwt_secret_key_12345")), time.Hour, "nanny-backend")
+	middleware.SetTokenVerifier(tokens)
+
+	tokenString, err := tokens.Issue(claims)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	return "Bearer " + tokenString
+}
+
+func TestMiddleware_RecordsRouteAction(t *testing.T) {
+	recorder := &recordingRecorder{}
+	router := newRouter(recorder, http.MethodDelete, "/api/pets/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/pets/12", nil)
+	req.Header.Set("Authorization", bearer(t, 3, "owner"))
+	req.RemoteAddr = "10.0.0.5:51234"
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	if len(recorder.entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	if entry.ActorID == nil || *entry.ActorID != 3 || entry.ActorRole != "owner" {
+		t.Errorf("expected actor 3/owner, got %v/%s", entry.ActorID, entry.ActorRole)
+	}
+	if entry.Action != "DELETE /api/pets/{id:[0-9]+}" {
+		t.Errorf("unexpected action %q", entry.Action)
+	}
+	if entry.EntityType != "pet" || entry.EntityID == nil || *entry.EntityID != 12 {
+		t.Errorf("expected entity pet 12, got %s %v", entry.EntityType, entry.EntityID)
+	}
+	if entry.Status != http.StatusNoContent || entry.IP != "10.0.0.5" || entry.Path != "/api/pets/12" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
+
+func TestMiddleware_Describe(t *testing.T) {
+	type service struct {
+		Type         string  `json:"type"`
+		PricePerHour float64 `json:"price_per_hour"`
+	}
+
+	recorder := &recordingRecorder{}
+	router := newRouter(recorder, http.MethodPut, "/api/services/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		Describe(r.Context(), "service.update", "service", 4,
+			service{Type: "walking", PricePerHour: 2000},
+			service{Type: "walking", PricePerHour: 2500},
+		)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/services/4", nil)
+	req.Header.Set("Authorization", bearer(t, 9, "sitter"))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(recorder.entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	if entry.Action != "service.update" || entry.EntityType != "service" || *entry.EntityID != 4 {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if string(entry.Before) != `{"price_per_hour":2000}` || string(entry.After) != `{"price_per_hour":2500}` {
+		t.Errorf("expected only the price in the diff, got %s -> %s", entry.Before, entry.After)
+	}
+}
+
+func TestMiddleware_SkipsReads(t *testing.T) {
+	recorder := &recordingRecorder{}
+	router := newRouter(recorder, http.MethodGet, "/api/pets/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pets/1", nil)
+	req.Header.Set("Authorization", bearer(t, 1, "owner"))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(recorder.entries) != 0 {
+		t.Errorf("expected reads not to be recorded, got %d entries", len(recorder.entries))
+	}
+}
+
+func TestMiddleware_RecordsImpersonatedReads(t *testing.T) {
+	recorder := &recordingRecorder{}
+	router := newRouter(recorder, http.MethodGet, "/api/owners/{owner_id:[0-9]+}/pets", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/owners/7/pets", nil)
+	req.Header.Set("Authorization", issue(t, token.Claims{UserID: 7, Role: "owner", Act: &token.Act{UserID: 1}}))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(recorder.entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	if entry.ActorID == nil || *entry.ActorID != 7 {
+		t.Errorf("expected actor 7, got %v", entry.ActorID)
+	}
+	if entry.ImpersonatorID == nil || *entry.ImpersonatorID != 1 {
+		t.Errorf("expected impersonator 1, got %v", entry.ImpersonatorID)
+	}
+	if entry.Action != "GET /api/owners/{owner_id:[0-9]+}/pets" || entry.EntityType != "owner" {
+		t.Errorf("unexpected action %q on %q", entry.Action, entry.EntityType)
+	}
+}
+
+func TestMiddleware_AnonymousAndFailed(t *testing.T) {
+	recorder := &recordingRecorder{err: errors.New("db down")}
+	router := mux.NewRouter()
+	router.Use(Middleware(recorder))
+	router.HandleFunc("/api/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}).Methods(http.MethodPost)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/auth/login", nil))
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected the response to stand when recording fails, got %d", rr.Code)
+	}
+	if len(recorder.entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	if entry.ActorID != nil || entry.Status != http.StatusUnauthorized || entry.EntityID != nil {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
+
+func TestDescribe_OutsideRequest(t *testing.T) {
+	// Must not panic without the middleware, e.g. in the expiry worker.
+	Describe(context.Background(), "booking.expire", "booking", 1, nil, nil)
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after interface{}
+		wantBefore    string
+		wantAfter     string
+	}{
+		{"changed fields only", map[string]string{"status": "pending", "city": "Almaty"}, map[string]string{"status": "approved", "city": "Almaty"}, `{"status":"pending"}`, `{"status":"approved"}`},
+		{"creation", nil, map[string]int{"service_id": 5}, ``, `{"service_id":5}`},
+		{"deletion", map[string]int{"service_id": 5}, nil, `{"service_id":5}`, ``},
+		{"added field", map[string]string{}, map[string]string{"reason": "spam"}, `{}`, `{"reason":"spam"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := diff(tt.before, tt.after)
+			if string(before) != tt.wantBefore || string(after) != tt.wantAfter {
+				t.Errorf("expected %s -> %s, got %s -> %s", tt.wantBefore, tt.wantAfter, before, after)
+			}
+			if len(after) > 0 && !json.Valid(after) {
+				t.Errorf("invalid JSON %s", after)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/listing"
+)
+
+type Repository interface {
+	Insert(ctx context.Context, entry *Entry) error
+	GetEntries(ctx context.Context, q listing.Query) (*listing.Page[Entry], error)
+}
+
+// ListSpec is how the audit log can be sorted and filtered; newest first
+// by default.
+var ListSpec = listing.Spec[Entry]{
+	IDColumn: "entry_id",
+	ID:       func(e Entry) int { return e.EntryID },
+	Sorts: map[string]listing.Sort[Entry]{
+		"created_at": {Column: "created_at", Value: func(e Entry) interface{} { return e.CreatedAt }},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"actor_id":        {Column: "actor_id", Type: listing.Int},
+		"actor_role":      {Column: "actor_role", Type: listing.Text},
+		"impersonator_id": {Column: "impersonator_id", Type: listing.Int},
+		"action":          {Column: "action", Type: listing.Text},
+		"entity_type":     {Column: "entity_type", Type: listing.Text},
+		"entity_id":       {Column: "entity_id", Type: listing.Int},
+		"method":          {Column: "method", Type: listing.Text},
+		"status":          {Column: "status", Type: listing.Int},
+		"created_at":      {Column: "created_at", Type: listing.Time},
+	},
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Insert(ctx context.Context, entry *Entry) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO audit_log (actor_id, actor_role, impersonator_id, action, entity_type, entity_id, before, after, method, path, status, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING entry_id, created_at
+	`, entry.ActorID, entry.ActorRole, entry.ImpersonatorID, entry.Action, entry.EntityType, entry.EntityID,
+		jsonValue(entry.Before), jsonValue(entry.After), entry.Method, entry.Path, entry.Status, entry.IP,
+	).Scan(&entry.EntryID, &entry.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error writing audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetEntries(ctx context.Context, q listing.Query) (*listing.Page[Entry], error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query, args := q.Apply(`
+		SELECT entry_id, actor_id, actor_role, impersonator_id, action, entity_type, entity_id, before, after,
+			method, path, status, ip, created_at
+		FROM audit_log
+		WHERE TRUE`, nil)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var e Entry
+		var before, after []byte
+		if err := rows.Scan(
+			&e.EntryID,
+			&e.ActorID,
+			&e.ActorRole,
+			&e.ImpersonatorID,
+			&e.Action,
+			&e.EntityType,
+			&e.EntityID,
+			&before,
+			&after,
+			&e.Method,
+			&e.Path,
+			&e.Status,
+			&e.IP,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning audit entry: %w", err)
+		}
+		e.Before, e.After = before, after
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting audit entries: %w", err)
+	}
+
+	return ListSpec.Page(entries, q), nil
+}
+
+// jsonValue sends a JSON document as text, which Postgres casts to JSONB;
+// the driver would send []byte as bytea.
+func jsonValue(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRepository_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db)
+	actorID, entityID := 1, 5
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(&actorID, "admin", nil, "sitter.approve", "sitter", &entityID,
+			`{"status":"pending"}`, `{"status":"approved"}`, "POST", "/api/admin/sitters/5/approve", 200, "10.0.0.1").
+		WillReturnRows(sqlmock.NewRows([]string{"entry_id", "created_at"}).AddRow(42, now))
+
+	entry := &Entry{
+		ActorID:    &actorID,
+		ActorRole:  "admin",
+		Action:     "sitter.approve",
+		EntityType: "sitter",
+		EntityID:   &entityID,
+		Before:     []byte(`{"status":"pending"}`),
+		After:      []byte(`{"status":"approved"}`),
+		Method:     "POST",
+		Path:       "/api/admin/sitters/5/approve",
+		Status:     200,
+		IP:         "10.0.0.1",
+	}
+	if err := repo.Insert(context.Background(), entry); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if entry.EntryID != 42 {
+		t.Errorf("expected entry id 42, got %d", entry.EntryID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_InsertWithoutChange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(nil, "", nil, "POST /api/auth/login", "", nil, nil, nil, "POST", "/api/auth/login", 401, "10.0.0.1").
+		WillReturnRows(sqlmock.NewRows([]string{"entry_id", "created_at"}).AddRow(43, time.Now()))
+
+	entry := &Entry{Action: "POST /api/auth/login", Method: "POST", Path: "/api/auth/login", Status: 401, IP: "10.0.0.1"}
+	if err := repo.Insert(context.Background(), entry); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	q, err := ListSpec.Parse(map[string][]string{"entity_type": {"booking"}, "limit": {"1"}})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	columns := []string{"entry_id", "actor_id", "actor_role", "impersonator_id", "action", "entity_type", "entity_id", "before", "after",
+		"method", "path", "status", "ip", "created_at"}
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM audit_log WHERE TRUE AND entity_type = ANY").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(2, 3, "owner", 1, "booking.cancel", "booking", 7, []byte(`{"status":"confirmed"}`), []byte(`{"status":"cancelled_by_owner"}`),
+				"POST", "/api/bookings/7/cancel", 200, "10.0.0.1", now).
+			AddRow(1, nil, "", nil, "POST /api/auth/login", "", nil, nil, nil,
+				"POST", "/api/auth/login", 401, "10.0.0.1", now))
+
+	page, err := repo.GetEntries(context.Background(), q)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(page.Items) != 1 || page.NextCursor == "" {
+		t.Fatalf("expected 1 entry and a next page, got %d entries", len(page.Items))
+	}
+	entry := page.Items[0]
+	if *entry.ActorID != 3 || *entry.ImpersonatorID != 1 || *entry.EntityID != 7 || string(entry.After) != `{"status":"cancelled_by_owner"}` {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+// Package audit keeps the append-only record of who changed what. Its
+// middleware writes one entry for every state-changing request, and for
+// every request made with an impersonation token; services add what the
+// request did with Describe.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"nanny-backend/internal/common/listing"
+)
+
+// Entry is one recorded request. ActorID is nil for anonymous requests
+// such as logins; ImpersonatorID is the admin when the actor was being
+// impersonated. Before and After hold only the fields the action changed
+// and are empty when nothing described the change.
+type Entry struct {
+	EntryID        int             `json:"entry_id"`
+	ActorID        *int            `json:"actor_id,omitempty"`
+	ActorRole      string          `json:"actor_role,omitempty"`
+	ImpersonatorID *int            `json:"impersonator_id,omitempty"`
+	Action         string          `json:"action"`
+	EntityType     string          `json:"entity_type,omitempty"`
+	EntityID       *int            `json:"entity_id,omitempty"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	Method         string          `json:"method"`
+	Path           string          `json:"path"`
+	Status         int             `json:"status"`
+	IP             string          `json:"ip"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// Recorder stores entries; Middleware only needs this much.
+type Recorder interface {
+	Record(ctx context.Context, entry *Entry) error
+}
+
+type Service interface {
+	Recorder
+	GetEntries(ctx context.Context, q listing.Query) (*listing.Page[Entry], error)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Record(ctx context.Context, entry *Entry) error {
+	return s.repo.Insert(ctx, entry)
+}
+
+func (s *service) GetEntries(ctx context.Context, q listing.Query) (*listing.Page[Entry], error) {
+	return s.repo.GetEntries(ctx, q)
+}
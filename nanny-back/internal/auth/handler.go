@@ -2,8 +2,11 @@ package auth
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
 	"nanny-backend/pkg/validator"
 )
 
@@ -38,42 +41,49 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,min=1"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
 func (h *Handler) RegisterOwner(w http.ResponseWriter, r *http.Request) {
 	var req RegisterOwnerRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if err := validator.Validate(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	err := h.service.RegisterOwner(req.FullName, req.Email, req.Phone, req.Password)
+	err := h.service.RegisterOwner(r.Context(), req.FullName, req.Email, req.Phone, req.Password)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, map[string]string{
+	httpx.JSON(w, http.StatusCreated, map[string]string{
 		"message": "owner registered succesfully",
 	})
 }
 
 func (h *Handler) RegisterSitter(w http.ResponseWriter, r *http.Request) {
 	var req RegisterSitterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
-		return
-	}
-
-	if err := validator.Validate(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err := h.service.RegisterSitter(
+	err := h.service.RegisterSitter(r.Context(),
 		req.FullName,
 		req.Email,
 		req.Phone,
@@ -84,49 +94,180 @@ func (h *Handler) RegisterSitter(w http.ResponseWriter, r *http.Request) {
 		req.Location,
 	)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, map[string]string{
+	httpx.JSON(w, http.StatusCreated, map[string]string{
 		"message": "nanny registered, expecting acceptance",
 	})
 }
 
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if err := validator.Validate(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+	user, tokens, err := h.service.Login(r.Context(), req.Email, req.Password)
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	user, token, err := h.service.Login(req.Email, req.Password)
+	httpx.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":        "login happened",
+		"user_id":        user.UserID,
+		"role":           user.Role,
+		"email":          user.Email,
+		"full_name":      user.FullName,
+		"email_verified": user.EmailVerifiedAt != nil,
+		"token":          tokens.AccessToken,
+		"refresh_token":  tokens.RefreshToken,
+		"expires_in":     tokens.ExpiresIn,
+	})
+}
+
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), req.RefreshToken)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, err.Error())
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]interface{}{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]interface{}{
-		"message":   "login happened",
-		"user_id":   user.UserID,
-		"role":      user.Role,
-		"email":     user.Email,
-		"full_name": user.FullName,
-		"token":     token,
+	if err := h.service.Logout(r.Context(), req.RefreshToken); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "logged out",
 	})
 }
 
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
+func (h *Handler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	if err := h.service.LogoutAll(r.Context(), req.RefreshToken); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "logged out from all devices",
+	})
 }
 
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(payload)
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		httpx.Error(w, r, fmt.Errorf("could not send reset email: %w", err))
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "if the account exists, a reset link has been sent",
+	})
+}
+
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "password changed",
+	})
+}
+
+// VerifyEmail accepts the token either as ?token= (the link from the
+// email) or in a JSON body.
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	req := VerifyEmailRequest{Token: r.URL.Query().Get("token")}
+	if req.Token == "" && r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpx.Error(w, r, httpx.ErrMalformedBody)
+			return
+		}
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	if err := h.service.VerifyEmail(r.Context(), req.Token); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "email verified",
+	})
+}
+
+// Impersonate answers like Login, minus the refresh token, with
+// "impersonated_by" set to the admin so clients can show it.
+func (h *Handler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	userID, err := httpx.PathID(r, "user_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	actor := middleware.ActorFromContext(r.Context())
+
+	user, tokens, err := h.service.Impersonate(r.Context(), actor, userID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]interface{}{
+		"message":         "impersonation started",
+		"user_id":         user.UserID,
+		"role":            user.Role,
+		"email":           user.Email,
+		"full_name":       user.FullName,
+		"token":           tokens.AccessToken,
+		"expires_in":      tokens.ExpiresIn,
+		"impersonated_by": actor.UserID,
+	})
 }
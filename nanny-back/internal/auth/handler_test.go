@@ -2,14 +2,18 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
 	"nanny-backend/internal/common/models"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,12 +22,12 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) RegisterOwner(fullName, email, phone, password string) error {
+func (m *MockService) RegisterOwner(ctx context.Context, fullName, email, phone, password string) error {
 	args := m.Called(fullName, email, phone, password)
 	return args.Error(0)
 }
 
-func (m *MockService) RegisterSitter(
+func (m *MockService) RegisterSitter(ctx context.Context,
 	fullName, email, phone, password string,
 	experienceYears int,
 	certificates, preferences, location string,
@@ -41,12 +45,63 @@ func (m *MockService) RegisterSitter(
 	return args.Error(0)
 }
 
-func (m *MockService) Login(email, password string) (*models.User, string, error) {
+func (m *MockService) Login(ctx context.Context, email, password string) (*models.User, *TokenPair, error) {
 	args := m.Called(email, password)
 	if args.Get(0) == nil {
-		return nil, "", args.Error(2)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).(*models.User), args.String(1), args.Error(2)
+	return args.Get(0).(*models.User), args.Get(1).(*TokenPair), args.Error(2)
+}
+
+func (m *MockService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*TokenPair), args.Error(1)
+}
+
+func (m *MockService) Logout(ctx context.Context, refreshToken string) error {
+	args := m.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockService) LogoutAll(ctx context.Context, refreshToken string) error {
+	args := m.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockService) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	args := m.Called(sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) CheckAccount(ctx context.Context, userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockService) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	args := m.Called(resetToken, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockService) VerifyEmail(ctx context.Context, verificationToken string) error {
+	args := m.Called(verificationToken)
+	return args.Error(0)
+}
+
+func (m *MockService) Impersonate(ctx context.Context, actor authz.Actor, userID int) (*models.User, *TokenPair, error) {
+	args := m.Called(actor, userID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*models.User), args.Get(1).(*TokenPair), args.Error(2)
 }
 
 func TestHandler_RegisterOwner_Success(t *testing.T) {
@@ -97,9 +152,9 @@ func TestHandler_RegisterOwner_InvalidBody(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "not reliable data")
+	assert.Equal(t, "malformed_body", resp.Code)
 }
 
 func TestHandler_RegisterOwner_ValidationError(t *testing.T) {
@@ -147,15 +202,15 @@ func TestHandler_RegisterOwner_ServiceError(t *testing.T) {
 			reqBody.Phone,
 			reqBody.Password,
 		).
-		Return(errors.New("email already exists"))
+		Return(ErrEmailTaken)
 
 	handler.RegisterOwner(rec, req)
 
-	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, http.StatusConflict, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "email already exists")
+	assert.Equal(t, "email_taken", resp.Code)
 
 	mockService.AssertExpectations(t)
 }
@@ -216,9 +271,9 @@ func TestHandler_RegisterSitter_InvalidBody(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "not reliable data")
+	assert.Equal(t, "malformed_body", resp.Code)
 }
 
 func TestHandler_RegisterSitter_ValidationError(t *testing.T) {
@@ -276,15 +331,15 @@ func TestHandler_RegisterSitter_ServiceError(t *testing.T) {
 			reqBody.Preferences,
 			reqBody.Location,
 		).
-		Return(errors.New("email already exists"))
+		Return(ErrEmailTaken)
 
 	handler.RegisterSitter(rec, req)
 
-	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, http.StatusConflict, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "email already exists")
+	assert.Equal(t, "email_taken", resp.Code)
 
 	mockService.AssertExpectations(t)
 }
@@ -312,7 +367,7 @@ func TestHandler_Login_Success(t *testing.T) {
 
 	mockService.
 		On("Login", reqBody.Email, reqBody.Password).
-		Return(user, "jwt-token", nil)
+		Return(user, &TokenPair{AccessToken: "jwt-token", RefreshToken: "refresh-token", ExpiresIn: 900}, nil)
 
 	handler.Login(rec, req)
 
@@ -325,6 +380,7 @@ func TestHandler_Login_Success(t *testing.T) {
 	assert.Equal(t, float64(1), resp["user_id"])
 	assert.Equal(t, "owner", resp["role"])
 	assert.Equal(t, "jwt-token", resp["token"])
+	assert.Equal(t, "refresh-token", resp["refresh_token"])
 
 	mockService.AssertExpectations(t)
 }
@@ -345,15 +401,15 @@ func TestHandler_Login_InvalidCredentials(t *testing.T) {
 
 	mockService.
 		On("Login", reqBody.Email, reqBody.Password).
-		Return(nil, "", errors.New("not correct email or password"))
+		Return(nil, "", ErrInvalidCredentials)
 
 	handler.Login(rec, req)
 
 	assert.Equal(t, http.StatusUnauthorized, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "not correct email or password")
+	assert.Equal(t, "invalid_credentials", resp.Code)
 
 	mockService.AssertExpectations(t)
 }
@@ -369,9 +425,9 @@ func TestHandler_Login_InvalidBody(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect data")
+	assert.Equal(t, "malformed_body", resp.Code)
 }
 
 func TestHandler_Login_ValidationError(t *testing.T) {
@@ -392,3 +448,163 @@ func TestHandler_Login_ValidationError(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
+
+func TestHandler_Refresh_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(RefreshRequest{RefreshToken: "old-refresh"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(body))
+	rec := httptest.NewRecorder()
+
+	mockService.
+		On("Refresh", "old-refresh").
+		Return(&TokenPair{AccessToken: "new-access", RefreshToken: "new-refresh", ExpiresIn: 900}, nil)
+
+	handler.Refresh(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	assert.Equal(t, "new-access", resp["token"])
+	assert.Equal(t, "new-refresh", resp["refresh_token"])
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Refresh_InvalidToken(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(RefreshRequest{RefreshToken: "revoked"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(body))
+	rec := httptest.NewRecorder()
+
+	mockService.
+		On("Refresh", "revoked").
+		Return(nil, ErrInvalidRefreshToken)
+
+	handler.Refresh(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Logout_MissingToken(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", bytes.NewBufferString(`{}`))
+	rec := httptest.NewRecorder()
+
+	handler.Logout(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "Logout", mock.Anything)
+}
+
+func TestHandler_LogoutAll_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(RefreshRequest{RefreshToken: "refresh"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout/all", bytes.NewBuffer(body))
+	rec := httptest.NewRecorder()
+
+	mockService.On("LogoutAll", "refresh").Return(nil)
+
+	handler.LogoutAll(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ForgotPassword_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(ForgotPasswordRequest{Email: "user@test.com"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewBuffer(body))
+	rec := httptest.NewRecorder()
+
+	mockService.On("RequestPasswordReset", "user@test.com").Return(nil)
+
+	handler.ForgotPassword(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ResetPassword_InvalidToken(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(ResetPasswordRequest{Token: "used", Password: "newpassword1"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/reset", bytes.NewBuffer(body))
+	rec := httptest.NewRecorder()
+
+	mockService.On("ResetPassword", "used", "newpassword1").Return(ErrInvalidActionToken)
+
+	handler.ResetPassword(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ResetPassword_ShortPassword(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(ResetPasswordRequest{Token: "token", Password: "short"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/reset", bytes.NewBuffer(body))
+	rec := httptest.NewRecorder()
+
+	handler.ResetPassword(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything)
+}
+
+func TestHandler_VerifyEmail_FromLink(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/verify?token=abc", nil)
+	rec := httptest.NewRecorder()
+
+	mockService.On("VerifyEmail", "abc").Return(nil)
+
+	handler.VerifyEmail(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Impersonate(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users/7/impersonate", nil)
+	admin := authz.Actor{UserID: 1, Role: authz.RoleAdmin}
+	req = mux.SetURLVars(req, map[string]string{"user_id": "7"})
+	req = middleware.WithActor(req, admin)
+	rec := httptest.NewRecorder()
+
+	mockService.
+		On("Impersonate", admin, 7).
+		Return(&models.User{UserID: 7, Role: "owner", Email: "owner@test.com"}, &TokenPair{AccessToken: "jwt-token", ExpiresIn: 900}, nil)
+
+	handler.Impersonate(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	assert.Equal(t, float64(7), resp["user_id"])
+	assert.Equal(t, "jwt-token", resp["token"])
+	assert.Equal(t, float64(1), resp["impersonated_by"])
+	assert.NotContains(t, resp, "refresh_token")
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nanny-backend/internal/audit"
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/models"
+	"nanny-backend/internal/common/token"
+)
+
+var ErrCannotImpersonateAdmin = apperr.Forbidden("cannot_impersonate_admin", "admins cannot be impersonated")
+
+// Impersonate lets an admin see the API as userID does. The access token
+// it returns lives for the configured impersonation TTL, carries the
+// admin in its "act" claim and comes without a refresh token: when it
+// expires the admin asks for a new one. It is backed by a session of its
+// own, so revoking the user's sessions ends it too.
+func (s *service) Impersonate(ctx context.Context, actor authz.Actor, userID int) (*models.User, *TokenPair, error) {
+	if !actor.IsAdmin() {
+		return nil, nil, authz.ErrForbidden
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.DeletedAt != nil {
+		return nil, nil, ErrUserNotFound
+	}
+	if user.Role == authz.RoleAdmin {
+		return nil, nil, ErrCannotImpersonateAdmin
+	}
+	if err := accountRestriction(user, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	sessionID, err := randomToken(16)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating session: %w", err)
+	}
+
+	// The session needs a refresh hash; nobody is given the token.
+	unused, err := randomToken(32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating session: %w", err)
+	}
+
+	err = s.repo.CreateSession(ctx, &models.Session{
+		SessionID:        sessionID,
+		UserID:           user.UserID,
+		RefreshTokenHash: hashToken(unused),
+		ExpiresAt:        time.Now().Add(s.impersonationTTL),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating session: %w", err)
+	}
+
+	accessToken, err := s.tokens.IssueFor(token.Claims{
+		UserID:    user.UserID,
+		Role:      user.Role,
+		SessionID: sessionID,
+		Act:       &token.Act{UserID: actor.UserID},
+	}, s.impersonationTTL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error generating token: %w", err)
+	}
+
+	audit.Describe(ctx, "user.impersonate", "user", user.UserID, nil, map[string]interface{}{
+		"role":       user.Role,
+		"expires_in": int64(s.impersonationTTL.Seconds()),
+	})
+
+	return user, &TokenPair{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(s.impersonationTTL.Seconds()),
+	}, nil
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/mailer"
+	"nanny-backend/internal/common/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PurposePasswordReset     = "password_reset"
+	PurposeEmailVerification = "email_verification"
+)
+
+var ErrInvalidActionToken = apperr.Validation("invalid_token", "invalid or expired token")
+
+// RequestPasswordReset always succeeds for unknown emails so the endpoint
+// can't be used to find out who has an account.
+func (s *service) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+
+	resetToken, err := s.createUserToken(ctx, user.UserID, PurposePasswordReset, s.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"Hello, %s!\n\nTo set a new password open the link below. It is valid for %s and can be used once.\n\n%s/reset-password.html?token=%s\n\nIf you did not ask for this, just ignore this email.",
+			user.FullName, s.resetTTL, s.appBaseURL, resetToken,
+		),
+	})
+}
+
+func (s *service) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	// The link stays usable unless the new password is really stored.
+	return s.repo.WithTx(ctx, func(ctx context.Context) error {
+		userID, err := s.repo.ConsumeUserToken(ctx, hashToken(resetToken), PurposePasswordReset)
+		if err != nil {
+			return ErrInvalidActionToken
+		}
+
+		if err := s.repo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+			return err
+		}
+
+		// Older reset links and every logged-in device go away with the old
+		// password.
+		if err := s.repo.InvalidateUserTokens(ctx, userID, PurposePasswordReset); err != nil {
+			return err
+		}
+		return s.repo.RevokeUserSessions(ctx, userID)
+	})
+}
+
+func (s *service) VerifyEmail(ctx context.Context, verificationToken string) error {
+	userID, err := s.repo.ConsumeUserToken(ctx, hashToken(verificationToken), PurposeEmailVerification)
+	if err != nil {
+		return ErrInvalidActionToken
+	}
+
+	return s.repo.MarkEmailVerified(ctx, userID)
+}
+
+// sendVerification is best effort: a mail outage must not fail the
+// registration itself.
+func (s *service) sendVerification(ctx context.Context, user *models.User) {
+	verificationToken, err := s.createUserToken(ctx, user.UserID, PurposeEmailVerification, s.verificationTTL)
+	if err != nil {
+		log.Printf("⚠️ could not create verification token for user %d: %v", user.UserID, err)
+		return
+	}
+
+	err = s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf(
+			"Hello, %s!\n\nPlease confirm your email by opening the link below:\n\n%s/api/auth/verify?token=%s",
+			user.FullName, s.appBaseURL, verificationToken,
+		),
+	})
+	if err != nil {
+		log.Printf("⚠️ could not send verification email to user %d: %v", user.UserID, err)
+	}
+}
+
+func (s *service) createUserToken(ctx context.Context, userID int, purpose string, ttl time.Duration) (string, error) {
+	value, err := randomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("error creating token: %w", err)
+	}
+
+	if err := s.repo.CreateUserToken(ctx, userID, purpose, hashToken(value), time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+	return value, nil
+}
@@ -1,16 +1,49 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
 	"nanny-backend/internal/common/models"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is raised when the email is already registered.
+const uniqueViolation = "23505"
+
+var (
+	ErrEmailTaken   = apperr.Conflict("email_taken", "email already exists")
+	ErrUserNotFound = apperr.NotFound("user_not_found", "user not found")
 )
 
 type Repository interface {
-	CreateUser(user *models.User) (int, error)
-	GetUserByEmail(email string) (*models.User, error)
-	CreateSitter(sitter *models.Sitter) error
+	// WithTx makes the calls fn makes with its ctx one transaction.
+	database.Transactor
+
+	CreateUser(ctx context.Context, user *models.User) (int, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	CreateSitter(ctx context.Context, sitter *models.Sitter) error
+	GetUserByID(ctx context.Context, userID int) (*models.User, error)
+
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSessionByRefreshHash(ctx context.Context, hash string) (*models.Session, error)
+	GetSessionByPreviousHash(ctx context.Context, hash string) (*models.Session, error)
+	RotateSession(ctx context.Context, sessionID, oldHash, newHash string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeUserSessions(ctx context.Context, userID int) error
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+
+	CreateUserToken(ctx context.Context, userID int, purpose, hash string, expiresAt time.Time) error
+	ConsumeUserToken(ctx context.Context, hash, purpose string) (int, error)
+	InvalidateUserTokens(ctx context.Context, userID int, purpose string) error
+	UpdatePassword(ctx context.Context, userID int, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, userID int) error
 }
 
 type repository struct {
@@ -21,14 +54,25 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) CreateUser(user *models.User) (int, error) {
+func (r *repository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.WithTx(ctx, r.db, fn)
+}
+
+func (r *repository) CreateUser(ctx context.Context, user *models.User) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	var userID int
-	err := r.db.QueryRow(`
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `
 		INSERT INTO users (full_name, email, phone, password_hash, role)
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING user_id
 	`, user.FullName, user.Email, user.Phone, user.PasswordHash, user.Role).Scan(&userID)
 
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return 0, ErrEmailTaken
+	}
 	if err != nil {
 		return 0, fmt.Errorf("could not create a user: %w", err)
 	}
@@ -36,13 +80,11 @@ func (r *repository) CreateUser(user *models.User) (int, error) {
 	return userID, nil
 }
 
-func (r *repository) GetUserByEmail(email string) (*models.User, error) {
-	user := &models.User{}
-	err := r.db.QueryRow(`
-		SELECT user_id, full_name, email, phone, password_hash, role, created_at
-		FROM users
-		WHERE email = $1
-	`, email).Scan(
+const userColumns = `user_id, full_name, email, phone, password_hash, role, created_at, email_verified_at,
+		suspended_until, banned_at, restriction_reason, deleted_at`
+
+func scanUser(row *sql.Row, user *models.User) error {
+	return row.Scan(
 		&user.UserID,
 		&user.FullName,
 		&user.Email,
@@ -50,10 +92,27 @@ func (r *repository) GetUserByEmail(email string) (*models.User, error) {
 		&user.PasswordHash,
 		&user.Role,
 		&user.CreatedAt,
+		&user.EmailVerifiedAt,
+		&user.SuspendedUntil,
+		&user.BannedAt,
+		&user.RestrictionReason,
+		&user.DeletedAt,
 	)
+}
+
+func (r *repository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	err := scanUser(database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+userColumns+`
+		FROM users
+		WHERE email = $1
+	`, email), user)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting user: %w", err)
@@ -62,8 +121,11 @@ func (r *repository) GetUserByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
-func (r *repository) CreateSitter(sitter *models.Sitter) error {
-	_, err := r.db.Exec(`
+func (r *repository) CreateSitter(ctx context.Context, sitter *models.Sitter) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
 		INSERT INTO sitters (sitter_id, experience_years, certificates, preferences, location, status)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`, sitter.SitterID, sitter.ExperienceYears, sitter.Certificates, sitter.Preferences, sitter.Location, sitter.Status)
@@ -74,3 +136,255 @@ func (r *repository) CreateSitter(sitter *models.Sitter) error {
 
 	return nil
 }
+
+func (r *repository) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	err := scanUser(database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+userColumns+`
+		FROM users
+		WHERE user_id = $1
+	`, userID), user)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *repository) CreateSession(ctx context.Context, session *models.Session) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO sessions (session_id, user_id, refresh_token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, session.SessionID, session.UserID, session.RefreshTokenHash, session.ExpiresAt)
+
+	if err != nil {
+		return fmt.Errorf("could not create a session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetSessionByRefreshHash(ctx context.Context, hash string) (*models.Session, error) {
+	return r.getSession(ctx, `WHERE refresh_token_hash = $1`, hash)
+}
+
+func (r *repository) GetSessionByPreviousHash(ctx context.Context, hash string) (*models.Session, error) {
+	return r.getSession(ctx, `WHERE previous_token_hash = $1`, hash)
+}
+
+func (r *repository) getSession(ctx context.Context, where string, arg interface{}) (*models.Session, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	session := &models.Session{}
+	var previousHash sql.NullString
+	var revokedAt sql.NullTime
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT session_id, user_id, refresh_token_hash, previous_token_hash,
+		       expires_at, created_at, last_used_at, revoked_at
+		FROM sessions
+		`+where, arg).Scan(
+		&session.SessionID,
+		&session.UserID,
+		&session.RefreshTokenHash,
+		&previousHash,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&revokedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting session: %w", err)
+	}
+
+	session.PreviousTokenHash = previousHash.String
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+
+	return session, nil
+}
+
+// RotateSession swaps the refresh token hash only if oldHash is still the
+// current one, so two concurrent refreshes with the same token can't both win.
+func (r *repository) RotateSession(ctx context.Context, sessionID, oldHash, newHash string, expiresAt time.Time) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE sessions
+		SET previous_token_hash = refresh_token_hash,
+		    refresh_token_hash = $1,
+		    expires_at = $2,
+		    last_used_at = NOW()
+		WHERE session_id = $3 AND refresh_token_hash = $4 AND revoked_at IS NULL
+	`, newHash, expiresAt, sessionID, oldHash)
+
+	if err != nil {
+		return fmt.Errorf("could not rotate session: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not rotate session: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("session was already rotated or revoked")
+	}
+
+	return nil
+}
+
+func (r *repository) RevokeSession(ctx context.Context, sessionID string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE session_id = $1 AND revoked_at IS NULL
+	`, sessionID)
+
+	if err != nil {
+		return fmt.Errorf("could not revoke session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) RevokeUserSessions(ctx context.Context, userID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+
+	if err != nil {
+		return fmt.Errorf("could not revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var active bool
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM sessions
+			WHERE session_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		)
+	`, sessionID).Scan(&active)
+
+	if err != nil {
+		return false, fmt.Errorf("error checking session: %w", err)
+	}
+
+	return active, nil
+}
+
+func (r *repository) CreateUserToken(ctx context.Context, userID int, purpose, hash string, expiresAt time.Time) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO user_tokens (token_hash, user_id, purpose, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, hash, userID, purpose, expiresAt)
+
+	if err != nil {
+		return fmt.Errorf("could not create token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeUserToken marks the token used and returns its user in one
+// statement, so a token can't be redeemed twice by concurrent requests.
+func (r *repository) ConsumeUserToken(ctx context.Context, hash, purpose string) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var userID int
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`, hash, purpose).Scan(&userID)
+
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("token not found or already used")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error using token: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (r *repository) InvalidateUserTokens(ctx context.Context, userID int, purpose string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`, userID, purpose)
+
+	if err != nil {
+		return fmt.Errorf("could not invalidate tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `UPDATE users SET password_hash = $1 WHERE user_id = $2`, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("could not update password: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) MarkEmailVerified(ctx context.Context, userID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE users
+		SET email_verified_at = NOW()
+		WHERE user_id = $1 AND email_verified_at IS NULL
+	`, userID)
+
+	if err != nil {
+		return fmt.Errorf("could not verify email: %w", err)
+	}
+
+	return nil
+}
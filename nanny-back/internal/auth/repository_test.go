@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"nanny-backend/internal/common/models"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,7 +35,7 @@ func TestCreateUser_Success(t *testing.T) {
 		WithArgs(user.FullName, user.Email, user.Phone, user.PasswordHash, user.Role).
 		WillReturnRows(rows)
 
-	userID, err := repo.CreateUser(user)
+	userID, err := repo.CreateUser(context.Background(), user)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, userID)
@@ -59,7 +61,7 @@ func TestCreateUser_Error(t *testing.T) {
 		WithArgs(user.FullName, user.Email, user.Phone, user.PasswordHash, user.Role).
 		WillReturnError(errors.New("duplicate email"))
 
-	userID, err := repo.CreateUser(user)
+	userID, err := repo.CreateUser(context.Background(), user)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, userID)
@@ -67,6 +69,22 @@ func TestCreateUser_Error(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCreateUser_EmailTaken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	mock.ExpectQuery(`INSERT INTO users`).
+		WillReturnError(&pq.Error{Code: uniqueViolation, Message: "duplicate key value violates unique constraint"})
+
+	_, err = repo.CreateUser(context.Background(), &models.User{Email: "test@mail.com"})
+
+	assert.ErrorIs(t, err, ErrEmailTaken)
+	assert.NotContains(t, err.Error(), "duplicate key")
+}
+
 func TestGetUserByEmail_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -84,6 +102,11 @@ func TestGetUserByEmail_Success(t *testing.T) {
 		"password_hash",
 		"role",
 		"created_at",
+		"email_verified_at",
+		"suspended_until",
+		"banned_at",
+		"restriction_reason",
+		"deleted_at",
 	}).AddRow(
 		1,
 		"Test User",
@@ -92,13 +115,18 @@ func TestGetUserByEmail_Success(t *testing.T) {
 		"hashed_password",
 		"owner",
 		now,
+		nil,
+		nil,
+		nil,
+		"",
+		nil,
 	)
 
 	mock.ExpectQuery(`SELECT (.+) FROM users WHERE email`).
 		WithArgs("test@mail.com").
 		WillReturnRows(rows)
 
-	user, err := repo.GetUserByEmail("test@mail.com")
+	user, err := repo.GetUserByEmail(context.Background(), "test@mail.com")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
@@ -120,7 +148,7 @@ func TestGetUserByEmail_NotFound(t *testing.T) {
 		WithArgs("notfound@mail.com").
 		WillReturnError(sql.ErrNoRows)
 
-	user, err := repo.GetUserByEmail("notfound@mail.com")
+	user, err := repo.GetUserByEmail(context.Background(), "notfound@mail.com")
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
@@ -139,7 +167,7 @@ func TestGetUserByEmail_DatabaseError(t *testing.T) {
 		WithArgs("test@mail.com").
 		WillReturnError(errors.New("database connection error"))
 
-	user, err := repo.GetUserByEmail("test@mail.com")
+	user, err := repo.GetUserByEmail(context.Background(), "test@mail.com")
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
@@ -164,6 +192,11 @@ func TestGetUserByEmail_SitterRole(t *testing.T) {
 		"password_hash",
 		"role",
 		"created_at",
+		"email_verified_at",
+		"suspended_until",
+		"banned_at",
+		"restriction_reason",
+		"deleted_at",
 	}).AddRow(
 		2,
 		"Test Sitter",
@@ -172,13 +205,18 @@ func TestGetUserByEmail_SitterRole(t *testing.T) {
 		"hashed_password",
 		"sitter",
 		now,
+		nil,
+		nil,
+		nil,
+		"",
+		nil,
 	)
 
 	mock.ExpectQuery(`SELECT (.+) FROM users WHERE email`).
 		WithArgs("sitter@mail.com").
 		WillReturnRows(rows)
 
-	user, err := repo.GetUserByEmail("sitter@mail.com")
+	user, err := repo.GetUserByEmail(context.Background(), "sitter@mail.com")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
@@ -214,7 +252,7 @@ func TestCreateSitter_Success(t *testing.T) {
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.CreateSitter(sitter)
+	err = repo.CreateSitter(context.Background(), sitter)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -247,7 +285,7 @@ func TestCreateSitter_Error(t *testing.T) {
 		).
 		WillReturnError(errors.New("foreign key constraint failed"))
 
-	err = repo.CreateSitter(sitter)
+	err = repo.CreateSitter(context.Background(), sitter)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create a nanny profile")
@@ -281,7 +319,7 @@ func TestCreateSitter_DuplicateID(t *testing.T) {
 		).
 		WillReturnError(errors.New("duplicate key value"))
 
-	err = repo.CreateSitter(sitter)
+	err = repo.CreateSitter(context.Background(), sitter)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create nanny profile")
@@ -297,3 +335,77 @@ func TestNewRepository(t *testing.T) {
 
 	assert.NotNil(t, repo)
 }
+
+func TestRotateSession_AlreadyRotated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectExec(`UPDATE sessions`).
+		WithArgs("new-hash", expiresAt, "sid-1", "old-hash").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.RotateSession(context.Background(), "sid-1", "old-hash", "new-hash", expiresAt)
+
+	assert.EqualError(t, err, "session was already rotated or revoked")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsSessionActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs("sid-1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	active, err := repo.IsSessionActive(context.Background(), "sid-1")
+
+	assert.NoError(t, err)
+	assert.True(t, active)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConsumeUserToken_AlreadyUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	mock.ExpectQuery(`UPDATE user_tokens`).
+		WithArgs("hash", "password_reset").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.ConsumeUserToken(context.Background(), "hash", "password_reset")
+
+	assert.EqualError(t, err, "token not found or already used")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterSitter_RollsBackUserWhenProfileFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewService(NewRepository(db), testTokens, &recordingMailer{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO users`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(7))
+	mock.ExpectExec(`INSERT INTO sitters`).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	err = service.RegisterSitter(context.Background(),
+		"Test Sitter", "sitter@mail.com", "+77001234567", "password123", 5, "CPR", "Dogs", "Almaty")
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,37 +1,83 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/mailer"
 	"nanny-backend/internal/common/models"
+	"nanny-backend/internal/common/token"
 	"nanny-backend/pkg/config"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidRefreshToken = apperr.Unauthorized("invalid_refresh_token", "invalid or expired refresh token")
+	ErrInvalidCredentials  = apperr.Unauthorized("invalid_credentials", "incorrect email or password")
+	ErrAccountSuspended    = apperr.Forbidden("account_suspended", "account suspended")
+	ErrAccountBanned       = apperr.Forbidden("account_banned", "account banned")
+	ErrAccountDeleted      = apperr.Unauthorized("account_deleted", "account deleted")
+)
+
 type Service interface {
-	RegisterOwner(fullName, email, phone, password string) error
-	RegisterSitter(fullName, email, phone, password string, experienceYears int, certificates, preferences, location string) error
-	Login(email, password string) (*models.User, string, error) // ← token added
+	RegisterOwner(ctx context.Context, fullName, email, phone, password string) error
+	RegisterSitter(ctx context.Context, fullName, email, phone, password string, experienceYears int, certificates, preferences, location string) error
+	Login(ctx context.Context, email, password string) (*models.User, *TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, refreshToken string) error
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+	CheckAccount(ctx context.Context, userID int) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, resetToken, newPassword string) error
+	VerifyEmail(ctx context.Context, verificationToken string) error
+	Impersonate(ctx context.Context, actor authz.Actor, userID int) (*models.User, *TokenPair, error)
+}
+
+// TokenPair is a short-lived access token plus the refresh token that can
+// be exchanged for a new pair via /api/auth/refresh.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
 }
 
 type service struct {
-	repo      Repository
-	jwtSecret string
+	repo             Repository
+	tokens           *token.Manager
+	mail             mailer.Mailer
+	refreshTTL       time.Duration
+	resetTTL         time.Duration
+	verificationTTL  time.Duration
+	impersonationTTL time.Duration
+	appBaseURL       string
 }
 
-func NewService(repo Repository) Service {
+func NewService(repo Repository, tokens *token.Manager, mail mailer.Mailer) Service {
 	cfg := config.Load()
 
 	return &service{
-		repo:      repo,
-		jwtSecret: cfg.JWTSecret,
+		repo:             repo,
+		tokens:           tokens,
+		mail:             mail,
+		refreshTTL:       cfg.Auth.RefreshTokenTTL,
+		resetTTL:         cfg.Auth.PasswordResetTTL,
+		verificationTTL:  cfg.Auth.EmailVerificationTTL,
+		impersonationTTL: cfg.Auth.ImpersonationTTL,
+		appBaseURL:       cfg.Mail.AppBaseURL,
 	}
 }
 
-func (s *service) RegisterOwner(fullName, email, phone, password string) error {
+func (s *service) RegisterOwner(ctx context.Context, fullName, email, phone, password string) error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("error hashing password: %w", err)
@@ -45,15 +91,20 @@ func (s *service) RegisterOwner(fullName, email, phone, password string) error {
 		Role:         "owner",
 	}
 
-	_, err = s.repo.CreateUser(user)
+	user.UserID, err = s.repo.CreateUser(ctx, user)
+	if errors.Is(err, ErrEmailTaken) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("error registration owner: %w", err)
 	}
 
+	s.sendVerification(ctx, user)
+
 	return nil
 }
 
-func (s *service) RegisterSitter(fullName, email, phone, password string, experienceYears int, certificates, preferences, location string) error {
+func (s *service) RegisterSitter(ctx context.Context, fullName, email, phone, password string, experienceYears int, certificates, preferences, location string) error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("error hashing password: %w", err)
@@ -67,49 +118,213 @@ func (s *service) RegisterSitter(fullName, email, phone, password string, experi
 		Role:         "sitter",
 	}
 
-	userID, err := s.repo.CreateUser(user)
-	if err != nil {
-		return fmt.Errorf("error creating user: %w", err)
-	}
+	// A sitter user without a sitters row could log in but never be
+	// approved, so both rows are written or neither.
+	err = s.repo.WithTx(ctx, func(ctx context.Context) error {
+		userID, err := s.repo.CreateUser(ctx, user)
+		if errors.Is(err, ErrEmailTaken) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("error creating user: %w", err)
+		}
 
-	sitter := &models.Sitter{
-		SitterID:        userID,
-		ExperienceYears: experienceYears,
-		Certificates:    certificates,
-		Preferences:     preferences,
-		Location:        location,
-		Status:          "pending",
-	}
+		sitter := &models.Sitter{
+			SitterID:        userID,
+			ExperienceYears: experienceYears,
+			Certificates:    certificates,
+			Preferences:     preferences,
+			Location:        location,
+			Status:          "pending",
+		}
+
+		if err := s.repo.CreateSitter(ctx, sitter); err != nil {
+			return fmt.Errorf("error creating nanny profile: %w", err)
+		}
 
-	err = s.repo.CreateSitter(sitter)
+		user.UserID = userID
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("error creating nanny profile: %w", err)
+		return err
 	}
 
+	s.sendVerification(ctx, user)
+
 	return nil
 }
 
-func (s *service) Login(email, password string) (*models.User, string, error) {
-	user, err := s.repo.GetUserByEmail(email)
+func (s *service) Login(ctx context.Context, email, password string) (*models.User, *TokenPair, error) {
+	user, err := s.repo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, "", fmt.Errorf("incorrect email or password")
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		return nil, "", fmt.Errorf("incorrect email or password")
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	if err := accountRestriction(user, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	sessionID, err := randomToken(16)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating session: %w", err)
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating session: %w", err)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.UserID,
-		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(), 
+	err = s.repo.CreateSession(ctx, &models.Session{
+		SessionID:        sessionID,
+		UserID:           user.UserID,
+		RefreshTokenHash: hashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(s.refreshTTL),
 	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating session: %w", err)
+	}
+
+	tokens, err := s.issueTokens(user, sessionID, refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	signedToken, err := token.SignedString([]byte(s.jwtSecret))
+	return user, tokens, nil
+}
+
+func (s *service) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	hash := hashToken(refreshToken)
+
+	session, err := s.repo.GetSessionByRefreshHash(ctx, hash)
 	if err != nil {
-		return nil, "", fmt.Errorf("error generating token: %w", err)
+		// A token that was already rotated away is being replayed: assume it
+		// leaked and kill the whole session.
+		if reused, lookupErr := s.repo.GetSessionByPreviousHash(ctx, hash); lookupErr == nil {
+			_ = s.repo.RevokeSession(ctx, reused.SessionID)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
 	}
 
-	return user, signedToken, nil
+	user, err := s.repo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err := accountRestriction(user, time.Now()); err != nil {
+		return nil, err
+	}
+
+	newRefreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing session: %w", err)
+	}
+
+	err = s.repo.RotateSession(ctx, session.SessionID, hash, hashToken(newRefreshToken), time.Now().Add(s.refreshTTL))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issueTokens(user, session.SessionID, newRefreshToken)
+}
+
+func (s *service) Logout(ctx context.Context, refreshToken string) error {
+	session, err := s.repo.GetSessionByRefreshHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	return s.repo.RevokeSession(ctx, session.SessionID)
+}
+
+func (s *service) LogoutAll(ctx context.Context, refreshToken string) error {
+	session, err := s.repo.GetSessionByRefreshHash(ctx, hashToken(refreshToken))
+	if err != nil || session.RevokedAt != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	return s.repo.RevokeUserSessions(ctx, session.UserID)
+}
+
+func (s *service) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+	return s.repo.IsSessionActive(ctx, sessionID)
+}
+
+// CheckAccount is called by AuthMiddleware on every request, so a
+// restriction applies to tokens that were issued before it.
+func (s *service) CheckAccount(ctx context.Context, userID int) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if errors.Is(err, ErrUserNotFound) {
+		return ErrAccountDeleted
+	}
+	if err != nil {
+		return err
+	}
+
+	return accountRestriction(user, time.Now())
+}
+
+// accountRestriction tells a banned, suspended or deleted user why they
+// are locked out.
+func accountRestriction(user *models.User, now time.Time) error {
+	switch {
+	case user.DeletedAt != nil:
+		return ErrAccountDeleted
+	case user.BannedAt != nil:
+		return withReason(ErrAccountBanned, user.RestrictionReason)
+	case user.SuspendedUntil != nil && now.Before(*user.SuspendedUntil):
+		err := fmt.Errorf("%w until %s", ErrAccountSuspended, user.SuspendedUntil.UTC().Format(time.RFC3339))
+		return withReason(err, user.RestrictionReason)
+	}
+	return nil
+}
+
+func withReason(err error, reason string) error {
+	if reason == "" {
+		return err
+	}
+	return fmt.Errorf("%w: %s", err, reason)
+}
+
+func (s *service) issueTokens(user *models.User, sessionID, refreshToken string) (*TokenPair, error) {
+	accessToken, err := s.tokens.Issue(token.Claims{
+		UserID:    user.UserID,
+		Role:      user.Role,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error generating token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.tokens.TTL().Seconds()),
+	}, nil
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is what we store instead of the refresh token itself, so a
+// leaked sessions table can't be replayed.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
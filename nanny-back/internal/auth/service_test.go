@@ -1,26 +1,47 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/mailer"
 	"nanny-backend/internal/common/models"
+	"nanny-backend/internal/common/token"
 )
 
+var testTokens = token.NewManager(token.NewHMACKey("test", []byte("test_secret")), 15*time.Minute, "nanny-backend")
+
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
 type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) CreateUser(user *models.User) (int, error) {
+func (m *MockRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *MockRepository) CreateUser(ctx context.Context, user *models.User) (int, error) {
 	args := m.Called(user)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockRepository) GetUserByEmail(email string) (*models.User, error) {
+func (m *MockRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -28,20 +49,97 @@ func (m *MockRepository) GetUserByEmail(email string) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockRepository) CreateSitter(sitter *models.Sitter) error {
+func (m *MockRepository) CreateSitter(ctx context.Context, sitter *models.Sitter) error {
 	args := m.Called(sitter)
 	return args.Error(0)
 }
 
+func (m *MockRepository) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetSessionByRefreshHash(ctx context.Context, hash string) (*models.Session, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+func (m *MockRepository) GetSessionByPreviousHash(ctx context.Context, hash string) (*models.Session, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+func (m *MockRepository) RotateSession(ctx context.Context, sessionID, oldHash, newHash string, expiresAt time.Time) error {
+	args := m.Called(sessionID, oldHash, newHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RevokeSession(ctx context.Context, sessionID string) error {
+	args := m.Called(sessionID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RevokeUserSessions(ctx context.Context, userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	args := m.Called(sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) CreateUserToken(ctx context.Context, userID int, purpose, hash string, expiresAt time.Time) error {
+	args := m.Called(userID, purpose, hash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ConsumeUserToken(ctx context.Context, hash, purpose string) (int, error) {
+	args := m.Called(hash, purpose)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) InvalidateUserTokens(ctx context.Context, userID int, purpose string) error {
+	args := m.Called(userID, purpose)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	args := m.Called(userID, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkEmailVerified(ctx context.Context, userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
 func TestRegisterOwner_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
 
 	mockRepo.
 		On("CreateUser", mock.Anything).
 		Return(1, nil)
+	mockRepo.
+		On("CreateUserToken", 1, PurposeEmailVerification, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(nil)
 
-	err := service.RegisterOwner(
+	err := service.RegisterOwner(context.Background(),
 		"Test User",
 		"test@mail.com",
 		"+77001234567",
@@ -54,13 +152,13 @@ func TestRegisterOwner_Success(t *testing.T) {
 
 func TestRegisterOwner_EmailExists(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
 
 	mockRepo.
 		On("CreateUser", mock.Anything).
 		Return(0, errors.New("email already exists"))
 
-	err := service.RegisterOwner(
+	err := service.RegisterOwner(context.Background(),
 		"Test User",
 		"test@mail.com",
 		"+77001234567",
@@ -74,7 +172,7 @@ func TestRegisterOwner_EmailExists(t *testing.T) {
 
 func TestRegisterSitter_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
 
 	mockRepo.
 		On("CreateUser", mock.Anything).
@@ -83,8 +181,11 @@ func TestRegisterSitter_Success(t *testing.T) {
 	mockRepo.
 		On("CreateSitter", mock.Anything).
 		Return(nil)
+	mockRepo.
+		On("CreateUserToken", 1, PurposeEmailVerification, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(nil)
 
-	err := service.RegisterSitter(
+	err := service.RegisterSitter(context.Background(),
 		"Test Sitter",
 		"sitter@mail.com",
 		"+77001234567",
@@ -101,13 +202,13 @@ func TestRegisterSitter_Success(t *testing.T) {
 
 func TestRegisterSitter_CreateUserError(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
 
 	mockRepo.
 		On("CreateUser", mock.Anything).
 		Return(0, errors.New("database error"))
 
-	err := service.RegisterSitter(
+	err := service.RegisterSitter(context.Background(),
 		"Test Sitter",
 		"sitter@mail.com",
 		"+77001234567",
@@ -125,7 +226,7 @@ func TestRegisterSitter_CreateUserError(t *testing.T) {
 
 func TestRegisterSitter_CreateSitterError(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
 
 	mockRepo.
 		On("CreateUser", mock.Anything).
@@ -135,7 +236,7 @@ func TestRegisterSitter_CreateSitterError(t *testing.T) {
 		On("CreateSitter", mock.Anything).
 		Return(errors.New("database error"))
 
-	err := service.RegisterSitter(
+	err := service.RegisterSitter(context.Background(),
 		"Test Sitter",
 		"sitter@mail.com",
 		"+77001234567",
@@ -153,7 +254,7 @@ func TestRegisterSitter_CreateSitterError(t *testing.T) {
 
 func TestLogin_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword(
 		[]byte("password123"),
@@ -171,8 +272,11 @@ func TestLogin_Success(t *testing.T) {
 	mockRepo.
 		On("GetUserByEmail", "test@mail.com").
 		Return(user, nil)
+	mockRepo.
+		On("CreateSession", mock.AnythingOfType("*models.Session")).
+		Return(nil)
 
-	resultUser, token, err := service.Login("test@mail.com", "password123")
+	resultUser, token, err := service.Login(context.Background(), "test@mail.com", "password123")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resultUser)
@@ -182,15 +286,77 @@ func TestLogin_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestLogin_RestrictedAccount(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	until := time.Now().Add(48 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		user    models.User
+		wantErr error
+	}{
+		{"suspended", models.User{SuspendedUntil: &until, RestrictionReason: "spam"}, ErrAccountSuspended},
+		{"banned", models.User{BannedAt: &past, RestrictionReason: "fraud"}, ErrAccountBanned},
+		{"suspension over", models.User{SuspendedUntil: &past}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+			user := tt.user
+			user.UserID = 1
+			user.Email = "test@mail.com"
+			user.PasswordHash = string(hashedPassword)
+			user.Role = "owner"
+
+			mockRepo.On("GetUserByEmail", "test@mail.com").Return(&user, nil)
+			mockRepo.On("CreateSession", mock.AnythingOfType("*models.Session")).Return(nil).Maybe()
+
+			_, tokens, err := service.Login(context.Background(), "test@mail.com", "password123")
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				assert.NotNil(t, tokens)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), user.RestrictionReason)
+			assert.Nil(t, tokens)
+			mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+		})
+	}
+}
+
+func TestCheckAccount(t *testing.T) {
+	until := time.Now().Add(time.Hour)
+	deleted := time.Now()
+
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+	mockRepo.On("GetUserByID", 1).Return(&models.User{UserID: 1}, nil)
+	mockRepo.On("GetUserByID", 2).Return(&models.User{UserID: 2, SuspendedUntil: &until}, nil)
+	mockRepo.On("GetUserByID", 3).Return(&models.User{UserID: 3, DeletedAt: &deleted}, nil)
+	mockRepo.On("GetUserByID", 4).Return(nil, ErrUserNotFound)
+
+	assert.NoError(t, service.CheckAccount(context.Background(), 1))
+	assert.ErrorIs(t, service.CheckAccount(context.Background(), 2), ErrAccountSuspended)
+	assert.ErrorIs(t, service.CheckAccount(context.Background(), 3), ErrAccountDeleted)
+	assert.ErrorIs(t, service.CheckAccount(context.Background(), 4), ErrAccountDeleted)
+}
+
 func TestLogin_UserNotFound(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
 
 	mockRepo.
 		On("GetUserByEmail", "wrong@mail.com").
 		Return(nil, errors.New("user not found"))
 
-	user, token, err := service.Login("wrong@mail.com", "password")
+	user, token, err := service.Login(context.Background(), "wrong@mail.com", "password")
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
@@ -201,7 +367,7 @@ func TestLogin_UserNotFound(t *testing.T) {
 
 func TestLogin_WrongPassword(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword(
 		[]byte("correctpassword"),
@@ -219,7 +385,7 @@ func TestLogin_WrongPassword(t *testing.T) {
 		On("GetUserByEmail", "test@mail.com").
 		Return(user, nil)
 
-	resultUser, token, err := service.Login("test@mail.com", "wrongpassword")
+	resultUser, token, err := service.Login(context.Background(), "test@mail.com", "wrongpassword")
 
 	assert.Error(t, err)
 	assert.Nil(t, resultUser)
@@ -230,7 +396,7 @@ func TestLogin_WrongPassword(t *testing.T) {
 
 func TestLogin_SitterRole(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword(
 		[]byte("password123"),
@@ -248,8 +414,11 @@ func TestLogin_SitterRole(t *testing.T) {
 	mockRepo.
 		On("GetUserByEmail", "sitter@mail.com").
 		Return(user, nil)
+	mockRepo.
+		On("CreateSession", mock.AnythingOfType("*models.Session")).
+		Return(nil)
 
-	resultUser, token, err := service.Login("sitter@mail.com", "password123")
+	resultUser, token, err := service.Login(context.Background(), "sitter@mail.com", "password123")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resultUser)
@@ -258,3 +427,223 @@ func TestLogin_SitterRole(t *testing.T) {
 	assert.Equal(t, 2, resultUser.UserID)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestRefresh_RotatesToken(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+	session := &models.Session{
+		SessionID: "sid-1",
+		UserID:    1,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockRepo.On("GetSessionByRefreshHash", hashToken("old-refresh")).Return(session, nil)
+	mockRepo.On("GetUserByID", 1).Return(&models.User{UserID: 1, Role: "owner"}, nil)
+	mockRepo.
+		On("RotateSession", "sid-1", hashToken("old-refresh"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(nil)
+
+	tokens, err := service.Refresh(context.Background(), "old-refresh")
+
+	assert.NoError(t, err)
+	claims, err := testTokens.Verify(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "sid-1", claims.SessionID)
+	assert.NotEqual(t, "old-refresh", tokens.RefreshToken)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefresh_ReusedTokenRevokesSession(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+	mockRepo.On("GetSessionByRefreshHash", hashToken("stolen")).Return(nil, errors.New("session not found"))
+	mockRepo.On("GetSessionByPreviousHash", hashToken("stolen")).Return(&models.Session{SessionID: "sid-1"}, nil)
+	mockRepo.On("RevokeSession", "sid-1").Return(nil)
+
+	tokens, err := service.Refresh(context.Background(), "stolen")
+
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.Nil(t, tokens)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefresh_RevokedSession(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+	revokedAt := time.Now().Add(-time.Minute)
+	mockRepo.On("GetSessionByRefreshHash", hashToken("refresh")).Return(&models.Session{
+		SessionID: "sid-1",
+		UserID:    1,
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}, nil)
+
+	_, err := service.Refresh(context.Background(), "refresh")
+
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	mockRepo.AssertNotCalled(t, "RotateSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogoutAll_RevokesEverySession(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+	mockRepo.On("GetSessionByRefreshHash", hashToken("refresh")).Return(&models.Session{SessionID: "sid-1", UserID: 7}, nil)
+	mockRepo.On("RevokeUserSessions", 7).Return(nil)
+
+	err := service.LogoutAll(context.Background(), "refresh")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegisterOwner_SendsVerificationEmail(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mail := &recordingMailer{}
+	service := NewService(mockRepo, testTokens, mail)
+
+	mockRepo.On("CreateUser", mock.Anything).Return(4, nil)
+	mockRepo.
+		On("CreateUserToken", 4, PurposeEmailVerification, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(nil)
+
+	err := service.RegisterOwner(context.Background(), "Test User", "test@mail.com", "+77001234567", "password123")
+
+	assert.NoError(t, err)
+	assert.Len(t, mail.sent, 1)
+	assert.Equal(t, "test@mail.com", mail.sent[0].To)
+	assert.Contains(t, mail.sent[0].Body, "/api/auth/verify?token=")
+}
+
+func TestRequestPasswordReset_UnknownEmail(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mail := &recordingMailer{}
+	service := NewService(mockRepo, testTokens, mail)
+
+	mockRepo.On("GetUserByEmail", "nobody@mail.com").Return(nil, errors.New("user not found"))
+
+	err := service.RequestPasswordReset(context.Background(), "nobody@mail.com")
+
+	assert.NoError(t, err)
+	assert.Empty(t, mail.sent)
+	mockRepo.AssertNotCalled(t, "CreateUserToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRequestPasswordReset_SendsLink(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mail := &recordingMailer{}
+	service := NewService(mockRepo, testTokens, mail)
+
+	mockRepo.On("GetUserByEmail", "test@mail.com").Return(&models.User{UserID: 1, Email: "test@mail.com"}, nil)
+	mockRepo.
+		On("CreateUserToken", 1, PurposePasswordReset, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(nil)
+
+	err := service.RequestPasswordReset(context.Background(), "test@mail.com")
+
+	assert.NoError(t, err)
+	assert.Len(t, mail.sent, 1)
+	assert.Contains(t, mail.sent[0].Body, "/reset-password.html?token=")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+	mockRepo.On("ConsumeUserToken", hashToken("reset"), PurposePasswordReset).Return(1, nil)
+	mockRepo.On("UpdatePassword", 1, mock.AnythingOfType("string")).Return(nil)
+	mockRepo.On("InvalidateUserTokens", 1, PurposePasswordReset).Return(nil)
+	mockRepo.On("RevokeUserSessions", 1).Return(nil)
+
+	err := service.ResetPassword(context.Background(), "reset", "newpassword1")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResetPassword_UsedToken(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+	mockRepo.On("ConsumeUserToken", hashToken("reset"), PurposePasswordReset).Return(0, errors.New("token not found or already used"))
+
+	err := service.ResetPassword(context.Background(), "reset", "newpassword1")
+
+	assert.ErrorIs(t, err, ErrInvalidActionToken)
+	mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+}
+
+func TestVerifyEmail_Success(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+	mockRepo.On("ConsumeUserToken", hashToken("verify"), PurposeEmailVerification).Return(3, nil)
+	mockRepo.On("MarkEmailVerified", 3).Return(nil)
+
+	err := service.VerifyEmail(context.Background(), "verify")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImpersonate_Success(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+	admin := authz.Actor{UserID: 1, Role: authz.RoleAdmin}
+	mockRepo.On("GetUserByID", 7).Return(&models.User{UserID: 7, Role: "sitter"}, nil)
+	mockRepo.On("CreateSession", mock.MatchedBy(func(s *models.Session) bool {
+		return s.UserID == 7 && s.ExpiresAt.Before(time.Now().Add(time.Hour))
+	})).Return(nil)
+
+	user, tokens, err := service.Impersonate(context.Background(), admin, 7)
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, user.UserID)
+	assert.Empty(t, tokens.RefreshToken)
+
+	claims, err := testTokens.Verify(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, 7, claims.UserID)
+	assert.Equal(t, "sitter", claims.Role)
+	require.NotNil(t, claims.Act)
+	assert.Equal(t, 1, claims.Act.UserID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImpersonate_Refused(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		actor   authz.Actor
+		user    *models.User
+		wantErr error
+	}{
+		{"not an admin", authz.Actor{UserID: 2, Role: authz.RoleOwner}, nil, authz.ErrForbidden},
+		{"admin target", authz.Actor{UserID: 1, Role: authz.RoleAdmin}, &models.User{UserID: 7, Role: authz.RoleAdmin}, ErrCannotImpersonateAdmin},
+		{"deleted", authz.Actor{UserID: 1, Role: authz.RoleAdmin}, &models.User{UserID: 7, Role: "owner", DeletedAt: &past}, ErrUserNotFound},
+		{"banned", authz.Actor{UserID: 1, Role: authz.RoleAdmin}, &models.User{UserID: 7, Role: "owner", BannedAt: &past}, ErrAccountBanned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := NewService(mockRepo, testTokens, &recordingMailer{})
+
+			if tt.user != nil {
+				mockRepo.On("GetUserByID", 7).Return(tt.user, nil)
+			}
+
+			_, tokens, err := service.Impersonate(context.Background(), tt.actor, 7)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, tokens)
+			mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+		})
+	}
+}
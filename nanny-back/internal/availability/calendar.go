@@ -0,0 +1,142 @@
+package availability
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"nanny-backend/internal/common/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseClock turns "HH:MM" (or "HH:MM:SS", as Postgres returns TIME) into
+// minutes since midnight. "24:00" is allowed as an end of day.
+func parseClock(s string) (int, error) {
+	if len(s) < 5 || s[2] != ':' {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", s)
+	}
+
+	h, errH := strconv.Atoi(s[:2])
+	m, errM := strconv.Atoi(s[3:5])
+	if errH != nil || errM != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", s)
+	}
+	return h*60 + m, nil
+}
+
+// workingHours expands the weekly schedule into concrete ranges for every
+// local date from..to (inclusive), skipping blackout days. A sitter with
+// no weekly slots is treated as available around the clock. Adjacent
+// ranges are merged, so a 18:00-24:00 slot followed by 00:00-08:00 the next
+// day gives one overnight range.
+func workingHours(loc *time.Location, slots []models.AvailabilitySlot, blackouts []models.Blackout, from, to time.Time) []models.TimeRange {
+	var ranges []models.TimeRange
+
+	for d := dayStart(from, loc); !d.After(to); d = d.AddDate(0, 0, 1) {
+		if blackedOut(d.Format(dateLayout), blackouts) {
+			continue
+		}
+
+		if len(slots) == 0 {
+			ranges = append(ranges, models.TimeRange{Start: d, End: d.AddDate(0, 0, 1)})
+			continue
+		}
+
+		for _, slot := range slots {
+			if time.Weekday(slot.Weekday) != d.Weekday() {
+				continue
+			}
+			startMin, err := parseClock(slot.StartTime)
+			if err != nil {
+				continue
+			}
+			endMin, err := parseClock(slot.EndTime)
+			if err != nil {
+				continue
+			}
+			ranges = append(ranges, models.TimeRange{
+				Start: atMinute(d, startMin, loc),
+				End:   atMinute(d, endMin, loc),
+			})
+		}
+	}
+
+	return merge(ranges)
+}
+
+func dayStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// atMinute uses time.Date rather than adding a duration so DST days keep
+// their wall-clock hours.
+func atMinute(day time.Time, minute int, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, loc)
+}
+
+func blackedOut(date string, blackouts []models.Blackout) bool {
+	for _, b := range blackouts {
+		if date >= b.StartDate && date <= b.EndDate {
+			return true
+		}
+	}
+	return false
+}
+
+func merge(ranges []models.TimeRange) []models.TimeRange {
+	if len(ranges) == 0 {
+		return ranges
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start.Before(ranges[j].Start) })
+
+	merged := []models.TimeRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if !r.Start.After(last.End) {
+			if r.End.After(last.End) {
+				last.End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// subtract removes busy ranges from free ones. Both must be merged and
+// sorted.
+func subtract(free, busy []models.TimeRange) []models.TimeRange {
+	var result []models.TimeRange
+
+	for _, f := range free {
+		start := f.Start
+		for _, b := range busy {
+			if !b.End.After(start) || !b.Start.Before(f.End) {
+				continue
+			}
+			if b.Start.After(start) {
+				result = append(result, models.TimeRange{Start: start, End: b.Start})
+			}
+			if b.End.After(start) {
+				start = b.End
+			}
+		}
+		if start.Before(f.End) {
+			result = append(result, models.TimeRange{Start: start, End: f.End})
+		}
+	}
+	return result
+}
+
+func covers(ranges []models.TimeRange, start, end time.Time) bool {
+	for _, r := range ranges {
+		if !r.Start.After(start) && !r.End.Before(end) {
+			return true
+		}
+	}
+	return false
+}
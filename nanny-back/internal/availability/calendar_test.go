@@ -0,0 +1,97 @@
+package availability
+
+import (
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"09:30", 570, false},
+		{"09:30:00", 570, false},
+		{"24:00", 1440, false},
+		{"24:30", 0, true},
+		{"9:30", 0, true},
+		{"12:60", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseClock(tt.in)
+		if tt.wantErr {
+			assert.Error(t, err, tt.in)
+			continue
+		}
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
+func TestWorkingHours_MergesOvernightAndSkipsBlackouts(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Almaty")
+	require.NoError(t, err)
+
+	// 2026-10-19 is a Monday.
+	slots := []models.AvailabilitySlot{
+		{Weekday: int(time.Monday), StartTime: "18:00", EndTime: "24:00"},
+		{Weekday: int(time.Tuesday), StartTime: "00:00", EndTime: "08:00"},
+		{Weekday: int(time.Wednesday), StartTime: "09:00", EndTime: "12:00"},
+	}
+	blackouts := []models.Blackout{{StartDate: "2026-10-21", EndDate: "2026-10-21"}}
+
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, loc)
+	to := time.Date(2026, 10, 21, 0, 0, 0, 0, loc)
+
+	ranges := workingHours(loc, slots, blackouts, from, to)
+
+	require.Len(t, ranges, 1)
+	assert.True(t, ranges[0].Start.Equal(time.Date(2026, 10, 19, 18, 0, 0, 0, loc)))
+	assert.True(t, ranges[0].End.Equal(time.Date(2026, 10, 20, 8, 0, 0, 0, loc)))
+}
+
+func TestWorkingHours_NoScheduleMeansAllDay(t *testing.T) {
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	ranges := workingHours(time.UTC, nil, nil, from, from.AddDate(0, 0, 1))
+
+	require.Len(t, ranges, 1)
+	assert.Equal(t, 48*time.Hour, ranges[0].End.Sub(ranges[0].Start))
+}
+
+func TestWorkingHours_KeepsWallClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 2026-03-29 is a Sunday and the spring-forward day in Berlin.
+	slots := []models.AvailabilitySlot{{Weekday: int(time.Sunday), StartTime: "09:00", EndTime: "17:00"}}
+	day := time.Date(2026, 3, 29, 0, 0, 0, 0, loc)
+
+	ranges := workingHours(loc, slots, nil, day, day)
+
+	require.Len(t, ranges, 1)
+	assert.Equal(t, 9, ranges[0].Start.Hour())
+	assert.Equal(t, 17, ranges[0].End.Hour())
+}
+
+func TestSubtract(t *testing.T) {
+	base := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+
+	free := []models.TimeRange{{Start: at(9), End: at(18)}}
+	busy := []models.TimeRange{{Start: at(10), End: at(12)}, {Start: at(16), End: at(20)}}
+
+	got := subtract(free, busy)
+
+	assert.Equal(t, []models.TimeRange{
+		{Start: at(9), End: at(10)},
+		{Start: at(12), End: at(16)},
+	}, got)
+}
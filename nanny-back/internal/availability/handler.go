@@ -0,0 +1,157 @@
+package availability
+
+import (
+	"net/http"
+
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
+	"nanny-backend/internal/common/models"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+type SlotRequest struct {
+	Weekday   int    `json:"weekday" validate:"gte=0,lte=6"`
+	StartTime string `json:"start_time" validate:"required"`
+	EndTime   string `json:"end_time" validate:"required"`
+}
+
+type SetScheduleRequest struct {
+	TimeZone string        `json:"time_zone" validate:"required"`
+	Slots    []SlotRequest `json:"slots" validate:"dive"`
+}
+
+type BlackoutRequest struct {
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+	Reason    string `json:"reason" validate:"max=500"`
+}
+
+func (h *Handler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(r.Context(), sitterID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, schedule)
+}
+
+func (h *Handler) SetSchedule(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	var req SetScheduleRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	slots := make([]models.AvailabilitySlot, 0, len(req.Slots))
+	for _, s := range req.Slots {
+		slots = append(slots, models.AvailabilitySlot{
+			SitterID:  sitterID,
+			Weekday:   s.Weekday,
+			StartTime: s.StartTime,
+			EndTime:   s.EndTime,
+		})
+	}
+
+	err = h.service.SetSchedule(r.Context(), middleware.ActorFromContext(r.Context()), sitterID, req.TimeZone, slots)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "availability updated",
+	})
+}
+
+func (h *Handler) AddBlackout(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	var req BlackoutRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	blackoutID, err := h.service.AddBlackout(r.Context(), middleware.ActorFromContext(r.Context()), sitterID, req.StartDate, req.EndDate, req.Reason)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusCreated, map[string]interface{}{
+		"message":     "blackout dates added",
+		"blackout_id": blackoutID,
+	})
+}
+
+func (h *Handler) DeleteBlackout(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	blackoutID, err := httpx.PathID(r, "blackout_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	err = h.service.DeleteBlackout(r.Context(), middleware.ActorFromContext(r.Context()), sitterID, blackoutID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "blackout dates deleted",
+	})
+}
+
+// GetFreeSlots: GET /api/sitters/{sitter_id}/availability/free?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *Handler) GetFreeSlots(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		httpx.Error(w, r, ErrMissingRange)
+		return
+	}
+
+	slots, err := h.service.GetFreeSlots(r.Context(), sitterID, from, to)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, slots)
+}
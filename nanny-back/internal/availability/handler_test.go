@@ -0,0 +1,55 @@
+package availability
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/middleware"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_GetFreeSlots_MissingRange(t *testing.T) {
+	handler := NewHandler(newTestService(&fakeRepository{timeZone: "UTC"}, time.Now()))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sitters/5/availability/free?from=2026-10-19", nil)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/sitters/{sitter_id}/availability/free", handler.GetFreeSlots)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandler_GetSchedule_UnknownSitter(t *testing.T) {
+	handler := NewHandler(newTestService(&fakeRepository{}, time.Now()))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sitters/99/availability", nil)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/sitters/{sitter_id}/availability", handler.GetSchedule)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandler_SetSchedule_Forbidden(t *testing.T) {
+	handler := NewHandler(newTestService(&fakeRepository{timeZone: "UTC"}, time.Now()))
+
+	body := `{"time_zone":"UTC","slots":[{"weekday":1,"start_time":"09:00","end_time":"18:00"}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/sitters/6/availability", bytes.NewBufferString(body))
+	req = middleware.WithActor(req, sitterActor)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/sitters/{sitter_id}/availability", handler.SetSchedule)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,230 @@
+package availability
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/models"
+)
+
+var (
+	ErrSitterNotFound   = apperr.NotFound("sitter_not_found", "nanny not found")
+	ErrBlackoutNotFound = apperr.NotFound("blackout_not_found", "blackout date not found")
+)
+
+type Repository interface {
+	GetTimeZone(ctx context.Context, sitterID int) (string, error)
+	GetSlots(ctx context.Context, sitterID int) ([]models.AvailabilitySlot, error)
+	ReplaceSchedule(ctx context.Context, sitterID int, timeZone string, slots []models.AvailabilitySlot) error
+	GetBlackouts(ctx context.Context, sitterID int, fromDate, toDate string) ([]models.Blackout, error)
+	CreateBlackout(ctx context.Context, blackout *models.Blackout) (int, error)
+	DeleteBlackout(ctx context.Context, sitterID, blackoutID int) error
+	GetBusyRanges(ctx context.Context, sitterID int, from, to time.Time) ([]models.TimeRange, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetTimeZone(ctx context.Context, sitterID int) (string, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var timeZone string
+	err := r.db.QueryRowContext(ctx, `SELECT time_zone FROM sitters WHERE sitter_id = $1`, sitterID).Scan(&timeZone)
+
+	if err == sql.ErrNoRows {
+		return "", ErrSitterNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error getting nanny time zone: %w", err)
+	}
+
+	return timeZone, nil
+}
+
+func (r *repository) GetSlots(ctx context.Context, sitterID int) ([]models.AvailabilitySlot, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT slot_id, sitter_id, weekday, start_time, end_time
+		FROM availability_slots
+		WHERE sitter_id = $1
+		ORDER BY weekday, start_time
+	`, sitterID)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting availability: %w", err)
+	}
+	defer rows.Close()
+
+	slots := []models.AvailabilitySlot{}
+	for rows.Next() {
+		var slot models.AvailabilitySlot
+		if err := rows.Scan(&slot.SlotID, &slot.SitterID, &slot.Weekday, &slot.StartTime, &slot.EndTime); err != nil {
+			return nil, fmt.Errorf("error scanning availability: %w", err)
+		}
+		slot.StartTime = trimSeconds(slot.StartTime)
+		slot.EndTime = trimSeconds(slot.EndTime)
+		slots = append(slots, slot)
+	}
+
+	return slots, rows.Err()
+}
+
+// ReplaceSchedule swaps the whole weekly schedule in one transaction so
+// readers never see a half-written week.
+func (r *repository) ReplaceSchedule(ctx context.Context, sitterID int, timeZone string, slots []models.AvailabilitySlot) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not update availability: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE sitters SET time_zone = $1 WHERE sitter_id = $2`, timeZone, sitterID)
+	if err != nil {
+		return fmt.Errorf("could not update availability: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrSitterNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM availability_slots WHERE sitter_id = $1`, sitterID); err != nil {
+		return fmt.Errorf("could not update availability: %w", err)
+	}
+
+	for _, slot := range slots {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO availability_slots (sitter_id, weekday, start_time, end_time)
+			VALUES ($1, $2, $3, $4)
+		`, sitterID, slot.Weekday, slot.StartTime, slot.EndTime)
+		if err != nil {
+			return fmt.Errorf("could not update availability: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not update availability: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetBlackouts(ctx context.Context, sitterID int, fromDate, toDate string) ([]models.Blackout, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT blackout_id, sitter_id, start_date, end_date, COALESCE(reason, '')
+		FROM availability_blackouts
+		WHERE sitter_id = $1 AND end_date >= $2 AND start_date <= $3
+		ORDER BY start_date
+	`, sitterID, fromDate, toDate)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting blackout dates: %w", err)
+	}
+	defer rows.Close()
+
+	blackouts := []models.Blackout{}
+	for rows.Next() {
+		var b models.Blackout
+		var start, end time.Time
+		if err := rows.Scan(&b.BlackoutID, &b.SitterID, &start, &end, &b.Reason); err != nil {
+			return nil, fmt.Errorf("error scanning blackout date: %w", err)
+		}
+		b.StartDate = start.Format(dateLayout)
+		b.EndDate = end.Format(dateLayout)
+		blackouts = append(blackouts, b)
+	}
+
+	return blackouts, rows.Err()
+}
+
+func (r *repository) CreateBlackout(ctx context.Context, blackout *models.Blackout) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var blackoutID int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO availability_blackouts (sitter_id, start_date, end_date, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING blackout_id
+	`, blackout.SitterID, blackout.StartDate, blackout.EndDate, blackout.Reason).Scan(&blackoutID)
+
+	if err != nil {
+		return 0, fmt.Errorf("could not create blackout date: %w", err)
+	}
+
+	return blackoutID, nil
+}
+
+func (r *repository) DeleteBlackout(ctx context.Context, sitterID, blackoutID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM availability_blackouts
+		WHERE blackout_id = $1 AND sitter_id = $2
+	`, blackoutID, sitterID)
+
+	if err != nil {
+		return fmt.Errorf("could not delete blackout date: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrBlackoutNotFound
+	}
+
+	return nil
+}
+
+// GetBusyRanges returns bookings that hold the sitter's time: the same
+// statuses the bookings_no_overlap constraint covers.
+func (r *repository) GetBusyRanges(ctx context.Context, sitterID int, from, to time.Time) ([]models.TimeRange, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT start_time, end_time
+		FROM bookings
+		WHERE sitter_id = $1
+		  AND status IN ('pending', 'confirmed', 'in_progress')
+		  AND start_time < $3 AND end_time > $2
+		ORDER BY start_time
+	`, sitterID, from, to)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var ranges []models.TimeRange
+	for rows.Next() {
+		var tr models.TimeRange
+		if err := rows.Scan(&tr.Start, &tr.End); err != nil {
+			return nil, fmt.Errorf("error scanning booking: %w", err)
+		}
+		ranges = append(ranges, tr)
+	}
+
+	return ranges, rows.Err()
+}
+
+func trimSeconds(clock string) string {
+	if len(clock) > 5 {
+		return clock[:5]
+	}
+	return clock
+}
@@ -0,0 +1,76 @@
+package availability
+
+import (
+	"context"
+	"testing"
+
+	"nanny-backend/internal/common/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceSchedule_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE sitters SET time_zone`).
+		WithArgs("Asia/Almaty", 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM availability_slots`).
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`INSERT INTO availability_slots`).
+		WithArgs(5, 1, "09:00", "18:00").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.ReplaceSchedule(context.Background(), 5, "Asia/Almaty", []models.AvailabilitySlot{
+		{Weekday: 1, StartTime: "09:00", EndTime: "18:00"},
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceSchedule_UnknownSitter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE sitters SET time_zone`).
+		WithArgs("UTC", 99).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.ReplaceSchedule(context.Background(), 99, "UTC", nil)
+
+	assert.ErrorIs(t, err, ErrSitterNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSlots_TrimsSeconds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectQuery(`FROM availability_slots`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"slot_id", "sitter_id", "weekday", "start_time", "end_time"}).
+			AddRow(1, 5, 1, "09:00:00", "24:00:00"))
+
+	slots, err := repo.GetSlots(context.Background(), 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "09:00", slots[0].StartTime)
+	assert.Equal(t, "24:00", slots[0].EndTime)
+}
@@ -0,0 +1,225 @@
+package availability
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/models"
+)
+
+// maxFreeSlotsDays caps how far GetFreeSlots expands the calendar in one
+// call.
+const maxFreeSlotsDays = 31
+
+var (
+	ErrInvalidSchedule = apperr.Validation("invalid_schedule", "invalid schedule")
+	ErrInvalidDates    = apperr.Validation("invalid_dates", "invalid dates")
+	ErrMissingRange    = apperr.Validation("invalid_dates", "from and to dates are required")
+)
+
+type Schedule struct {
+	SitterID  int                       `json:"sitter_id"`
+	TimeZone  string                    `json:"time_zone"`
+	Slots     []models.AvailabilitySlot `json:"slots"`
+	Blackouts []models.Blackout         `json:"blackouts"`
+}
+
+type Service interface {
+	GetSchedule(ctx context.Context, sitterID int) (*Schedule, error)
+	SetSchedule(ctx context.Context, actor authz.Actor, sitterID int, timeZone string, slots []models.AvailabilitySlot) error
+	AddBlackout(ctx context.Context, actor authz.Actor, sitterID int, startDate, endDate, reason string) (int, error)
+	DeleteBlackout(ctx context.Context, actor authz.Actor, sitterID, blackoutID int) error
+	GetFreeSlots(ctx context.Context, sitterID int, fromDate, toDate string) ([]models.TimeRange, error)
+	IsAvailable(ctx context.Context, sitterID int, start, end time.Time) (bool, error)
+}
+
+type service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo, now: time.Now}
+}
+
+func (s *service) GetSchedule(ctx context.Context, sitterID int) (*Schedule, error) {
+	timeZone, err := s.repo.GetTimeZone(ctx, sitterID)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.repo.GetSlots(ctx, sitterID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("nanny has an invalid time zone: %w", err)
+	}
+
+	today := s.now().In(loc).Format(dateLayout)
+	blackouts, err := s.repo.GetBlackouts(ctx, sitterID, today, "9999-12-31")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schedule{
+		SitterID:  sitterID,
+		TimeZone:  timeZone,
+		Slots:     slots,
+		Blackouts: blackouts,
+	}, nil
+}
+
+func (s *service) SetSchedule(ctx context.Context, actor authz.Actor, sitterID int, timeZone string, slots []models.AvailabilitySlot) error {
+	if !actor.CanActAs(sitterID) {
+		return fmt.Errorf("schedule belongs to another nanny: %w", authz.ErrForbidden)
+	}
+
+	if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "" {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, timeZone)
+	}
+
+	for i, slot := range slots {
+		if slot.Weekday < 0 || slot.Weekday > 6 {
+			return fmt.Errorf("%w: slot %d: weekday must be between 0 (Sunday) and 6 (Saturday)", ErrInvalidSchedule, i)
+		}
+		startMin, err := parseClock(slot.StartTime)
+		if err != nil {
+			return fmt.Errorf("%w: slot %d: %v", ErrInvalidSchedule, i, err)
+		}
+		endMin, err := parseClock(slot.EndTime)
+		if err != nil {
+			return fmt.Errorf("%w: slot %d: %v", ErrInvalidSchedule, i, err)
+		}
+		if startMin >= endMin {
+			return fmt.Errorf("%w: slot %d: start time must be before end time", ErrInvalidSchedule, i)
+		}
+	}
+
+	return s.repo.ReplaceSchedule(ctx, sitterID, timeZone, slots)
+}
+
+func (s *service) AddBlackout(ctx context.Context, actor authz.Actor, sitterID int, startDate, endDate, reason string) (int, error) {
+	if !actor.CanActAs(sitterID) {
+		return 0, fmt.Errorf("schedule belongs to another nanny: %w", authz.ErrForbidden)
+	}
+
+	start, err := time.Parse(dateLayout, startDate)
+	if err != nil {
+		return 0, fmt.Errorf("%w: incorrect start date (use YYYY-MM-DD)", ErrInvalidDates)
+	}
+	end, err := time.Parse(dateLayout, endDate)
+	if err != nil {
+		return 0, fmt.Errorf("%w: incorrect end date (use YYYY-MM-DD)", ErrInvalidDates)
+	}
+	if end.Before(start) {
+		return 0, fmt.Errorf("%w: end date cannot be before start date", ErrInvalidDates)
+	}
+
+	return s.repo.CreateBlackout(ctx, &models.Blackout{
+		SitterID:  sitterID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Reason:    reason,
+	})
+}
+
+func (s *service) DeleteBlackout(ctx context.Context, actor authz.Actor, sitterID, blackoutID int) error {
+	if !actor.CanActAs(sitterID) {
+		return fmt.Errorf("schedule belongs to another nanny: %w", authz.ErrForbidden)
+	}
+
+	return s.repo.DeleteBlackout(ctx, sitterID, blackoutID)
+}
+
+// GetFreeSlots returns working hours between two local dates (inclusive)
+// minus blackout days, booked time and anything already in the past.
+func (s *service) GetFreeSlots(ctx context.Context, sitterID int, fromDate, toDate string) ([]models.TimeRange, error) {
+	loc, slots, err := s.loadCalendar(ctx, sitterID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := time.ParseInLocation(dateLayout, fromDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: incorrect from date (use YYYY-MM-DD)", ErrInvalidDates)
+	}
+	to, err := time.ParseInLocation(dateLayout, toDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: incorrect to date (use YYYY-MM-DD)", ErrInvalidDates)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date cannot be before from date", ErrInvalidDates)
+	}
+	if to.Sub(from) > maxFreeSlotsDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: date range cannot be longer than %d days", ErrInvalidDates, maxFreeSlotsDays)
+	}
+
+	blackouts, err := s.repo.GetBlackouts(ctx, sitterID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	working := workingHours(loc, slots, blackouts, from, to)
+	if len(working) == 0 {
+		return []models.TimeRange{}, nil
+	}
+
+	busy, err := s.repo.GetBusyRanges(ctx, sitterID, working[0].Start, working[len(working)-1].End)
+	if err != nil {
+		return nil, err
+	}
+
+	past := []models.TimeRange{{Start: working[0].Start, End: s.now()}}
+	free := subtract(subtract(working, merge(busy)), past)
+	if free == nil {
+		free = []models.TimeRange{}
+	}
+	return free, nil
+}
+
+// IsAvailable reports whether start..end falls inside the sitter's
+// working hours. It does not look at other bookings: overlaps are
+// rejected by the bookings_no_overlap constraint when the booking is
+// written.
+func (s *service) IsAvailable(ctx context.Context, sitterID int, start, end time.Time) (bool, error) {
+	loc, slots, err := s.loadCalendar(ctx, sitterID)
+	if err != nil {
+		return false, err
+	}
+
+	// Start a day early so an overnight slot that began yesterday counts.
+	from := start.In(loc).AddDate(0, 0, -1)
+	to := end.In(loc)
+
+	blackouts, err := s.repo.GetBlackouts(ctx, sitterID, from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return false, err
+	}
+
+	return covers(workingHours(loc, slots, blackouts, from, to), start, end), nil
+}
+
+func (s *service) loadCalendar(ctx context.Context, sitterID int) (*time.Location, []models.AvailabilitySlot, error) {
+	timeZone, err := s.repo.GetTimeZone(ctx, sitterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("nanny has an invalid time zone: %w", err)
+	}
+
+	slots, err := s.repo.GetSlots(ctx, sitterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return loc, slots, nil
+}
@@ -0,0 +1,158 @@
+package availability
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	timeZone  string
+	slots     []models.AvailabilitySlot
+	blackouts []models.Blackout
+	busy      []models.TimeRange
+	replaced  []models.AvailabilitySlot
+}
+
+func (f *fakeRepository) GetTimeZone(ctx context.Context, sitterID int) (string, error) {
+	if f.timeZone == "" {
+		return "", ErrSitterNotFound
+	}
+	return f.timeZone, nil
+}
+
+func (f *fakeRepository) GetSlots(ctx context.Context, sitterID int) ([]models.AvailabilitySlot, error) {
+	return f.slots, nil
+}
+
+func (f *fakeRepository) ReplaceSchedule(ctx context.Context, sitterID int, timeZone string, slots []models.AvailabilitySlot) error {
+	f.timeZone = timeZone
+	f.replaced = slots
+	return nil
+}
+
+func (f *fakeRepository) GetBlackouts(ctx context.Context, sitterID int, fromDate, toDate string) ([]models.Blackout, error) {
+	return f.blackouts, nil
+}
+
+func (f *fakeRepository) CreateBlackout(ctx context.Context, blackout *models.Blackout) (int, error) {
+	f.blackouts = append(f.blackouts, *blackout)
+	return len(f.blackouts), nil
+}
+
+func (f *fakeRepository) DeleteBlackout(ctx context.Context, sitterID, blackoutID int) error {
+	return nil
+}
+
+func (f *fakeRepository) GetBusyRanges(ctx context.Context, sitterID int, from, to time.Time) ([]models.TimeRange, error) {
+	return f.busy, nil
+}
+
+var (
+	sitterActor = authz.Actor{UserID: 5, Role: authz.RoleSitter}
+	weekdays    = []models.AvailabilitySlot{
+		{Weekday: int(time.Monday), StartTime: "09:00", EndTime: "18:00"},
+		{Weekday: int(time.Tuesday), StartTime: "09:00", EndTime: "18:00"},
+	}
+)
+
+func newTestService(repo Repository, now time.Time) *service {
+	return &service{repo: repo, now: func() time.Time { return now }}
+}
+
+func TestIsAvailable(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Almaty")
+	repo := &fakeRepository{timeZone: "Asia/Almaty", slots: weekdays}
+	svc := newTestService(repo, time.Date(2026, 10, 1, 0, 0, 0, 0, loc))
+
+	monday := func(h int) time.Time { return time.Date(2026, 10, 19, h, 0, 0, 0, loc) }
+
+	ok, err := svc.IsAvailable(context.Background(), 5, monday(10), monday(12))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = svc.IsAvailable(context.Background(), 5, monday(17), monday(19))
+	assert.NoError(t, err)
+	assert.False(t, ok, "booking runs past working hours")
+
+	// Same wall-clock hours given in UTC still map to the sitter's zone.
+	ok, err = svc.IsAvailable(context.Background(), 5, monday(10).UTC(), monday(12).UTC())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestIsAvailable_Blackout(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Almaty")
+	repo := &fakeRepository{
+		timeZone:  "Asia/Almaty",
+		slots:     weekdays,
+		blackouts: []models.Blackout{{StartDate: "2026-10-19", EndDate: "2026-10-19"}},
+	}
+	svc := newTestService(repo, time.Date(2026, 10, 1, 0, 0, 0, 0, loc))
+
+	ok, err := svc.IsAvailable(context.Background(), 5, time.Date(2026, 10, 19, 10, 0, 0, 0, loc), time.Date(2026, 10, 19, 12, 0, 0, 0, loc))
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestGetFreeSlots_RemovesBookingsAndPast(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Almaty")
+	at := func(day, h int) time.Time { return time.Date(2026, 10, day, h, 0, 0, 0, loc) }
+
+	repo := &fakeRepository{
+		timeZone: "Asia/Almaty",
+		slots:    weekdays,
+		busy:     []models.TimeRange{{Start: at(20, 12), End: at(20, 14)}},
+	}
+	svc := newTestService(repo, at(19, 15))
+
+	free, err := svc.GetFreeSlots(context.Background(), 5, "2026-10-19", "2026-10-20")
+
+	require.NoError(t, err)
+	require.Len(t, free, 3)
+	assert.True(t, free[0].Start.Equal(at(19, 15)))
+	assert.True(t, free[0].End.Equal(at(19, 18)))
+	assert.True(t, free[1].End.Equal(at(20, 12)))
+	assert.True(t, free[2].Start.Equal(at(20, 14)))
+}
+
+func TestGetFreeSlots_RangeTooLong(t *testing.T) {
+	svc := newTestService(&fakeRepository{timeZone: "UTC"}, time.Now())
+
+	_, err := svc.GetFreeSlots(context.Background(), 5, "2026-01-01", "2026-03-01")
+
+	assert.Error(t, err)
+}
+
+func TestSetSchedule_Validation(t *testing.T) {
+	repo := &fakeRepository{timeZone: "UTC"}
+	svc := newTestService(repo, time.Now())
+
+	err := svc.SetSchedule(context.Background(), sitterActor, 5, "Mars/Olympus", weekdays)
+	assert.Error(t, err)
+
+	err = svc.SetSchedule(context.Background(), sitterActor, 5, "UTC", []models.AvailabilitySlot{
+		{Weekday: 1, StartTime: "18:00", EndTime: "09:00"},
+	})
+	assert.Error(t, err)
+
+	err = svc.SetSchedule(context.Background(), sitterActor, 5, "Europe/Berlin", weekdays)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", repo.timeZone)
+	assert.Len(t, repo.replaced, 2)
+}
+
+func TestSetSchedule_OtherSitter(t *testing.T) {
+	svc := newTestService(&fakeRepository{timeZone: "UTC"}, time.Now())
+
+	err := svc.SetSchedule(context.Background(), sitterActor, 6, "UTC", weekdays)
+
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+}
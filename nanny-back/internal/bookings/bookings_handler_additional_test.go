@@ -2,6 +2,7 @@ package bookings
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -9,6 +10,9 @@ import (
 	"testing"
 	"time"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 
 	"github.com/gorilla/mux"
@@ -17,11 +21,11 @@ import (
 
 func TestHandler_GetOwnerBookings_Success(t *testing.T) {
 	mockSvc := &mockBookingService{
-		getOwnerBookingsFunc: func(ownerID int) ([]models.Booking, error) {
-			return []models.Booking{
+		getOwnerBookingsFunc: func(ownerID int, q listing.Query) (*listing.Page[models.Booking], error) {
+			return ListSpec.Page([]models.Booking{
 				{BookingID: 1, OwnerID: ownerID, Status: "pending"},
 				{BookingID: 2, OwnerID: ownerID, Status: "confirmed"},
-			}, nil
+			}, q), nil
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -34,10 +38,10 @@ func TestHandler_GetOwnerBookings_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	var resp []models.Booking
+	var resp listing.Page[models.Booking]
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Equal(t, 2, len(resp))
-	assert.Equal(t, "pending", resp[0].Status)
+	assert.Equal(t, 2, len(resp.Items))
+	assert.Equal(t, "pending", resp.Items[0].Status)
 }
 
 func TestHandler_GetOwnerBookings_InvalidID(t *testing.T) {
@@ -52,9 +56,9 @@ func TestHandler_GetOwnerBookings_InvalidID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect ID owner")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_GetOwnerBookings_ZeroID(t *testing.T) {
@@ -69,9 +73,9 @@ func TestHandler_GetOwnerBookings_ZeroID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "ID owner must be positive number")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_GetOwnerBookings_NegativeID(t *testing.T) {
@@ -89,7 +93,7 @@ func TestHandler_GetOwnerBookings_NegativeID(t *testing.T) {
 
 func TestHandler_GetOwnerBookings_ServiceError(t *testing.T) {
 	mockSvc := &mockBookingService{
-		getOwnerBookingsFunc: func(ownerID int) ([]models.Booking, error) {
+		getOwnerBookingsFunc: func(ownerID int, q listing.Query) (*listing.Page[models.Booking], error) {
 			return nil, errors.New("database error")
 		},
 	}
@@ -103,18 +107,19 @@ func TestHandler_GetOwnerBookings_ServiceError(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "database error")
+	assert.Equal(t, "internal_error", resp.Code)
+	assert.NotContains(t, rec.Body.String(), "database error")
 }
 
 func TestHandler_GetSitterBookings_Success(t *testing.T) {
 	mockSvc := &mockBookingService{
-		getSitterBookingsFunc: func(sitterID int) ([]models.Booking, error) {
-			return []models.Booking{
+		getSitterBookingsFunc: func(sitterID int, q listing.Query) (*listing.Page[models.Booking], error) {
+			return ListSpec.Page([]models.Booking{
 				{BookingID: 1, SitterID: sitterID, Status: "pending"},
 				{BookingID: 2, SitterID: sitterID, Status: "confirmed"},
-			}, nil
+			}, q), nil
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -127,9 +132,9 @@ func TestHandler_GetSitterBookings_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	var resp []models.Booking
+	var resp listing.Page[models.Booking]
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Equal(t, 2, len(resp))
+	assert.Equal(t, 2, len(resp.Items))
 }
 
 func TestHandler_GetSitterBookings_InvalidID(t *testing.T) {
@@ -144,9 +149,9 @@ func TestHandler_GetSitterBookings_InvalidID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect ID nanny")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_GetSitterBookings_ZeroID(t *testing.T) {
@@ -161,9 +166,9 @@ func TestHandler_GetSitterBookings_ZeroID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "ID nanny must be positive number")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_GetSitterBookings_NegativeID(t *testing.T) {
@@ -181,7 +186,7 @@ func TestHandler_GetSitterBookings_NegativeID(t *testing.T) {
 
 func TestHandler_GetSitterBookings_ServiceError(t *testing.T) {
 	mockSvc := &mockBookingService{
-		getSitterBookingsFunc: func(sitterID int) ([]models.Booking, error) {
+		getSitterBookingsFunc: func(sitterID int, q listing.Query) (*listing.Page[models.Booking], error) {
 			return nil, errors.New("database error")
 		},
 	}
@@ -195,9 +200,10 @@ func TestHandler_GetSitterBookings_ServiceError(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "database error")
+	assert.Equal(t, "internal_error", resp.Code)
+	assert.NotContains(t, rec.Body.String(), "database error")
 }
 
 func TestHandler_CreateBooking_ValidationErrors(t *testing.T) {
@@ -205,7 +211,6 @@ func TestHandler_CreateBooking_ValidationErrors(t *testing.T) {
 	handler := NewHandler(mockSvc)
 
 	reqBody := map[string]interface{}{
-		"owner_id":   1,
 		"sitter_id":  1,
 		"pet_id":     1,
 		"service_id": 1,
@@ -288,60 +293,76 @@ func TestHandler_CompleteBooking_ZeroID(t *testing.T) {
 }
 
 type mockBookingService struct {
-	createBookingFunc     func(int, int, int, int, time.Time, time.Time) (int, error)
+	createBookingFunc     func(authz.Actor, int, int, int, time.Time, time.Time) (int, error)
 	getBookingByIDFunc    func(int) (*models.Booking, error)
-	getOwnerBookingsFunc  func(int) ([]models.Booking, error)
-	getSitterBookingsFunc func(int) ([]models.Booking, error)
-	confirmBookingFunc    func(int) error
-	cancelBookingFunc     func(int) error
-	completeBookingFunc   func(int) error
+	getOwnerBookingsFunc  func(int, listing.Query) (*listing.Page[models.Booking], error)
+	getSitterBookingsFunc func(int, listing.Query) (*listing.Page[models.Booking], error)
+	confirmBookingFunc    func(authz.Actor, int) error
+	cancelBookingFunc     func(authz.Actor, int, string) error
+	completeBookingFunc   func(authz.Actor, int) error
 }
 
-func (m *mockBookingService) CreateBooking(ownerID, sitterID, petID, serviceID int, startDate, endDate time.Time) (int, error) {
+func (m *mockBookingService) CreateBooking(ctx context.Context, actor authz.Actor, sitterID, petID, serviceID int, startDate, endDate time.Time) (int, error) {
 	if m.createBookingFunc != nil {
-		return m.createBookingFunc(ownerID, sitterID, petID, serviceID, startDate, endDate)
+		return m.createBookingFunc(actor, sitterID, petID, serviceID, startDate, endDate)
 	}
 	return 1, nil
 }
 
-func (m *mockBookingService) GetBookingByID(bookingID int) (*models.Booking, error) {
+func (m *mockBookingService) GetBookingByID(ctx context.Context, bookingID int) (*models.Booking, error) {
 	if m.getBookingByIDFunc != nil {
 		return m.getBookingByIDFunc(bookingID)
 	}
 	return &models.Booking{BookingID: bookingID}, nil
 }
 
-func (m *mockBookingService) GetOwnerBookings(ownerID int) ([]models.Booking, error) {
+func (m *mockBookingService) GetOwnerBookings(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Booking], error) {
 	if m.getOwnerBookingsFunc != nil {
-		return m.getOwnerBookingsFunc(ownerID)
+		return m.getOwnerBookingsFunc(ownerID, q)
 	}
-	return []models.Booking{}, nil
+	return ListSpec.Page(nil, q), nil
 }
 
-func (m *mockBookingService) GetSitterBookings(sitterID int) ([]models.Booking, error) {
+func (m *mockBookingService) GetSitterBookings(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Booking], error) {
 	if m.getSitterBookingsFunc != nil {
-		return m.getSitterBookingsFunc(sitterID)
+		return m.getSitterBookingsFunc(sitterID, q)
 	}
-	return []models.Booking{}, nil
+	return ListSpec.Page(nil, q), nil
 }
 
-func (m *mockBookingService) ConfirmBooking(bookingID int) error {
+func (m *mockBookingService) ConfirmBooking(ctx context.Context, actor authz.Actor, bookingID int) error {
 	if m.confirmBookingFunc != nil {
-		return m.confirmBookingFunc(bookingID)
+		return m.confirmBookingFunc(actor, bookingID)
 	}
 	return nil
 }
 
-func (m *mockBookingService) CancelBooking(bookingID int) error {
+func (m *mockBookingService) CancelBooking(ctx context.Context, actor authz.Actor, bookingID int, reason string) error {
 	if m.cancelBookingFunc != nil {
-		return m.cancelBookingFunc(bookingID)
+		return m.cancelBookingFunc(actor, bookingID, reason)
 	}
 	return nil
 }
 
-func (m *mockBookingService) CompleteBooking(bookingID int) error {
+func (m *mockBookingService) CompleteBooking(ctx context.Context, actor authz.Actor, bookingID int) error {
 	if m.completeBookingFunc != nil {
-		return m.completeBookingFunc(bookingID)
+		return m.completeBookingFunc(actor, bookingID)
 	}
 	return nil
 }
+
+func (m *mockBookingService) StartBooking(ctx context.Context, actor authz.Actor, bookingID int) error {
+	return nil
+}
+
+func (m *mockBookingService) ReportNoShow(ctx context.Context, actor authz.Actor, bookingID int, reason string) error {
+	return nil
+}
+
+func (m *mockBookingService) GetBookingHistory(ctx context.Context, actor authz.Actor, bookingID int) ([]models.BookingEvent, error) {
+	return []models.BookingEvent{}, nil
+}
+
+func (m *mockBookingService) ExpireOverdueBookings(ctx context.Context) (int64, error) {
+	return 0, nil
+}
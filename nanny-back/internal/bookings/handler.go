@@ -2,13 +2,15 @@ package bookings
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
-	"strconv"
 	"time"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
 	"nanny-backend/pkg/validator"
-
-	"github.com/gorilla/mux"
 )
 
 type Handler struct {
@@ -20,7 +22,6 @@ func NewHandler(service Service) *Handler {
 }
 
 type CreateBookingRequest struct {
-	OwnerID   int    `json:"owner_id" validate:"required,gt=0"`
 	SitterID  int    `json:"sitter_id" validate:"required,gt=0"`
 	PetID     int    `json:"pet_id" validate:"required,gt=0"`
 	ServiceID int    `json:"service_id" validate:"required,gt=0"`
@@ -28,53 +29,37 @@ type CreateBookingRequest struct {
 	EndTime   string `json:"end_time" validate:"required"`
 }
 
+// TransitionRequest is the optional body of cancel and no-show.
+type TransitionRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
 func (h *Handler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	var req CreateBookingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
-		return
-	}
-
-	if err := validator.Validate(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
 	startTime, err := time.Parse(time.RFC3339, req.StartTime)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect format start date (use ISO 8601)")
+		httpx.Error(w, r, apperr.InvalidFields([]apperr.FieldError{{Field: "start_time", Message: "incorrect format start date (use ISO 8601)"}}))
 		return
 	}
 
 	endTime, err := time.Parse(time.RFC3339, req.EndTime)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect format end date (use ISO 8601)")
-		return
-	}
-
-	if endTime.Before(startTime) {
-		respondWithError(w, http.StatusBadRequest, "end time must be later than start time")
-		return
-	}
-
-	if startTime.Before(time.Now()) {
-		respondWithError(w, http.StatusBadRequest, "start time cannot be past time")
-		return
-	}
-
-	duration := endTime.Sub(startTime)
-	if duration.Hours() > 24 {
-		respondWithError(w, http.StatusBadRequest, "max duration booking  - 24 hours")
+		httpx.Error(w, r, apperr.InvalidFields([]apperr.FieldError{{Field: "end_time", Message: "incorrect format end date (use ISO 8601)"}}))
 		return
 	}
 
-	if duration.Minutes() < 30 {
-		respondWithError(w, http.StatusBadRequest, "min duration booking - 30 min")
+	if err := validateBookingTimes(startTime, endTime); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	bookingID, err := h.service.CreateBooking(
-		req.OwnerID,
+	bookingID, err := h.service.CreateBooking(r.Context(),
+		middleware.ActorFromContext(r.Context()),
 		req.SitterID,
 		req.PetID,
 		req.ServiceID,
@@ -82,160 +67,220 @@ func (h *Handler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 		endTime,
 	)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+	httpx.JSON(w, http.StatusCreated, map[string]interface{}{
 		"message":    "booking created successfully",
 		"booking_id": bookingID,
 	})
 }
 
 func (h *Handler) GetBooking(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	bookingID, err := strconv.Atoi(vars["id"])
+	bookingID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID booking")
-		return
-	}
-
-	if bookingID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID booking must be positive")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	booking, err := h.service.GetBookingByID(bookingID)
+	booking, err := h.service.GetBookingByID(r.Context(), bookingID)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, booking)
+	httpx.JSON(w, http.StatusOK, booking)
 }
 
 func (h *Handler) GetOwnerBookings(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	ownerID, err := strconv.Atoi(vars["owner_id"])
+	ownerID, err := httpx.PathID(r, "owner_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID owner")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if ownerID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID owner must be positive")
+	q, err := ListSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	bookings, err := h.service.GetOwnerBookings(ownerID)
+	page, err := h.service.GetOwnerBookings(r.Context(), ownerID, q)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, bookings)
+	httpx.JSON(w, http.StatusOK, page)
 }
 
 func (h *Handler) GetSitterBookings(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	sitterID, err := strconv.Atoi(vars["sitter_id"])
+	sitterID, err := httpx.PathID(r, "sitter_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID nanny")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if sitterID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID nanny must be positive")
+	q, err := ListSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	bookings, err := h.service.GetSitterBookings(sitterID)
+	page, err := h.service.GetSitterBookings(r.Context(), sitterID, q)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, bookings)
+	httpx.JSON(w, http.StatusOK, page)
 }
 
 func (h *Handler) ConfirmBooking(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	bookingID, err := strconv.Atoi(vars["id"])
+	bookingID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID booking")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if bookingID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID booking must be positive")
+	if err := h.service.ConfirmBooking(r.Context(), middleware.ActorFromContext(r.Context()), bookingID); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err = h.service.ConfirmBooking(bookingID)
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "booking confirmed",
+	})
+}
+
+func (h *Handler) StartBooking(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "booking confirmed",
+	if err := h.service.StartBooking(r.Context(), middleware.ActorFromContext(r.Context()), bookingID); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "booking started",
 	})
 }
 
 func (h *Handler) CancelBooking(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	bookingID, err := strconv.Atoi(vars["id"])
+	bookingID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID booking")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if bookingID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID booking must be positive")
+	req, err := decodeTransitionRequest(r)
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err = h.service.CancelBooking(bookingID)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+	if err := h.service.CancelBooking(r.Context(), middleware.ActorFromContext(r.Context()), bookingID, req.Reason); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
+	httpx.JSON(w, http.StatusOK, map[string]string{
 		"message": "booking declined",
 	})
 }
 
-func (h *Handler) CompleteBooking(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	bookingID, err := strconv.Atoi(vars["id"])
+func (h *Handler) ReportNoShow(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	req, err := decodeTransitionRequest(r)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID booking")
+		httpx.Error(w, r, err)
+		return
+	}
+
+	if err := h.service.ReportNoShow(r.Context(), middleware.ActorFromContext(r.Context()), bookingID, req.Reason); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if bookingID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID booking must be positive")
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "no-show reported",
+	})
+}
+
+func (h *Handler) GetBookingHistory(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err = h.service.CompleteBooking(bookingID)
+	events, err := h.service.GetBookingHistory(r.Context(), middleware.ActorFromContext(r.Context()), bookingID)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, events)
+}
+
+func (h *Handler) CompleteBooking(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	if err := h.service.CompleteBooking(r.Context(), middleware.ActorFromContext(r.Context()), bookingID); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
+	httpx.JSON(w, http.StatusOK, map[string]string{
 		"message": "booking completed",
 	})
 }
 
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
+func validateBookingTimes(startTime, endTime time.Time) error {
+	if endTime.Before(startTime) {
+		return apperr.Validation("invalid_time_range", "end time must be later than start time")
+	}
+
+	if startTime.Before(time.Now()) {
+		return apperr.Validation("start_in_past", "start time cannot be past time")
+	}
+
+	duration := endTime.Sub(startTime)
+	if duration.Hours() > 24 {
+		return apperr.Validation("booking_too_long", "max duration booking - 24 hours")
+	}
+
+	if duration.Minutes() < 30 {
+		return apperr.Validation("booking_too_short", "min duration booking - 30 min")
+	}
+
+	return nil
 }
 
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(payload)
+// decodeTransitionRequest accepts an empty body: the reason is optional.
+func decodeTransitionRequest(r *http.Request) (*TransitionRequest, error) {
+	var req TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return nil, httpx.ErrMalformedBody
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
 }
@@ -2,10 +2,15 @@ package bookings
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/middleware"
 	"nanny-backend/internal/common/models"
 	"net/http"
 	"net/http/httptest"
@@ -18,14 +23,14 @@ type MockService struct {
 }
 
 func (m *MockService) CreateBooking(
-	ownerID, sitterID, petID, serviceID int,
+	ctx context.Context, actor authz.Actor, sitterID, petID, serviceID int,
 	startTime, endTime time.Time,
 ) (int, error) {
-	args := m.Called(ownerID, sitterID, petID, serviceID, startTime, endTime)
+	args := m.Called(actor, sitterID, petID, serviceID, startTime, endTime)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockService) GetBookingByID(bookingID int) (*models.Booking, error) {
+func (m *MockService) GetBookingByID(ctx context.Context, bookingID int) (*models.Booking, error) {
 	args := m.Called(bookingID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -33,26 +38,53 @@ func (m *MockService) GetBookingByID(bookingID int) (*models.Booking, error) {
 	return args.Get(0).(*models.Booking), args.Error(1)
 }
 
-func (m *MockService) GetOwnerBookings(ownerID int) ([]models.Booking, error) {
-	args := m.Called(ownerID)
-	return args.Get(0).([]models.Booking), args.Error(1)
+func (m *MockService) GetOwnerBookings(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Booking], error) {
+	args := m.Called(ownerID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[models.Booking]), args.Error(1)
+}
+
+func (m *MockService) GetSitterBookings(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Booking], error) {
+	args := m.Called(sitterID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[models.Booking]), args.Error(1)
+}
+
+func (m *MockService) ConfirmBooking(ctx context.Context, actor authz.Actor, bookingID int) error {
+	return m.Called(actor, bookingID).Error(0)
+}
+
+func (m *MockService) StartBooking(ctx context.Context, actor authz.Actor, bookingID int) error {
+	return m.Called(actor, bookingID).Error(0)
 }
 
-func (m *MockService) GetSitterBookings(sitterID int) ([]models.Booking, error) {
-	args := m.Called(sitterID)
-	return args.Get(0).([]models.Booking), args.Error(1)
+func (m *MockService) CompleteBooking(ctx context.Context, actor authz.Actor, bookingID int) error {
+	return m.Called(actor, bookingID).Error(0)
 }
 
-func (m *MockService) ConfirmBooking(bookingID int) error {
-	return m.Called(bookingID).Error(0)
+func (m *MockService) CancelBooking(ctx context.Context, actor authz.Actor, bookingID int, reason string) error {
+	return m.Called(actor, bookingID, reason).Error(0)
 }
 
-func (m *MockService) CancelBooking(bookingID int) error {
-	return m.Called(bookingID).Error(0)
+func (m *MockService) ReportNoShow(ctx context.Context, actor authz.Actor, bookingID int, reason string) error {
+	return m.Called(actor, bookingID, reason).Error(0)
 }
 
-func (m *MockService) CompleteBooking(bookingID int) error {
-	return m.Called(bookingID).Error(0)
+func (m *MockService) GetBookingHistory(ctx context.Context, actor authz.Actor, bookingID int) ([]models.BookingEvent, error) {
+	args := m.Called(actor, bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BookingEvent), args.Error(1)
+}
+
+func (m *MockService) ExpireOverdueBookings(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 func TestHandler_CreateBooking_Success(t *testing.T) {
@@ -63,7 +95,6 @@ func TestHandler_CreateBooking_Success(t *testing.T) {
 	endTime := startTime.Add(2 * time.Hour)
 
 	reqBody := map[string]interface{}{
-		"owner_id":   1,
 		"sitter_id":  2,
 		"pet_id":     3,
 		"service_id": 4,
@@ -74,12 +105,13 @@ func TestHandler_CreateBooking_Success(t *testing.T) {
 	bodyBytes, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/api/bookings", bytes.NewBuffer(bodyBytes))
 	req.Header.Set("Content-Type", "application/json")
+	req = middleware.WithActor(req, authz.Actor{UserID: 1, Role: authz.RoleOwner})
 	rec := httptest.NewRecorder()
 
 	mockService.
 		On(
 			"CreateBooking",
-			1, 2, 3, 4,
+			authz.Actor{UserID: 1, Role: authz.RoleOwner}, 2, 3, 4,
 			mock.AnythingOfType("time.Time"),
 			mock.AnythingOfType("time.Time"),
 		).
@@ -128,10 +160,11 @@ func TestHandler_ConfirmBooking_Success(t *testing.T) {
 	handler := NewHandler(mockService)
 
 	mockService.
-		On("ConfirmBooking", 10).
+		On("ConfirmBooking", authz.Actor{UserID: 2, Role: authz.RoleSitter}, 10).
 		Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/bookings/10/confirm", nil)
+	req = middleware.WithActor(req, authz.Actor{UserID: 2, Role: authz.RoleSitter})
 	rec := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -148,10 +181,11 @@ func TestHandler_CancelBooking_Success(t *testing.T) {
 	handler := NewHandler(mockService)
 
 	mockService.
-		On("CancelBooking", 10).
+		On("CancelBooking", authz.Actor{UserID: 1, Role: authz.RoleOwner}, 10, "").
 		Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/bookings/10/cancel", nil)
+	req = middleware.WithActor(req, authz.Actor{UserID: 1, Role: authz.RoleOwner})
 	rec := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -168,10 +202,11 @@ func TestHandler_CompleteBooking_Success(t *testing.T) {
 	handler := NewHandler(mockService)
 
 	mockService.
-		On("CompleteBooking", 10).
+		On("CompleteBooking", authz.Actor{UserID: 2, Role: authz.RoleSitter}, 10).
 		Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/bookings/10/complete", nil)
+	req = middleware.WithActor(req, authz.Actor{UserID: 2, Role: authz.RoleSitter})
 	rec := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -182,3 +217,114 @@ func TestHandler_CompleteBooking_Success(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestHandler_ConfirmBooking_Forbidden(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.
+		On("ConfirmBooking", authz.Actor{UserID: 2, Role: authz.RoleSitter}, 10).
+		Return(authz.ErrForbidden)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/bookings/10/confirm", nil)
+	req = middleware.WithActor(req, authz.Actor{UserID: 2, Role: authz.RoleSitter})
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/bookings/{id}/confirm", handler.ConfirmBooking)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ConfirmBooking_Conflict(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.
+		On("ConfirmBooking", authz.Actor{UserID: 2, Role: authz.RoleSitter}, 10).
+		Return(ErrTimeSlotTaken)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/bookings/10/confirm", nil)
+	req = middleware.WithActor(req, authz.Actor{UserID: 2, Role: authz.RoleSitter})
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/bookings/{id}/confirm", handler.ConfirmBooking)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_CancelBooking_WithReason(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.
+		On("CancelBooking", authz.Actor{UserID: 2, Role: authz.RoleSitter}, 10, "sick").
+		Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/bookings/10/cancel", bytes.NewBufferString(`{"reason":"sick"}`))
+	req = middleware.WithActor(req, authz.Actor{UserID: 2, Role: authz.RoleSitter})
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/bookings/{id}/cancel", handler.CancelBooking)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_StartBooking_InvalidTransition(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.
+		On("StartBooking", authz.Actor{UserID: 2, Role: authz.RoleSitter}, 10).
+		Return(fmt.Errorf("%w: cannot start a booking that is pending", ErrInvalidTransition))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/bookings/10/start", nil)
+	req = middleware.WithActor(req, authz.Actor{UserID: 2, Role: authz.RoleSitter})
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/bookings/{id}/start", handler.StartBooking)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetBookingHistory_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	events := []models.BookingEvent{
+		{EventID: 1, BookingID: 10, ToStatus: "pending", ActorRole: "owner"},
+		{EventID: 2, BookingID: 10, FromStatus: "pending", ToStatus: "expired", ActorRole: "system"},
+	}
+	mockService.
+		On("GetBookingHistory", authz.Actor{UserID: 1, Role: authz.RoleOwner}, 10).
+		Return(events, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/bookings/10/history", nil)
+	req = middleware.WithActor(req, authz.Actor{UserID: 1, Role: authz.RoleOwner})
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/bookings/{id}/history", handler.GetBookingHistory)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var got []models.BookingEvent
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, events, got)
+}
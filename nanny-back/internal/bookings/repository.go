@@ -3,18 +3,58 @@ package bookings
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"nanny-backend/internal/common/models"
 	"time"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/models"
+
+	"github.com/lib/pq"
 )
 
+// exclusionViolation is raised by the bookings_no_overlap constraint.
+const exclusionViolation = "23P01"
+
+var (
+	ErrBookingNotFound = apperr.NotFound("booking_not_found", "booking not found")
+	ErrPetNotFound     = apperr.NotFound("pet_not_found", "pet not found")
+	ErrServiceNotFound = apperr.NotFound("service_not_found", "service not found")
+
+	// ErrStatusChanged means the booking left the expected status between
+	// reading and updating it.
+	ErrStatusChanged = apperr.Conflict("status_changed", "booking status was changed by someone else, reload and try again")
+)
+
+// ListSpec is how owner and sitter booking lists can be sorted and filtered.
+var ListSpec = listing.Spec[models.Booking]{
+	IDColumn: "booking_id",
+	ID:       func(b models.Booking) int { return b.BookingID },
+	Sorts: map[string]listing.Sort[models.Booking]{
+		"start_time": {Column: "start_time", Value: func(b models.Booking) interface{} { return b.StartTime }},
+	},
+	DefaultSort: "-start_time",
+	Filters: map[string]listing.Filter{
+		"status":     {Column: "status", Type: listing.Text},
+		"pet_id":     {Column: "pet_id", Type: listing.Int},
+		"service_id": {Column: "service_id", Type: listing.Int},
+		"start_time": {Column: "start_time", Type: listing.Time},
+	},
+}
+
 type Repository interface {
-	Create(booking *models.Booking) (int, error)
-	GetByID(bookingID int) (*models.Booking, error)
-	GetByOwnerID(ownerID int) ([]models.Booking, error)
-	GetBySitterID(sitterID int) ([]models.Booking, error)
-	UpdateStatus(bookingID int, status string) error
-	Delete(bookingID int) error
+	Create(ctx context.Context, booking *models.Booking) (int, error)
+	GetByID(ctx context.Context, bookingID int) (*models.Booking, error)
+	GetByOwnerID(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Booking], error)
+	GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Booking], error)
+	Transition(ctx context.Context, event *models.BookingEvent) error
+	GetHistory(ctx context.Context, bookingID int) ([]models.BookingEvent, error)
+	ExpireOverdue(ctx context.Context, from, to string, startedBefore time.Time) (int64, error)
+	Delete(ctx context.Context, bookingID int) error
+	GetPetOwnerID(ctx context.Context, petID int) (int, error)
+	GetServiceSitterID(ctx context.Context, serviceID int) (int, error)
 }
 
 type repository struct {
@@ -25,15 +65,28 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Create(booking *models.Booking) (int, error) {
+func (r *repository) Create(ctx context.Context, booking *models.Booking) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	var bookingID int
-	err := r.db.QueryRow(`
-		INSERT INTO bookings (owner_id, sitter_id, pet_id, service_id, start_time, end_time, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	// The creation event is written by the same statement so history never
+	// misses a booking.
+	err := r.db.QueryRowContext(ctx, `
+		WITH created AS (
+			INSERT INTO bookings (owner_id, sitter_id, pet_id, service_id, start_time, end_time, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING booking_id, owner_id, status
+		)
+		INSERT INTO booking_events (booking_id, to_status, actor_id, actor_role)
+		SELECT booking_id, status, owner_id, 'owner' FROM created
 		RETURNING booking_id
 	`, booking.OwnerID, booking.SitterID, booking.PetID, booking.ServiceID,
 		booking.StartTime, booking.EndTime, booking.Status).Scan(&bookingID)
 
+	if isOverlap(err) {
+		return 0, ErrTimeSlotTaken
+	}
 	if err != nil {
 		return 0, fmt.Errorf("could not create booking: %w", err)
 	}
@@ -41,8 +94,8 @@ func (r *repository) Create(booking *models.Booking) (int, error) {
 	return bookingID, nil
 }
 
-func (r *repository) GetByID(bookingID int) (*models.Booking, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (r *repository) GetByID(ctx context.Context, bookingID int) (*models.Booking, error) {
+	ctx, cancel := database.WithTimeout(ctx)
 	defer cancel()
 
 	booking := &models.Booking{}
@@ -62,7 +115,7 @@ func (r *repository) GetByID(bookingID int) (*models.Booking, error) {
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("booking not found")
+		return nil, ErrBookingNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting booking: %w", err)
@@ -71,60 +124,190 @@ func (r *repository) GetByID(bookingID int) (*models.Booking, error) {
 	return booking, nil
 }
 
-func (r *repository) GetByOwnerID(ownerID int) ([]models.Booking, error) {
-	rows, err := r.db.Query(`
+func (r *repository) GetByOwnerID(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Booking], error) {
+	return r.list(ctx, "owner_id", ownerID, q)
+}
+
+func (r *repository) GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Booking], error) {
+	return r.list(ctx, "sitter_id", sitterID, q)
+}
+
+// list returns one page of the bookings whose column equals id.
+func (r *repository) list(ctx context.Context, column string, id int, q listing.Query) (*listing.Page[models.Booking], error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query, args := q.Apply(`
 		SELECT booking_id, owner_id, sitter_id, pet_id, service_id, start_time, end_time, status
 		FROM bookings
-		WHERE owner_id = $1
-		ORDER BY start_time DESC
-	`, ownerID)
+		WHERE `+column+` = $1`, []interface{}{id})
 
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error getting booking: %w", err)
 	}
 	defer rows.Close()
 
-	return scanBookings(rows)
+	bookings, err := scanBookings(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListSpec.Page(bookings, q), nil
 }
 
-func (r *repository) GetBySitterID(sitterID int) ([]models.Booking, error) {
-	rows, err := r.db.Query(`
-		SELECT booking_id, owner_id, sitter_id, pet_id, service_id, start_time, end_time, status
-		FROM bookings
-		WHERE sitter_id = $1
-		ORDER BY start_time DESC
-	`, sitterID)
+// Transition moves a booking from event.FromStatus to event.ToStatus and
+// records the event, atomically. ErrStatusChanged if the booking is no
+// longer in FromStatus.
+func (r *repository) Transition(ctx context.Context, event *models.BookingEvent) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		WITH changed AS (
+			UPDATE bookings
+			SET status = $3
+			WHERE booking_id = $1 AND status = $2
+			RETURNING booking_id
+		)
+		INSERT INTO booking_events (booking_id, from_status, to_status, actor_id, actor_role, reason)
+		SELECT booking_id, $2, $3, $4, $5, NULLIF($6, '') FROM changed
+	`, event.BookingID, event.FromStatus, event.ToStatus, event.ActorID, event.ActorRole, event.Reason)
 
+	if isOverlap(err) {
+		return ErrTimeSlotTaken
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error getting booking: %w", err)
+		return fmt.Errorf("could not update booking status: %w", err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrStatusChanged
+	}
+
+	return nil
+}
+
+func (r *repository) GetHistory(ctx context.Context, bookingID int) ([]models.BookingEvent, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT event_id, booking_id, COALESCE(from_status, ''), to_status,
+		       actor_id, actor_role, COALESCE(reason, ''), created_at
+		FROM booking_events
+		WHERE booking_id = $1
+		ORDER BY event_id
+	`, bookingID)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting booking history: %w", err)
 	}
 	defer rows.Close()
 
-	return scanBookings(rows)
+	events := []models.BookingEvent{}
+	for rows.Next() {
+		var event models.BookingEvent
+		var actorID sql.NullInt64
+
+		err := rows.Scan(
+			&event.EventID,
+			&event.BookingID,
+			&event.FromStatus,
+			&event.ToStatus,
+			&actorID,
+			&event.ActorRole,
+			&event.Reason,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning booking event: %w", err)
+		}
+
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			event.ActorID = &id
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
 }
 
-func (r *repository) UpdateStatus(bookingID int, status string) error {
-	_, err := r.db.Exec(`
-		UPDATE bookings
-		SET status = $1
-		WHERE booking_id = $2
-	`, status, bookingID)
+// ExpireOverdue moves every booking still in from that started before
+// startedBefore to to, recording a system event for each.
+func (r *repository) ExpireOverdue(ctx context.Context, from, to string, startedBefore time.Time) (int64, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		WITH expired AS (
+			UPDATE bookings
+			SET status = $2
+			WHERE status = $1 AND start_time < $3
+			RETURNING booking_id
+		)
+		INSERT INTO booking_events (booking_id, from_status, to_status, actor_role, reason)
+		SELECT booking_id, $1, $2, 'system', 'not confirmed in time' FROM expired
+	`, from, to, startedBefore)
 
 	if err != nil {
-		return fmt.Errorf("could not refresh the status booking: %w", err)
+		return 0, fmt.Errorf("could not expire bookings: %w", err)
 	}
 
-	return nil
+	return res.RowsAffected()
 }
 
-func (r *repository) Delete(bookingID int) error {
-	_, err := r.db.Exec(`DELETE FROM bookings WHERE booking_id = $1`, bookingID)
+func (r *repository) Delete(ctx context.Context, bookingID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM bookings WHERE booking_id = $1`, bookingID)
 	if err != nil {
 		return fmt.Errorf("could not delete the booking: %w", err)
 	}
 	return nil
 }
 
+func (r *repository) GetPetOwnerID(ctx context.Context, petID int) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var ownerID int
+	err := r.db.QueryRowContext(ctx, `SELECT owner_id FROM pets WHERE pet_id = $1`, petID).Scan(&ownerID)
+
+	if err == sql.ErrNoRows {
+		return 0, ErrPetNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error getting pet: %w", err)
+	}
+
+	return ownerID, nil
+}
+
+func (r *repository) GetServiceSitterID(ctx context.Context, serviceID int) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var sitterID int
+	err := r.db.QueryRowContext(ctx, `SELECT sitter_id FROM services WHERE service_id = $1`, serviceID).Scan(&sitterID)
+
+	if err == sql.ErrNoRows {
+		return 0, ErrServiceNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error getting service: %w", err)
+	}
+
+	return sitterID, nil
+}
+
+func isOverlap(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == exclusionViolation
+}
+
 func scanBookings(rows *sql.Rows) ([]models.Booking, error) {
 	var bookings []models.Booking
 	for rows.Next() {
@@ -150,5 +333,5 @@ func scanBookings(rows *sql.Rows) ([]models.Booking, error) {
 		bookings = append(bookings, booking)
 	}
 
-	return bookings, nil
+	return bookings, rows.Err()
 }
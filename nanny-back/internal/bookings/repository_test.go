@@ -1,12 +1,15 @@
 package bookings
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 )
 
@@ -42,7 +45,7 @@ func TestCreate_Success(t *testing.T) {
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow(10))
 
-	id, err := repo.Create(booking)
+	id, err := repo.Create(context.Background(), booking)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 10, id)
@@ -83,7 +86,7 @@ func TestGetByID_Success(t *testing.T) {
 		WithArgs(10).
 		WillReturnRows(rows)
 
-	booking, err := repo.GetByID(10)
+	booking, err := repo.GetByID(context.Background(), 10)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, booking)
@@ -103,7 +106,7 @@ func TestGetByID_NotFound(t *testing.T) {
 		WithArgs(999).
 		WillReturnError(sql.ErrNoRows)
 
-	booking, err := repo.GetByID(999)
+	booking, err := repo.GetByID(context.Background(), 999)
 
 	assert.Error(t, err)
 	assert.Nil(t, booking)
@@ -132,14 +135,17 @@ func TestGetByOwnerID_Success(t *testing.T) {
 		AddRow(1, 5, 10, 3, 4, now, now.Add(1*time.Hour), "pending").
 		AddRow(2, 5, 11, 4, 5, now, now.Add(2*time.Hour), "confirmed")
 
-	mock.ExpectQuery(`FROM bookings WHERE owner_id = \$1`).
-		WithArgs(5).
+	q, _ := ListSpec.Parse(nil)
+
+	mock.ExpectQuery(`FROM bookings WHERE owner_id = \$1 ORDER BY start_time DESC, booking_id DESC LIMIT \$2`).
+		WithArgs(5, listing.DefaultLimit+1).
 		WillReturnRows(rows)
 
-	bookings, err := repo.GetByOwnerID(5)
+	page, err := repo.GetByOwnerID(context.Background(), 5, q)
 
 	assert.NoError(t, err)
-	assert.Len(t, bookings, 2)
+	assert.Len(t, page.Items, 2)
+	assert.Empty(t, page.NextCursor)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -165,35 +171,103 @@ func TestGetBySitterID_Success(t *testing.T) {
 		1, 2, 7, 3, 4, now, now.Add(time.Hour), "completed",
 	)
 
+	q, _ := ListSpec.Parse(nil)
+
 	mock.ExpectQuery(`FROM bookings WHERE sitter_id = \$1`).
-		WithArgs(7).
+		WithArgs(7, listing.DefaultLimit+1).
 		WillReturnRows(rows)
 
-	bookings, err := repo.GetBySitterID(7)
+	page, err := repo.GetBySitterID(context.Background(), 7, q)
 
 	assert.NoError(t, err)
-	assert.Len(t, bookings, 1)
-	assert.Equal(t, "completed", bookings[0].Status)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "completed", page.Items[0].Status)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestUpdateStatus_Success(t *testing.T) {
+func TestTransition_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
 	repo := &repository{db: db}
+	actorID := 2
 
-	mock.ExpectExec(`UPDATE bookings`).
-		WithArgs("confirmed", 10).
+	mock.ExpectExec(`UPDATE bookings .* INSERT INTO booking_events`).
+		WithArgs(10, "pending", "confirmed", &actorID, "sitter", "").
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.UpdateStatus(10, "confirmed")
+	err = repo.Transition(context.Background(), &models.BookingEvent{
+		BookingID:  10,
+		FromStatus: "pending",
+		ToStatus:   "confirmed",
+		ActorID:    &actorID,
+		ActorRole:  "sitter",
+	})
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestTransition_StatusChanged(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectExec(`UPDATE bookings`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Transition(context.Background(), &models.BookingEvent{BookingID: 10, FromStatus: "pending", ToStatus: "confirmed", ActorRole: "sitter"})
+
+	assert.ErrorIs(t, err, ErrStatusChanged)
+}
+
+func TestGetHistory_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"event_id", "booking_id", "from_status", "to_status", "actor_id", "actor_role", "reason", "created_at"}).
+		AddRow(1, 10, "", "pending", 1, "owner", "", now).
+		AddRow(2, 10, "pending", "expired", nil, "system", "not confirmed in time", now)
+
+	mock.ExpectQuery(`FROM booking_events`).
+		WithArgs(10).
+		WillReturnRows(rows)
+
+	events, err := repo.GetHistory(context.Background(), 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, 1, *events[0].ActorID)
+	assert.Nil(t, events[1].ActorID)
+	assert.Equal(t, "system", events[1].ActorRole)
+}
+
+func TestExpireOverdue_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	cutoff := time.Now().Add(-24 * time.Hour)
+
+	mock.ExpectExec(`UPDATE bookings .* INSERT INTO booking_events`).
+		WithArgs("pending", "expired", cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	affected, err := repo.ExpireOverdue(context.Background(), "pending", "expired", cutoff)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), affected)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDelete_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -205,8 +279,62 @@ func TestDelete_Success(t *testing.T) {
 		WithArgs(10).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Delete(10)
+	err = repo.Delete(context.Background(), 10)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPetOwnerID_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectQuery(`SELECT owner_id FROM pets WHERE pet_id = \$1`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id"}).AddRow(1))
+
+	ownerID, err := repo.GetPetOwnerID(context.Background(), 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ownerID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
 
+func TestGetServiceSitterID_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectQuery(`SELECT sitter_id FROM services WHERE service_id = \$1`).
+		WithArgs(4).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetServiceSitterID(context.Background(), 4)
+
+	assert.EqualError(t, err, "service not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreate_OverlappingBooking(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	start := time.Now().Add(24 * time.Hour)
+	booking := &models.Booking{OwnerID: 1, SitterID: 2, PetID: 3, ServiceID: 4, StartTime: start, EndTime: start.Add(time.Hour), Status: "pending"}
+
+	mock.ExpectQuery(`INSERT INTO bookings`).
+		WillReturnError(&pq.Error{Code: "23P01", Constraint: "bookings_no_overlap"})
+
+	_, err = repo.Create(context.Background(), booking)
+
+	assert.ErrorIs(t, err, ErrTimeSlotTaken)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
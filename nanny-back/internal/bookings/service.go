@@ -1,106 +1,343 @@
 package bookings
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"nanny-backend/internal/audit"
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 )
 
 type Service interface {
-	CreateBooking(ownerID, sitterID, petID, serviceID int, startTime, endTime time.Time) (int, error)
-	GetBookingByID(bookingID int) (*models.Booking, error)
-	GetOwnerBookings(ownerID int) ([]models.Booking, error)
-	GetSitterBookings(sitterID int) ([]models.Booking, error)
-	ConfirmBooking(bookingID int) error
-	CancelBooking(bookingID int) error
-	CompleteBooking(bookingID int) error
+	CreateBooking(ctx context.Context, actor authz.Actor, sitterID, petID, serviceID int, startTime, endTime time.Time) (int, error)
+	GetBookingByID(ctx context.Context, bookingID int) (*models.Booking, error)
+	GetOwnerBookings(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Booking], error)
+	GetSitterBookings(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Booking], error)
+	ConfirmBooking(ctx context.Context, actor authz.Actor, bookingID int) error
+	StartBooking(ctx context.Context, actor authz.Actor, bookingID int) error
+	CompleteBooking(ctx context.Context, actor authz.Actor, bookingID int) error
+	CancelBooking(ctx context.Context, actor authz.Actor, bookingID int, reason string) error
+	ReportNoShow(ctx context.Context, actor authz.Actor, bookingID int, reason string) error
+	GetBookingHistory(ctx context.Context, actor authz.Actor, bookingID int) ([]models.BookingEvent, error)
+	ExpireOverdueBookings(ctx context.Context) (int64, error)
+}
+
+// expireAfter is how long past its start a booking may stay pending.
+const expireAfter = 24 * time.Hour
+
+var (
+	ErrTimeSlotTaken     = apperr.Conflict("time_slot_taken", "nanny is already booked for this time")
+	ErrSitterUnavailable = apperr.Conflict("sitter_unavailable", "nanny does not work at this time")
+	ErrOutOfServiceArea  = apperr.Conflict("out_of_service_area", "owner's address is outside the nanny's service area")
+	ErrServiceMismatch   = apperr.Validation("service_mismatch", "service is not offered by this nanny")
+)
+
+// AvailabilityChecker is implemented by the availability module.
+type AvailabilityChecker interface {
+	IsAvailable(ctx context.Context, sitterID int, start, end time.Time) (bool, error)
+}
+
+// CoverageChecker is implemented by the locations module.
+type CoverageChecker interface {
+	Covers(ctx context.Context, sitterID, ownerID int) (bool, error)
+}
+
+// PaymentRecorder is implemented by the payments module. ChargeBooking
+// must be idempotent; RefundBooking must be a no-op for unpaid bookings.
+type PaymentRecorder interface {
+	ChargeBooking(ctx context.Context, bookingID int) (*models.Payment, error)
+	RefundBooking(ctx context.Context, bookingID int) error
+}
+
+// ChatOpener is implemented by the chat module.
+type ChatOpener interface {
+	CreateChat(ctx context.Context, bookingID int) error
 }
 
 type service struct {
-	repo Repository
+	repo         Repository
+	availability AvailabilityChecker
+	coverage     CoverageChecker
+	payments     PaymentRecorder
+	chats        ChatOpener
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, availability AvailabilityChecker, coverage CoverageChecker, payments PaymentRecorder, chats ChatOpener) Service {
+	return &service{repo: repo, availability: availability, coverage: coverage, payments: payments, chats: chats}
 }
 
-func (s *service) CreateBooking(ownerID, sitterID, petID, serviceID int, startTime, endTime time.Time) (int, error) {
+func (s *service) CreateBooking(ctx context.Context, actor authz.Actor, sitterID, petID, serviceID int, startTime, endTime time.Time) (int, error) {
+	if actor.UserID <= 0 {
+		return 0, authz.ErrForbidden
+	}
 
 	if startTime.After(endTime) {
-		return 0, fmt.Errorf("start data cannot be after end data")
+		return 0, apperr.Validation("invalid_time_range", "start data cannot be after end data")
 	}
 
 	if startTime.Before(time.Now()) {
-		return 0, fmt.Errorf("cannot create booking in the past")
+		return 0, apperr.Validation("start_in_past", "cannot create booking in the past")
+	}
+
+	petOwnerID, err := s.repo.GetPetOwnerID(ctx, petID)
+	if err != nil {
+		return 0, err
+	}
+
+	if petOwnerID != actor.UserID {
+		return 0, fmt.Errorf("pet belongs to another owner: %w", authz.ErrForbidden)
+	}
+
+	serviceSitterID, err := s.repo.GetServiceSitterID(ctx, serviceID)
+	if err != nil {
+		return 0, err
+	}
+
+	if serviceSitterID != sitterID {
+		return 0, ErrServiceMismatch
+	}
+
+	available, err := s.availability.IsAvailable(ctx, sitterID, startTime, endTime)
+	if err != nil {
+		return 0, err
+	}
+
+	if !available {
+		return 0, ErrSitterUnavailable
+	}
+
+	covered, err := s.coverage.Covers(ctx, sitterID, actor.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !covered {
+		return 0, ErrOutOfServiceArea
 	}
 
 	booking := &models.Booking{
-		OwnerID:   ownerID,
+		OwnerID:   actor.UserID,
 		SitterID:  sitterID,
 		PetID:     petID,
 		ServiceID: serviceID,
 		StartTime: startTime,
 		EndTime:   endTime,
-		Status:    "pending",
+		Status:    StatusPending,
 	}
 
-	bookingID, err := s.repo.Create(booking)
+	bookingID, err := s.repo.Create(ctx, booking)
+	if errors.Is(err, ErrTimeSlotTaken) {
+		return 0, err
+	}
 	if err != nil {
 		return 0, fmt.Errorf("error creating booking: %w", err)
 	}
 
+	booking.BookingID = bookingID
+	audit.Describe(ctx, "booking.create", "booking", bookingID, nil, booking)
+
+	// The chat module also opens the chat on first use, so the booking
+	// stands even if this fails.
+	_ = s.chats.CreateChat(ctx, bookingID)
+
 	return bookingID, nil
 }
 
-func (s *service) GetBookingByID(bookingID int) (*models.Booking, error) {
-	return s.repo.GetByID(bookingID)
+func (s *service) GetBookingByID(ctx context.Context, bookingID int) (*models.Booking, error) {
+	return s.repo.GetByID(ctx, bookingID)
 }
 
-func (s *service) GetOwnerBookings(ownerID int) ([]models.Booking, error) {
-	return s.repo.GetByOwnerID(ownerID)
+func (s *service) GetOwnerBookings(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Booking], error) {
+	return s.repo.GetByOwnerID(ctx, ownerID, q)
 }
 
-func (s *service) GetSitterBookings(sitterID int) ([]models.Booking, error) {
-	return s.repo.GetBySitterID(sitterID)
+func (s *service) GetSitterBookings(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Booking], error) {
+	return s.repo.GetBySitterID(ctx, sitterID, q)
+}
+
+func (s *service) ConfirmBooking(ctx context.Context, actor authz.Actor, bookingID int) error {
+	booking, event, err := s.prepareSitterAction(ctx, actor, bookingID, ActionConfirm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.payments.ChargeBooking(ctx, booking.BookingID); err != nil {
+		return err
+	}
+
+	if err := s.transition(ctx, "booking.confirm", event); err != nil {
+		_ = s.payments.RefundBooking(ctx, booking.BookingID)
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) StartBooking(ctx context.Context, actor authz.Actor, bookingID int) error {
+	_, event, err := s.prepareSitterAction(ctx, actor, bookingID, ActionStart)
+	if err != nil {
+		return err
+	}
+
+	return s.transition(ctx, "booking.start", event)
 }
 
-func (s *service) ConfirmBooking(bookingID int) error {
+func (s *service) CompleteBooking(ctx context.Context, actor authz.Actor, bookingID int) error {
+	booking, event, err := s.prepareSitterAction(ctx, actor, bookingID, ActionComplete)
+	if err != nil {
+		return err
+	}
 
-	booking, err := s.repo.GetByID(bookingID)
+	// Bookings confirmed before payments existed are charged here.
+	if _, err := s.payments.ChargeBooking(ctx, booking.BookingID); err != nil {
+		return err
+	}
+
+	return s.transition(ctx, "booking.complete", event)
+}
+
+// CancelBooking refunds only once the booking is cancelled, so a booking
+// that was confirmed or completed in the meantime keeps its payment. A
+// failed refund leaves the payment paid for an admin to refund again.
+func (s *service) CancelBooking(ctx context.Context, actor authz.Actor, bookingID int, reason string) error {
+	booking, event, err := s.prepareParticipantAction(ctx, actor, bookingID, ActionCancel, reason)
 	if err != nil {
 		return err
 	}
 
-	if booking.Status != "pending" {
-		return fmt.Errorf("can complete only booking with status 'pending'")
+	if err := s.transition(ctx, "booking.cancel", event); err != nil {
+		return err
 	}
 
-	return s.repo.UpdateStatus(bookingID, "confirmed")
+	if err := s.payments.RefundBooking(ctx, booking.BookingID); err != nil {
+		return fmt.Errorf("booking cancelled but not refunded: %w", err)
+	}
+
+	return nil
 }
 
-func (s *service) CancelBooking(bookingID int) error {
-	booking, err := s.repo.GetByID(bookingID)
+// ReportNoShow is filed by whoever turned up. A sitter who did not come
+// does not keep the money; an owner who did not come pays as booked. As
+// with CancelBooking the refund follows the status change.
+func (s *service) ReportNoShow(ctx context.Context, actor authz.Actor, bookingID int, reason string) error {
+	booking, event, err := s.prepareParticipantAction(ctx, actor, bookingID, ActionNoShow, reason)
 	if err != nil {
 		return err
 	}
 
-	if booking.Status == "completed" {
-		return fmt.Errorf("cannot cancel completed booking")
+	if time.Now().Before(booking.StartTime) {
+		return fmt.Errorf("%w: the booking has not started yet", ErrInvalidTransition)
+	}
+
+	if err := s.transition(ctx, "booking.no_show", event); err != nil {
+		return err
+	}
+
+	if booking.OwnerID == actor.UserID {
+		if err := s.payments.RefundBooking(ctx, booking.BookingID); err != nil {
+			return fmt.Errorf("no-show recorded but not refunded: %w", err)
+		}
 	}
 
-	return s.repo.UpdateStatus(bookingID, "cancelled")
+	return nil
 }
 
-func (s *service) CompleteBooking(bookingID int) error {
-	booking, err := s.repo.GetByID(bookingID)
+func (s *service) GetBookingHistory(ctx context.Context, actor authz.Actor, bookingID int) ([]models.BookingEvent, error) {
+	booking, err := s.repo.GetByID(ctx, bookingID)
 	if err != nil {
+		return nil, err
+	}
+
+	if !actor.CanActAs(booking.OwnerID) && !actor.CanActAs(booking.SitterID) {
+		return nil, fmt.Errorf("booking belongs to another user: %w", authz.ErrForbidden)
+	}
+
+	return s.repo.GetHistory(ctx, bookingID)
+}
+
+// ExpireOverdueBookings is run periodically: bookings nobody confirmed
+// within expireAfter of their start are expired.
+func (s *service) ExpireOverdueBookings(ctx context.Context) (int64, error) {
+	return s.repo.ExpireOverdue(ctx, StatusPending, StatusExpired, time.Now().Add(-expireAfter))
+}
+
+// transition stores the status change and describes it for the audit log.
+func (s *service) transition(ctx context.Context, action string, event *models.BookingEvent) error {
+	if err := s.repo.Transition(ctx, event); err != nil {
 		return err
 	}
 
-	if booking.Status != "confirmed" {
-		return fmt.Errorf("can complete only accepted booking")
+	audit.Describe(ctx, action, "booking", event.BookingID,
+		map[string]string{"status": event.FromStatus},
+		map[string]string{"status": event.ToStatus, "reason": event.Reason},
+	)
+	return nil
+}
+
+// prepareSitterAction loads the booking and checks that actor may perform
+// a sitter-only action on it. Admins may act for the sitter.
+func (s *service) prepareSitterAction(ctx context.Context, actor authz.Actor, bookingID int, action Action) (*models.Booking, *models.BookingEvent, error) {
+	booking, err := s.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !actor.CanActAs(booking.SitterID) {
+		return nil, nil, fmt.Errorf("booking belongs to another nanny: %w", authz.ErrForbidden)
+	}
+
+	event, err := newEvent(actor, booking, action, PartySitter, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return booking, event, nil
+}
+
+// prepareParticipantAction is for actions either side can take; the
+// resulting status depends on which side the actor is on.
+func (s *service) prepareParticipantAction(ctx context.Context, actor authz.Actor, bookingID int, action Action, reason string) (*models.Booking, *models.BookingEvent, error) {
+	booking, err := s.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var party Party
+	switch actor.UserID {
+	case booking.OwnerID:
+		party = PartyOwner
+	case booking.SitterID:
+		party = PartySitter
+	default:
+		return nil, nil, fmt.Errorf("booking belongs to another user: %w", authz.ErrForbidden)
+	}
+
+	event, err := newEvent(actor, booking, action, party, reason)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return booking, event, nil
+}
+
+func newEvent(actor authz.Actor, booking *models.Booking, action Action, party Party, reason string) (*models.BookingEvent, error) {
+	to, err := NextStatus(booking.Status, action, party)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.UpdateStatus(bookingID, "completed")
+	actorID := actor.UserID
+	return &models.BookingEvent{
+		BookingID:  booking.BookingID,
+		FromStatus: booking.Status,
+		ToStatus:   to,
+		ActorID:    &actorID,
+		ActorRole:  actor.Role,
+		Reason:     reason,
+	}, nil
 }
@@ -1,10 +1,13 @@
 package bookings
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 
 	"github.com/stretchr/testify/assert"
@@ -15,12 +18,12 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) Create(booking *models.Booking) (int, error) {
+func (m *MockRepository) Create(ctx context.Context, booking *models.Booking) (int, error) {
 	args := m.Called(booking)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockRepository) GetByID(bookingID int) (*models.Booking, error) {
+func (m *MockRepository) GetByID(ctx context.Context, bookingID int) (*models.Booking, error) {
 	args := m.Called(bookingID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -28,32 +31,116 @@ func (m *MockRepository) GetByID(bookingID int) (*models.Booking, error) {
 	return args.Get(0).(*models.Booking), args.Error(1)
 }
 
-func (m *MockRepository) GetByOwnerID(ownerID int) ([]models.Booking, error) {
-	args := m.Called(ownerID)
-	return args.Get(0).([]models.Booking), args.Error(1)
+func (m *MockRepository) GetByOwnerID(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Booking], error) {
+	args := m.Called(ownerID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[models.Booking]), args.Error(1)
 }
 
-func (m *MockRepository) GetBySitterID(sitterID int) ([]models.Booking, error) {
-	args := m.Called(sitterID)
-	return args.Get(0).([]models.Booking), args.Error(1)
+func (m *MockRepository) GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Booking], error) {
+	args := m.Called(sitterID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[models.Booking]), args.Error(1)
 }
 
-func (m *MockRepository) UpdateStatus(bookingID int, status string) error {
-	args := m.Called(bookingID, status)
+func (m *MockRepository) Transition(ctx context.Context, event *models.BookingEvent) error {
+	args := m.Called(event)
 	return args.Error(0)
 }
-func (m *MockRepository) Delete(bookingID int) error {
+
+func (m *MockRepository) GetHistory(ctx context.Context, bookingID int) ([]models.BookingEvent, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).([]models.BookingEvent), args.Error(1)
+}
+
+func (m *MockRepository) ExpireOverdue(ctx context.Context, from, to string, startedBefore time.Time) (int64, error) {
+	args := m.Called(from, to, startedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// transitionTo matches a Transition call moving bookingID to status.
+func transitionTo(bookingID int, status string) interface{} {
+	return mock.MatchedBy(func(e *models.BookingEvent) bool {
+		return e.BookingID == bookingID && e.ToStatus == status
+	})
+}
+func (m *MockRepository) Delete(ctx context.Context, bookingID int) error {
 	args := m.Called(bookingID)
 	return args.Error(0)
 }
 
+func (m *MockRepository) GetPetOwnerID(ctx context.Context, petID int) (int, error) {
+	args := m.Called(petID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) GetServiceSitterID(ctx context.Context, serviceID int) (int, error) {
+	args := m.Called(serviceID)
+	return args.Int(0), args.Error(1)
+}
+
+type availableAt struct {
+	ok bool
+}
+
+func (a availableAt) IsAvailable(ctx context.Context, sitterID int, start, end time.Time) (bool, error) {
+	return a.ok, nil
+}
+
+type coveredBy struct {
+	ok bool
+}
+
+func (c coveredBy) Covers(ctx context.Context, sitterID, ownerID int) (bool, error) {
+	return c.ok, nil
+}
+
+type fakePayments struct {
+	chargeErr error
+	charged   []int
+	refunded  []int
+}
+
+func (f *fakePayments) ChargeBooking(ctx context.Context, bookingID int) (*models.Payment, error) {
+	if f.chargeErr != nil {
+		return nil, f.chargeErr
+	}
+	f.charged = append(f.charged, bookingID)
+	return &models.Payment{BookingID: bookingID, Status: "paid"}, nil
+}
+
+func (f *fakePayments) RefundBooking(ctx context.Context, bookingID int) error {
+	f.refunded = append(f.refunded, bookingID)
+	return nil
+}
+
+type fakeChats struct {
+	opened []int
+}
+
+func (f *fakeChats) CreateChat(ctx context.Context, bookingID int) error {
+	f.opened = append(f.opened, bookingID)
+	return nil
+}
+
+var (
+	owner  = authz.Actor{UserID: 1, Role: authz.RoleOwner}
+	sitter = authz.Actor{UserID: 2, Role: authz.RoleSitter}
+)
+
 func TestCreateBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	startTime := time.Now().Add(24 * time.Hour)
 	endTime := startTime.Add(2 * time.Hour)
 
+	mockRepo.On("GetPetOwnerID", 3).Return(1, nil)
+	mockRepo.On("GetServiceSitterID", 4).Return(2, nil)
 	mockRepo.On("Create", mock.MatchedBy(func(b *models.Booking) bool {
 		return b.OwnerID == 1 &&
 			b.SitterID == 2 &&
@@ -62,7 +149,7 @@ func TestCreateBooking_Success(t *testing.T) {
 			b.Status == "pending"
 	})).Return(42, nil)
 
-	bookingID, err := service.CreateBooking(1, 2, 3, 4, startTime, endTime)
+	bookingID, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 42, bookingID)
@@ -71,12 +158,12 @@ func TestCreateBooking_Success(t *testing.T) {
 
 func TestCreateBooking_EndTimeBeforeStartTime(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	startTime := time.Now().Add(24 * time.Hour)
 	endTime := startTime.Add(-1 * time.Hour)
 
-	bookingID, err := service.CreateBooking(1, 2, 3, 4, startTime, endTime)
+	bookingID, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, bookingID)
@@ -86,12 +173,12 @@ func TestCreateBooking_EndTimeBeforeStartTime(t *testing.T) {
 
 func TestCreateBooking_StartTimeInPast(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	startTime := time.Now().Add(-1 * time.Hour)
 	endTime := time.Now().Add(1 * time.Hour)
 
-	bookingID, err := service.CreateBooking(1, 2, 3, 4, startTime, endTime)
+	bookingID, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, bookingID)
@@ -101,14 +188,16 @@ func TestCreateBooking_StartTimeInPast(t *testing.T) {
 
 func TestCreateBooking_RepositoryError(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	startTime := time.Now().Add(24 * time.Hour)
 	endTime := startTime.Add(2 * time.Hour)
 
+	mockRepo.On("GetPetOwnerID", 3).Return(1, nil)
+	mockRepo.On("GetServiceSitterID", 4).Return(2, nil)
 	mockRepo.On("Create", mock.Anything).Return(0, errors.New("database error"))
 
-	bookingID, err := service.CreateBooking(1, 2, 3, 4, startTime, endTime)
+	bookingID, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, bookingID)
@@ -118,7 +207,7 @@ func TestCreateBooking_RepositoryError(t *testing.T) {
 
 func TestGetBookingByID_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	expectedBooking := &models.Booking{
 		BookingID: 1,
@@ -129,7 +218,7 @@ func TestGetBookingByID_Success(t *testing.T) {
 
 	mockRepo.On("GetByID", 1).Return(expectedBooking, nil)
 
-	booking, err := service.GetBookingByID(1)
+	booking, err := service.GetBookingByID(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedBooking, booking)
@@ -138,11 +227,11 @@ func TestGetBookingByID_Success(t *testing.T) {
 
 func TestGetBookingByID_NotFound(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	mockRepo.On("GetByID", 999).Return((*models.Booking)(nil), errors.New("booking not found"))
 
-	booking, err := service.GetBookingByID(999)
+	booking, err := service.GetBookingByID(context.Background(), 999)
 
 	assert.Error(t, err)
 	assert.Nil(t, booking)
@@ -151,17 +240,19 @@ func TestGetBookingByID_NotFound(t *testing.T) {
 
 func TestConfirmBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	existingBooking := &models.Booking{
 		BookingID: 1,
+		OwnerID:   1,
+		SitterID:  2,
 		Status:    "pending",
 	}
 
 	mockRepo.On("GetByID", 1).Return(existingBooking, nil)
-	mockRepo.On("UpdateStatus", 1, "confirmed").Return(nil)
+	mockRepo.On("Transition", transitionTo(1, "confirmed")).Return(nil)
 
-	err := service.ConfirmBooking(1)
+	err := service.ConfirmBooking(context.Background(), sitter, 1)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -169,35 +260,39 @@ func TestConfirmBooking_Success(t *testing.T) {
 
 func TestConfirmBooking_InvalidStatus(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	existingBooking := &models.Booking{
 		BookingID: 1,
+		OwnerID:   1,
+		SitterID:  2,
 		Status:    "confirmed",
 	}
 
 	mockRepo.On("GetByID", 1).Return(existingBooking, nil)
 
-	err := service.ConfirmBooking(1)
+	err := service.ConfirmBooking(context.Background(), sitter, 1)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "can approve only booking with status 'pending'")
-	mockRepo.AssertNotCalled(t, "UpdateStatus")
+	mockRepo.AssertNotCalled(t, "Transition", mock.Anything)
 }
 
 func TestCancelBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	existingBooking := &models.Booking{
 		BookingID: 1,
+		OwnerID:   1,
+		SitterID:  2,
 		Status:    "pending",
 	}
 
 	mockRepo.On("GetByID", 1).Return(existingBooking, nil)
-	mockRepo.On("UpdateStatus", 1, "cancelled").Return(nil)
+	mockRepo.On("Transition", transitionTo(1, "cancelled_by_owner")).Return(nil)
 
-	err := service.CancelBooking(1)
+	err := service.CancelBooking(context.Background(), owner, 1, "")
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -205,35 +300,38 @@ func TestCancelBooking_Success(t *testing.T) {
 
 func TestCancelBooking_CompletedBooking(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	existingBooking := &models.Booking{
 		BookingID: 1,
+		OwnerID:   1,
+		SitterID:  2,
 		Status:    "completed",
 	}
 
 	mockRepo.On("GetByID", 1).Return(existingBooking, nil)
 
-	err := service.CancelBooking(1)
+	err := service.CancelBooking(context.Background(), owner, 1, "")
 
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "cannot cancel completed booking")
-	mockRepo.AssertNotCalled(t, "UpdateStatus")
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	mockRepo.AssertNotCalled(t, "Transition", mock.Anything)
 }
 
 func TestCompleteBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	existingBooking := &models.Booking{
 		BookingID: 1,
-		Status:    "confirmed",
+		OwnerID:   1,
+		SitterID:  2,
+		Status:    "in_progress",
 	}
 
 	mockRepo.On("GetByID", 1).Return(existingBooking, nil)
-	mockRepo.On("UpdateStatus", 1, "completed").Return(nil)
+	mockRepo.On("Transition", transitionTo(1, "completed")).Return(nil)
 
-	err := service.CompleteBooking(1)
+	err := service.CompleteBooking(context.Background(), sitter, 1)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -241,44 +339,103 @@ func TestCompleteBooking_Success(t *testing.T) {
 
 func TestCompleteBooking_NotConfirmed(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	existingBooking := &models.Booking{
 		BookingID: 1,
+		OwnerID:   1,
+		SitterID:  2,
 		Status:    "pending",
 	}
 
 	mockRepo.On("GetByID", 1).Return(existingBooking, nil)
 
-	err := service.CompleteBooking(1)
+	err := service.CompleteBooking(context.Background(), sitter, 1)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "can only finish accepted booking")
-	mockRepo.AssertNotCalled(t, "UpdateStatus")
+	mockRepo.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+func TestCreateBooking_ForeignPet(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	startTime := time.Now().Add(24 * time.Hour)
+	endTime := startTime.Add(2 * time.Hour)
+
+	mockRepo.On("GetPetOwnerID", 3).Return(99, nil)
+
+	bookingID, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
+
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+	assert.Equal(t, 0, bookingID)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateBooking_ServiceOfOtherSitter(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	startTime := time.Now().Add(24 * time.Hour)
+	endTime := startTime.Add(2 * time.Hour)
+
+	mockRepo.On("GetPetOwnerID", 3).Return(1, nil)
+	mockRepo.On("GetServiceSitterID", 4).Return(7, nil)
+
+	_, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
+
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestConfirmBooking_OtherSitter(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 5, Status: "pending"}, nil)
+
+	err := service.ConfirmBooking(context.Background(), sitter, 1)
+
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+	mockRepo.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+func TestCancelBooking_Stranger(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 8, SitterID: 9, Status: "pending"}, nil)
+
+	err := service.CancelBooking(context.Background(), owner, 1, "")
+
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+	mockRepo.AssertNotCalled(t, "Transition", mock.Anything)
 }
 
 func TestGetOwnerBookings_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	expectedBookings := []models.Booking{
 		{BookingID: 1, OwnerID: 5},
 		{BookingID: 2, OwnerID: 5},
 	}
 
-	mockRepo.On("GetByOwnerID", 5).Return(expectedBookings, nil)
+	q, _ := ListSpec.Parse(nil)
+	mockRepo.On("GetByOwnerID", 5, q).Return(ListSpec.Page(expectedBookings, q), nil)
 
-	bookings, err := service.GetOwnerBookings(5)
+	page, err := service.GetOwnerBookings(context.Background(), 5, q)
 
 	assert.NoError(t, err)
-	assert.Len(t, bookings, 2)
-	assert.Equal(t, expectedBookings, bookings)
+	assert.Equal(t, expectedBookings, page.Items)
+	assert.Empty(t, page.NextCursor)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestGetSitterBookings_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
 
 	expectedBookings := []models.Booking{
 		{BookingID: 3, SitterID: 10},
@@ -286,12 +443,272 @@ func TestGetSitterBookings_Success(t *testing.T) {
 		{BookingID: 5, SitterID: 10},
 	}
 
-	mockRepo.On("GetBySitterID", 10).Return(expectedBookings, nil)
+	q, _ := ListSpec.Parse(nil)
+	mockRepo.On("GetBySitterID", 10, q).Return(ListSpec.Page(expectedBookings, q), nil)
+
+	page, err := service.GetSitterBookings(context.Background(), 10, q)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedBookings, page.Items)
+	assert.Empty(t, page.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBooking_SitterNotWorking(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: false}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	startTime := time.Now().Add(24 * time.Hour)
+	endTime := startTime.Add(2 * time.Hour)
+
+	mockRepo.On("GetPetOwnerID", 3).Return(1, nil)
+	mockRepo.On("GetServiceSitterID", 4).Return(2, nil)
+
+	_, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
+
+	assert.ErrorIs(t, err, ErrSitterUnavailable)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateBooking_OutOfServiceArea(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: false}, &fakePayments{}, &fakeChats{})
+
+	startTime := time.Now().Add(24 * time.Hour)
+	endTime := startTime.Add(2 * time.Hour)
+
+	mockRepo.On("GetPetOwnerID", 3).Return(1, nil)
+	mockRepo.On("GetServiceSitterID", 4).Return(2, nil)
+
+	_, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
+
+	assert.ErrorIs(t, err, ErrOutOfServiceArea)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateBooking_Overlap(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	startTime := time.Now().Add(24 * time.Hour)
+	endTime := startTime.Add(2 * time.Hour)
+
+	mockRepo.On("GetPetOwnerID", 3).Return(1, nil)
+	mockRepo.On("GetServiceSitterID", 4).Return(2, nil)
+	mockRepo.On("Create", mock.Anything).Return(0, ErrTimeSlotTaken)
+
+	_, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
+
+	assert.ErrorIs(t, err, ErrTimeSlotTaken)
+}
+
+func TestConfirmBooking_ChargesPayment(t *testing.T) {
+	mockRepo := new(MockRepository)
+	payments := &fakePayments{}
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, payments, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 2, Status: "pending"}, nil)
+	mockRepo.On("Transition", transitionTo(1, "confirmed")).Return(nil)
+
+	err := service.ConfirmBooking(context.Background(), sitter, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, payments.charged)
+}
+
+func TestConfirmBooking_PaymentFailed(t *testing.T) {
+	mockRepo := new(MockRepository)
+	payments := &fakePayments{chargeErr: errors.New("payment failed: card declined")}
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, payments, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 2, Status: "pending"}, nil)
+
+	err := service.ConfirmBooking(context.Background(), sitter, 1)
+
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+func TestConfirmBooking_RefundsWhenUpdateFails(t *testing.T) {
+	mockRepo := new(MockRepository)
+	payments := &fakePayments{}
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, payments, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 2, Status: "pending"}, nil)
+	mockRepo.On("Transition", transitionTo(1, "confirmed")).Return(ErrTimeSlotTaken)
+
+	err := service.ConfirmBooking(context.Background(), sitter, 1)
+
+	assert.ErrorIs(t, err, ErrTimeSlotTaken)
+	assert.Equal(t, []int{1}, payments.refunded)
+}
+
+func TestCancelBooking_RefundsPayment(t *testing.T) {
+	mockRepo := new(MockRepository)
+	payments := &fakePayments{}
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, payments, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 2, Status: "confirmed"}, nil)
+	mockRepo.On("Transition", transitionTo(1, "cancelled_by_owner")).Return(nil)
+
+	err := service.CancelBooking(context.Background(), owner, 1, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, payments.refunded)
+}
+
+func TestCancelBooking_NoRefundWhenTransitionFails(t *testing.T) {
+	for _, transitionErr := range []error{ErrStatusChanged, ErrInvalidTransition} {
+		mockRepo := new(MockRepository)
+		payments := &fakePayments{}
+		service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, payments, &fakeChats{})
+
+		mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 2, Status: "confirmed"}, nil)
+		mockRepo.On("Transition", transitionTo(1, "cancelled_by_owner")).Return(transitionErr)
+
+		err := service.CancelBooking(context.Background(), owner, 1, "")
+
+		assert.ErrorIs(t, err, transitionErr)
+		assert.Empty(t, payments.refunded)
+	}
+}
+
+func TestCreateBooking_OpensChat(t *testing.T) {
+	mockRepo := new(MockRepository)
+	chats := &fakeChats{}
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, chats)
+
+	startTime := time.Now().Add(24 * time.Hour)
+	endTime := startTime.Add(2 * time.Hour)
+
+	mockRepo.On("GetPetOwnerID", 3).Return(1, nil)
+	mockRepo.On("GetServiceSitterID", 4).Return(2, nil)
+	mockRepo.On("Create", mock.Anything).Return(7, nil)
 
-	bookings, err := service.GetSitterBookings(10)
+	bookingID, err := service.CreateBooking(context.Background(), owner, 2, 3, 4, startTime, endTime)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{bookingID}, chats.opened)
+}
+
+func TestStartBooking_Success(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 2, Status: "confirmed"}, nil)
+	mockRepo.On("Transition", mock.MatchedBy(func(e *models.BookingEvent) bool {
+		return e.FromStatus == "confirmed" && e.ToStatus == "in_progress" &&
+			*e.ActorID == 2 && e.ActorRole == authz.RoleSitter
+	})).Return(nil)
+
+	err := service.StartBooking(context.Background(), sitter, 1)
 
 	assert.NoError(t, err)
-	assert.Len(t, bookings, 3)
-	assert.Equal(t, expectedBookings, bookings)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestCancelBooking_BySitterWithReason(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 2, Status: "confirmed"}, nil)
+	mockRepo.On("Transition", mock.MatchedBy(func(e *models.BookingEvent) bool {
+		return e.ToStatus == "cancelled_by_sitter" && e.Reason == "sick"
+	})).Return(nil)
+
+	err := service.CancelBooking(context.Background(), sitter, 1, "sick")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelBooking_InProgress(t *testing.T) {
+	mockRepo := new(MockRepository)
+	payments := &fakePayments{}
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, payments, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 2, Status: "in_progress"}, nil)
+
+	err := service.CancelBooking(context.Background(), owner, 1, "")
+
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	assert.Empty(t, payments.refunded)
+	mockRepo.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+func TestReportNoShow_BeforeStart(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{
+		BookingID: 1, OwnerID: 1, SitterID: 2, Status: "confirmed",
+		StartTime: time.Now().Add(time.Hour),
+	}, nil)
+
+	err := service.ReportNoShow(context.Background(), owner, 1, "")
+
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	mockRepo.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+func TestReportNoShow_RefundsOnlyWhenSitterMissing(t *testing.T) {
+	booking := &models.Booking{
+		BookingID: 1, OwnerID: 1, SitterID: 2, Status: "confirmed",
+		StartTime: time.Now().Add(-time.Hour),
+	}
+
+	mockRepo := new(MockRepository)
+	payments := &fakePayments{}
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, payments, &fakeChats{})
+	mockRepo.On("GetByID", 1).Return(booking, nil)
+	mockRepo.On("Transition", transitionTo(1, "no_show")).Return(nil)
+
+	assert.NoError(t, service.ReportNoShow(context.Background(), sitter, 1, "nobody home"))
+	assert.Empty(t, payments.refunded)
+
+	assert.NoError(t, service.ReportNoShow(context.Background(), owner, 1, "nanny never came"))
+	assert.Equal(t, []int{1}, payments.refunded)
+}
+
+func TestReportNoShow_NoRefundWhenTransitionFails(t *testing.T) {
+	mockRepo := new(MockRepository)
+	payments := &fakePayments{}
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, payments, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{
+		BookingID: 1, OwnerID: 1, SitterID: 2, Status: "confirmed",
+		StartTime: time.Now().Add(-time.Hour),
+	}, nil)
+	mockRepo.On("Transition", transitionTo(1, "no_show")).Return(ErrStatusChanged)
+
+	err := service.ReportNoShow(context.Background(), owner, 1, "nanny never came")
+
+	assert.ErrorIs(t, err, ErrStatusChanged)
+	assert.Empty(t, payments.refunded)
+}
+
+func TestGetBookingHistory_Stranger(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	mockRepo.On("GetByID", 1).Return(&models.Booking{BookingID: 1, OwnerID: 1, SitterID: 2}, nil)
+
+	_, err := service.GetBookingHistory(context.Background(), authz.Actor{UserID: 3, Role: authz.RoleOwner}, 1)
+
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+	mockRepo.AssertNotCalled(t, "GetHistory", mock.Anything)
+}
+
+func TestExpireOverdueBookings(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, availableAt{ok: true}, coveredBy{ok: true}, &fakePayments{}, &fakeChats{})
+
+	mockRepo.On("ExpireOverdue", "pending", "expired", mock.MatchedBy(func(cutoff time.Time) bool {
+		return time.Since(cutoff) > 23*time.Hour
+	})).Return(int64(2), nil)
+
+	affected, err := service.ExpireOverdueBookings(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+}
@@ -0,0 +1,84 @@
+package bookings
+
+import (
+	"fmt"
+
+	"nanny-backend/internal/common/apperr"
+)
+
+// Booking statuses. "cancelled" only exists on rows created before the
+// state machine and is terminal.
+const (
+	StatusPending           = "pending"
+	StatusConfirmed         = "confirmed"
+	StatusInProgress        = "in_progress"
+	StatusCompleted         = "completed"
+	StatusCancelledByOwner  = "cancelled_by_owner"
+	StatusCancelledBySitter = "cancelled_by_sitter"
+	StatusExpired           = "expired"
+	StatusNoShow            = "no_show"
+)
+
+type Action string
+
+const (
+	ActionConfirm  Action = "confirm"
+	ActionStart    Action = "start"
+	ActionComplete Action = "complete"
+	ActionCancel   Action = "cancel"
+	ActionNoShow   Action = "report no-show for"
+	ActionExpire   Action = "expire"
+)
+
+// Party is who performs a transition. Admins act as the party whose
+// action they take over.
+type Party string
+
+const (
+	PartyOwner  Party = "owner"
+	PartySitter Party = "sitter"
+	PartySystem Party = "system"
+)
+
+var ErrInvalidTransition = apperr.Conflict("invalid_transition", "booking status does not allow this")
+
+type transition struct {
+	action Action
+	party  Party
+	from   []string
+	to     string
+}
+
+// transitions is the whole booking lifecycle:
+//
+//	pending -> confirmed -> in_progress -> completed
+//	pending, confirmed -> cancelled_by_owner | cancelled_by_sitter
+//	confirmed -> no_show
+//	pending -> expired
+var transitions = []transition{
+	{ActionConfirm, PartySitter, []string{StatusPending}, StatusConfirmed},
+	{ActionStart, PartySitter, []string{StatusConfirmed}, StatusInProgress},
+	{ActionComplete, PartySitter, []string{StatusInProgress}, StatusCompleted},
+	{ActionCancel, PartyOwner, []string{StatusPending, StatusConfirmed}, StatusCancelledByOwner},
+	{ActionCancel, PartySitter, []string{StatusPending, StatusConfirmed}, StatusCancelledBySitter},
+	{ActionNoShow, PartyOwner, []string{StatusConfirmed}, StatusNoShow},
+	{ActionNoShow, PartySitter, []string{StatusConfirmed}, StatusNoShow},
+	{ActionExpire, PartySystem, []string{StatusPending}, StatusExpired},
+}
+
+// NextStatus returns the status a booking in current moves to when party
+// performs action, or ErrInvalidTransition.
+func NextStatus(current string, action Action, party Party) (string, error) {
+	for _, t := range transitions {
+		if t.action != action || t.party != party {
+			continue
+		}
+		for _, from := range t.from {
+			if from == current {
+				return t.to, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: cannot %s a booking that is %s", ErrInvalidTransition, action, current)
+}
@@ -0,0 +1,52 @@
+package bookings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextStatus(t *testing.T) {
+	tests := []struct {
+		current string
+		action  Action
+		party   Party
+		want    string
+	}{
+		{StatusPending, ActionConfirm, PartySitter, StatusConfirmed},
+		{StatusConfirmed, ActionStart, PartySitter, StatusInProgress},
+		{StatusInProgress, ActionComplete, PartySitter, StatusCompleted},
+		{StatusPending, ActionCancel, PartyOwner, StatusCancelledByOwner},
+		{StatusConfirmed, ActionCancel, PartySitter, StatusCancelledBySitter},
+		{StatusConfirmed, ActionNoShow, PartyOwner, StatusNoShow},
+		{StatusPending, ActionExpire, PartySystem, StatusExpired},
+	}
+
+	for _, tt := range tests {
+		got, err := NextStatus(tt.current, tt.action, tt.party)
+		assert.NoError(t, err, "%s %s by %s", tt.action, tt.current, tt.party)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestNextStatus_Rejected(t *testing.T) {
+	tests := []struct {
+		current string
+		action  Action
+		party   Party
+	}{
+		{StatusPending, ActionConfirm, PartyOwner},
+		{StatusConfirmed, ActionComplete, PartySitter},
+		{StatusPending, ActionStart, PartySitter},
+		{StatusInProgress, ActionCancel, PartyOwner},
+		{StatusCompleted, ActionCancel, PartySitter},
+		{StatusPending, ActionNoShow, PartyOwner},
+		{StatusConfirmed, ActionExpire, PartySystem},
+		{"cancelled", ActionConfirm, PartySitter},
+	}
+
+	for _, tt := range tests {
+		_, err := NextStatus(tt.current, tt.action, tt.party)
+		assert.ErrorIs(t, err, ErrInvalidTransition, "%s %s by %s", tt.action, tt.current, tt.party)
+	}
+}
@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"sync"
+
+	"nanny-backend/internal/common/models"
+)
+
+// subscriberBuffer is how many messages a slow stream may lag behind
+// before it is dropped; the client reconnects with Last-Event-ID and
+// catches up from the database.
+const subscriberBuffer = 32
+
+// Broker fans new messages out to the streams open on this instance.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[int]map[chan models.Message]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: map[int]map[chan models.Message]struct{}{}}
+}
+
+// Subscribe returns a channel of messages posted to chatID. The channel is
+// closed by unsubscribe, or by the broker if the reader falls behind.
+func (b *Broker) Subscribe(chatID int) (<-chan models.Message, func()) {
+	ch := make(chan models.Message, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[chatID] == nil {
+		b.subs[chatID] = map[chan models.Message]struct{}{}
+	}
+	b.subs[chatID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() { b.remove(chatID, ch) }
+}
+
+func (b *Broker) Publish(message models.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[message.ChatID] {
+		select {
+		case ch <- message:
+		default:
+			b.removeLocked(message.ChatID, ch)
+		}
+	}
+}
+
+func (b *Broker) remove(chatID int, ch chan models.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(chatID, ch)
+}
+
+func (b *Broker) removeLocked(chatID int, ch chan models.Message) {
+	if _, ok := b.subs[chatID][ch]; !ok {
+		return
+	}
+	delete(b.subs[chatID], ch)
+	if len(b.subs[chatID]) == 0 {
+		delete(b.subs, chatID)
+	}
+	close(ch)
+}
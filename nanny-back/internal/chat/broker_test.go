@@ -0,0 +1,37 @@
+package chat
+
+import (
+	"testing"
+
+	"nanny-backend/internal/common/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker_OnlySameChat(t *testing.T) {
+	broker := NewBroker()
+	mine, closeMine := broker.Subscribe(1)
+	defer closeMine()
+	other, closeOther := broker.Subscribe(2)
+	defer closeOther()
+
+	broker.Publish(models.Message{MessageID: 1, ChatID: 1})
+
+	assert.Len(t, mine, 1)
+	assert.Len(t, other, 0)
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+	ch, unsubscribe := broker.Subscribe(1)
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish(models.Message{MessageID: i + 1, ChatID: 1})
+	}
+
+	for range ch {
+	}
+	// Unsubscribing after the broker dropped us must not panic.
+	unsubscribe()
+	assert.Empty(t, broker.subs)
+}
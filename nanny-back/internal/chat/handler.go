@@ -0,0 +1,190 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
+	"nanny-backend/internal/common/models"
+)
+
+// heartbeatInterval keeps idle streams alive through proxies.
+var heartbeatInterval = 25 * time.Second
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+type PostMessageRequest struct {
+	Content string `json:"content" validate:"required"`
+}
+
+func (h *Handler) GetChat(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	chat, err := h.service.GetChat(r.Context(), middleware.ActorFromContext(r.Context()), bookingID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, chat)
+}
+
+func (h *Handler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	before, err := queryInt(r, "before")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.ListMessages(r.Context(), middleware.ActorFromContext(r.Context()), bookingID, before, limit)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) PostMessage(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	var req PostMessageRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	message, err := h.service.PostMessage(r.Context(), middleware.ActorFromContext(r.Context()), bookingID, req.Content)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusCreated, message)
+}
+
+// Stream pushes new messages as Server-Sent Events. Each event carries the
+// message id, so a reconnecting EventSource resumes via Last-Event-ID.
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	lastEventID, err := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		lastEventID = 0
+	}
+
+	sub, err := h.service.Subscribe(r.Context(), middleware.ActorFromContext(r.Context()), bookingID, lastEventID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	// The server-wide WriteTimeout would cut the stream off.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sent := lastEventID
+	send := func(message models.Message) error {
+		if message.MessageID <= sent {
+			return nil
+		}
+		sent = message.MessageID
+		return writeEvent(w, message)
+	}
+
+	for _, message := range sub.Backlog {
+		if err := send(message); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case message, open := <-sub.Messages:
+			if !open {
+				return
+			}
+			if err := send(message); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, message models.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: message\ndata: %s\n\n", message.MessageID, data)
+	return err
+}
+
+func queryInt(r *http.Request, key string) (int, error) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, apperr.Validation("invalid_"+key, key+" must be a non-negative number")
+	}
+	return value, nil
+}
@@ -0,0 +1,131 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/middleware"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(handler *Handler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/bookings/{id}/chat/messages", handler.ListMessages).Methods("GET")
+	router.HandleFunc("/api/bookings/{id}/chat/messages", handler.PostMessage).Methods("POST")
+	router.HandleFunc("/api/bookings/{id}/chat/stream", handler.Stream).Methods("GET")
+	return router
+}
+
+func TestHandler_PostAndListMessages(t *testing.T) {
+	router := newTestRouter(NewHandler(NewService(newFakeRepository(), NewBroker())))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/bookings/1/chat/messages", bytes.NewBufferString(`{"content":"hello"}`))
+	req = middleware.WithActor(req, ownerActor)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	req = middleware.WithActor(httptest.NewRequest(http.MethodGet, "/api/bookings/1/chat/messages?limit=10", nil), sitterActor)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var page MessagePage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Messages, 1)
+	assert.Equal(t, "hello", page.Messages[0].Content)
+}
+
+func TestHandler_PostMessage_Forbidden(t *testing.T) {
+	router := newTestRouter(NewHandler(NewService(newFakeRepository(), NewBroker())))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/bookings/1/chat/messages", bytes.NewBufferString(`{"content":"hello"}`))
+	req = middleware.WithActor(req, otherActor)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestHandler_ListMessages_UnknownBooking(t *testing.T) {
+	router := newTestRouter(NewHandler(NewService(newFakeRepository(), NewBroker())))
+
+	req := middleware.WithActor(httptest.NewRequest(http.MethodGet, "/api/bookings/99/chat/messages", nil), ownerActor)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandler_ListMessages_BadCursor(t *testing.T) {
+	router := newTestRouter(NewHandler(NewService(newFakeRepository(), NewBroker())))
+
+	req := middleware.WithActor(httptest.NewRequest(http.MethodGet, "/api/bookings/1/chat/messages?before=abc", nil), ownerActor)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandler_Stream(t *testing.T) {
+	repo := newFakeRepository()
+	broker := NewBroker()
+	svc := NewService(repo, broker)
+	router := newTestRouter(NewHandler(svc))
+
+	_, err := svc.PostMessage(context.Background(), ownerActor, 1, "before reconnect")
+	require.NoError(t, err)
+	_, err = svc.PostMessage(context.Background(), ownerActor, 1, "missed")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/api/bookings/1/chat/stream", nil).WithContext(ctx)
+	req = middleware.WithActor(req, sitterActor)
+	req.Header.Set("Last-Event-ID", "1")
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return len(broker.subs) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = svc.PostMessage(context.Background(), ownerActor, 1, "live")
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := rec.Body.String()
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.NotContains(t, body, "before reconnect")
+	assert.Contains(t, body, "id: 2\nevent: message\n")
+	assert.Contains(t, body, `"content":"missed"`)
+	assert.Contains(t, body, "id: 3\nevent: message\n")
+	assert.Contains(t, body, `"content":"live"`)
+}
+
+func TestHandler_Stream_Forbidden(t *testing.T) {
+	router := newTestRouter(NewHandler(NewService(newFakeRepository(), NewBroker())))
+
+	req := middleware.WithActor(httptest.NewRequest(http.MethodGet, "/api/bookings/1/chat/stream", nil), otherActor)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
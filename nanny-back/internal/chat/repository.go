@@ -0,0 +1,132 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/models"
+)
+
+var ErrBookingNotFound = apperr.NotFound("booking_not_found", "booking not found")
+
+type Repository interface {
+	EnsureChat(ctx context.Context, bookingID int) (*models.Chat, error)
+	GetParticipants(ctx context.Context, bookingID int) (ownerID, sitterID int, err error)
+	CreateMessage(ctx context.Context, message *models.Message) error
+	// ListBefore returns up to limit messages with message_id < beforeID,
+	// newest first. beforeID 0 means from the latest message.
+	ListBefore(ctx context.Context, chatID, beforeID, limit int) ([]models.Message, error)
+	// ListAfter returns up to limit messages with message_id > afterID,
+	// oldest first.
+	ListAfter(ctx context.Context, chatID, afterID, limit int) ([]models.Message, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) EnsureChat(ctx context.Context, bookingID int) (*models.Chat, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	chat := &models.Chat{}
+	// DO UPDATE instead of DO NOTHING so RETURNING also yields the
+	// existing row.
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO chats (booking_id)
+		VALUES ($1)
+		ON CONFLICT (booking_id) DO UPDATE SET booking_id = EXCLUDED.booking_id
+		RETURNING chat_id, booking_id, created_at
+	`, bookingID).Scan(&chat.ChatID, &chat.BookingID, &chat.CreatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not open chat: %w", err)
+	}
+
+	return chat, nil
+}
+
+func (r *repository) GetParticipants(ctx context.Context, bookingID int) (int, int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var ownerID, sitterID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT owner_id, sitter_id FROM bookings WHERE booking_id = $1
+	`, bookingID).Scan(&ownerID, &sitterID)
+
+	if err == sql.ErrNoRows {
+		return 0, 0, ErrBookingNotFound
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("error getting booking: %w", err)
+	}
+
+	return ownerID, sitterID, nil
+}
+
+func (r *repository) CreateMessage(ctx context.Context, message *models.Message) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO messages (chat_id, sender_id, content)
+		VALUES ($1, $2, $3)
+		RETURNING message_id, sent_at
+	`, message.ChatID, message.SenderID, message.Content).Scan(&message.MessageID, &message.SentAt)
+
+	if err != nil {
+		return fmt.Errorf("could not send message: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) ListBefore(ctx context.Context, chatID, beforeID, limit int) ([]models.Message, error) {
+	return r.list(ctx, `
+		SELECT message_id, chat_id, sender_id, content, sent_at
+		FROM messages
+		WHERE chat_id = $1 AND ($2 = 0 OR message_id < $2)
+		ORDER BY message_id DESC
+		LIMIT $3
+	`, chatID, beforeID, limit)
+}
+
+func (r *repository) ListAfter(ctx context.Context, chatID, afterID, limit int) ([]models.Message, error) {
+	return r.list(ctx, `
+		SELECT message_id, chat_id, sender_id, content, sent_at
+		FROM messages
+		WHERE chat_id = $1 AND message_id > $2
+		ORDER BY message_id
+		LIMIT $3
+	`, chatID, afterID, limit)
+}
+
+func (r *repository) list(ctx context.Context, query string, args ...interface{}) ([]models.Message, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.Message{}
+	for rows.Next() {
+		var m models.Message
+		if err := rows.Scan(&m.MessageID, &m.ChatID, &m.SenderID, &m.Content, &m.SentAt); err != nil {
+			return nil, fmt.Errorf("error scanning message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
@@ -0,0 +1,89 @@
+package chat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_EnsureChat(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	now := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO chats .* ON CONFLICT \(booking_id\)`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"chat_id", "booking_id", "created_at"}).AddRow(3, 7, now))
+
+	chat, err := repo.EnsureChat(context.Background(), 7)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, chat.ChatID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetParticipants_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectQuery(`SELECT owner_id, sitter_id FROM bookings`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id", "sitter_id"}))
+
+	_, _, err = repo.GetParticipants(context.Background(), 7)
+
+	assert.ErrorIs(t, err, ErrBookingNotFound)
+}
+
+func TestRepository_CreateMessage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	now := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO messages`).
+		WithArgs(3, 10, "hello").
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "sent_at"}).AddRow(5, now))
+
+	message := &models.Message{ChatID: 3, SenderID: 10, Content: "hello"}
+	err = repo.CreateMessage(context.Background(), message)
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, message.MessageID)
+	assert.Equal(t, now, message.SentAt)
+}
+
+func TestRepository_ListBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	now := time.Now()
+
+	mock.ExpectQuery(`ORDER BY message_id DESC`).
+		WithArgs(3, 10, 51).
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "chat_id", "sender_id", "content", "sent_at"}).
+			AddRow(9, 3, 10, "b", now).
+			AddRow(8, 3, 20, "a", now))
+
+	messages, err := repo.ListBefore(context.Background(), 3, 10, 51)
+
+	require.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,160 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/models"
+)
+
+const (
+	DefaultPageSize  = 50
+	MaxPageSize      = 100
+	MaxMessageLength = 2000
+)
+
+var (
+	ErrEmptyMessage   = apperr.Validation("empty_message", "message cannot be empty")
+	ErrMessageTooLong = apperr.Validation("message_too_long", "message is too long")
+)
+
+// MessagePage is one page of a chat, newest message first. NextCursor is
+// passed back as ?before= to get older messages; 0 means no more pages.
+type MessagePage struct {
+	Messages   []models.Message `json:"messages"`
+	NextCursor int              `json:"next_cursor,omitempty"`
+}
+
+// Subscription is an open live stream. Backlog holds the messages missed
+// since the client's Last-Event-ID; Messages delivers new ones until
+// Close is called or the stream falls behind (the channel is closed).
+type Subscription struct {
+	Backlog  []models.Message
+	Messages <-chan models.Message
+	Close    func()
+}
+
+type Service interface {
+	CreateChat(ctx context.Context, bookingID int) error
+	GetChat(ctx context.Context, actor authz.Actor, bookingID int) (*models.Chat, error)
+	ListMessages(ctx context.Context, actor authz.Actor, bookingID, before, limit int) (*MessagePage, error)
+	PostMessage(ctx context.Context, actor authz.Actor, bookingID int, content string) (*models.Message, error)
+	Subscribe(ctx context.Context, actor authz.Actor, bookingID, lastEventID int) (*Subscription, error)
+}
+
+type service struct {
+	repo   Repository
+	broker *Broker
+}
+
+func NewService(repo Repository, broker *Broker) Service {
+	return &service{repo: repo, broker: broker}
+}
+
+func (s *service) CreateChat(ctx context.Context, bookingID int) error {
+	_, err := s.repo.EnsureChat(ctx, bookingID)
+	return err
+}
+
+func (s *service) GetChat(ctx context.Context, actor authz.Actor, bookingID int) (*models.Chat, error) {
+	return s.openChat(ctx, actor, bookingID)
+}
+
+func (s *service) ListMessages(ctx context.Context, actor authz.Actor, bookingID, before, limit int) (*MessagePage, error) {
+	chat, err := s.openChat(ctx, actor, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	// One extra row tells us whether there is another page.
+	messages, err := s.repo.ListBefore(ctx, chat.ChatID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		page.NextCursor = page.Messages[limit-1].MessageID
+	}
+
+	return page, nil
+}
+
+func (s *service) PostMessage(ctx context.Context, actor authz.Actor, bookingID int, content string) (*models.Message, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, ErrEmptyMessage
+	}
+	if utf8.RuneCountInString(content) > MaxMessageLength {
+		return nil, fmt.Errorf("%w: max %d characters", ErrMessageTooLong, MaxMessageLength)
+	}
+
+	chat, err := s.openChat(ctx, actor, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &models.Message{
+		ChatID:   chat.ChatID,
+		SenderID: actor.UserID,
+		Content:  content,
+	}
+
+	if err := s.repo.CreateMessage(ctx, message); err != nil {
+		return nil, err
+	}
+
+	s.broker.Publish(*message)
+
+	return message, nil
+}
+
+func (s *service) Subscribe(ctx context.Context, actor authz.Actor, bookingID, lastEventID int) (*Subscription, error) {
+	chat, err := s.openChat(ctx, actor, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe before reading the backlog so nothing posted in between is
+	// lost; the stream skips anything it already sent from the backlog.
+	messages, unsubscribe := s.broker.Subscribe(chat.ChatID)
+
+	sub := &Subscription{Messages: messages, Close: unsubscribe}
+	if lastEventID > 0 {
+		sub.Backlog, err = s.repo.ListAfter(ctx, chat.ChatID, lastEventID, MaxPageSize)
+		if err != nil {
+			unsubscribe()
+			return nil, err
+		}
+	}
+
+	return sub, nil
+}
+
+// openChat checks that actor is the booking's owner or sitter and returns
+// its chat, creating it for bookings made before chats were automatic.
+// Admins are deliberately not let in: the chat is private to the two.
+func (s *service) openChat(ctx context.Context, actor authz.Actor, bookingID int) (*models.Chat, error) {
+	ownerID, sitterID, err := s.repo.GetParticipants(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor.UserID <= 0 || (actor.UserID != ownerID && actor.UserID != sitterID) {
+		return nil, fmt.Errorf("chat belongs to another booking: %w", authz.ErrForbidden)
+	}
+
+	return s.repo.EnsureChat(ctx, bookingID)
+}
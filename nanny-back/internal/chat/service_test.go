@@ -0,0 +1,205 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type booking struct {
+	ownerID, sitterID int
+}
+
+type fakeRepository struct {
+	mu       sync.Mutex
+	bookings map[int]booking
+	chats    map[int]*models.Chat
+	messages []models.Message
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		bookings: map[int]booking{1: {ownerID: 10, sitterID: 20}},
+		chats:    map[int]*models.Chat{},
+	}
+}
+
+func (f *fakeRepository) EnsureChat(ctx context.Context, bookingID int) (*models.Chat, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if chat, ok := f.chats[bookingID]; ok {
+		return chat, nil
+	}
+	chat := &models.Chat{ChatID: len(f.chats) + 100, BookingID: bookingID, CreatedAt: time.Now()}
+	f.chats[bookingID] = chat
+	return chat, nil
+}
+
+func (f *fakeRepository) GetParticipants(ctx context.Context, bookingID int) (int, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, ok := f.bookings[bookingID]
+	if !ok {
+		return 0, 0, ErrBookingNotFound
+	}
+	return b.ownerID, b.sitterID, nil
+}
+
+func (f *fakeRepository) CreateMessage(ctx context.Context, message *models.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	message.MessageID = len(f.messages) + 1
+	message.SentAt = time.Now()
+	f.messages = append(f.messages, *message)
+	return nil
+}
+
+func (f *fakeRepository) ListBefore(ctx context.Context, chatID, beforeID, limit int) ([]models.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := []models.Message{}
+	for i := len(f.messages) - 1; i >= 0 && len(out) < limit; i-- {
+		m := f.messages[i]
+		if m.ChatID == chatID && (beforeID == 0 || m.MessageID < beforeID) {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) ListAfter(ctx context.Context, chatID, afterID, limit int) ([]models.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := []models.Message{}
+	for _, m := range f.messages {
+		if len(out) < limit && m.ChatID == chatID && m.MessageID > afterID {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+var (
+	ownerActor  = authz.Actor{UserID: 10, Role: authz.RoleOwner}
+	sitterActor = authz.Actor{UserID: 20, Role: authz.RoleSitter}
+	otherActor  = authz.Actor{UserID: 30, Role: authz.RoleOwner}
+	adminActor  = authz.Actor{UserID: 1, Role: authz.RoleAdmin}
+)
+
+func TestPostMessage_Participants(t *testing.T) {
+	svc := NewService(newFakeRepository(), NewBroker())
+
+	message, err := svc.PostMessage(context.Background(), ownerActor, 1, "  Hi, is Rex ok?  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Hi, is Rex ok?", message.Content)
+	assert.Equal(t, 10, message.SenderID)
+
+	_, err = svc.PostMessage(context.Background(), sitterActor, 1, "All good")
+	assert.NoError(t, err)
+}
+
+func TestPostMessage_Outsiders(t *testing.T) {
+	svc := NewService(newFakeRepository(), NewBroker())
+
+	_, err := svc.PostMessage(context.Background(), otherActor, 1, "hello")
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+
+	_, err = svc.PostMessage(context.Background(), adminActor, 1, "hello")
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+
+	_, err = svc.PostMessage(context.Background(), ownerActor, 99, "hello")
+	assert.ErrorIs(t, err, ErrBookingNotFound)
+}
+
+func TestPostMessage_Validation(t *testing.T) {
+	svc := NewService(newFakeRepository(), NewBroker())
+
+	_, err := svc.PostMessage(context.Background(), ownerActor, 1, "   ")
+	assert.ErrorIs(t, err, ErrEmptyMessage)
+
+	_, err = svc.PostMessage(context.Background(), ownerActor, 1, strings.Repeat("я", MaxMessageLength+1))
+	assert.ErrorIs(t, err, ErrMessageTooLong)
+}
+
+func TestListMessages_CursorPagination(t *testing.T) {
+	svc := NewService(newFakeRepository(), NewBroker())
+	for i := 0; i < 5; i++ {
+		_, err := svc.PostMessage(context.Background(), ownerActor, 1, "msg")
+		require.NoError(t, err)
+	}
+
+	page, err := svc.ListMessages(context.Background(), sitterActor, 1, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 4}, ids(page.Messages))
+	assert.Equal(t, 4, page.NextCursor)
+
+	page, err = svc.ListMessages(context.Background(), sitterActor, 1, page.NextCursor, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 2}, ids(page.Messages))
+
+	page, err = svc.ListMessages(context.Background(), sitterActor, 1, page.NextCursor, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, ids(page.Messages))
+	assert.Zero(t, page.NextCursor)
+}
+
+func TestSubscribe_ReceivesNewMessages(t *testing.T) {
+	svc := NewService(newFakeRepository(), NewBroker())
+
+	sub, err := svc.Subscribe(context.Background(), sitterActor, 1, 0)
+	require.NoError(t, err)
+	defer sub.Close()
+	assert.Empty(t, sub.Backlog)
+
+	_, err = svc.PostMessage(context.Background(), ownerActor, 1, "live")
+	require.NoError(t, err)
+
+	select {
+	case m := <-sub.Messages:
+		assert.Equal(t, "live", m.Content)
+	case <-time.After(time.Second):
+		t.Fatal("message was not pushed")
+	}
+}
+
+func TestSubscribe_Backlog(t *testing.T) {
+	svc := NewService(newFakeRepository(), NewBroker())
+	for _, text := range []string{"one", "two", "three"} {
+		_, err := svc.PostMessage(context.Background(), ownerActor, 1, text)
+		require.NoError(t, err)
+	}
+
+	sub, err := svc.Subscribe(context.Background(), sitterActor, 1, 1)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	assert.Equal(t, []int{2, 3}, ids(sub.Backlog))
+}
+
+func TestSubscribe_Outsider(t *testing.T) {
+	svc := NewService(newFakeRepository(), NewBroker())
+
+	_, err := svc.Subscribe(context.Background(), otherActor, 1, 0)
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+}
+
+func ids(messages []models.Message) []int {
+	out := []int{}
+	for _, m := range messages {
+		out = append(out, m.MessageID)
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+// Package apperr holds the typed errors services return for conditions a
+// client caused and can act on. Anything else is an internal error and is
+// never shown to the client.
+package apperr
+
+import "strings"
+
+// Kind is the class of an error; httpx maps it to a status code.
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindForbidden    Kind = "forbidden"
+	KindUnauthorized Kind = "unauthorized"
+	KindRateLimited  Kind = "rate_limited"
+)
+
+// FieldError is one invalid request field. Field is the JSON name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a client-facing error. Code is a stable machine-readable
+// identifier ("booking_not_found"); Message is safe to show to users.
+// Package-level *Error values serve as sentinels for errors.Is, wrapped
+// with fmt.Errorf("...: %w", ...) for context.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Message)
+	}
+	return e.Message + ": " + strings.Join(messages, "; ")
+}
+
+func Validation(code, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+// InvalidFields is a validation error listing each broken field.
+func InvalidFields(fields []FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: "validation_failed", Message: "request is invalid", Fields: fields}
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func RateLimited(code, message string) *Error {
+	return &Error{Kind: KindRateLimited, Code: code, Message: message}
+}
@@ -0,0 +1,25 @@
+package authz
+
+import "nanny-backend/internal/common/apperr"
+
+var ErrForbidden = apperr.Forbidden("forbidden", "access denied")
+
+// Actor is the authenticated user performing a call, taken from the JWT
+// by the HTTP layer and passed down to services.
+type Actor struct {
+	UserID int
+	Role   string
+}
+
+func (a Actor) IsAdmin() bool {
+	return a.Role == RoleAdmin
+}
+
+// CanActAs reports whether the actor may touch a resource belonging to
+// userID. Admins may act on any resource.
+func (a Actor) CanActAs(userID int) bool {
+	if a.UserID <= 0 {
+		return false
+	}
+	return a.UserID == userID || a.IsAdmin()
+}
@@ -0,0 +1,17 @@
+package authz
+
+const (
+	RoleOwner  = "owner"
+	RoleSitter = "sitter"
+	RoleAdmin  = "admin"
+)
+
+// HasRole reports whether role is one of the allowed roles.
+func HasRole(role string, allowed ...string) bool {
+	for _, a := range allowed {
+		if role == a {
+			return true
+		}
+	}
+	return false
+}
@@ -10,6 +10,26 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// DefaultQueryTimeout is used until SetQueryTimeout is called.
+const DefaultQueryTimeout = 5 * time.Second
+
+var queryTimeout = DefaultQueryTimeout
+
+// SetQueryTimeout changes the limit WithTimeout puts on each query.
+// Called once at startup.
+func SetQueryTimeout(d time.Duration) {
+	if d > 0 {
+		queryTimeout = d
+	}
+}
+
+// WithTimeout derives the context for one query from the caller's
+// context, so a query stops when the request is cancelled or after the
+// query timeout, whichever comes first.
+func WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, queryTimeout)
+}
+
 type Database struct {
 	DB *sql.DB
 }
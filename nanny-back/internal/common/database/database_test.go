@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
@@ -66,3 +68,34 @@ func TestDatabaseStruct(t *testing.T) {
 		t.Error("expected DB to be set")
 	}
 }
+
+func TestWithTimeout(t *testing.T) {
+	SetQueryTimeout(50 * time.Millisecond)
+	defer SetQueryTimeout(DefaultQueryTimeout)
+
+	ctx, cancel := WithTimeout(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > 50*time.Millisecond {
+		t.Errorf("expected a deadline within 50ms, got %v", deadline)
+	}
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel = WithTimeout(parent)
+	defer cancel()
+
+	cancelParent()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("expected the query context to follow its parent, got %v", ctx.Err())
+	}
+}
+
+func TestSetQueryTimeout_IgnoresNonPositive(t *testing.T) {
+	defer SetQueryTimeout(DefaultQueryTimeout)
+
+	SetQueryTimeout(0)
+	if queryTimeout != DefaultQueryTimeout {
+		t.Errorf("expected %v, got %v", DefaultQueryTimeout, queryTimeout)
+	}
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestWithTx_Commit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO a`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO b`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = WithTx(context.Background(), db, func(ctx context.Context) error {
+		if _, err := Conn(ctx, db).ExecContext(ctx, `INSERT INTO a VALUES (1)`); err != nil {
+			return err
+		}
+		// A nested unit of work joins the outer transaction.
+		return WithTx(ctx, db, func(ctx context.Context) error {
+			_, err := Conn(ctx, db).ExecContext(ctx, `INSERT INTO b VALUES (1)`)
+			return err
+		})
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestWithTx_RollbackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	failure := errors.New("second insert failed")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO a`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectRollback()
+
+	err = WithTx(context.Background(), db, func(ctx context.Context) error {
+		if _, err := Conn(ctx, db).ExecContext(ctx, `INSERT INTO a VALUES (1)`); err != nil {
+			return err
+		}
+		return failure
+	})
+
+	if !errors.Is(err, failure) {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConn_WithoutTx(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	if Conn(context.Background(), db) != db {
+		t.Error("expected the database itself outside a transaction")
+	}
+}
@@ -0,0 +1,66 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"nanny-backend/pkg/config"
+)
+
+// EarthRadiusKm is the mean Earth radius used for distances.
+const EarthRadiusKm = 6371.0
+
+var ErrNotFound = errors.New("address could not be located")
+
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Address is what gets geocoded: a street line (optional) and a city.
+type Address struct {
+	Line string
+	City string
+}
+
+// Geocoder turns an address into coordinates. Implementations must be
+// safe for concurrent use.
+type Geocoder interface {
+	Geocode(ctx context.Context, address Address) (Point, error)
+}
+
+// FromConfig picks the implementation by GEOCODER. Only "static" (the
+// default) is built in: it works offline from a built-in list of cities
+// plus the optional GEOCODER_FILE.
+func FromConfig(cfg config.GeoConfig) (Geocoder, error) {
+	switch cfg.Geocoder {
+	case "static", "":
+		places := map[string]Point{}
+		if cfg.PlacesFile != "" {
+			loaded, err := LoadPlaces(cfg.PlacesFile)
+			if err != nil {
+				return nil, err
+			}
+			places = loaded
+		}
+		return NewStaticGeocoder(places), nil
+	default:
+		return nil, fmt.Errorf("unknown geocoder %q", cfg.Geocoder)
+	}
+}
+
+// DistanceKm is the great-circle (haversine) distance between a and b.
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLng := radians(b.Longitude - a.Longitude)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,49 @@
+package geo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistanceKm(t *testing.T) {
+	almaty := Point{Latitude: 43.2389, Longitude: 76.8897}
+	astana := Point{Latitude: 51.1605, Longitude: 71.4704}
+
+	assert.InDelta(t, 970, DistanceKm(almaty, astana), 10)
+	assert.Zero(t, DistanceKm(almaty, almaty))
+}
+
+func TestStaticGeocoder_ExactAddressThenCity(t *testing.T) {
+	g := NewStaticGeocoder(map[string]Point{"Abay Ave 10, Almaty": {Latitude: 43.24, Longitude: 76.95}})
+
+	point, err := g.Geocode(context.Background(), Address{Line: " abay  ave 10", City: "ALMATY"})
+	require.NoError(t, err)
+	assert.Equal(t, Point{Latitude: 43.24, Longitude: 76.95}, point)
+
+	point, err = g.Geocode(context.Background(), Address{Line: "Unknown St 1", City: "Almaty"})
+	require.NoError(t, err)
+	assert.Equal(t, cities["almaty"], point)
+}
+
+func TestStaticGeocoder_NotFound(t *testing.T) {
+	_, err := NewStaticGeocoder(nil).Geocode(context.Background(), Address{City: "Atlantis"})
+
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLoadPlaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "places.csv")
+	content := "# query,latitude,longitude\n\"Dostyk 5, Astana\",51.12,71.43\nTemirtau,50.05,72.96\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	places, err := LoadPlaces(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, Point{Latitude: 50.05, Longitude: 72.96}, places["Temirtau"])
+	assert.Len(t, places, 2)
+}
@@ -0,0 +1,114 @@
+package geo
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cities are the built-in city centres used when nothing more precise
+// is known.
+var cities = map[string]Point{
+	"almaty":      {43.2389, 76.8897},
+	"astana":      {51.1605, 71.4704},
+	"shymkent":    {42.3417, 69.5901},
+	"karaganda":   {49.8047, 73.1094},
+	"aktobe":      {50.2839, 57.1670},
+	"taraz":       {42.9000, 71.3667},
+	"pavlodar":    {52.2873, 76.9674},
+	"oskemen":     {49.9483, 82.6279},
+	"semey":       {50.4111, 80.2275},
+	"atyrau":      {47.1164, 51.8830},
+	"kostanay":    {53.2144, 63.6246},
+	"kyzylorda":   {44.8488, 65.4823},
+	"oral":        {51.2333, 51.3667},
+	"petropavl":   {54.8753, 69.1628},
+	"aktau":       {43.6500, 51.1500},
+	"turkistan":   {43.2973, 68.2518},
+	"taldykorgan": {45.0156, 78.3739},
+	"kokshetau":   {53.2833, 69.3833},
+}
+
+// StaticGeocoder answers from an in-memory table and never touches the
+// network. It first looks up "line, city" and then falls back to the
+// city centre.
+type StaticGeocoder struct {
+	places map[string]Point
+}
+
+// NewStaticGeocoder adds places (keyed by "line, city" or a city name)
+// on top of the built-in cities.
+func NewStaticGeocoder(places map[string]Point) *StaticGeocoder {
+	all := make(map[string]Point, len(cities)+len(places))
+	for name, point := range cities {
+		all[name] = point
+	}
+	for name, point := range places {
+		all[normalize(name)] = point
+	}
+
+	return &StaticGeocoder{places: all}
+}
+
+func (g *StaticGeocoder) Geocode(ctx context.Context, address Address) (Point, error) {
+	if address.Line != "" {
+		if point, ok := g.places[normalize(address.Line+", "+address.City)]; ok {
+			return point, nil
+		}
+	}
+
+	if point, ok := g.places[normalize(address.City)]; ok {
+		return point, nil
+	}
+
+	return Point{}, fmt.Errorf("%w: %s", ErrNotFound, address.City)
+}
+
+// LoadPlaces reads a CSV file of "query,latitude,longitude" rows, where
+// query is a city or "street, city" (quote it when it has commas).
+func LoadPlaces(path string) (map[string]Point, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening places file: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = 3
+	reader.Comment = '#'
+
+	places := map[string]Point{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading places file: %w", err)
+		}
+
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		lng, lngErr := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if latErr != nil || lngErr != nil {
+			return nil, fmt.Errorf("places file: bad coordinates for %q", record[0])
+		}
+
+		places[record[0]] = Point{Latitude: lat, Longitude: lng}
+	}
+
+	return places, nil
+}
+
+// normalize makes lookups ignore case, spacing and punctuation around
+// commas: "Abay Ave 10 ,  ALMATY" and "abay ave 10, almaty" match.
+func normalize(s string) string {
+	parts := strings.Split(strings.ToLower(s), ",")
+	for i, part := range parts {
+		parts[i] = strings.Join(strings.Fields(part), " ")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,124 @@
+// Package httpx writes JSON responses and turns errors into RFC 7807
+// problem details, so every handler reports errors the same way.
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/pkg/validator"
+
+	"github.com/gorilla/mux"
+)
+
+// ProblemTypePrefix is prepended to the error code to form the problem
+// type URI.
+const ProblemTypePrefix = "urn:nanny:problem:"
+
+const internalCode = "internal_error"
+
+var ErrMalformedBody = apperr.Validation("malformed_body", "request body is not valid JSON")
+
+// Problem is an RFC 7807 problem details body. Code repeats the last
+// segment of Type for clients that prefer a plain identifier.
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     string              `json:"code"`
+	Errors   []apperr.FieldError `json:"errors,omitempty"`
+}
+
+var statusByKind = map[apperr.Kind]int{
+	apperr.KindValidation:   http.StatusBadRequest,
+	apperr.KindNotFound:     http.StatusNotFound,
+	apperr.KindConflict:     http.StatusConflict,
+	apperr.KindForbidden:    http.StatusForbidden,
+	apperr.KindUnauthorized: http.StatusUnauthorized,
+	apperr.KindRateLimited:  http.StatusTooManyRequests,
+}
+
+func JSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+// Error writes err as a problem. Typed errors (apperr, validator) are
+// shown to the client with their message; anything else is logged and
+// answered with a bare 500 so internals such as SQL errors never leak.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	WriteProblem(w, ProblemFor(r, err))
+}
+
+// ProblemFor builds the problem err is reported as.
+func ProblemFor(r *http.Request, err error) Problem {
+	var fieldErrs validator.Errors
+	if errors.As(err, &fieldErrs) {
+		fields := make([]apperr.FieldError, 0, len(fieldErrs))
+		for _, f := range fieldErrs {
+			fields = append(fields, apperr.FieldError{Field: f.Field, Message: f.Message})
+		}
+		err = apperr.InvalidFields(fields)
+	}
+
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) {
+		log.Printf("❌ %s %s: %v", r.Method, r.URL.Path, err)
+		return newProblem(r, http.StatusInternalServerError, internalCode, "")
+	}
+
+	status, ok := statusByKind[appErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	detail := err.Error()
+	if len(appErr.Fields) > 0 {
+		detail = appErr.Message
+	}
+
+	problem := newProblem(r, status, appErr.Code, detail)
+	problem.Errors = appErr.Fields
+	return problem
+}
+
+func WriteProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// Decode reads the JSON body into dst and runs its validate tags.
+func Decode(r *http.Request, dst interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		return ErrMalformedBody
+	}
+	return validator.Validate(dst)
+}
+
+// PathID reads the positive integer path variable name.
+func PathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil || id <= 0 {
+		return 0, apperr.Validation("invalid_id", name+" must be a positive number")
+	}
+	return id, nil
+}
+
+func newProblem(r *http.Request, status int, code, detail string) Problem {
+	return Problem{
+		Type:     ProblemTypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+	}
+}
@@ -0,0 +1,129 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nanny-backend/internal/common/apperr"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+var errThingNotFound = apperr.NotFound("thing_not_found", "thing not found")
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) Problem {
+	t.Helper()
+
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	return problem
+}
+
+func TestError_MapsKindToStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{apperr.Validation("bad", "bad"), http.StatusBadRequest},
+		{apperr.Unauthorized("who", "who"), http.StatusUnauthorized},
+		{apperr.Forbidden("no", "no"), http.StatusForbidden},
+		{errThingNotFound, http.StatusNotFound},
+		{apperr.Conflict("taken", "taken"), http.StatusConflict},
+		{apperr.RateLimited("slow", "slow"), http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		Error(rec, httptest.NewRequest(http.MethodGet, "/things/1", nil), tt.err)
+
+		assert.Equal(t, tt.status, rec.Code, tt.err.Error())
+		assert.Equal(t, tt.status, decodeProblem(t, rec).Status)
+	}
+}
+
+func TestError_WrappedDomainError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/things/7", nil)
+
+	Error(rec, req, fmt.Errorf("loading thing 7: %w", errThingNotFound))
+
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "urn:nanny:problem:thing_not_found", problem.Type)
+	assert.Equal(t, "thing_not_found", problem.Code)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, "loading thing 7: thing not found", problem.Detail)
+	assert.Equal(t, "/things/7", problem.Instance)
+}
+
+func TestError_InternalErrorIsHidden(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/things", nil)
+
+	Error(rec, req, errors.New(`pq: relation "things" does not exist`))
+
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.Empty(t, problem.Detail)
+	assert.NotContains(t, rec.Body.String(), "relation")
+}
+
+type thingRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Count int    `json:"count" validate:"gte=1"`
+}
+
+func TestDecode_ValidationFields(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/things", bytes.NewBufferString(`{"count": 0}`))
+
+	var body thingRequest
+	err := Decode(req, &body)
+
+	rec := httptest.NewRecorder()
+	Error(rec, req, err)
+
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "validation_failed", problem.Code)
+	if assert.Len(t, problem.Errors, 2) {
+		assert.Equal(t, "name", problem.Errors[0].Field)
+		assert.Equal(t, "count", problem.Errors[1].Field)
+	}
+}
+
+func TestDecode_MalformedBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/things", bytes.NewBufferString(`{bad json`))
+
+	var body thingRequest
+	err := Decode(req, &body)
+
+	assert.ErrorIs(t, err, ErrMalformedBody)
+}
+
+func TestPathID(t *testing.T) {
+	for _, value := range []string{"abc", "0", "-3"} {
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"id": value})
+
+		_, err := PathID(req, "id")
+
+		var appErr *apperr.Error
+		if assert.ErrorAs(t, err, &appErr, value) {
+			assert.Equal(t, "invalid_id", appErr.Code)
+		}
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"id": "42"})
+	id, err := PathID(req, "id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, id)
+}
@@ -0,0 +1,294 @@
+// Package listing implements limit/cursor pagination, sorting and field
+// filters for list endpoints. Each list declares a Spec with the fields
+// it can be sorted and filtered by; handlers parse the query string with
+// Spec.Parse, repositories add the resulting clauses with Query.Apply and
+// wrap the rows with Spec.Page.
+//
+// Query string:
+//
+//	limit=20           page size (default 20, max 100)
+//	sort=-created_at   field name, "-" for descending
+//	cursor=...         next_cursor of the previous page, same sort
+//	status=a,b         text/int filters, comma separated values
+//	start_time_from=   time filters take _from (inclusive) and _to
+//	start_time_to=     (exclusive), RFC 3339 or YYYY-MM-DD
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidQuery = apperr.Validation("invalid_query", "invalid list query")
+
+type FilterType int
+
+const (
+	Text FilterType = iota
+	Int
+	Time
+)
+
+// Filter is a field that can be filtered on.
+type Filter struct {
+	Column string
+	Type   FilterType
+}
+
+// Sort is a field that can be sorted on. Column must not be NULL; Value
+// reads the same value from a row so the next cursor can be built.
+type Sort[T any] struct {
+	Column string
+	Value  func(T) interface{}
+}
+
+// Spec describes one list. IDColumn and ID are the unique tie-breaker
+// appended to every sort.
+type Spec[T any] struct {
+	IDColumn    string
+	ID          func(T) int
+	Sorts       map[string]Sort[T]
+	DefaultSort string
+	Filters     map[string]Filter
+}
+
+// Page is the response envelope of every list endpoint. NextCursor is
+// empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type condition struct {
+	column string
+	op     string
+	value  interface{}
+}
+
+// Query is a parsed and validated list request.
+type Query struct {
+	Limit int
+	// Sort is the field name with an optional "-" prefix.
+	Sort string
+
+	sortColumn string
+	idColumn   string
+	desc       bool
+	after      *cursor
+	conditions []condition
+}
+
+type cursor struct {
+	Sort  string      `json:"s"`
+	Value interface{} `json:"v"`
+	ID    int         `json:"id"`
+}
+
+// Parse reads limit, sort, cursor and the spec's filters from values.
+// Other parameters are ignored. Errors wrap ErrInvalidQuery.
+func (s Spec[T]) Parse(values url.Values) (Query, error) {
+	q := Query{Limit: DefaultLimit, Sort: s.DefaultSort, idColumn: s.IDColumn}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return Query{}, fmt.Errorf("%w: limit must be a positive number", ErrInvalidQuery)
+		}
+		q.Limit = min(n, MaxLimit)
+	}
+
+	if sortParam := values.Get("sort"); sortParam != "" {
+		q.Sort = sortParam
+	}
+
+	q.desc = strings.HasPrefix(q.Sort, "-")
+	field, ok := s.Sorts[strings.TrimPrefix(q.Sort, "-")]
+	if !ok {
+		return Query{}, fmt.Errorf("%w: sort must be one of %s", ErrInvalidQuery, strings.Join(s.sortNames(), ", "))
+	}
+	q.sortColumn = field.Column
+
+	if raw := values.Get("cursor"); raw != "" {
+		c, err := decodeCursor(raw)
+		if err != nil {
+			return Query{}, err
+		}
+		if c.Sort != q.Sort {
+			return Query{}, fmt.Errorf("%w: cursor belongs to another sort order", ErrInvalidQuery)
+		}
+		q.after = c
+	}
+
+	for name, filter := range s.Filters {
+		conditions, err := filter.parse(name, values)
+		if err != nil {
+			return Query{}, err
+		}
+		q.conditions = append(q.conditions, conditions...)
+	}
+
+	// Map order is random; keep placeholders stable for logs and tests.
+	sort.Slice(q.conditions, func(i, j int) bool {
+		if q.conditions[i].column != q.conditions[j].column {
+			return q.conditions[i].column < q.conditions[j].column
+		}
+		return q.conditions[i].op < q.conditions[j].op
+	})
+
+	return q, nil
+}
+
+// Apply appends the filters, cursor, ORDER BY and LIMIT to sqlQuery,
+// which must end with a WHERE clause ("WHERE TRUE" if there is nothing to
+// filter). One extra row is fetched to tell whether there is a next page.
+func (q Query) Apply(sqlQuery string, args []interface{}) (string, []interface{}) {
+	var b strings.Builder
+	b.WriteString(sqlQuery)
+
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	for _, c := range q.conditions {
+		if c.op == "IN" {
+			fmt.Fprintf(&b, " AND %s = ANY(%s)", c.column, arg(c.value))
+		} else {
+			fmt.Fprintf(&b, " AND %s %s %s", c.column, c.op, arg(c.value))
+		}
+	}
+
+	direction, comparison := "ASC", ">"
+	if q.desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if q.after != nil {
+		fmt.Fprintf(&b, " AND (%s, %s) %s (%s, %s)", q.sortColumn, q.idColumn, comparison, arg(q.after.Value), arg(q.after.ID))
+	}
+
+	fmt.Fprintf(&b, " ORDER BY %s %s, %s %s LIMIT %s", q.sortColumn, direction, q.idColumn, direction, arg(q.Limit+1))
+
+	return b.String(), args
+}
+
+// Page trims the extra row fetched by Apply and builds the next cursor.
+func (s Spec[T]) Page(items []T, q Query) *Page[T] {
+	page := &Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(items) > q.Limit {
+		page.Items = items[:q.Limit]
+		last := page.Items[q.Limit-1]
+		field := s.Sorts[strings.TrimPrefix(q.Sort, "-")]
+		page.NextCursor = encodeCursor(cursor{Sort: q.Sort, Value: field.Value(last), ID: s.ID(last)})
+	}
+
+	return page
+}
+
+func (s Spec[T]) sortNames() []string {
+	names := make([]string, 0, len(s.Sorts))
+	for name := range s.Sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f Filter) parse(name string, values url.Values) ([]condition, error) {
+	if f.Type == Time {
+		var conditions []condition
+		for suffix, op := range map[string]string{"_from": ">=", "_to": "<"} {
+			raw := values.Get(name + suffix)
+			if raw == "" {
+				continue
+			}
+			t, err := parseTime(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s%s must be RFC 3339 or YYYY-MM-DD", ErrInvalidQuery, name, suffix)
+			}
+			conditions = append(conditions, condition{column: f.Column, op: op, value: t})
+		}
+		return conditions, nil
+	}
+
+	raw := values.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	if f.Type == Int {
+		ids := make([]int64, 0, len(parts))
+		for _, part := range parts {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidQuery, name)
+			}
+			ids = append(ids, id)
+		}
+		return []condition{{column: f.Column, op: "IN", value: pq.Array(ids)}}, nil
+	}
+
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return []condition{{column: f.Column, op: "IN", value: pq.Array(parts)}}, nil
+}
+
+func parseTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+func encodeCursor(c cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor keeps numbers as their decimal text: lib/pq sends every
+// parameter as text, so Postgres reads it as the column's type.
+func decodeCursor(raw string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	var c cursor
+	if err := decoder.Decode(&c); err != nil || c.ID <= 0 || c.Value == nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	switch v := c.Value.(type) {
+	case json.Number:
+		c.Value = v.String()
+	case string:
+	default:
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	return &c, nil
+}
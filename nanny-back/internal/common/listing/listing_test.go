@@ -0,0 +1,118 @@
+package listing
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	ID        int
+	CreatedAt time.Time
+	Price     float64
+}
+
+var testSpec = Spec[item]{
+	IDColumn: "id",
+	ID:       func(i item) int { return i.ID },
+	Sorts: map[string]Sort[item]{
+		"created_at": {Column: "created_at", Value: func(i item) interface{} { return i.CreatedAt }},
+		"price":      {Column: "price", Value: func(i item) interface{} { return i.Price }},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]Filter{
+		"status":     {Column: "status", Type: Text},
+		"pet_id":     {Column: "pet_id", Type: Int},
+		"created_at": {Column: "created_at", Type: Time},
+	},
+}
+
+func TestParse_Defaults(t *testing.T) {
+	q, err := testSpec.Parse(nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultLimit, q.Limit)
+	assert.Equal(t, "-created_at", q.Sort)
+
+	query, args := q.Apply("SELECT id FROM items WHERE owner_id = $1", []interface{}{5})
+
+	assert.Equal(t, "SELECT id FROM items WHERE owner_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2", query)
+	assert.Equal(t, []interface{}{5, DefaultLimit + 1}, args)
+}
+
+func TestParse_FiltersAndLimit(t *testing.T) {
+	values := url.Values{
+		"limit":           {"500"},
+		"sort":            {"price"},
+		"status":          {"pending, confirmed"},
+		"pet_id":          {"3"},
+		"created_at_from": {"2026-10-01"},
+	}
+
+	q, err := testSpec.Parse(values)
+	assert.NoError(t, err)
+	assert.Equal(t, MaxLimit, q.Limit)
+
+	query, args := q.Apply("SELECT id FROM items WHERE TRUE", nil)
+
+	assert.Equal(t, "SELECT id FROM items WHERE TRUE AND created_at >= $1 AND pet_id = ANY($2) AND status = ANY($3)"+
+		" ORDER BY price ASC, id ASC LIMIT $4", query)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), args[0])
+	assert.Equal(t, pq.Array([]int64{3}), args[1])
+	assert.Equal(t, pq.Array([]string{"pending", "confirmed"}), args[2])
+	assert.Equal(t, MaxLimit+1, args[3])
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for name, values := range map[string]url.Values{
+		"limit":  {"limit": {"0"}},
+		"sort":   {"sort": {"password"}},
+		"cursor": {"cursor": {"not-a-cursor"}},
+		"int":    {"pet_id": {"cat"}},
+		"time":   {"created_at_to": {"yesterday"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := testSpec.Parse(values)
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+		})
+	}
+}
+
+func TestPage_CursorRoundTrip(t *testing.T) {
+	q, err := testSpec.Parse(url.Values{"limit": {"2"}, "sort": {"price"}})
+	assert.NoError(t, err)
+
+	page := testSpec.Page([]item{{ID: 1, Price: 10}, {ID: 2, Price: 12.5}, {ID: 3, Price: 15}}, q)
+
+	assert.Len(t, page.Items, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	next, err := testSpec.Parse(url.Values{"limit": {"2"}, "sort": {"price"}, "cursor": {page.NextCursor}})
+	assert.NoError(t, err)
+
+	query, args := next.Apply("SELECT id FROM items WHERE TRUE", nil)
+
+	assert.Equal(t, "SELECT id FROM items WHERE TRUE AND (price, id) > ($1, $2) ORDER BY price ASC, id ASC LIMIT $3", query)
+	assert.Equal(t, []interface{}{"12.5", 2, 3}, args)
+}
+
+func TestPage_CursorOfAnotherSort(t *testing.T) {
+	q, _ := testSpec.Parse(url.Values{"limit": {"1"}})
+	page := testSpec.Page([]item{{ID: 1}, {ID: 2}}, q)
+
+	_, err := testSpec.Parse(url.Values{"sort": {"price"}, "cursor": {page.NextCursor}})
+
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestPage_LastPage(t *testing.T) {
+	q, _ := testSpec.Parse(nil)
+
+	page := testSpec.Page(nil, q)
+
+	assert.NotNil(t, page.Items)
+	assert.Empty(t, page.NextCursor)
+}
@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"nanny-backend/pkg/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends plain-text email. Implementations must be safe for
+// concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// FromConfig picks the implementation by MAIL_DRIVER: "smtp", "file"
+// (append to MAIL_FILE) or "log" (default, for local development).
+func FromConfig(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.From), nil
+	case "file":
+		return NewFileMailer(cfg.File, cfg.From), nil
+	case "log", "":
+		return NewLogMailer(cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nanny-backend/pkg/config"
+)
+
+func TestFileMailer_AppendsMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := NewFileMailer(path, "no-reply@nanny.local")
+
+	for _, to := range []string{"a@test.com", "b@test.com"} {
+		if err := m.Send(context.Background(), Message{To: to, Subject: "Hi", Body: "link"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, "To: a@test.com") || !strings.Contains(content, "To: b@test.com") {
+		t.Errorf("expected both messages in file, got %q", content)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	tests := []struct {
+		driver  string
+		wantErr bool
+	}{
+		{"log", false},
+		{"file", false},
+		{"smtp", false},
+		{"pigeon", true},
+	}
+
+	for _, tt := range tests {
+		_, err := FromConfig(config.MailConfig{Driver: tt.driver})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("driver %q: expected error %v, got %v", tt.driver, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/token"
+)
+
+var ErrImpersonationForbidden = apperr.Forbidden("impersonation_forbidden", "not allowed while impersonating a user")
+
+// impersonationMethods is everything an impersonation token may do: look.
+// Any other request, whatever the route, is refused, so a new write route
+// is closed to impersonation without anyone having to remember it.
+var impersonationMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+}
+
+// ImpersonatorFromContext returns the admin behind an impersonation
+// token; ok is false for the user's own tokens.
+func ImpersonatorFromContext(ctx context.Context) (int, bool) {
+	adminID, ok := ctx.Value(ImpersonatorKey).(int)
+	return adminID, ok && adminID > 0
+}
+
+// GuardImpersonation refuses requests made with an impersonation token
+// that do more than read. Register it with Router.Use: it also covers
+// routes without AuthMiddleware, such as /api/auth/logout/all. Requests
+// whose token does not verify are left to the route.
+func GuardImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if impersonationMethods[r.Method] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tokenString, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		v := verifier()
+		if !ok || v == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := v.Verify(tokenString)
+		if err != nil || claims.Act == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		trackImpersonation(r.Context(), claims)
+		httpx.Error(w, r, ErrImpersonationForbidden)
+	})
+}
+
+// trackImpersonation fills in the Identity TrackIdentity put into ctx, so
+// refused requests are still recorded against the impersonated user.
+func trackImpersonation(ctx context.Context, claims *token.Claims) {
+	if identity, ok := ctx.Value(identityKey).(*Identity); ok {
+		identity.UserID = claims.UserID
+		identity.Role = claims.Role
+		identity.ImpersonatorID = claims.Act.UserID
+	}
+}
@@ -2,22 +2,137 @@ package middleware
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
+	"sync"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/token"
 	"nanny-backend/pkg/config"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type contextKey string
 
+var (
+	ErrMissingToken   = apperr.Unauthorized("missing_token", "missing or invalid Authorization header")
+	ErrInvalidToken   = apperr.Unauthorized("invalid_token", "invalid token")
+	ErrSessionRevoked = apperr.Unauthorized("session_revoked", "session revoked")
+)
+
 const (
-	UserIDKey   contextKey = "user_id"
-	UserRoleKey contextKey = "user_role"
+	UserIDKey    contextKey = "user_id"
+	UserRoleKey  contextKey = "user_role"
+	SessionIDKey contextKey = "session_id"
+	// ImpersonatorKey holds the admin's user ID on impersonated requests.
+	ImpersonatorKey contextKey = "impersonator_id"
+
+	identityKey contextKey = "identity"
 )
 
+// Identity is who AuthMiddleware let through. Middleware running before
+// it, such as the audit log, never sees the context AuthMiddleware
+// builds, so it puts an empty Identity in with TrackIdentity and reads it
+// once the handler returns.
+type Identity struct {
+	UserID         int
+	Role           string
+	ImpersonatorID int
+}
+
+// TrackIdentity returns a context in which AuthMiddleware fills in the
+// returned Identity.
+func TrackIdentity(ctx context.Context) (context.Context, *Identity) {
+	identity := &Identity{}
+	return context.WithValue(ctx, identityKey, identity), identity
+}
+
+// SessionChecker tells AuthMiddleware whether the session a token was
+// issued for is still alive, so logout and revocation take effect before
+// the access token expires.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// AccountChecker tells AuthMiddleware whether the user may still use the
+// API; a non-nil error (e.g. the account is suspended) is sent as is.
+type AccountChecker interface {
+	CheckAccount(ctx context.Context, userID int) error
+}
+
+// TokenVerifier checks an access token and returns its claims.
+type TokenVerifier interface {
+	Verify(tokenString string) (*token.Claims, error)
+}
+
+var (
+	sessionChecker SessionChecker
+	accountChecker AccountChecker
+
+	tokenVerifier       TokenVerifier
+	defaultVerifierOnce sync.Once
+)
+
+// SetSessionChecker must be called once at startup, before serving.
+func SetSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
+
+// SetAccountChecker must be called once at startup, before serving.
+func SetAccountChecker(checker AccountChecker) {
+	accountChecker = checker
+}
+
+// SetTokenVerifier must be called once at startup, before serving, with
+// the same token manager the auth module issues tokens with.
+func SetTokenVerifier(verifier TokenVerifier) {
+	tokenVerifier = verifier
+}
+
+// verifier falls back to a manager built from the environment, once, so
+// AuthMiddleware keeps working in setups that never call SetTokenVerifier.
+func verifier() TokenVerifier {
+	defaultVerifierOnce.Do(func() {
+		if tokenVerifier != nil {
+			return
+		}
+		manager, err := token.FromConfig(config.Load())
+		if err == nil {
+			tokenVerifier = manager
+		}
+	})
+	return tokenVerifier
+}
+
+// QueryToken lets clients that cannot set headers (browser EventSource)
+// pass the access token as ?access_token=. Only wrap streaming routes with
+// it: query strings end up in access logs.
+func QueryToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			if accessToken := r.URL.Query().Get("access_token"); accessToken != "" {
+				r.Header.Set("Authorization", "Bearer "+accessToken)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// OptionalAuth is for public routes that do more for logged-in users: a
+// request without a token goes through anonymously, one with a token is
+// checked like AuthMiddleware.
+func OptionalAuth(next http.Handler) http.Handler {
+	authenticated := AuthMiddleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -33,41 +148,59 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		authHeader := r.Header.Get("Authorization")
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			http.Error(w, "missing or invalid Authorization header", http.StatusUnauthorized)
+			httpx.Error(w, r, ErrMissingToken)
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		cfg := config.Load()
-
-		token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-			}
-			return []byte(cfg.JWTSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			http.Error(w, "invalid token", http.StatusUnauthorized)
+		v := verifier()
+		if v == nil {
+			httpx.Error(w, r, errors.New("authentication is not configured"))
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			http.Error(w, "invalid token claims", http.StatusUnauthorized)
+		claims, err := v.Verify(tokenString)
+		if err != nil {
+			httpx.Error(w, r, ErrInvalidToken)
 			return
 		}
 
-		var userID int
-		if v, ok := claims["user_id"].(float64); ok {
-			userID = int(v)
+		if sessionChecker != nil {
+			active, err := sessionChecker.IsSessionActive(r.Context(), claims.SessionID)
+			if err != nil || !active {
+				httpx.Error(w, r, ErrSessionRevoked)
+				return
+			}
 		}
 
-		role, _ := claims["role"].(string)
+		if accountChecker != nil {
+			if err := accountChecker.CheckAccount(r.Context(), claims.UserID); err != nil {
+				httpx.Error(w, r, err)
+				return
+			}
+		}
 
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
-		ctx = context.WithValue(ctx, UserRoleKey, role)
+		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+		ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+
+		if identity, ok := ctx.Value(identityKey).(*Identity); ok {
+			identity.UserID = claims.UserID
+			identity.Role = claims.Role
+		}
+
+		if claims.Act != nil {
+			ctx = context.WithValue(ctx, ImpersonatorKey, claims.Act.UserID)
+			trackImpersonation(ctx, claims)
+
+			// GuardImpersonation normally refuses these first; this holds
+			// for routers that do not use it.
+			if !impersonationMethods[r.Method] {
+				httpx.Error(w, r, ErrImpersonationForbidden)
+				return
+			}
+		}
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -1,11 +1,15 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/token"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -52,6 +56,9 @@ func TestAuthMiddleware_MissingToken(t *testing.T) {
 	if rr.Code != http.StatusUnauthorized {
 		t.Errorf("expected status 401, got %d", rr.Code)
 	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected a problem+json body, got %q", ct)
+	}
 }
 
 func TestAuthMiddleware_InvalidToken(t *testing.T) {
@@ -98,6 +105,206 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 
 }
 
+type stubSessionChecker map[string]bool
+
+func (s stubSessionChecker) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	return s[sessionID], nil
+}
+
+func TestAuthMiddleware_RevokedSession(t *testing.T) {
+	tokens := token.NewManager(token.NewHMACKey("test", []byte("test_jwt_secret_key_12345")), time.Hour, "nanny-backend")
+	SetTokenVerifier(tokens)
+	SetSessionChecker(stubSessionChecker{"live": true})
+	defer SetSessionChecker(nil)
+
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		sid      string
+		expected int
+	}{
+		{"live", http.StatusOK},
+		{"revoked", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		tokenString, _ := tokens.Issue(token.Claims{UserID: 1, Role: "owner", SessionID: tt.sid})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/pets", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != tt.expected {
+			t.Errorf("sid %q: expected %d, got %d", tt.sid, tt.expected, rr.Code)
+		}
+	}
+}
+
+type stubAccountChecker map[int]error
+
+func (s stubAccountChecker) CheckAccount(ctx context.Context, userID int) error {
+	return s[userID]
+}
+
+func TestAuthMiddleware_RestrictedAccount(t *testing.T) {
+	tokens := token.NewManager(token.NewHMACKey("test", []byte("test_jwt_secret_key_12345")), time.Hour, "nanny-backend")
+	SetTokenVerifier(tokens)
+	SetAccountChecker(stubAccountChecker{2: apperr.Forbidden("account_suspended", "account suspended")})
+	defer SetAccountChecker(nil)
+
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		userID   int
+		expected int
+	}{
+		{1, http.StatusOK},
+		{2, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		tokenString, _ := tokens.Issue(token.Claims{UserID: tt.userID, Role: "owner"})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/pets", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != tt.expected {
+			t.Errorf("user %d: expected %d, got %d", tt.userID, tt.expected, rr.Code)
+		}
+	}
+}
+
+func TestAuthMiddleware_TrackIdentity(t *testing.T) {
+	tokens := token.NewManager(token.NewHMACKey("test", []byte("test_jwt_secret_key_12345")), time.Hour, "nanny-backend")
+	SetTokenVerifier(tokens)
+
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tokenString, _ := tokens.Issue(token.Claims{UserID: 7, Role: "sitter"})
+
+	ctx, identity := TrackIdentity(context.Background())
+	req := httptest.NewRequest(http.MethodPost, "/api/services", nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if identity.UserID != 7 || identity.Role != "sitter" {
+		t.Errorf("expected identity 7/sitter, got %d/%s", identity.UserID, identity.Role)
+	}
+}
+
+func TestAuthMiddleware_Impersonation(t *testing.T) {
+	tokens := token.NewManager(token.NewHMACKey("test", []byte("test_jwt_secret_key_12345")), time.Hour, "nanny-backend")
+	SetTokenVerifier(tokens)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	guarded := GuardImpersonation(ok)
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if adminID, ok := ImpersonatorFromContext(r.Context()); ok && adminID != 1 {
+			t.Errorf("expected impersonator 1, got %d", adminID)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	impersonation, _ := tokens.Issue(token.Claims{UserID: 7, Role: "owner", Act: &token.Act{UserID: 1}})
+	own, _ := tokens.Issue(token.Claims{UserID: 7, Role: "owner"})
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		method   string
+		path     string
+		token    string
+		expected int
+	}{
+		{"impersonated read", handler, http.MethodGet, "/api/bookings/1", impersonation, http.StatusOK},
+		{"impersonated post", handler, http.MethodPost, "/api/bookings/1/complete", impersonation, http.StatusForbidden},
+		{"impersonated put", handler, http.MethodPut, "/api/users/me", impersonation, http.StatusForbidden},
+		{"impersonated delete", handler, http.MethodDelete, "/api/pets/1", impersonation, http.StatusForbidden},
+		{"own token post", handler, http.MethodPost, "/api/bookings/1/complete", own, http.StatusOK},
+		{"own token delete", handler, http.MethodDelete, "/api/pets/1", own, http.StatusOK},
+		{"guarded impersonated logout all", guarded, http.MethodPost, "/api/auth/logout/all", impersonation, http.StatusForbidden},
+		{"guarded impersonated read", guarded, http.MethodGet, "/api/bookings/1", impersonation, http.StatusOK},
+		{"guarded own token logout all", guarded, http.MethodPost, "/api/auth/logout/all", own, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		ctx, identity := TrackIdentity(context.Background())
+		req := httptest.NewRequest(tt.method, tt.path, nil).WithContext(ctx)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		rr := httptest.NewRecorder()
+
+		tt.handler.ServeHTTP(rr, req)
+
+		if rr.Code != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.expected, rr.Code)
+		}
+		if tt.token == impersonation && tt.expected == http.StatusForbidden && identity.ImpersonatorID != 1 {
+			t.Errorf("%s: expected identity impersonator 1, got %d", tt.name, identity.ImpersonatorID)
+		}
+	}
+}
+
+func TestQueryToken(t *testing.T) {
+	tokens := token.NewManager(token.NewHMACKey("test", []byte("test_jwt_secret_key_12345")), time.Hour, "nanny-backend")
+	SetTokenVerifier(tokens)
+
+	handler := QueryToken(AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	tokenString, _ := tokens.Issue(token.Claims{UserID: 1, Role: "owner"})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/bookings/1/chat/stream?access_token="+tokenString, nil)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rr.Code)
+	}
+}
+
+func TestOptionalAuth(t *testing.T) {
+	handler := OptionalAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ActorFromContext(r.Context()).UserID != 0 {
+			t.Error("expected anonymous request")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/services/search", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("anonymous: expected status 200, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/services/search", nil)
+	req.Header.Set("Authorization", "Bearer invalid_token")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("bad token: expected status 401, got %d", rr.Code)
+	}
+}
+
 func TestRequestLogger(t *testing.T) {
 	handler := RequestLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -206,3 +413,54 @@ func TestGetLimiter(t *testing.T) {
 		t.Error("expected different limiter for different IP")
 	}
 }
+
+func TestRequireRole(t *testing.T) {
+	handler := RequireRole("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		role     string
+		expected int
+	}{
+		{"allowed role", "admin", http.StatusOK},
+		{"wrong role", "owner", http.StatusForbidden},
+		{"no role", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+			if tt.role != "" {
+				ctx := context.WithValue(req.Context(), UserIDKey, 1)
+				ctx = context.WithValue(ctx, UserRoleKey, tt.role)
+				req = req.WithContext(ctx)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rr.Code)
+			}
+		})
+	}
+}
+
+func TestRequireRole_MultipleRoles(t *testing.T) {
+	handler := RequireRole("owner", "sitter")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/bookings/1/cancel", nil)
+	ctx := context.WithValue(req.Context(), UserIDKey, 2)
+	ctx = context.WithValue(ctx, UserRoleKey, "sitter")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req.WithContext(ctx))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rr.Code)
+	}
+}
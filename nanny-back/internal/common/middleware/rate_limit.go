@@ -4,9 +4,14 @@ import (
 	"net/http"
 	"sync"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/httpx"
+
 	"golang.org/x/time/rate"
 )
 
+var ErrTooManyRequests = apperr.RateLimited("too_many_requests", "too many requests, slow down")
+
 type client struct {
 	limiter *rate.Limiter
 }
@@ -35,7 +40,7 @@ func RateLimit(next http.Handler) http.Handler {
 		limiter := getLimiter(ip)
 
 		if !limiter.Allow() {
-			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			httpx.Error(w, r, ErrTooManyRequests)
 			return
 		}
 
@@ -0,0 +1,285 @@
+// Package migrate applies the versioned SQL migrations. It keeps state in
+// golang-migrate's schema_migrations table (one row: version, dirty), so a
+// database migrated with the migrate CLI can be taken over by the binary
+// and the other way round.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// lockID is the pg_advisory_lock key; several API replicas starting at
+// once apply migrations one at a time.
+const lockID int64 = 7_264_117_050
+
+var (
+	ErrDirty          = errors.New("database is dirty: a migration failed half-way, fix it by hand and run `migrate force VERSION`")
+	ErrNoChange       = errors.New("no migrations to apply")
+	ErrUnknownVersion = errors.New("database version is not among the known migrations")
+	ErrInvalidStep    = errors.New("steps must be positive")
+)
+
+// Status describes where the database stands.
+type Status struct {
+	Current uint64
+	Dirty   bool
+	Latest  uint64
+	Pending []Migration
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New loads migrations from fsys (usually migrations.FS).
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations and returns how many ran.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= current {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, migration.Version, migration.Up, migration.Version); err != nil {
+				return err
+			}
+			applied++
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the last steps migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if steps <= 0 {
+		return 0, ErrInvalidStep
+	}
+
+	reverted := 0
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for reverted < steps && current > 0 {
+			index := m.indexOf(current)
+			if index < 0 {
+				return fmt.Errorf("%w: %d", ErrUnknownVersion, current)
+			}
+
+			var previous uint64
+			if index > 0 {
+				previous = m.migrations[index-1].Version
+			}
+
+			if err := m.apply(ctx, conn, current, m.migrations[index].Down, previous); err != nil {
+				return err
+			}
+
+			current = previous
+			reverted++
+		}
+
+		if reverted == 0 {
+			return ErrNoChange
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status reports the current version and the migrations not yet applied.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	current, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Current: current, Dirty: dirty}
+	for _, migration := range m.migrations {
+		status.Latest = migration.Version
+		if migration.Version > current {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+
+	return status, nil
+}
+
+// Force marks the database as being at version without running anything
+// and clears the dirty flag. Version 0 means an empty schema.
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 && m.indexOf(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		return setVersion(ctx, conn, version, false)
+	})
+}
+
+// withLock runs fn on a single connection holding the advisory lock;
+// session-level locks belong to a connection, not to the pool.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error opening connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("error taking migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// version returns the current version, refusing to go on from a dirty state.
+func (m *Migrator) version(ctx context.Context, conn *sql.Conn) (uint64, error) {
+	current, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	if dirty {
+		return 0, fmt.Errorf("%w (version %d)", ErrDirty, current)
+	}
+
+	if current != 0 && m.indexOf(current) < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownVersion, current)
+	}
+
+	return current, nil
+}
+
+// apply marks the database dirty at version, runs script in a transaction
+// and records target. A failing script leaves the dirty flag behind, the
+// same way golang-migrate does.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, version uint64, script string, target uint64) error {
+	if err := setVersion(ctx, conn, version, true); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting migration %d: %w", version, err)
+	}
+
+	// An empty script, e.g. a down that has nothing to undo, only moves
+	// the version.
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", version, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing migration %d: %w", version, err)
+	}
+
+	return setVersion(ctx, conn, target, false)
+}
+
+func (m *Migrator) indexOf(version uint64) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+
+	return nil
+}
+
+func readVersion(ctx context.Context, conn *sql.Conn) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+
+	err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("error reading schema version: %w", err)
+	}
+
+	return uint64(version), dirty, nil
+}
+
+func setVersion(ctx context.Context, conn *sql.Conn, version uint64, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error updating schema version: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("error updating schema version: %w", err)
+	}
+
+	if version > 0 || dirty {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, int64(version), dirty); err != nil {
+			return fmt.Errorf("error updating schema version: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error updating schema version: %w", err)
+	}
+
+	return nil
+}
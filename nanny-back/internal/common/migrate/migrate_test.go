@@ -0,0 +1,222 @@
+package migrate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"nanny-backend/migrations"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"000002_add_pets.up.sql":    {Data: []byte("CREATE TABLE pets (id INT);")},
+		"000002_add_pets.down.sql":  {Data: []byte("DROP TABLE pets;")},
+		"000001_add_users.up.sql":   {Data: []byte("CREATE TABLE users (id INT);")},
+		"000001_add_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"migrations.go":             {Data: []byte("package migrations")},
+	}
+}
+
+func expectLocked(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectVersion(mock sqlmock.Sqlmock, version int64, dirty bool) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 1))
+	if version > 0 || dirty {
+		mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(version, dirty).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func TestLoad_SortsAndPairsFiles(t *testing.T) {
+	list, err := Load(testFS())
+
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, uint64(1), list[0].Version)
+	assert.Equal(t, "add_users", list[0].Name)
+	assert.Equal(t, "DROP TABLE pets;", list[1].Down)
+}
+
+func TestLoad_MissingDown(t *testing.T) {
+	fsys := testFS()
+	delete(fsys, "000002_add_pets.down.sql")
+
+	_, err := Load(fsys)
+
+	assert.ErrorContains(t, err, "needs both an up and a down file")
+}
+
+func TestLoad_EmptyFiles(t *testing.T) {
+	fsys := testFS()
+	fsys["000003_add_reviews.up.sql"] = &fstest.MapFile{}
+	fsys["000003_add_reviews.down.sql"] = &fstest.MapFile{}
+
+	list, err := Load(fsys)
+
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Empty(t, list[2].Up)
+}
+
+func TestLoad_BadName(t *testing.T) {
+	fsys := testFS()
+	fsys["init.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+
+	_, err := Load(fsys)
+
+	assert.Error(t, err)
+}
+
+func TestEmbeddedMigrations_AreContiguous(t *testing.T) {
+	list, err := Load(migrations.FS)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, list)
+	for i, migration := range list {
+		assert.Equal(t, uint64(i+1), migration.Version, migration.Name)
+	}
+}
+
+func TestUp_AppliesPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := New(db, testFS())
+	require.NoError(t, err)
+
+	expectLocked(mock)
+	mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
+	expectVersion(mock, 2, true)
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE pets`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	expectVersion(mock, 2, false)
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := m.Up(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUp_FailedMigrationStaysDirty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := New(db, testFS())
+	require.NoError(t, err)
+
+	expectLocked(mock)
+	mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}))
+	expectVersion(mock, 1, true)
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE users`).WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := m.Up(context.Background())
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 0, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUp_RefusesDirtyDatabase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := New(db, testFS())
+	require.NoError(t, err)
+
+	expectLocked(mock)
+	mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(2, true))
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = m.Up(context.Background())
+
+	assert.ErrorIs(t, err, ErrDirty)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDown_RevertsToPreviousVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := New(db, testFS())
+	require.NoError(t, err)
+
+	expectLocked(mock)
+	mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(2, false))
+	expectVersion(mock, 2, true)
+	mock.ExpectBegin()
+	mock.ExpectExec(`DROP TABLE pets`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	expectVersion(mock, 1, false)
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	reverted, err := m.Down(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatus_ListsPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := New(db, testFS())
+	require.NoError(t, err)
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
+
+	status, err := m.Status(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), status.Current)
+	assert.Equal(t, uint64(2), status.Latest)
+	require.Len(t, status.Pending, 1)
+	assert.Equal(t, "add_pets", status.Pending[0].Name)
+}
+
+func TestCreate_NextVersion(t *testing.T) {
+	dir := t.TempDir()
+	for name, file := range testFS() {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), file.Data, 0o644))
+	}
+
+	up, down, err := Create(dir, "Add Reviews")
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "000003_add_reviews.up.sql"), up)
+	assert.FileExists(t, down)
+
+	// The empty pair just created must not block the next one.
+	up, _, err = Create(dir, "add_payments")
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "000004_add_payments.up.sql"), up)
+}
@@ -3,13 +3,19 @@ package models
 import "time"
 
 type User struct {
-	UserID       int       `json:"user_id"`
-	FullName     string    `json:"full_name"`
-	Email        string    `json:"email"`
-	Phone        string    `json:"phone"`
-	PasswordHash string    `json:"-"`
-	Role         string    `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
+	UserID          int        `json:"user_id"`
+	FullName        string     `json:"full_name"`
+	Email           string     `json:"email"`
+	Phone           string     `json:"phone"`
+	PasswordHash    string     `json:"-"`
+	Role            string     `json:"role"`
+	CreatedAt       time.Time  `json:"created_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	SuspendedUntil    *time.Time `json:"suspended_until,omitempty"`
+	BannedAt          *time.Time `json:"banned_at,omitempty"`
+	RestrictionReason string     `json:"restriction_reason,omitempty"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Pet struct {
@@ -31,11 +37,12 @@ type Sitter struct {
 }
 
 type Service struct {
-	ServiceID    int     `json:"service_id"`
-	SitterID     int     `json:"sitter_id"`
-	Type         string  `json:"type"`
-	PricePerHour float64 `json:"price_per_hour"`
-	Description  string  `json:"description,omitempty"`
+	ServiceID    int      `json:"service_id"`
+	SitterID     int      `json:"sitter_id"`
+	Type         string   `json:"type"`
+	PricePerHour float64  `json:"price_per_hour"`
+	Description  string   `json:"description,omitempty"`
+	PetTypes     []string `json:"pet_types"`
 }
 
 type Booking struct {
@@ -49,23 +56,55 @@ type Booking struct {
 	Status    string    `json:"status"`
 }
 
+// BookingEvent is one status change of a booking. FromStatus is empty
+// for the creation event; ActorID is nil when the system made the change.
+type BookingEvent struct {
+	EventID    int       `json:"event_id"`
+	BookingID  int       `json:"booking_id"`
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	ActorID    *int      `json:"actor_id,omitempty"`
+	ActorRole  string    `json:"actor_role"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type Payment struct {
-	PaymentID int       `json:"payment_id"`
-	BookingID int       `json:"booking_id"`
-	Amount    float64   `json:"amount"`
-	Method    string    `json:"method"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	PaymentID   int        `json:"payment_id"`
+	BookingID   int        `json:"booking_id"`
+	OwnerID     int        `json:"owner_id,omitempty"`
+	SitterID    int        `json:"sitter_id,omitempty"`
+	Amount      float64    `json:"amount"`
+	Method      string     `json:"method"`
+	Status      string     `json:"status"`
+	ProviderRef string     `json:"provider_ref,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RefundedAt  *time.Time `json:"refunded_at,omitempty"`
 }
 
+// Review is an owner's review of a completed booking. Verified reviews
+// come from a booking that was paid for. Reply is the sitter's answer;
+// Hidden reviews were taken down by an admin.
 type Review struct {
-	ReviewID  int       `json:"review_id"`
-	BookingID int       `json:"booking_id"`
-	OwnerID   int       `json:"owner_id"`
-	SitterID  int       `json:"sitter_id"`
-	Rating    int       `json:"rating"`
-	Comment   string    `json:"comment"`
-	CreatedAt time.Time `json:"created_at"`
+	ReviewID  int `json:"review_id"`
+	BookingID int `json:"booking_id"`
+	OwnerID   int `json:"owner_id"`
+	SitterID  int `json:"sitter_id"`
+	Rating    int `json:"rating"`
+	ReviewScores
+	Comment   string     `json:"comment"`
+	Verified  bool       `json:"verified"`
+	CreatedAt time.Time  `json:"created_at"`
+	Reply     string     `json:"reply,omitempty"`
+	RepliedAt *time.Time `json:"replied_at,omitempty"`
+	Hidden    bool       `json:"hidden,omitempty"`
+}
+
+// ReviewScores rate parts of the stay from 1 to 5. Each one is optional.
+type ReviewScores struct {
+	Punctuality   *int `json:"punctuality,omitempty"`
+	Communication *int `json:"communication,omitempty"`
+	PetCare       *int `json:"pet_care,omitempty"`
 }
 
 type Message struct {
@@ -81,3 +120,61 @@ type Chat struct {
 	BookingID int       `json:"booking_id"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+type Session struct {
+	SessionID         string     `json:"session_id"`
+	UserID            int        `json:"user_id"`
+	RefreshTokenHash  string     `json:"-"`
+	PreviousTokenHash string     `json:"-"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	LastUsedAt        time.Time  `json:"last_used_at"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+}
+
+// AvailabilitySlot is a weekly recurring working window in the sitter's
+// time zone. Times are "HH:MM"; EndTime may be "24:00".
+type AvailabilitySlot struct {
+	SlotID    int    `json:"slot_id"`
+	SitterID  int    `json:"sitter_id"`
+	Weekday   int    `json:"weekday"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// Blackout is a run of whole days off, both dates inclusive ("YYYY-MM-DD").
+type Blackout struct {
+	BlackoutID int    `json:"blackout_id"`
+	SitterID   int    `json:"sitter_id"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// Address is a street line and city with the coordinates they were
+// geocoded to. Coordinates are nil for sitters who never set an address.
+type Address struct {
+	Line      string   `json:"line,omitempty"`
+	City      string   `json:"city"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// SitterLocation is where a sitter works from and how far they travel.
+type SitterLocation struct {
+	SitterID int `json:"sitter_id"`
+	Address
+	ServiceRadiusKm float64 `json:"service_radius_km"`
+}
+
+// OwnerLocation is the owner's home, where the pets are looked after.
+type OwnerLocation struct {
+	OwnerID int `json:"owner_id"`
+	Address
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
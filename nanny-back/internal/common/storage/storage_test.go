@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskStore_PutOpenDelete(t *testing.T) {
+	store := NewDiskStore(t.TempDir())
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "sitters/7/cert.pdf", strings.NewReader("%PDF-1.4")))
+
+	f, err := store.Open(ctx, "sitters/7/cert.pdf")
+	require.NoError(t, err)
+	content, err := io.ReadAll(f)
+	f.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4", string(content))
+
+	require.NoError(t, store.Delete(ctx, "sitters/7/cert.pdf"))
+	_, err = store.Open(ctx, "sitters/7/cert.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDiskStore_RejectsKeysOutsideDir(t *testing.T) {
+	store := NewDiskStore(t.TempDir())
+
+	for _, key := range []string{"", "../secret", "/etc/passwd", "a/../../b"} {
+		err := store.Put(context.Background(), key, strings.NewReader("x"))
+		assert.Error(t, err, key)
+	}
+}
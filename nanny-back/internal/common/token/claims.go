@@ -0,0 +1,19 @@
+package token
+
+import "github.com/golang-jwt/jwt/v5"
+
+// Claims is the payload of every access token we issue.
+type Claims struct {
+	UserID    int    `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
+	// Act is set on impersonation tokens: UserID is the user being looked
+	// at, Act the admin doing the looking (RFC 8693 "act").
+	Act *Act `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Act names the admin an impersonation token was issued to.
+type Act struct {
+	UserID int `json:"user_id"`
+}
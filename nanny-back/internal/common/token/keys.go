@@ -0,0 +1,92 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is one signing or verification key, identified by the kid header.
+// SignKey is nil for keys that are only kept around to verify tokens
+// issued before a rotation.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	SignKey   interface{}
+	VerifyKey interface{}
+}
+
+func NewHMACKey(id string, secret []byte) Key {
+	return Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		SignKey:   secret,
+		VerifyKey: secret,
+	}
+}
+
+// ParsePrivateKeyPEM accepts an RSA (PKCS#1 or PKCS#8) or Ed25519 (PKCS#8)
+// private key and picks RS256 or EdDSA accordingly.
+func ParsePrivateKeyPEM(id string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM block found in private key")
+	}
+
+	var parsed interface{}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if rsaErr != nil {
+			return Key{}, fmt.Errorf("error parsing private key: %w", err)
+		}
+		parsed = rsaKey
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return Key{ID: id, Method: jwt.SigningMethodRS256, SignKey: k, VerifyKey: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return Key{ID: id, Method: jwt.SigningMethodEdDSA, SignKey: k, VerifyKey: k.Public()}, nil
+	default:
+		return Key{}, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+}
+
+// ParsePublicKeyPEM loads a verify-only key, typically the public half of
+// a key that has been rotated out.
+func ParsePublicKeyPEM(id string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM block found in public key")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return Key{}, fmt.Errorf("error parsing public key: %w", err)
+	}
+
+	return publicKey(id, parsed)
+}
+
+func publicKey(id string, pub crypto.PublicKey) (Key, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return Key{ID: id, Method: jwt.SigningMethodRS256, VerifyKey: k}, nil
+	case ed25519.PublicKey:
+		return Key{ID: id, Method: jwt.SigningMethodEdDSA, VerifyKey: k}, nil
+	default:
+		return Key{}, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+func (k Key) symmetric() bool {
+	_, ok := k.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
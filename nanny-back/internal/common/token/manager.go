@@ -0,0 +1,157 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"nanny-backend/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Manager issues and verifies access tokens. It signs with exactly one
+// key and accepts tokens signed by any key it knows, so keys can be
+// rotated by moving the old one to the verify-only set.
+type Manager struct {
+	signing Key
+	keys    map[string]Key
+	ttl     time.Duration
+	issuer  string
+}
+
+func NewManager(signing Key, ttl time.Duration, issuer string, verifyOnly ...Key) *Manager {
+	m := &Manager{
+		signing: signing,
+		keys:    map[string]Key{signing.ID: signing},
+		ttl:     ttl,
+		issuer:  issuer,
+	}
+	for _, k := range verifyOnly {
+		if _, exists := m.keys[k.ID]; !exists {
+			m.keys[k.ID] = k
+		}
+	}
+	return m
+}
+
+// FromConfig builds a Manager from the auth settings. With
+// JWT_PRIVATE_KEY_FILE set, tokens are signed with that RSA/Ed25519 key;
+// otherwise JWT_SECRET is used with HS256.
+func FromConfig(cfg *config.Config) (*Manager, error) {
+	var signing Key
+	if cfg.Auth.PrivateKeyFile != "" {
+		data, err := os.ReadFile(cfg.Auth.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading private key: %w", err)
+		}
+		signing, err = ParsePrivateKeyPEM(cfg.Auth.KeyID, data)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		signing = NewHMACKey(cfg.Auth.KeyID, []byte(cfg.JWTSecret))
+	}
+
+	previous, err := parsePreviousKeys(cfg.Auth.PreviousKeys, signing.symmetric())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewManager(signing, cfg.Auth.AccessTokenTTL, cfg.Auth.Issuer, previous...), nil
+}
+
+// parsePreviousKeys reads "kid=value,kid=value" where value is an old
+// HMAC secret or, in asymmetric mode, the path to an old public key.
+func parsePreviousKeys(spec string, symmetric bool) ([]Key, error) {
+	var keys []Key
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, value, ok := strings.Cut(entry, "=")
+		if !ok || id == "" || value == "" {
+			return nil, fmt.Errorf("invalid previous key entry %q", entry)
+		}
+
+		if symmetric {
+			keys = append(keys, NewHMACKey(id, []byte(value)))
+			continue
+		}
+
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("error reading public key %s: %w", id, err)
+		}
+		key, err := ParsePublicKeyPEM(id, data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (m *Manager) TTL() time.Duration {
+	return m.ttl
+}
+
+// Issue signs claims with the current key, filling in issuer, issued-at
+// and expiry.
+func (m *Manager) Issue(claims Claims) (string, error) {
+	return m.IssueFor(claims, m.ttl)
+}
+
+// IssueFor is Issue with a token lifetime other than the configured one.
+func (m *Manager) IssueFor(claims Claims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.Issuer = m.issuer
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	t := jwt.NewWithClaims(m.signing.Method, claims)
+	t.Header["kid"] = m.signing.ID
+
+	signed, err := t.SignedString(m.signing.SignKey)
+	if err != nil {
+		return "", fmt.Errorf("error signing token: %w", err)
+	}
+	return signed, nil
+}
+
+func (m *Manager) Verify(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	t, err := jwt.ParseWithClaims(tokenString, claims, m.keyFor,
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || !t.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (m *Manager) keyFor(t *jwt.Token) (interface{}, error) {
+	key := m.signing
+	if kid, ok := t.Header["kid"].(string); ok {
+		known, exists := m.keys[kid]
+		if !exists {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		key = known
+	}
+
+	// Never let the token pick the algorithm, otherwise a public key
+	// could be used as an HMAC secret.
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+	}
+	return key.VerifyKey, nil
+}
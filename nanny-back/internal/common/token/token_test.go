@@ -0,0 +1,129 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueAndVerify_HMAC(t *testing.T) {
+	m := NewManager(NewHMACKey("v1", []byte("secret")), time.Minute, "nanny-backend")
+
+	signed, err := m.Issue(Claims{UserID: 7, Role: "sitter", SessionID: "sid"})
+	require.NoError(t, err)
+
+	claims, err := m.Verify(signed)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, claims.UserID)
+	assert.Equal(t, "sitter", claims.Role)
+	assert.Equal(t, "sid", claims.SessionID)
+	assert.Equal(t, "nanny-backend", claims.Issuer)
+}
+
+func TestIssueFor_Impersonation(t *testing.T) {
+	m := NewManager(NewHMACKey("v1", []byte("secret")), time.Hour, "nanny-backend")
+
+	signed, err := m.IssueFor(Claims{UserID: 7, Role: "owner", Act: &Act{UserID: 1}}, 5*time.Minute)
+	require.NoError(t, err)
+
+	claims, err := m.Verify(signed)
+
+	require.NoError(t, err)
+	require.NotNil(t, claims.Act)
+	assert.Equal(t, 1, claims.Act.UserID)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+
+	plain, err := m.Issue(Claims{UserID: 7, Role: "owner"})
+	require.NoError(t, err)
+	claims, err = m.Verify(plain)
+	require.NoError(t, err)
+	assert.Nil(t, claims.Act)
+}
+
+func TestVerify_RotatedKey(t *testing.T) {
+	old := NewManager(NewHMACKey("v1", []byte("old-secret")), time.Minute, "nanny-backend")
+	signed, err := old.Issue(Claims{UserID: 1, Role: "owner"})
+	require.NoError(t, err)
+
+	rotated := NewManager(NewHMACKey("v2", []byte("new-secret")), time.Minute, "nanny-backend",
+		NewHMACKey("v1", []byte("old-secret")))
+	_, err = rotated.Verify(signed)
+	assert.NoError(t, err)
+
+	dropped := NewManager(NewHMACKey("v2", []byte("new-secret")), time.Minute, "nanny-backend")
+	_, err = dropped.Verify(signed)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerify_Expired(t *testing.T) {
+	m := NewManager(NewHMACKey("v1", []byte("secret")), -time.Minute, "nanny-backend")
+
+	signed, err := m.Issue(Claims{UserID: 1})
+	require.NoError(t, err)
+
+	_, err = m.Verify(signed)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerify_RejectsAlgorithmSwitch(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	key, err := ParsePrivateKeyPEM("rsa", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	m := NewManager(key, time.Minute, "nanny-backend")
+
+	// HS256 token "signed" with the public key bytes must not pass.
+	pubDER, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID:           1,
+		Role:             "admin",
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "nanny-backend", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	forged.Header["kid"] = "rsa"
+	signed, _ := forged.SignedString(pubDER)
+
+	_, err = m.Verify(signed)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestEdDSA_AndJWKS(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	key, err := ParsePrivateKeyPEM("ed1", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	m := NewManager(key, time.Minute, "nanny-backend")
+
+	signed, err := m.Issue(Claims{UserID: 3, Role: "owner"})
+	require.NoError(t, err)
+	_, err = m.Verify(signed)
+	assert.NoError(t, err)
+
+	set := m.JWKS()
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "OKP", set.Keys[0].KeyType)
+	assert.Equal(t, "ed1", set.Keys[0].KeyID)
+	assert.Equal(t, "EdDSA", set.Keys[0].Algorithm)
+}
+
+func TestJWKS_HidesHMACKeys(t *testing.T) {
+	m := NewManager(NewHMACKey("v1", []byte("secret")), time.Minute, "nanny-backend")
+
+	assert.Empty(t, m.JWKS().Keys)
+}
+
+func TestParsePreviousKeys_Invalid(t *testing.T) {
+	_, err := parsePreviousKeys("v1", true)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package locations
+
+import (
+	"net/http"
+
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+type LocationRequest struct {
+	Line            string   `json:"line" validate:"max=200"`
+	City            string   `json:"city" validate:"required,max=100"`
+	Latitude        *float64 `json:"latitude"`
+	Longitude       *float64 `json:"longitude"`
+	ServiceRadiusKm float64  `json:"service_radius_km"`
+}
+
+func (r LocationRequest) input() Input {
+	return Input{
+		Line:            r.Line,
+		City:            r.City,
+		Latitude:        r.Latitude,
+		Longitude:       r.Longitude,
+		ServiceRadiusKm: r.ServiceRadiusKm,
+	}
+}
+
+func (h *Handler) GetSitterLocation(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	location, err := h.service.GetSitterLocation(r.Context(), middleware.ActorFromContext(r.Context()), sitterID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, location)
+}
+
+func (h *Handler) SetSitterLocation(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	var req LocationRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	location, err := h.service.SetSitterLocation(r.Context(), middleware.ActorFromContext(r.Context()), sitterID, req.input())
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, location)
+}
+
+func (h *Handler) GetOwnerLocation(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := httpx.PathID(r, "owner_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	location, err := h.service.GetOwnerLocation(r.Context(), middleware.ActorFromContext(r.Context()), ownerID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, location)
+}
+
+func (h *Handler) SetOwnerLocation(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := httpx.PathID(r, "owner_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	var req LocationRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	location, err := h.service.SetOwnerLocation(r.Context(), middleware.ActorFromContext(r.Context()), ownerID, req.input())
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, location)
+}
@@ -0,0 +1,57 @@
+package locations
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/middleware"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveOwnerLocation(body string, actor authz.Actor) *httptest.ResponseRecorder {
+	handler := NewHandler(newTestService(newFakeRepository()))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/owners/{owner_id}/location", handler.SetOwnerLocation).Methods("PUT")
+	router.HandleFunc("/api/owners/{owner_id}/location", handler.GetOwnerLocation).Methods("GET")
+
+	method := http.MethodPut
+	if body == "" {
+		method = http.MethodGet
+	}
+
+	req := middleware.WithActor(httptest.NewRequest(method, "/api/owners/1/location", bytes.NewBufferString(body)), actor)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_SetOwnerLocation(t *testing.T) {
+	rec := serveOwnerLocation(`{"line":"Abay 1","city":"Almaty"}`, ownerActor)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"latitude":43.2389`)
+}
+
+func TestHandler_SetOwnerLocation_UnknownCity(t *testing.T) {
+	rec := serveOwnerLocation(`{"city":"Atlantis"}`, ownerActor)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandler_SetOwnerLocation_Forbidden(t *testing.T) {
+	rec := serveOwnerLocation(`{"city":"Almaty"}`, authz.Actor{UserID: 2, Role: authz.RoleOwner})
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestHandler_GetOwnerLocation_NotSet(t *testing.T) {
+	rec := serveOwnerLocation("", ownerActor)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,131 @@
+package locations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/models"
+)
+
+var (
+	ErrSitterNotFound   = apperr.NotFound("sitter_not_found", "nanny not found")
+	ErrLocationNotFound = apperr.NotFound("location_not_found", "location not set")
+)
+
+type Repository interface {
+	GetSitterLocation(ctx context.Context, sitterID int) (*models.SitterLocation, error)
+	SaveSitterLocation(ctx context.Context, location *models.SitterLocation) error
+	GetOwnerLocation(ctx context.Context, ownerID int) (*models.OwnerLocation, error)
+	SaveOwnerLocation(ctx context.Context, location *models.OwnerLocation) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetSitterLocation(ctx context.Context, sitterID int) (*models.SitterLocation, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	location := &models.SitterLocation{}
+	var line sql.NullString
+	var lat, lng sql.NullFloat64
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT sitter_id, address_line, location, latitude, longitude, service_radius_km
+		FROM sitters
+		WHERE sitter_id = $1
+	`, sitterID).Scan(&location.SitterID, &line, &location.City, &lat, &lng, &location.ServiceRadiusKm)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrSitterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting nanny location: %w", err)
+	}
+
+	location.Line = line.String
+	if lat.Valid && lng.Valid {
+		location.Latitude, location.Longitude = &lat.Float64, &lng.Float64
+	}
+
+	return location, nil
+}
+
+func (r *repository) SaveSitterLocation(ctx context.Context, location *models.SitterLocation) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE sitters
+		SET address_line = NULLIF($1, ''), location = $2, latitude = $3, longitude = $4, service_radius_km = $5
+		WHERE sitter_id = $6
+	`, location.Line, location.City, location.Latitude, location.Longitude, location.ServiceRadiusKm, location.SitterID)
+
+	if err != nil {
+		return fmt.Errorf("error saving nanny location: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrSitterNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) GetOwnerLocation(ctx context.Context, ownerID int) (*models.OwnerLocation, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	location := &models.OwnerLocation{}
+	var line sql.NullString
+	var lat, lng float64
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT owner_id, address_line, city, latitude, longitude, updated_at
+		FROM owner_locations
+		WHERE owner_id = $1
+	`, ownerID).Scan(&location.OwnerID, &line, &location.City, &lat, &lng, &location.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrLocationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting home location: %w", err)
+	}
+
+	location.Line = line.String
+	location.Latitude, location.Longitude = &lat, &lng
+
+	return location, nil
+}
+
+func (r *repository) SaveOwnerLocation(ctx context.Context, location *models.OwnerLocation) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO owner_locations (owner_id, address_line, city, latitude, longitude)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5)
+		ON CONFLICT (owner_id) DO UPDATE
+		SET address_line = EXCLUDED.address_line,
+		    city = EXCLUDED.city,
+		    latitude = EXCLUDED.latitude,
+		    longitude = EXCLUDED.longitude,
+		    updated_at = NOW()
+		RETURNING updated_at
+	`, location.OwnerID, location.Line, location.City, location.Latitude, location.Longitude).Scan(&location.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error saving home location: %w", err)
+	}
+
+	return nil
+}
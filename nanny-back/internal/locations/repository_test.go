@@ -0,0 +1,74 @@
+package locations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSitterLocation_WithoutCoordinates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectQuery(`FROM sitters`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"sitter_id", "address_line", "location", "latitude", "longitude", "service_radius_km"}).
+			AddRow(5, nil, "Almaty", nil, nil, 10.0))
+
+	location, err := repo.GetSitterLocation(context.Background(), 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Almaty", location.City)
+	assert.Nil(t, location.Latitude)
+}
+
+func TestSaveSitterLocation_UnknownSitter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	lat, lng := 43.2, 76.9
+
+	mock.ExpectExec(`UPDATE sitters`).
+		WithArgs("", "Almaty", lat, lng, 10.0, 99).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.SaveSitterLocation(context.Background(), &models.SitterLocation{
+		SitterID:        99,
+		Address:         models.Address{City: "Almaty", Latitude: &lat, Longitude: &lng},
+		ServiceRadiusKm: 10,
+	})
+
+	assert.ErrorIs(t, err, ErrSitterNotFound)
+}
+
+func TestSaveOwnerLocation_Upserts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	lat, lng := 43.2, 76.9
+	now := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO owner_locations .* ON CONFLICT \(owner_id\) DO UPDATE`).
+		WithArgs(1, "Abay 1", "Almaty", lat, lng).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+
+	location := &models.OwnerLocation{OwnerID: 1, Address: models.Address{Line: "Abay 1", City: "Almaty", Latitude: &lat, Longitude: &lng}}
+	err = repo.SaveOwnerLocation(context.Background(), location)
+
+	require.NoError(t, err)
+	assert.Equal(t, now, location.UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
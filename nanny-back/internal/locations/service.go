@@ -0,0 +1,187 @@
+package locations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/geo"
+	"nanny-backend/internal/common/models"
+)
+
+const (
+	DefaultServiceRadiusKm = 10
+	MaxServiceRadiusKm     = 200
+)
+
+var ErrInvalidLocation = apperr.Validation("invalid_location", "invalid location")
+
+// Input is an address as entered by the user. When both coordinates are
+// given they are used as is; otherwise the address is geocoded.
+type Input struct {
+	Line      string
+	City      string
+	Latitude  *float64
+	Longitude *float64
+	// ServiceRadiusKm is for sitters only; zero keeps the current radius.
+	ServiceRadiusKm float64
+}
+
+type Service interface {
+	GetSitterLocation(ctx context.Context, actor authz.Actor, sitterID int) (*models.SitterLocation, error)
+	SetSitterLocation(ctx context.Context, actor authz.Actor, sitterID int, input Input) (*models.SitterLocation, error)
+	GetOwnerLocation(ctx context.Context, actor authz.Actor, ownerID int) (*models.OwnerLocation, error)
+	SetOwnerLocation(ctx context.Context, actor authz.Actor, ownerID int, input Input) (*models.OwnerLocation, error)
+	// Covers reports whether the owner's home lies within the sitter's
+	// service radius. An owner without a home is not checked; a sitter
+	// without coordinates covers no home, as in a near-home search.
+	Covers(ctx context.Context, sitterID, ownerID int) (bool, error)
+	// HomePoint returns the owner's home coordinates, or nil if unset.
+	HomePoint(ctx context.Context, ownerID int) (*geo.Point, error)
+}
+
+type service struct {
+	repo     Repository
+	geocoder geo.Geocoder
+}
+
+func NewService(repo Repository, geocoder geo.Geocoder) Service {
+	return &service{repo: repo, geocoder: geocoder}
+}
+
+func (s *service) GetSitterLocation(ctx context.Context, actor authz.Actor, sitterID int) (*models.SitterLocation, error) {
+	if !actor.CanActAs(sitterID) {
+		return nil, fmt.Errorf("location belongs to another nanny: %w", authz.ErrForbidden)
+	}
+
+	return s.repo.GetSitterLocation(ctx, sitterID)
+}
+
+func (s *service) SetSitterLocation(ctx context.Context, actor authz.Actor, sitterID int, input Input) (*models.SitterLocation, error) {
+	if !actor.CanActAs(sitterID) {
+		return nil, fmt.Errorf("location belongs to another nanny: %w", authz.ErrForbidden)
+	}
+
+	if input.ServiceRadiusKm < 0 || input.ServiceRadiusKm > MaxServiceRadiusKm {
+		return nil, fmt.Errorf("%w: service radius must be between 0 and %d km", ErrInvalidLocation, MaxServiceRadiusKm)
+	}
+
+	current, err := s.repo.GetSitterLocation(ctx, sitterID)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := s.resolve(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	location := &models.SitterLocation{SitterID: sitterID, Address: *address, ServiceRadiusKm: current.ServiceRadiusKm}
+	if input.ServiceRadiusKm > 0 {
+		location.ServiceRadiusKm = input.ServiceRadiusKm
+	}
+	if location.ServiceRadiusKm <= 0 {
+		location.ServiceRadiusKm = DefaultServiceRadiusKm
+	}
+
+	if err := s.repo.SaveSitterLocation(ctx, location); err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
+func (s *service) GetOwnerLocation(ctx context.Context, actor authz.Actor, ownerID int) (*models.OwnerLocation, error) {
+	if !actor.CanActAs(ownerID) {
+		return nil, fmt.Errorf("location belongs to another owner: %w", authz.ErrForbidden)
+	}
+
+	return s.repo.GetOwnerLocation(ctx, ownerID)
+}
+
+func (s *service) SetOwnerLocation(ctx context.Context, actor authz.Actor, ownerID int, input Input) (*models.OwnerLocation, error) {
+	if !actor.CanActAs(ownerID) {
+		return nil, fmt.Errorf("location belongs to another owner: %w", authz.ErrForbidden)
+	}
+
+	address, err := s.resolve(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	location := &models.OwnerLocation{OwnerID: ownerID, Address: *address}
+	if err := s.repo.SaveOwnerLocation(ctx, location); err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
+func (s *service) Covers(ctx context.Context, sitterID, ownerID int) (bool, error) {
+	home, err := s.HomePoint(ctx, ownerID)
+	if err != nil || home == nil {
+		return true, err
+	}
+
+	sitter, err := s.repo.GetSitterLocation(ctx, sitterID)
+	if err != nil {
+		return false, err
+	}
+
+	if sitter.Latitude == nil || sitter.Longitude == nil {
+		return false, nil
+	}
+
+	base := geo.Point{Latitude: *sitter.Latitude, Longitude: *sitter.Longitude}
+	return geo.DistanceKm(base, *home) <= sitter.ServiceRadiusKm, nil
+}
+
+func (s *service) HomePoint(ctx context.Context, ownerID int) (*geo.Point, error) {
+	home, err := s.repo.GetOwnerLocation(ctx, ownerID)
+	if errors.Is(err, ErrLocationNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &geo.Point{Latitude: *home.Latitude, Longitude: *home.Longitude}, nil
+}
+
+// resolve validates input and fills in coordinates.
+func (s *service) resolve(ctx context.Context, input Input) (*models.Address, error) {
+	address := &models.Address{
+		Line: strings.TrimSpace(input.Line),
+		City: strings.TrimSpace(input.City),
+	}
+
+	if address.City == "" {
+		return nil, fmt.Errorf("%w: city is required", ErrInvalidLocation)
+	}
+
+	if (input.Latitude == nil) != (input.Longitude == nil) {
+		return nil, fmt.Errorf("%w: latitude and longitude go together", ErrInvalidLocation)
+	}
+
+	if input.Latitude != nil {
+		if *input.Latitude < -90 || *input.Latitude > 90 || *input.Longitude < -180 || *input.Longitude > 180 {
+			return nil, fmt.Errorf("%w: coordinates out of range", ErrInvalidLocation)
+		}
+		address.Latitude, address.Longitude = input.Latitude, input.Longitude
+		return address, nil
+	}
+
+	point, err := s.geocoder.Geocode(ctx, geo.Address{Line: address.Line, City: address.City})
+	if errors.Is(err, geo.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %v; send latitude and longitude instead", ErrInvalidLocation, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error geocoding address: %w", err)
+	}
+
+	address.Latitude, address.Longitude = &point.Latitude, &point.Longitude
+	return address, nil
+}
@@ -0,0 +1,161 @@
+package locations
+
+import (
+	"context"
+	"testing"
+
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/geo"
+	"nanny-backend/internal/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sitterActor = authz.Actor{UserID: 5, Role: authz.RoleSitter}
+	ownerActor  = authz.Actor{UserID: 1, Role: authz.RoleOwner}
+)
+
+type fakeRepository struct {
+	sitters map[int]*models.SitterLocation
+	owners  map[int]*models.OwnerLocation
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		sitters: map[int]*models.SitterLocation{5: {SitterID: 5, Address: models.Address{City: "Almaty"}, ServiceRadiusKm: 10}},
+		owners:  map[int]*models.OwnerLocation{},
+	}
+}
+
+func (r *fakeRepository) GetSitterLocation(ctx context.Context, sitterID int) (*models.SitterLocation, error) {
+	location, ok := r.sitters[sitterID]
+	if !ok {
+		return nil, ErrSitterNotFound
+	}
+	copied := *location
+	return &copied, nil
+}
+
+func (r *fakeRepository) SaveSitterLocation(ctx context.Context, location *models.SitterLocation) error {
+	if _, ok := r.sitters[location.SitterID]; !ok {
+		return ErrSitterNotFound
+	}
+	r.sitters[location.SitterID] = location
+	return nil
+}
+
+func (r *fakeRepository) GetOwnerLocation(ctx context.Context, ownerID int) (*models.OwnerLocation, error) {
+	location, ok := r.owners[ownerID]
+	if !ok {
+		return nil, ErrLocationNotFound
+	}
+	return location, nil
+}
+
+func (r *fakeRepository) SaveOwnerLocation(ctx context.Context, location *models.OwnerLocation) error {
+	r.owners[location.OwnerID] = location
+	return nil
+}
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func newTestService(repo Repository) Service {
+	return NewService(repo, geo.NewStaticGeocoder(nil))
+}
+
+func TestSetSitterLocation_Geocodes(t *testing.T) {
+	repo := newFakeRepository()
+
+	location, err := newTestService(repo).SetSitterLocation(context.Background(), sitterActor, 5, Input{
+		Line: "Dostyk 5", City: "Astana", ServiceRadiusKm: 25,
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, location.Latitude)
+	assert.InDelta(t, 51.16, *location.Latitude, 0.01)
+	assert.Equal(t, 25.0, repo.sitters[5].ServiceRadiusKm)
+	assert.Equal(t, "Dostyk 5", repo.sitters[5].Line)
+}
+
+func TestSetSitterLocation_KeepsRadius(t *testing.T) {
+	repo := newFakeRepository()
+
+	_, err := newTestService(repo).SetSitterLocation(context.Background(), sitterActor, 5, Input{
+		City: "Almaty", Latitude: float(43.25), Longitude: float(76.9),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, repo.sitters[5].ServiceRadiusKm)
+	assert.Equal(t, 43.25, *repo.sitters[5].Latitude)
+}
+
+func TestSetSitterLocation_Forbidden(t *testing.T) {
+	_, err := newTestService(newFakeRepository()).SetSitterLocation(context.Background(), ownerActor, 5, Input{City: "Almaty"})
+
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+}
+
+func TestSetOwnerLocation_Invalid(t *testing.T) {
+	cases := map[string]Input{
+		"no city":         {Line: "Abay 1"},
+		"unknown city":    {City: "Atlantis"},
+		"half coordinate": {City: "Almaty", Latitude: float(43)},
+		"out of range":    {City: "Almaty", Latitude: float(95), Longitude: float(76)},
+	}
+
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := newTestService(newFakeRepository()).SetOwnerLocation(context.Background(), ownerActor, 1, input)
+			assert.ErrorIs(t, err, ErrInvalidLocation)
+		})
+	}
+}
+
+func TestCovers_SitterWithoutCoordinates(t *testing.T) {
+	repo := newFakeRepository()
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	_, err := svc.SetOwnerLocation(ctx, ownerActor, 1, Input{City: "Almaty", Latitude: float(43.26), Longitude: float(76.93)})
+	require.NoError(t, err)
+
+	covered, err := svc.Covers(ctx, 5, 1)
+
+	require.NoError(t, err)
+	assert.False(t, covered, "search near a home leaves this sitter out, so booking must too")
+}
+
+func TestCovers(t *testing.T) {
+	repo := newFakeRepository()
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	covered, err := svc.Covers(ctx, 5, 1)
+	require.NoError(t, err)
+	assert.True(t, covered, "owner without a home is not checked")
+
+	_, err = svc.SetOwnerLocation(ctx, ownerActor, 1, Input{City: "Almaty", Latitude: float(43.26), Longitude: float(76.93)})
+	require.NoError(t, err)
+
+	covered, err = svc.Covers(ctx, 5, 1)
+	require.NoError(t, err)
+	assert.False(t, covered, "sitter without coordinates covers no home")
+
+	_, err = svc.SetSitterLocation(ctx, sitterActor, 5, Input{City: "Almaty", Latitude: float(43.2389), Longitude: float(76.8897)})
+	require.NoError(t, err)
+
+	covered, err = svc.Covers(ctx, 5, 1)
+	require.NoError(t, err)
+	assert.True(t, covered)
+
+	_, err = svc.SetSitterLocation(ctx, sitterActor, 5, Input{City: "Astana"})
+	require.NoError(t, err)
+
+	covered, err = svc.Covers(ctx, 5, 1)
+	require.NoError(t, err)
+	assert.False(t, covered)
+}
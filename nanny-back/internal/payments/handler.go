@@ -0,0 +1,76 @@
+package payments
+
+import (
+	"net/http"
+
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) GetOwnerPayments(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := httpx.PathID(r, "owner_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	q, err := ListSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.GetOwnerPayments(r.Context(), middleware.ActorFromContext(r.Context()), ownerID, q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) GetSitterPayments(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	q, err := ListSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.GetSitterPayments(r.Context(), middleware.ActorFromContext(r.Context()), sitterID, q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) GetAllPayments(w http.ResponseWriter, r *http.Request) {
+	q, err := ListSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.GetAllPayments(r.Context(), q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,64 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/middleware"
+	"nanny-backend/internal/common/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_GetOwnerPayments(t *testing.T) {
+	repo := newFakeRepository()
+	svc := NewService(repo, NewFakeProvider())
+	_, err := svc.ChargeBooking(context.Background(), 1)
+	require.NoError(t, err)
+	handler := NewHandler(svc)
+
+	req := middleware.WithActor(httptest.NewRequest(http.MethodGet, "/api/owners/10/payments", nil), ownerActor)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/owners/{owner_id}/payments", handler.GetOwnerPayments)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var page listing.Page[models.Payment]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, 5000.0, page.Items[0].Amount)
+}
+
+func TestHandler_GetOwnerPayments_Forbidden(t *testing.T) {
+	handler := NewHandler(NewService(newFakeRepository(), NewFakeProvider()))
+
+	req := middleware.WithActor(httptest.NewRequest(http.MethodGet, "/api/owners/11/payments", nil), ownerActor)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/owners/{owner_id}/payments", handler.GetOwnerPayments)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestHandler_GetSitterPayments_InvalidID(t *testing.T) {
+	handler := NewHandler(NewService(newFakeRepository(), NewFakeProvider()))
+
+	req := middleware.WithActor(httptest.NewRequest(http.MethodGet, "/api/sitters/abc/payments", nil), sitterActor)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/sitters/{sitter_id}/payments", handler.GetSitterPayments)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,75 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"nanny-backend/internal/common/apperr"
+)
+
+// PaymentProvider is the gateway that actually moves money. Amounts are in
+// tenge with two decimals, the same as services.price_per_hour.
+type PaymentProvider interface {
+	Name() string
+	Charge(ctx context.Context, bookingID int, amount float64) (ref string, err error)
+	Refund(ctx context.Context, ref string, amount float64) error
+}
+
+func NewProvider(name string) (PaymentProvider, error) {
+	switch name {
+	case "fake", "":
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", name)
+	}
+}
+
+var ErrDeclined = apperr.Conflict("payment_declined", "payment declined")
+
+// FakeProvider approves everything in-process. Used for local development
+// and tests; set Decline to simulate a failing card.
+type FakeProvider struct {
+	mu      sync.Mutex
+	seq     int
+	charges map[string]float64
+	Decline bool
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{charges: map[string]float64{}}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Charge(ctx context.Context, bookingID int, amount float64) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Decline {
+		return "", ErrDeclined
+	}
+
+	p.seq++
+	ref := fmt.Sprintf("fake_ch_%d_%d", bookingID, p.seq)
+	p.charges[ref] = amount
+	return ref, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, ref string, amount float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charged, ok := p.charges[ref]
+	if !ok {
+		return fmt.Errorf("unknown charge %q", ref)
+	}
+	if amount > charged {
+		return fmt.Errorf("refund exceeds charged amount")
+	}
+
+	delete(p.charges, ref)
+	return nil
+}
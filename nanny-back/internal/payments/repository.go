@@ -0,0 +1,224 @@
+package payments
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/models"
+)
+
+var (
+	ErrPaymentNotFound = apperr.NotFound("payment_not_found", "payment not found")
+	ErrBookingNotFound = apperr.NotFound("booking_not_found", "booking not found")
+)
+
+// BookingCharge is what a booking costs: the service's hourly price over
+// the booked time.
+type BookingCharge struct {
+	BookingID    int
+	OwnerID      int
+	SitterID     int
+	StartTime    time.Time
+	EndTime      time.Time
+	PricePerHour float64
+}
+
+type Repository interface {
+	GetBookingCharge(ctx context.Context, bookingID int) (*BookingCharge, error)
+	GetPaidPayment(ctx context.Context, bookingID int) (*models.Payment, error)
+	Create(ctx context.Context, payment *models.Payment) (int, error)
+	MarkRefunded(ctx context.Context, paymentID int) error
+	GetByOwnerID(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Payment], error)
+	GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Payment], error)
+	GetAll(ctx context.Context, q listing.Query) (*listing.Page[models.Payment], error)
+}
+
+// ListSpec is how payment lists can be sorted and filtered.
+var ListSpec = listing.Spec[models.Payment]{
+	IDColumn: "p.payment_id",
+	ID:       func(p models.Payment) int { return p.PaymentID },
+	Sorts: map[string]listing.Sort[models.Payment]{
+		"created_at": {Column: "p.created_at", Value: func(p models.Payment) interface{} { return p.CreatedAt }},
+		"amount":     {Column: "p.amount", Value: func(p models.Payment) interface{} { return p.Amount }},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"status":     {Column: "p.status", Type: listing.Text},
+		"booking_id": {Column: "p.booking_id", Type: listing.Int},
+		"created_at": {Column: "p.created_at", Type: listing.Time},
+	},
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+const paymentColumns = `
+	p.payment_id, p.booking_id, b.owner_id, b.sitter_id, p.amount,
+	COALESCE(p.method, ''), p.status, COALESCE(p.provider_ref, ''), p.created_at, p.refunded_at`
+
+func (r *repository) GetBookingCharge(ctx context.Context, bookingID int) (*BookingCharge, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	charge := &BookingCharge{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT b.booking_id, b.owner_id, b.sitter_id, b.start_time, b.end_time, s.price_per_hour
+		FROM bookings b
+		JOIN services s ON s.service_id = b.service_id
+		WHERE b.booking_id = $1
+	`, bookingID).Scan(
+		&charge.BookingID,
+		&charge.OwnerID,
+		&charge.SitterID,
+		&charge.StartTime,
+		&charge.EndTime,
+		&charge.PricePerHour,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting booking price: %w", err)
+	}
+
+	return charge, nil
+}
+
+func (r *repository) GetPaidPayment(ctx context.Context, bookingID int) (*models.Payment, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+paymentColumns+`
+		FROM payments p
+		JOIN bookings b ON b.booking_id = p.booking_id
+		WHERE p.booking_id = $1 AND p.status = 'paid'
+	`, bookingID)
+
+	payment, err := scanPayment(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+func (r *repository) Create(ctx context.Context, payment *models.Payment) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var paymentID int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO payments (booking_id, amount, method, status, provider_ref)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING payment_id
+	`, payment.BookingID, payment.Amount, payment.Method, payment.Status, payment.ProviderRef).Scan(&paymentID)
+
+	if err != nil {
+		return 0, fmt.Errorf("could not record payment: %w", err)
+	}
+
+	return paymentID, nil
+}
+
+func (r *repository) MarkRefunded(ctx context.Context, paymentID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE payments
+		SET status = 'refunded', refunded_at = NOW()
+		WHERE payment_id = $1 AND status = 'paid'
+	`, paymentID)
+
+	if err != nil {
+		return fmt.Errorf("could not refund payment: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrPaymentNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) GetByOwnerID(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Payment], error) {
+	return r.list(ctx, q, `WHERE b.owner_id = $1`, ownerID)
+}
+
+func (r *repository) GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Payment], error) {
+	return r.list(ctx, q, `WHERE b.sitter_id = $1`, sitterID)
+}
+
+func (r *repository) GetAll(ctx context.Context, q listing.Query) (*listing.Page[models.Payment], error) {
+	return r.list(ctx, q, `WHERE TRUE`)
+}
+
+func (r *repository) list(ctx context.Context, q listing.Query, where string, args ...interface{}) (*listing.Page[models.Payment], error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query, args := q.Apply(`
+		SELECT `+paymentColumns+`
+		FROM payments p
+		JOIN bookings b ON b.booking_id = p.booking_id
+		`+where, args)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []models.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning payment: %w", err)
+		}
+		payments = append(payments, *payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting payments: %w", err)
+	}
+
+	return ListSpec.Page(payments, q), nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPayment(row scanner) (*models.Payment, error) {
+	p := &models.Payment{}
+	err := row.Scan(
+		&p.PaymentID,
+		&p.BookingID,
+		&p.OwnerID,
+		&p.SitterID,
+		&p.Amount,
+		&p.Method,
+		&p.Status,
+		&p.ProviderRef,
+		&p.CreatedAt,
+		&p.RefundedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,138 @@
+package payments
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetPaidPayment_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectQuery(`FROM payments p`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"payment_id"}))
+
+	_, err = repo.GetPaidPayment(context.Background(), 7)
+
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectQuery(`INSERT INTO payments`).
+		WithArgs(7, 5000.0, "fake", "paid", "fake_ch_7_1").
+		WillReturnRows(sqlmock.NewRows([]string{"payment_id"}).AddRow(3))
+
+	id, err := repo.Create(context.Background(), &models.Payment{
+		BookingID: 7, Amount: 5000, Method: "fake", Status: "paid", ProviderRef: "fake_ch_7_1",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetByOwnerID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{
+		"payment_id", "booking_id", "owner_id", "sitter_id", "amount",
+		"method", "status", "provider_ref", "created_at", "refunded_at",
+	}).
+		AddRow(2, 7, 10, 20, 5000.0, "fake", "refunded", "fake_ch_7_1", now, now).
+		AddRow(1, 6, 10, 21, 3000.0, "fake", "paid", "fake_ch_6_1", now, nil)
+
+	q, _ := ListSpec.Parse(nil)
+
+	mock.ExpectQuery(`WHERE b.owner_id = \$1 ORDER BY p.created_at DESC, p.payment_id DESC LIMIT \$2`).
+		WithArgs(10, listing.DefaultLimit+1).
+		WillReturnRows(rows)
+
+	page, err := repo.GetByOwnerID(context.Background(), 10, q)
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.NotNil(t, page.Items[0].RefundedAt)
+	assert.Nil(t, page.Items[1].RefundedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetAll_NextPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	now := time.Now()
+
+	columns := []string{
+		"payment_id", "booking_id", "owner_id", "sitter_id", "amount",
+		"method", "status", "provider_ref", "created_at", "refunded_at",
+	}
+	first, _ := ListSpec.Parse(url.Values{"limit": {"1"}, "sort": {"amount"}, "status": {"paid"}})
+
+	mock.ExpectQuery(`WHERE TRUE AND p.status = ANY\(\$1\) ORDER BY p.amount ASC, p.payment_id ASC LIMIT \$2`).
+		WithArgs(pq.Array([]string{"paid"}), 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(4, 9, 10, 20, 1500.0, "fake", "paid", "fake_ch_9_1", now, nil).
+			AddRow(3, 8, 10, 20, 3000.0, "fake", "paid", "fake_ch_8_1", now, nil))
+
+	page, err := repo.GetAll(context.Background(), first)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.NotEmpty(t, page.NextCursor)
+
+	next, err := ListSpec.Parse(url.Values{"limit": {"1"}, "sort": {"amount"}, "status": {"paid"}, "cursor": {page.NextCursor}})
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`AND \(p.amount, p.payment_id\) > \(\$2, \$3\) ORDER BY p.amount ASC`).
+		WithArgs(pq.Array([]string{"paid"}), "1500", 4, 2).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	page, err = repo.GetAll(context.Background(), next)
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_MarkRefunded_NotPaid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectExec(`UPDATE payments`).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.MarkRefunded(context.Background(), 3)
+
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+}
@@ -0,0 +1,128 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/models"
+)
+
+const (
+	StatusPaid     = "paid"
+	StatusRefunded = "refunded"
+	StatusFailed   = "failed"
+)
+
+type Service interface {
+	ChargeBooking(ctx context.Context, bookingID int) (*models.Payment, error)
+	RefundBooking(ctx context.Context, bookingID int) error
+	GetOwnerPayments(ctx context.Context, actor authz.Actor, ownerID int, q listing.Query) (*listing.Page[models.Payment], error)
+	GetSitterPayments(ctx context.Context, actor authz.Actor, sitterID int, q listing.Query) (*listing.Page[models.Payment], error)
+	GetAllPayments(ctx context.Context, q listing.Query) (*listing.Page[models.Payment], error)
+}
+
+type service struct {
+	repo     Repository
+	provider PaymentProvider
+}
+
+func NewService(repo Repository, provider PaymentProvider) Service {
+	return &service{repo: repo, provider: provider}
+}
+
+// Amount is the service price per hour times the booked hours, rounded
+// to two decimals.
+func Amount(charge *BookingCharge) float64 {
+	hours := charge.EndTime.Sub(charge.StartTime).Hours()
+	return math.Round(charge.PricePerHour*hours*100) / 100
+}
+
+// ChargeBooking is idempotent: a booking that already has a live payment
+// is not charged again, so it is safe to call on both confirm and
+// complete.
+func (s *service) ChargeBooking(ctx context.Context, bookingID int) (*models.Payment, error) {
+	existing, err := s.repo.GetPaidPayment(ctx, bookingID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, ErrPaymentNotFound) {
+		return nil, err
+	}
+
+	charge, err := s.repo.GetBookingCharge(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &models.Payment{
+		BookingID: bookingID,
+		OwnerID:   charge.OwnerID,
+		SitterID:  charge.SitterID,
+		Amount:    Amount(charge),
+		Method:    s.provider.Name(),
+		Status:    StatusPaid,
+	}
+
+	ref, chargeErr := s.provider.Charge(ctx, bookingID, payment.Amount)
+	if chargeErr != nil {
+		payment.Status = StatusFailed
+	}
+	payment.ProviderRef = ref
+
+	payment.PaymentID, err = s.repo.Create(ctx, payment)
+	if err != nil {
+		if chargeErr == nil {
+			// Don't keep money we have no record of.
+			_ = s.provider.Refund(ctx, ref, payment.Amount)
+		}
+		return nil, err
+	}
+
+	if chargeErr != nil {
+		return nil, fmt.Errorf("payment failed: %w", chargeErr)
+	}
+
+	return payment, nil
+}
+
+// RefundBooking returns the live payment of a booking, if there is one.
+// Bookings that were never charged are a no-op.
+func (s *service) RefundBooking(ctx context.Context, bookingID int) error {
+	payment, err := s.repo.GetPaidPayment(ctx, bookingID)
+	if errors.Is(err, ErrPaymentNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.provider.Refund(ctx, payment.ProviderRef, payment.Amount); err != nil {
+		return fmt.Errorf("refund failed: %w", err)
+	}
+
+	return s.repo.MarkRefunded(ctx, payment.PaymentID)
+}
+
+func (s *service) GetOwnerPayments(ctx context.Context, actor authz.Actor, ownerID int, q listing.Query) (*listing.Page[models.Payment], error) {
+	if !actor.CanActAs(ownerID) {
+		return nil, fmt.Errorf("payments belong to another owner: %w", authz.ErrForbidden)
+	}
+
+	return s.repo.GetByOwnerID(ctx, ownerID, q)
+}
+
+func (s *service) GetSitterPayments(ctx context.Context, actor authz.Actor, sitterID int, q listing.Query) (*listing.Page[models.Payment], error) {
+	if !actor.CanActAs(sitterID) {
+		return nil, fmt.Errorf("payments belong to another nanny: %w", authz.ErrForbidden)
+	}
+
+	return s.repo.GetBySitterID(ctx, sitterID, q)
+}
+
+func (s *service) GetAllPayments(ctx context.Context, q listing.Query) (*listing.Page[models.Payment], error) {
+	return s.repo.GetAll(ctx, q)
+}
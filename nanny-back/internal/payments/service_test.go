@@ -0,0 +1,195 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	charges   map[int]*BookingCharge
+	payments  []models.Payment
+	createErr error
+}
+
+func newFakeRepository() *fakeRepository {
+	start := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	return &fakeRepository{charges: map[int]*BookingCharge{
+		1: {BookingID: 1, OwnerID: 10, SitterID: 20, StartTime: start, EndTime: start.Add(150 * time.Minute), PricePerHour: 2000},
+	}}
+}
+
+func (f *fakeRepository) GetBookingCharge(ctx context.Context, bookingID int) (*BookingCharge, error) {
+	charge, ok := f.charges[bookingID]
+	if !ok {
+		return nil, errors.New("booking not found")
+	}
+	return charge, nil
+}
+
+func (f *fakeRepository) GetPaidPayment(ctx context.Context, bookingID int) (*models.Payment, error) {
+	for i := range f.payments {
+		if f.payments[i].BookingID == bookingID && f.payments[i].Status == StatusPaid {
+			p := f.payments[i]
+			return &p, nil
+		}
+	}
+	return nil, ErrPaymentNotFound
+}
+
+func (f *fakeRepository) Create(ctx context.Context, payment *models.Payment) (int, error) {
+	if f.createErr != nil {
+		return 0, f.createErr
+	}
+	payment.PaymentID = len(f.payments) + 1
+	f.payments = append(f.payments, *payment)
+	return payment.PaymentID, nil
+}
+
+func (f *fakeRepository) MarkRefunded(ctx context.Context, paymentID int) error {
+	for i := range f.payments {
+		if f.payments[i].PaymentID == paymentID && f.payments[i].Status == StatusPaid {
+			f.payments[i].Status = StatusRefunded
+			return nil
+		}
+	}
+	return ErrPaymentNotFound
+}
+
+func (f *fakeRepository) GetByOwnerID(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Payment], error) {
+	return ListSpec.Page(f.filter(func(p models.Payment) bool { return p.OwnerID == ownerID }), q), nil
+}
+
+func (f *fakeRepository) GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Payment], error) {
+	return ListSpec.Page(f.filter(func(p models.Payment) bool { return p.SitterID == sitterID }), q), nil
+}
+
+func (f *fakeRepository) GetAll(ctx context.Context, q listing.Query) (*listing.Page[models.Payment], error) {
+	return ListSpec.Page(f.payments, q), nil
+}
+
+func (f *fakeRepository) filter(keep func(models.Payment) bool) []models.Payment {
+	out := []models.Payment{}
+	for _, p := range f.payments {
+		if keep(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+var (
+	ownerActor  = authz.Actor{UserID: 10, Role: authz.RoleOwner}
+	sitterActor = authz.Actor{UserID: 20, Role: authz.RoleSitter}
+	adminActor  = authz.Actor{UserID: 1, Role: authz.RoleAdmin}
+)
+
+func TestChargeBooking_AmountFromPriceAndDuration(t *testing.T) {
+	repo := newFakeRepository()
+	svc := NewService(repo, NewFakeProvider())
+
+	payment, err := svc.ChargeBooking(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 5000.0, payment.Amount)
+	assert.Equal(t, StatusPaid, payment.Status)
+	assert.Equal(t, "fake", payment.Method)
+	assert.NotEmpty(t, payment.ProviderRef)
+}
+
+func TestChargeBooking_Idempotent(t *testing.T) {
+	repo := newFakeRepository()
+	svc := NewService(repo, NewFakeProvider())
+
+	first, err := svc.ChargeBooking(context.Background(), 1)
+	require.NoError(t, err)
+	second, err := svc.ChargeBooking(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.PaymentID, second.PaymentID)
+	assert.Len(t, repo.payments, 1)
+}
+
+func TestChargeBooking_DeclinedIsRecorded(t *testing.T) {
+	repo := newFakeRepository()
+	provider := NewFakeProvider()
+	provider.Decline = true
+	svc := NewService(repo, provider)
+
+	_, err := svc.ChargeBooking(context.Background(), 1)
+
+	assert.ErrorIs(t, err, ErrDeclined)
+	require.Len(t, repo.payments, 1)
+	assert.Equal(t, StatusFailed, repo.payments[0].Status)
+}
+
+func TestChargeBooking_RefundsWhenNotRecorded(t *testing.T) {
+	repo := newFakeRepository()
+	repo.createErr = errors.New("db down")
+	provider := NewFakeProvider()
+	svc := NewService(repo, provider)
+
+	_, err := svc.ChargeBooking(context.Background(), 1)
+
+	assert.Error(t, err)
+	assert.Empty(t, provider.charges)
+}
+
+func TestRefundBooking(t *testing.T) {
+	repo := newFakeRepository()
+	provider := NewFakeProvider()
+	svc := NewService(repo, provider)
+
+	_, err := svc.ChargeBooking(context.Background(), 1)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RefundBooking(context.Background(), 1))
+	assert.Equal(t, StatusRefunded, repo.payments[0].Status)
+	assert.Empty(t, provider.charges)
+}
+
+func TestRefundBooking_NothingPaid(t *testing.T) {
+	svc := NewService(newFakeRepository(), NewFakeProvider())
+
+	assert.NoError(t, svc.RefundBooking(context.Background(), 1))
+}
+
+func TestGetOwnerPayments_Ownership(t *testing.T) {
+	repo := newFakeRepository()
+	svc := NewService(repo, NewFakeProvider())
+	_, err := svc.ChargeBooking(context.Background(), 1)
+	require.NoError(t, err)
+
+	q, _ := ListSpec.Parse(nil)
+
+	page, err := svc.GetOwnerPayments(context.Background(), ownerActor, 10, q)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+
+	_, err = svc.GetOwnerPayments(context.Background(), authz.Actor{UserID: 11, Role: authz.RoleOwner}, 10, q)
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+
+	page, err = svc.GetOwnerPayments(context.Background(), adminActor, 10, q)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+}
+
+func TestGetSitterPayments_Ownership(t *testing.T) {
+	svc := NewService(newFakeRepository(), NewFakeProvider())
+
+	q, _ := ListSpec.Parse(nil)
+
+	_, err := svc.GetSitterPayments(context.Background(), sitterActor, 20, q)
+	assert.NoError(t, err)
+
+	_, err = svc.GetSitterPayments(context.Background(), sitterActor, 21, q)
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+}
@@ -1,13 +1,10 @@
 package pets
 
 import (
-	"encoding/json"
 	"net/http"
-	"strconv"
 
-	"nanny-backend/pkg/validator"
-
-	"github.com/gorilla/mux"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
 )
 
 type Handler struct {
@@ -19,11 +16,10 @@ func NewHandler(service Service) *Handler {
 }
 
 type CreatePetRequest struct {
-	OwnerID int    `json:"owner_id" validate:"required,gt=0"`
-	Name    string `json:"name" validate:"required,min=1,max=100"`
-	Type    string `json:"type" validate:"required,pet_type"`
-	Age     int    `json:"age" validate:"required,gte=0,lte=30"`
-	Notes   string `json:"notes,omitempty" validate:"max=500"`
+	Name  string `json:"name" validate:"required,min=1,max=100"`
+	Type  string `json:"type" validate:"required,pet_type"`
+	Age   int    `json:"age" validate:"required,gte=0,lte=30"`
+	Notes string `json:"notes,omitempty" validate:"max=500"`
 }
 
 type UpdatePetRequest struct {
@@ -35,137 +31,105 @@ type UpdatePetRequest struct {
 
 func (h *Handler) CreatePet(w http.ResponseWriter, r *http.Request) {
 	var req CreatePetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if err := validator.Validate(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
-		return
-	}
+	actor := middleware.ActorFromContext(r.Context())
 
-	petID, err := h.service.CreatePet(req.OwnerID, req.Name, req.Type, req.Age, req.Notes)
+	petID, err := h.service.CreatePet(r.Context(), actor, req.Name, req.Type, req.Age, req.Notes)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+	httpx.JSON(w, http.StatusCreated, map[string]interface{}{
 		"message": "pet created succesfully",
 		"pet_id":  petID,
 	})
 }
 
 func (h *Handler) GetPet(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	petID, err := strconv.Atoi(vars["id"])
+	petID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID pet")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if petID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID pet must be positive")
-		return
-	}
-
-	pet, err := h.service.GetPetByID(petID)
+	pet, err := h.service.GetPetByID(r.Context(), petID)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, pet)
+	httpx.JSON(w, http.StatusOK, pet)
 }
 
 func (h *Handler) GetOwnerPets(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	ownerID, err := strconv.Atoi(vars["owner_id"])
+	ownerID, err := httpx.PathID(r, "owner_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID owner")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if ownerID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID owner must be positive")
+	q, err := ListSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	pets, err := h.service.GetPetsByOwner(ownerID)
+	page, err := h.service.GetPetsByOwner(r.Context(), ownerID, q)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, pets)
+	httpx.JSON(w, http.StatusOK, page)
 }
 
 func (h *Handler) UpdatePet(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	petID, err := strconv.Atoi(vars["id"])
+	petID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID pet")
-		return
-	}
-
-	if petID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID pet must be positive")
+		httpx.Error(w, r, err)
 		return
 	}
 
 	var req UpdatePetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if err := validator.Validate(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
-		return
-	}
+	actor := middleware.ActorFromContext(r.Context())
 
-	err = h.service.UpdatePet(petID, req.Name, req.Type, req.Age, req.Notes)
+	err = h.service.UpdatePet(r.Context(), actor, petID, req.Name, req.Type, req.Age, req.Notes)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
+	httpx.JSON(w, http.StatusOK, map[string]string{
 		"message": "pet updated succefully",
 	})
 }
 
 func (h *Handler) DeletePet(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	petID, err := strconv.Atoi(vars["id"])
+	petID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID pet")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if petID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID pet must be positive")
-		return
-	}
+	actor := middleware.ActorFromContext(r.Context())
 
-	err = h.service.DeletePet(petID)
+	err = h.service.DeletePet(r.Context(), actor, petID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
+	httpx.JSON(w, http.StatusOK, map[string]string{
 		"message": "pet deleted succesfully",
 	})
 }
-
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
-}
-
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(payload)
-}
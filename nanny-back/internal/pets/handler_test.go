@@ -2,11 +2,15 @@ package pets
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/middleware"
 	"nanny-backend/internal/common/models"
 
 	"github.com/gorilla/mux"
@@ -18,12 +22,12 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) CreatePet(ownerID int, name, petType string, age int, notes string) (int, error) {
-	args := m.Called(ownerID, name, petType, age, notes)
+func (m *MockService) CreatePet(ctx context.Context, actor authz.Actor, name, petType string, age int, notes string) (int, error) {
+	args := m.Called(actor, name, petType, age, notes)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockService) GetPetByID(petID int) (*models.Pet, error) {
+func (m *MockService) GetPetByID(ctx context.Context, petID int) (*models.Pet, error) {
 	args := m.Called(petID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -31,40 +35,45 @@ func (m *MockService) GetPetByID(petID int) (*models.Pet, error) {
 	return args.Get(0).(*models.Pet), args.Error(1)
 }
 
-func (m *MockService) GetPetsByOwner(ownerID int) ([]models.Pet, error) {
-	args := m.Called(ownerID)
-	return args.Get(0).([]models.Pet), args.Error(1)
+func (m *MockService) GetPetsByOwner(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Pet], error) {
+	args := m.Called(ownerID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[models.Pet]), args.Error(1)
 }
 
-func (m *MockService) UpdatePet(petID int, name, petType string, age int, notes string) error {
-	args := m.Called(petID, name, petType, age, notes)
+func (m *MockService) UpdatePet(ctx context.Context, actor authz.Actor, petID int, name, petType string, age int, notes string) error {
+	args := m.Called(actor, petID, name, petType, age, notes)
 	return args.Error(0)
 }
 
-func (m *MockService) DeletePet(petID int) error {
-	args := m.Called(petID)
+func (m *MockService) DeletePet(ctx context.Context, actor authz.Actor, petID int) error {
+	args := m.Called(actor, petID)
 	return args.Error(0)
 }
 
+var testOwner = authz.Actor{UserID: 1, Role: authz.RoleOwner}
+
 func TestHandler_CreatePet_Success(t *testing.T) {
 	mockService := new(MockService)
 	handler := NewHandler(mockService)
 
 	reqBody := CreatePetRequest{
-		OwnerID: 1,
-		Name:    "Мурка",
-		Type:    "кошка",
-		Age:     3,
-		Notes:   "спокойная",
+		Name:  "Мурка",
+		Type:  "кошка",
+		Age:   3,
+		Notes: "спокойная",
 	}
 
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/pets", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req = middleware.WithActor(req, authz.Actor{UserID: 1, Role: authz.RoleOwner})
 	rec := httptest.NewRecorder()
 
 	mockService.
-		On("CreatePet", 1, "Мурка", "кошка", 3, "спокойная").
+		On("CreatePet", testOwner, "Мурка", "кошка", 3, "спокойная").
 		Return(10, nil)
 
 	handler.CreatePet(rec, req)
@@ -115,10 +124,10 @@ func TestHandler_GetOwnerPets_Success(t *testing.T) {
 	}
 
 	mockService.
-		On("GetPetsByOwner", 5).
-		Return(pets, nil)
+		On("GetPetsByOwner", 5, mock.AnythingOfType("listing.Query")).
+		Return(&listing.Page[models.Pet]{Items: pets}, nil)
 
-	req := httptest.NewRequest(http.MethodGet, "/owners/5/pets", nil)
+	req := httptest.NewRequest(http.MethodGet, "/owners/5/pets?sort=-name&type=cat", nil)
 	rec := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -143,10 +152,11 @@ func TestHandler_UpdatePet_Success(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPut, "/pets/10", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req = middleware.WithActor(req, authz.Actor{UserID: 1, Role: authz.RoleOwner})
 	rec := httptest.NewRecorder()
 
 	mockService.
-		On("UpdatePet", 10, "Мурка обновлённая", "кошка", 4, "обновлено").
+		On("UpdatePet", testOwner, 10, "Мурка обновлённая", "кошка", 4, "обновлено").
 		Return(nil)
 
 	router := mux.NewRouter()
@@ -162,10 +172,11 @@ func TestHandler_DeletePet_Success(t *testing.T) {
 	handler := NewHandler(mockService)
 
 	mockService.
-		On("DeletePet", 10).
+		On("DeletePet", testOwner, 10).
 		Return(nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/pets/10", nil)
+	req = middleware.WithActor(req, authz.Actor{UserID: 1, Role: authz.RoleOwner})
 	rec := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -175,3 +186,23 @@ func TestHandler_DeletePet_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestHandler_DeletePet_Forbidden(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.
+		On("DeletePet", testOwner, 10).
+		Return(authz.ErrForbidden)
+
+	req := httptest.NewRequest(http.MethodDelete, "/pets/10", nil)
+	req = middleware.WithActor(req, authz.Actor{UserID: 1, Role: authz.RoleOwner})
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/pets/{id}", handler.DeletePet)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	mockService.AssertExpectations(t)
+}
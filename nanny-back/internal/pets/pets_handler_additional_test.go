@@ -2,12 +2,16 @@ package pets
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 
 	"github.com/gorilla/mux"
@@ -25,9 +29,9 @@ func TestHandler_CreatePet_InvalidJSON(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect data")
+	assert.Equal(t, "malformed_body", resp.Code)
 }
 
 func TestHandler_CreatePet_ValidationError(t *testing.T) {
@@ -35,10 +39,9 @@ func TestHandler_CreatePet_ValidationError(t *testing.T) {
 	handler := NewHandler(mockSvc)
 
 	reqBody := map[string]interface{}{
-		"owner_id": 1,
-		"name":     "Buddy",
-		"type":     "invalid-type",
-		"age":      5,
+		"name": "Buddy",
+		"type": "invalid-type",
+		"age":  5,
 	}
 
 	body, _ := json.Marshal(reqBody)
@@ -53,17 +56,16 @@ func TestHandler_CreatePet_ValidationError(t *testing.T) {
 
 func TestHandler_CreatePet_ServiceError(t *testing.T) {
 	mockSvc := &mockPetService{
-		createPetFunc: func(ownerID int, name, petType string, age int, notes string) (int, error) {
+		createPetFunc: func(actor authz.Actor, name, petType string, age int, notes string) (int, error) {
 			return 0, errors.New("database error")
 		},
 	}
 	handler := NewHandler(mockSvc)
 
 	reqBody := map[string]interface{}{
-		"owner_id": 1,
-		"name":     "Max",
-		"type":     "dog",
-		"age":      3,
+		"name": "Max",
+		"type": "dog",
+		"age":  3,
 	}
 
 	body, _ := json.Marshal(reqBody)
@@ -72,7 +74,7 @@ func TestHandler_CreatePet_ServiceError(t *testing.T) {
 
 	handler.CreatePet(rec, req)
 
-	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 }
 
 func TestHandler_GetPet_InvalidID(t *testing.T) {
@@ -87,9 +89,9 @@ func TestHandler_GetPet_InvalidID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect ID пpetитомца")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_GetPet_ZeroID(t *testing.T) {
@@ -104,15 +106,15 @@ func TestHandler_GetPet_ZeroID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "ID pet must be positive")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_GetPet_NotFound(t *testing.T) {
 	mockSvc := &mockPetService{
 		getPetByIDFunc: func(petID int) (*models.Pet, error) {
-			return nil, errors.New("pet not found")
+			return nil, ErrPetNotFound
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -138,9 +140,9 @@ func TestHandler_GetOwnerPets_InvalidID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect ID owner")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_GetOwnerPets_ZeroID(t *testing.T) {
@@ -158,7 +160,7 @@ func TestHandler_GetOwnerPets_ZeroID(t *testing.T) {
 
 func TestHandler_GetOwnerPets_ServiceError(t *testing.T) {
 	mockSvc := &mockPetService{
-		getPetsByOwnerFunc: func(ownerID int) ([]models.Pet, error) {
+		getPetsByOwnerFunc: func(ownerID int, q listing.Query) (*listing.Page[models.Pet], error) {
 			return nil, errors.New("database error")
 		},
 	}
@@ -225,9 +227,9 @@ func TestHandler_UpdatePet_InvalidJSON(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect data")
+	assert.Equal(t, "malformed_body", resp.Code)
 }
 
 func TestHandler_UpdatePet_ValidationError(t *testing.T) {
@@ -252,8 +254,8 @@ func TestHandler_UpdatePet_ValidationError(t *testing.T) {
 
 func TestHandler_UpdatePet_ServiceError(t *testing.T) {
 	mockSvc := &mockPetService{
-		updatePetFunc: func(petID int, name, petType string, age int, notes string) error {
-			return errors.New("pet not found")
+		updatePetFunc: func(actor authz.Actor, petID int, name, petType string, age int, notes string) error {
+			return ErrPetNotFound
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -271,7 +273,7 @@ func TestHandler_UpdatePet_ServiceError(t *testing.T) {
 
 	handler.UpdatePet(rec, req)
 
-	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
 }
 
 func TestHandler_DeletePet_InvalidID(t *testing.T) {
@@ -302,8 +304,8 @@ func TestHandler_DeletePet_ZeroID(t *testing.T) {
 
 func TestHandler_DeletePet_ServiceError(t *testing.T) {
 	mockSvc := &mockPetService{
-		deletePetFunc: func(petID int) error {
-			return errors.New("pet not found")
+		deletePetFunc: func(actor authz.Actor, petID int) error {
+			return ErrPetNotFound
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -314,48 +316,48 @@ func TestHandler_DeletePet_ServiceError(t *testing.T) {
 
 	handler.DeletePet(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
 }
 
 type mockPetService struct {
-	createPetFunc      func(int, string, string, int, string) (int, error)
+	createPetFunc      func(authz.Actor, string, string, int, string) (int, error)
 	getPetByIDFunc     func(int) (*models.Pet, error)
-	getPetsByOwnerFunc func(int) ([]models.Pet, error)
-	updatePetFunc      func(int, string, string, int, string) error
-	deletePetFunc      func(int) error
+	getPetsByOwnerFunc func(int, listing.Query) (*listing.Page[models.Pet], error)
+	updatePetFunc      func(authz.Actor, int, string, string, int, string) error
+	deletePetFunc      func(authz.Actor, int) error
 }
 
-func (m *mockPetService) CreatePet(ownerID int, name, petType string, age int, notes string) (int, error) {
+func (m *mockPetService) CreatePet(ctx context.Context, actor authz.Actor, name, petType string, age int, notes string) (int, error) {
 	if m.createPetFunc != nil {
-		return m.createPetFunc(ownerID, name, petType, age, notes)
+		return m.createPetFunc(actor, name, petType, age, notes)
 	}
 	return 1, nil
 }
 
-func (m *mockPetService) GetPetByID(petID int) (*models.Pet, error) {
+func (m *mockPetService) GetPetByID(ctx context.Context, petID int) (*models.Pet, error) {
 	if m.getPetByIDFunc != nil {
 		return m.getPetByIDFunc(petID)
 	}
 	return &models.Pet{PetID: petID}, nil
 }
 
-func (m *mockPetService) GetPetsByOwner(ownerID int) ([]models.Pet, error) {
+func (m *mockPetService) GetPetsByOwner(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Pet], error) {
 	if m.getPetsByOwnerFunc != nil {
-		return m.getPetsByOwnerFunc(ownerID)
+		return m.getPetsByOwnerFunc(ownerID, q)
 	}
-	return []models.Pet{}, nil
+	return ListSpec.Page(nil, q), nil
 }
 
-func (m *mockPetService) UpdatePet(petID int, name, petType string, age int, notes string) error {
+func (m *mockPetService) UpdatePet(ctx context.Context, actor authz.Actor, petID int, name, petType string, age int, notes string) error {
 	if m.updatePetFunc != nil {
-		return m.updatePetFunc(petID, name, petType, age, notes)
+		return m.updatePetFunc(actor, petID, name, petType, age, notes)
 	}
 	return nil
 }
 
-func (m *mockPetService) DeletePet(petID int) error {
+func (m *mockPetService) DeletePet(ctx context.Context, actor authz.Actor, petID int) error {
 	if m.deletePetFunc != nil {
-		return m.deletePetFunc(petID)
+		return m.deletePetFunc(actor, petID)
 	}
 	return nil
 }
@@ -1,18 +1,39 @@
 package pets
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 )
 
+var ErrPetNotFound = apperr.NotFound("pet_not_found", "pet not found")
+
 type Repository interface {
-	Create(pet *models.Pet) (int, error)
-	GetByID(petID int) (*models.Pet, error)
-	GetByOwnerID(ownerID int) ([]models.Pet, error)
-	Update(pet *models.Pet) error
-	Delete(petID int) error
+	Create(ctx context.Context, pet *models.Pet) (int, error)
+	GetByID(ctx context.Context, petID int) (*models.Pet, error)
+	GetByOwnerID(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Pet], error)
+	Update(ctx context.Context, pet *models.Pet) error
+	Delete(ctx context.Context, petID int) error
+}
+
+// ListSpec is how an owner's pet list can be sorted and filtered.
+var ListSpec = listing.Spec[models.Pet]{
+	IDColumn: "pet_id",
+	ID:       func(p models.Pet) int { return p.PetID },
+	Sorts: map[string]listing.Sort[models.Pet]{
+		"pet_id": {Column: "pet_id", Value: func(p models.Pet) interface{} { return p.PetID }},
+		"name":   {Column: "name", Value: func(p models.Pet) interface{} { return p.Name }},
+		"age":    {Column: "age", Value: func(p models.Pet) interface{} { return p.Age }},
+	},
+	DefaultSort: "pet_id",
+	Filters: map[string]listing.Filter{
+		"type": {Column: "type", Type: listing.Text},
+	},
 }
 
 type repository struct {
@@ -23,9 +44,12 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Create(pet *models.Pet) (int, error) {
+func (r *repository) Create(ctx context.Context, pet *models.Pet) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	var petID int
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		INSERT INTO pets (owner_id, name, type, age, notes)
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING pet_id
@@ -38,9 +62,12 @@ func (r *repository) Create(pet *models.Pet) (int, error) {
 	return petID, nil
 }
 
-func (r *repository) GetByID(petID int) (*models.Pet, error) {
+func (r *repository) GetByID(ctx context.Context, petID int) (*models.Pet, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	pet := &models.Pet{}
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT pet_id, owner_id, name, type, age, notes
 		FROM pets
 		WHERE pet_id = $1
@@ -54,7 +81,7 @@ func (r *repository) GetByID(petID int) (*models.Pet, error) {
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("pet not found")
+		return nil, ErrPetNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting pet: %w", err)
@@ -63,12 +90,16 @@ func (r *repository) GetByID(petID int) (*models.Pet, error) {
 	return pet, nil
 }
 
-func (r *repository) GetByOwnerID(ownerID int) ([]models.Pet, error) {
-	rows, err := r.db.Query(`
+func (r *repository) GetByOwnerID(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Pet], error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query, args := q.Apply(`
 		SELECT pet_id, owner_id, name, type, age, notes
 		FROM pets
-		WHERE owner_id = $1
-	`, ownerID)
+		WHERE owner_id = $1`, []interface{}{ownerID})
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting pets: %w", err)
@@ -91,12 +122,18 @@ func (r *repository) GetByOwnerID(ownerID int) ([]models.Pet, error) {
 		}
 		pets = append(pets, pet)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting pets: %w", err)
+	}
 
-	return pets, nil
+	return ListSpec.Page(pets, q), nil
 }
 
-func (r *repository) Update(pet *models.Pet) error {
-	_, err := r.db.Exec(`
+func (r *repository) Update(ctx context.Context, pet *models.Pet) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE pets
 		SET name = $1, type = $2, age = $3, notes = $4
 		WHERE pet_id = $5
@@ -109,8 +146,11 @@ func (r *repository) Update(pet *models.Pet) error {
 	return nil
 }
 
-func (r *repository) Delete(petID int) error {
-	_, err := r.db.Exec(`DELETE FROM pets WHERE pet_id = $1`, petID)
+func (r *repository) Delete(ctx context.Context, petID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM pets WHERE pet_id = $1`, petID)
 	if err != nil {
 		return fmt.Errorf("cannot delete pet: %w", err)
 	}
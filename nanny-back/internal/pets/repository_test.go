@@ -1,7 +1,9 @@
 package pets
 
 import (
+	"context"
 	"database/sql"
+	"net/url"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -34,7 +36,7 @@ func TestCreate_Success(t *testing.T) {
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"pet_id"}).AddRow(10))
 
-	id, err := repo.Create(pet)
+	id, err := repo.Create(context.Background(), pet)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 10, id)
@@ -68,7 +70,7 @@ func TestGetByID_Success(t *testing.T) {
 		WithArgs(10).
 		WillReturnRows(rows)
 
-	pet, err := repo.GetByID(10)
+	pet, err := repo.GetByID(context.Background(), 10)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, pet)
@@ -88,7 +90,7 @@ func TestGetByID_NotFound(t *testing.T) {
 		WithArgs(999).
 		WillReturnError(sql.ErrNoRows)
 
-	pet, err := repo.GetByID(999)
+	pet, err := repo.GetByID(context.Background(), 999)
 
 	assert.Error(t, err)
 	assert.Nil(t, pet)
@@ -113,16 +115,18 @@ func TestGetByOwnerID_Success(t *testing.T) {
 		AddRow(1, 5, "Buddy", "dog", 3, "friendly").
 		AddRow(2, 5, "Murka", "cat", 2, "calm")
 
-	mock.ExpectQuery(`FROM pets WHERE owner_id = \$1`).
-		WithArgs(5).
+	q, _ := ListSpec.Parse(url.Values{"limit": {"1"}})
+
+	mock.ExpectQuery(`FROM pets WHERE owner_id = \$1 ORDER BY pet_id ASC, pet_id ASC LIMIT \$2`).
+		WithArgs(5, 2).
 		WillReturnRows(rows)
 
-	pets, err := repo.GetByOwnerID(5)
+	page, err := repo.GetByOwnerID(context.Background(), 5, q)
 
 	assert.NoError(t, err)
-	assert.Len(t, pets, 2)
-	assert.Equal(t, "Buddy", pets[0].Name)
-	assert.Equal(t, "Murka", pets[1].Name)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "Buddy", page.Items[0].Name)
+	assert.NotEmpty(t, page.NextCursor)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -151,7 +155,7 @@ func TestUpdate_Success(t *testing.T) {
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Update(pet)
+	err = repo.Update(context.Background(), pet)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -168,7 +172,7 @@ func TestDelete_Success(t *testing.T) {
 		WithArgs(10).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Delete(10)
+	err = repo.Delete(context.Background(), 10)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
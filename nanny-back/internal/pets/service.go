@@ -1,19 +1,25 @@
 package pets
 
 import (
+	"context"
 	"fmt"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 )
 
 type Service interface {
-	CreatePet(ownerID int, name, petType string, age int, notes string) (int, error)
-	GetPetByID(petID int) (*models.Pet, error)
-	GetPetsByOwner(ownerID int) ([]models.Pet, error)
-	UpdatePet(petID int, name, petType string, age int, notes string) error
-	DeletePet(petID int) error
+	CreatePet(ctx context.Context, actor authz.Actor, name, petType string, age int, notes string) (int, error)
+	GetPetByID(ctx context.Context, petID int) (*models.Pet, error)
+	GetPetsByOwner(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Pet], error)
+	UpdatePet(ctx context.Context, actor authz.Actor, petID int, name, petType string, age int, notes string) error
+	DeletePet(ctx context.Context, actor authz.Actor, petID int) error
 }
 
+var ErrInvalidPetType = apperr.Validation("invalid_pet_type", "incorrect type of pet. Only: cat, dog, rodent")
+
 type service struct {
 	repo Repository
 }
@@ -22,21 +28,25 @@ func NewService(repo Repository) Service {
 	return &service{repo: repo}
 }
 
-func (s *service) CreatePet(ownerID int, name, petType string, age int, notes string) (int, error) {
+func (s *service) CreatePet(ctx context.Context, actor authz.Actor, name, petType string, age int, notes string) (int, error) {
+	if actor.UserID <= 0 {
+		return 0, authz.ErrForbidden
+	}
+
 	validTypes := map[string]bool{"cat": true, "dog": true, "rodent": true}
 	if !validTypes[petType] {
-		return 0, fmt.Errorf("incorrect type of pet. Only: cat, dog, rodent")
+		return 0, ErrInvalidPetType
 	}
 
 	pet := &models.Pet{
-		OwnerID: ownerID,
+		OwnerID: actor.UserID,
 		Name:    name,
 		Type:    petType,
 		Age:     age,
 		Notes:   notes,
 	}
 
-	petID, err := s.repo.Create(pet)
+	petID, err := s.repo.Create(ctx, pet)
 	if err != nil {
 		return 0, fmt.Errorf("error creating pet: %w", err)
 	}
@@ -44,18 +54,22 @@ func (s *service) CreatePet(ownerID int, name, petType string, age int, notes st
 	return petID, nil
 }
 
-func (s *service) GetPetByID(petID int) (*models.Pet, error) {
-	return s.repo.GetByID(petID)
+func (s *service) GetPetByID(ctx context.Context, petID int) (*models.Pet, error) {
+	return s.repo.GetByID(ctx, petID)
 }
 
-func (s *service) GetPetsByOwner(ownerID int) ([]models.Pet, error) {
-	return s.repo.GetByOwnerID(ownerID)
+func (s *service) GetPetsByOwner(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Pet], error) {
+	return s.repo.GetByOwnerID(ctx, ownerID, q)
 }
 
-func (s *service) UpdatePet(petID int, name, petType string, age int, notes string) error {
+func (s *service) UpdatePet(ctx context.Context, actor authz.Actor, petID int, name, petType string, age int, notes string) error {
 	validTypes := map[string]bool{"cat": true, "dog": true, "rodent": true}
 	if !validTypes[petType] {
-		return fmt.Errorf("incorrect type of pet. Only: cat, dog, rodent")
+		return ErrInvalidPetType
+	}
+
+	if err := s.checkOwnership(ctx, actor, petID); err != nil {
+		return err
 	}
 
 	pet := &models.Pet{
@@ -66,9 +80,26 @@ func (s *service) UpdatePet(petID int, name, petType string, age int, notes stri
 		Notes: notes,
 	}
 
-	return s.repo.Update(pet)
+	return s.repo.Update(ctx, pet)
+}
+
+func (s *service) DeletePet(ctx context.Context, actor authz.Actor, petID int) error {
+	if err := s.checkOwnership(ctx, actor, petID); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, petID)
 }
 
-func (s *service) DeletePet(petID int) error {
-	return s.repo.Delete(petID)
+func (s *service) checkOwnership(ctx context.Context, actor authz.Actor, petID int) error {
+	pet, err := s.repo.GetByID(ctx, petID)
+	if err != nil {
+		return err
+	}
+
+	if !actor.CanActAs(pet.OwnerID) {
+		return fmt.Errorf("pet belongs to another owner: %w", authz.ErrForbidden)
+	}
+
+	return nil
 }
@@ -1,25 +1,30 @@
 package pets
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 )
 
+var owner = authz.Actor{UserID: 10, Role: authz.RoleOwner}
+
 type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) Create(pet *models.Pet) (int, error) {
+func (m *MockRepository) Create(ctx context.Context, pet *models.Pet) (int, error) {
 	args := m.Called(pet)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockRepository) GetByID(petID int) (*models.Pet, error) {
+func (m *MockRepository) GetByID(ctx context.Context, petID int) (*models.Pet, error) {
 	args := m.Called(petID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -27,17 +32,20 @@ func (m *MockRepository) GetByID(petID int) (*models.Pet, error) {
 	return args.Get(0).(*models.Pet), args.Error(1)
 }
 
-func (m *MockRepository) GetByOwnerID(ownerID int) ([]models.Pet, error) {
-	args := m.Called(ownerID)
-	return args.Get(0).([]models.Pet), args.Error(1)
+func (m *MockRepository) GetByOwnerID(ctx context.Context, ownerID int, q listing.Query) (*listing.Page[models.Pet], error) {
+	args := m.Called(ownerID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[models.Pet]), args.Error(1)
 }
 
-func (m *MockRepository) Update(pet *models.Pet) error {
+func (m *MockRepository) Update(ctx context.Context, pet *models.Pet) error {
 	args := m.Called(pet)
 	return args.Error(0)
 }
 
-func (m *MockRepository) Delete(petID int) error {
+func (m *MockRepository) Delete(ctx context.Context, petID int) error {
 	args := m.Called(petID)
 	return args.Error(0)
 }
@@ -50,8 +58,8 @@ func TestCreatePet_Success(t *testing.T) {
 		On("Create", mock.Anything).
 		Return(1, nil)
 
-	petID, err := service.CreatePet(
-		1,
+	petID, err := service.CreatePet(context.Background(),
+		owner,
 		"Buddy",
 		"dog",
 		3,
@@ -67,8 +75,8 @@ func TestCreatePet_InvalidType(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
-	petID, err := service.CreatePet(
-		1,
+	petID, err := service.CreatePet(context.Background(),
+		owner,
 		"Buddy",
 		"dragon",
 		3,
@@ -96,7 +104,7 @@ func TestGetPetByID_Success(t *testing.T) {
 		On("GetByID", 1).
 		Return(expectedPet, nil)
 
-	pet, err := service.GetPetByID(1)
+	pet, err := service.GetPetByID(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedPet, pet)
@@ -111,7 +119,7 @@ func TestGetPetByID_NotFound(t *testing.T) {
 		On("GetByID", 99).
 		Return(nil, errors.New("pet not found"))
 
-	pet, err := service.GetPetByID(99)
+	pet, err := service.GetPetByID(context.Background(), 99)
 
 	assert.Error(t, err)
 	assert.Nil(t, pet)
@@ -121,20 +129,20 @@ func TestGetPetsByOwner_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
+	q, _ := ListSpec.Parse(nil)
 	expectedPets := []models.Pet{
 		{PetID: 1, OwnerID: 5, Name: "Catty"},
 		{PetID: 2, OwnerID: 5, Name: "Doggy"},
 	}
 
 	mockRepo.
-		On("GetByOwnerID", 5).
-		Return(expectedPets, nil)
+		On("GetByOwnerID", 5, q).
+		Return(ListSpec.Page(expectedPets, q), nil)
 
-	pets, err := service.GetPetsByOwner(5)
+	page, err := service.GetPetsByOwner(context.Background(), 5, q)
 
 	assert.NoError(t, err)
-	assert.Len(t, pets, 2)
-	assert.Equal(t, expectedPets, pets)
+	assert.Equal(t, expectedPets, page.Items)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -142,11 +150,15 @@ func TestUpdatePet_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Pet{PetID: 1, OwnerID: 10}, nil)
 	mockRepo.
 		On("Update", mock.Anything).
 		Return(nil)
 
-	err := service.UpdatePet(
+	err := service.UpdatePet(context.Background(),
+		owner,
 		1,
 		"NewName",
 		"cat",
@@ -162,7 +174,8 @@ func TestUpdatePet_InvalidType(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
-	err := service.UpdatePet(
+	err := service.UpdatePet(context.Background(),
+		owner,
 		1,
 		"Name",
 		"dragon",
@@ -177,12 +190,43 @@ func TestDeletePet_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Pet{PetID: 1, OwnerID: 10}, nil)
 	mockRepo.
 		On("Delete", 1).
 		Return(nil)
 
-	err := service.DeletePet(1)
+	err := service.DeletePet(context.Background(), owner, 1)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestDeletePet_OtherOwner(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Pet{PetID: 1, OwnerID: 77}, nil)
+
+	err := service.DeletePet(context.Background(), owner, 1)
+
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+	mockRepo.AssertNotCalled(t, "Delete", 1)
+}
+
+func TestUpdatePet_OtherOwner(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Pet{PetID: 1, OwnerID: 77}, nil)
+
+	err := service.UpdatePet(context.Background(), owner, 1, "Name", "cat", 4, "")
+
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
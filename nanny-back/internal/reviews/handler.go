@@ -1,13 +1,11 @@
 package reviews
 
 import (
-	"encoding/json"
 	"net/http"
-	"strconv"
 
-	"nanny-backend/pkg/validator"
-
-	"github.com/gorilla/mux"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/middleware"
+	"nanny-backend/internal/common/models"
 )
 
 type Handler struct {
@@ -18,206 +16,309 @@ func NewHandler(service Service) *Handler {
 	return &Handler{service: service}
 }
 
+// ScoresRequest holds the optional per-dimension scores of a review.
+type ScoresRequest struct {
+	Punctuality   *int `json:"punctuality,omitempty" validate:"omitempty,gte=1,lte=5"`
+	Communication *int `json:"communication,omitempty" validate:"omitempty,gte=1,lte=5"`
+	PetCare       *int `json:"pet_care,omitempty" validate:"omitempty,gte=1,lte=5"`
+}
+
+func (s ScoresRequest) scores() models.ReviewScores {
+	return models.ReviewScores(s)
+}
+
 type CreateReviewRequest struct {
-	BookingID int    `json:"booking_id" validate:"required,gt=0"`
-	OwnerID   int    `json:"owner_id" validate:"required,gt=0"`
-	SitterID  int    `json:"sitter_id" validate:"required,gt=0"`
-	Rating    int    `json:"rating" validate:"required,gte=1,lte=5"`
-	Comment   string `json:"comment,omitempty" validate:"max=1000"`
+	BookingID int `json:"booking_id" validate:"required,gt=0"`
+	SitterID  int `json:"sitter_id" validate:"required,gt=0"`
+	Rating    int `json:"rating" validate:"required,gte=1,lte=5"`
+	ScoresRequest
+	Comment string `json:"comment,omitempty" validate:"max=1000"`
 }
 
 type UpdateReviewRequest struct {
-	Rating  int    `json:"rating" validate:"required,gte=1,lte=5"`
+	Rating int `json:"rating" validate:"required,gte=1,lte=5"`
+	ScoresRequest
 	Comment string `json:"comment,omitempty" validate:"max=1000"`
 }
 
+type ReplyRequest struct {
+	Reply string `json:"reply" validate:"required,max=1000"`
+}
+
+type FlagRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ModerateRequest struct {
+	Action string `json:"action" validate:"required,oneof=hide restore delete"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
 func (h *Handler) CreateReview(w http.ResponseWriter, r *http.Request) {
 	var req CreateReviewRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if err := validator.Validate(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	reviewID, err := h.service.CreateReview(
+	reviewID, err := h.service.CreateReview(r.Context(),
+		middleware.ActorFromContext(r.Context()),
 		req.BookingID,
-		req.OwnerID,
 		req.SitterID,
 		req.Rating,
+		req.scores(),
 		req.Comment,
 	)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+	httpx.JSON(w, http.StatusCreated, map[string]interface{}{
 		"message":   "review created succesfully",
 		"review_id": reviewID,
 	})
 }
 
 func (h *Handler) GetReview(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	reviewID, err := strconv.Atoi(vars["id"])
+	reviewID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID review")
-		return
-	}
-
-	if reviewID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID review must be positive")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	review, err := h.service.GetReview(reviewID)
+	review, err := h.service.GetReview(r.Context(), reviewID)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, review)
+	httpx.JSON(w, http.StatusOK, review)
 }
 
 func (h *Handler) GetSitterReviews(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	sitterID, err := strconv.Atoi(vars["sitter_id"])
+	sitterID, err := httpx.PathID(r, "sitter_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID nanny")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if sitterID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID nanny must be positive")
+	q, err := ListSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	reviews, err := h.service.GetSitterReviews(sitterID)
+	page, err := h.service.GetSitterReviews(r.Context(), sitterID, q)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, reviews)
+	httpx.JSON(w, http.StatusOK, page)
 }
 
 func (h *Handler) GetSitterRating(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	sitterID, err := strconv.Atoi(vars["sitter_id"])
+	sitterID, err := httpx.PathID(r, "sitter_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID nanny")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if sitterID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID nanny must be positive")
-		return
-	}
-
-	avgRating, count, err := h.service.GetSitterRating(sitterID)
+	avgRating, count, err := h.service.GetSitterRating(r.Context(), sitterID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+	httpx.JSON(w, http.StatusOK, map[string]interface{}{
 		"sitter_id":      sitterID,
 		"average_rating": avgRating,
 		"review_count":   count,
 	})
 }
 
-func (h *Handler) GetBookingReview(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	bookingID, err := strconv.Atoi(vars["booking_id"])
+func (h *Handler) GetRatingSummary(w http.ResponseWriter, r *http.Request) {
+	sitterID, err := httpx.PathID(r, "sitter_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID booking")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if bookingID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID booking must be positive")
+	summary, err := h.service.GetRatingSummary(r.Context(), sitterID)
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	review, err := h.service.GetBookingReview(bookingID)
+	httpx.JSON(w, http.StatusOK, summary)
+}
+
+func (h *Handler) GetBookingReview(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := httpx.PathID(r, "booking_id")
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, review)
+	review, err := h.service.GetBookingReview(r.Context(), bookingID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, review)
 }
 
 func (h *Handler) UpdateReview(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	reviewID, err := strconv.Atoi(vars["id"])
+	reviewID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID review")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if reviewID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID review must be positive")
+	var req UpdateReviewRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	var req UpdateReviewRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
+	actor := middleware.ActorFromContext(r.Context())
+
+	err = h.service.UpdateReview(r.Context(), actor, reviewID, req.Rating, req.scores(), req.Comment)
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if err := validator.Validate(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "review refreshed succesfully",
+	})
+}
+
+func (h *Handler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err = h.service.UpdateReview(reviewID, req.Rating, req.Comment)
+	actor := middleware.ActorFromContext(r.Context())
+
+	err = h.service.DeleteReview(r.Context(), actor, reviewID)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "review refreshed succesfully",
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "review deleted successfully",
 	})
 }
 
-func (h *Handler) DeleteReview(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	reviewID, err := strconv.Atoi(vars["id"])
+func (h *Handler) ReplyToReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID review")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	if reviewID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "ID review must be positive")
+	var req ReplyRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err = h.service.DeleteReview(reviewID)
+	actor := middleware.ActorFromContext(r.Context())
+
+	if err := h.service.ReplyToReview(r.Context(), actor, reviewID, req.Reply); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusCreated, map[string]string{
+		"message": "reply posted",
+	})
+}
+
+func (h *Handler) FlagReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "review deleted successfully",
+	var req FlagRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	actor := middleware.ActorFromContext(r.Context())
+
+	if err := h.service.FlagReview(r.Context(), actor, reviewID, req.Reason); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusAccepted, map[string]string{
+		"message": "review sent to moderation",
 	})
 }
 
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
+func (h *Handler) GetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	q, err := QueueSpec.Parse(r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.GetModerationQueue(r.Context(), q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, page)
 }
 
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(payload)
+func (h *Handler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	var req ModerateRequest
+	if err := httpx.Decode(r, &req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	actor := middleware.ActorFromContext(r.Context())
+
+	if err := h.service.ModerateReview(r.Context(), actor, reviewID, req.Action, req.Reason); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{
+		"message": "review moderated",
+	})
+}
+
+func (h *Handler) GetModerationHistory(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := httpx.PathID(r, "id")
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	actions, err := h.service.GetModerationHistory(r.Context(), reviewID)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, actions)
 }
@@ -2,7 +2,11 @@ package reviews
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/middleware"
 	"nanny-backend/internal/common/models"
 	"net/http"
 	"net/http/httptest"
@@ -17,12 +21,12 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) CreateReview(bookingID, ownerID, sitterID, rating int, comment string) (int, error) {
-	args := m.Called(bookingID, ownerID, sitterID, rating, comment)
+func (m *MockService) CreateReview(ctx context.Context, actor authz.Actor, bookingID, sitterID, rating int, scores models.ReviewScores, comment string) (int, error) {
+	args := m.Called(actor, bookingID, sitterID, rating, scores, comment)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockService) GetReview(reviewID int) (*models.Review, error) {
+func (m *MockService) GetReview(ctx context.Context, reviewID int) (*models.Review, error) {
 	args := m.Called(reviewID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -30,12 +34,15 @@ func (m *MockService) GetReview(reviewID int) (*models.Review, error) {
 	return args.Get(0).(*models.Review), args.Error(1)
 }
 
-func (m *MockService) GetSitterReviews(sitterID int) ([]models.Review, error) {
-	args := m.Called(sitterID)
-	return args.Get(0).([]models.Review), args.Error(1)
+func (m *MockService) GetSitterReviews(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Review], error) {
+	args := m.Called(sitterID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[models.Review]), args.Error(1)
 }
 
-func (m *MockService) GetBookingReview(bookingID int) (*models.Review, error) {
+func (m *MockService) GetBookingReview(ctx context.Context, bookingID int) (*models.Review, error) {
 	args := m.Called(bookingID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -43,28 +50,68 @@ func (m *MockService) GetBookingReview(bookingID int) (*models.Review, error) {
 	return args.Get(0).(*models.Review), args.Error(1)
 }
 
-func (m *MockService) UpdateReview(reviewID, rating int, comment string) error {
-	args := m.Called(reviewID, rating, comment)
+func (m *MockService) UpdateReview(ctx context.Context, actor authz.Actor, reviewID, rating int, scores models.ReviewScores, comment string) error {
+	args := m.Called(actor, reviewID, rating, scores, comment)
 	return args.Error(0)
 }
 
-func (m *MockService) DeleteReview(reviewID int) error {
-	args := m.Called(reviewID)
+func (m *MockService) DeleteReview(ctx context.Context, actor authz.Actor, reviewID int) error {
+	args := m.Called(actor, reviewID)
 	return args.Error(0)
 }
 
-func (m *MockService) GetSitterRating(sitterID int) (float64, int, error) {
+func (m *MockService) GetSitterRating(ctx context.Context, sitterID int) (float64, int, error) {
 	args := m.Called(sitterID)
 	return args.Get(0).(float64), args.Int(1), args.Error(2)
 }
 
+func (m *MockService) GetRatingSummary(ctx context.Context, sitterID int) (*RatingSummary, error) {
+	args := m.Called(sitterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RatingSummary), args.Error(1)
+}
+
+func (m *MockService) ReplyToReview(ctx context.Context, actor authz.Actor, reviewID int, reply string) error {
+	args := m.Called(actor, reviewID, reply)
+	return args.Error(0)
+}
+
+func (m *MockService) FlagReview(ctx context.Context, actor authz.Actor, reviewID int, reason string) error {
+	args := m.Called(actor, reviewID, reason)
+	return args.Error(0)
+}
+
+func (m *MockService) GetModerationQueue(ctx context.Context, q listing.Query) (*listing.Page[FlaggedReview], error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[FlaggedReview]), args.Error(1)
+}
+
+func (m *MockService) ModerateReview(ctx context.Context, actor authz.Actor, reviewID int, action, reason string) error {
+	args := m.Called(actor, reviewID, action, reason)
+	return args.Error(0)
+}
+
+func (m *MockService) GetModerationHistory(ctx context.Context, reviewID int) ([]ModerationAction, error) {
+	args := m.Called(reviewID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ModerationAction), args.Error(1)
+}
+
+var testOwner = authz.Actor{UserID: 2, Role: authz.RoleOwner}
+
 func TestHandler_CreateReview_Success(t *testing.T) {
 	mockService := new(MockService)
 	handler := NewHandler(mockService)
 
 	reqBody := CreateReviewRequest{
 		BookingID: 1,
-		OwnerID:   2,
 		SitterID:  3,
 		Rating:    5,
 		Comment:   "Отличная няня",
@@ -73,10 +120,11 @@ func TestHandler_CreateReview_Success(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/reviews", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req = middleware.WithActor(req, testOwner)
 	rec := httptest.NewRecorder()
 
 	mockService.
-		On("CreateReview", 1, 2, 3, 5, "Отличная няня").
+		On("CreateReview", testOwner, 1, 3, 5, models.ReviewScores{}, "Отличная няня").
 		Return(10, nil)
 
 	handler.CreateReview(rec, req)
@@ -128,8 +176,8 @@ func TestHandler_GetSitterReviews_Success(t *testing.T) {
 	}
 
 	mockService.
-		On("GetSitterReviews", 3).
-		Return(reviews, nil)
+		On("GetSitterReviews", 3, mock.AnythingOfType("listing.Query")).
+		Return(&listing.Page[models.Review]{Items: reviews}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/sitters/3/reviews", nil)
 	rec := httptest.NewRecorder()
@@ -142,6 +190,21 @@ func TestHandler_GetSitterReviews_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestHandler_GetSitterReviews_InvalidSort(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/sitters/3/reviews?sort=comment", nil)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/sitters/{sitter_id}/reviews", handler.GetSitterReviews)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "GetSitterReviews", mock.Anything, mock.Anything)
+}
+
 func TestHandler_UpdateReview_Success(t *testing.T) {
 	mockService := new(MockService)
 	handler := NewHandler(mockService)
@@ -154,10 +217,11 @@ func TestHandler_UpdateReview_Success(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPut, "/reviews/5", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req = middleware.WithActor(req, testOwner)
 	rec := httptest.NewRecorder()
 
 	mockService.
-		On("UpdateReview", 5, 4, "Хорошо").
+		On("UpdateReview", testOwner, 5, 4, models.ReviewScores{}, "Хорошо").
 		Return(nil)
 
 	router := mux.NewRouter()
@@ -173,10 +237,11 @@ func TestHandler_DeleteReview_Success(t *testing.T) {
 	handler := NewHandler(mockService)
 
 	mockService.
-		On("DeleteReview", 7).
+		On("DeleteReview", testOwner, 7).
 		Return(nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/reviews/7", nil)
+	req = middleware.WithActor(req, testOwner)
 	rec := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -205,3 +270,92 @@ func TestHandler_GetSitterRating_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestHandler_GetRatingSummary_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.
+		On("GetRatingSummary", 3).
+		Return(&RatingSummary{
+			SitterID:      3,
+			AverageRating: 4.5,
+			ReviewCount:   2,
+			Score:         4.14,
+			Histogram:     map[int]int{1: 0, 2: 0, 3: 0, 4: 1, 5: 1},
+			ByServiceType: []ServiceTypeRating{{ServiceType: "walking", AverageRating: 4.5, ReviewCount: 2}},
+		}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/sitters/3/rating/summary", nil)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/sitters/{sitter_id}/rating/summary", handler.GetRatingSummary)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]interface{}{"1": 0.0, "2": 0.0, "3": 0.0, "4": 1.0, "5": 1.0}, resp["histogram"])
+	assert.Equal(t, 4.14, resp["score"])
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_DeleteReview_Forbidden(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.
+		On("DeleteReview", testOwner, 7).
+		Return(authz.ErrForbidden)
+
+	req := httptest.NewRequest(http.MethodDelete, "/reviews/7", nil)
+	req = middleware.WithActor(req, testOwner)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/reviews/{id}", handler.DeleteReview)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ReplyToReview_AlreadyReplied(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	sitter := authz.Actor{UserID: 3, Role: authz.RoleSitter}
+	mockService.
+		On("ReplyToReview", sitter, 7, "Thank you!").
+		Return(ErrReplyExists)
+
+	req := httptest.NewRequest(http.MethodPost, "/reviews/7/reply", bytes.NewBufferString(`{"reply": "Thank you!"}`))
+	req = middleware.WithActor(req, sitter)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/reviews/{id}/reply", handler.ReplyToReview)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ModerateReview_UnknownAction(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	admin := authz.Actor{UserID: 5, Role: authz.RoleAdmin}
+	req := httptest.NewRequest(http.MethodPost, "/admin/reviews/7/moderate", bytes.NewBufferString(`{"action": "ban", "reason": "abusive"}`))
+	req = middleware.WithActor(req, admin)
+	rec := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/reviews/{id}/moderate", handler.ModerateReview)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "ModerateReview", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
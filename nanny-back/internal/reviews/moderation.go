@@ -0,0 +1,105 @@
+package reviews
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/models"
+)
+
+// Admin decisions on a flagged review.
+const (
+	ActionHide    = "hide"
+	ActionRestore = "restore"
+	ActionDelete  = "delete"
+)
+
+var ErrInvalidAction = apperr.Validation("invalid_action", "action must be hide, restore or delete")
+
+// FlaggedReview is a review in the moderation queue with the sitter's
+// reason for reporting it.
+type FlaggedReview struct {
+	models.Review
+	FlagReason string    `json:"flag_reason"`
+	FlaggedAt  time.Time `json:"flagged_at"`
+}
+
+// ModerationAction is one admin decision. AdminID is nil once the admin's
+// account is gone.
+type ModerationAction struct {
+	ActionID  int       `json:"action_id"`
+	ReviewID  int       `json:"review_id"`
+	AdminID   *int      `json:"admin_id,omitempty"`
+	Action    string    `json:"action"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReplyToReview posts the sitter's public answer; there is one per review.
+func (s *service) ReplyToReview(ctx context.Context, actor authz.Actor, reviewID int, reply string) error {
+	if _, err := s.sitterReview(ctx, actor, reviewID); err != nil {
+		return err
+	}
+
+	return s.repo.SetReply(ctx, reviewID, reply)
+}
+
+// FlagReview sends a review about the sitter to the admins.
+func (s *service) FlagReview(ctx context.Context, actor authz.Actor, reviewID int, reason string) error {
+	if _, err := s.sitterReview(ctx, actor, reviewID); err != nil {
+		return err
+	}
+
+	return s.repo.Flag(ctx, reviewID, reason)
+}
+
+func (s *service) GetModerationQueue(ctx context.Context, q listing.Query) (*listing.Page[FlaggedReview], error) {
+	return s.repo.GetFlagged(ctx, q)
+}
+
+// ModerateReview hides, restores or deletes a review and records why.
+func (s *service) ModerateReview(ctx context.Context, actor authz.Actor, reviewID int, action, reason string) error {
+	if !actor.IsAdmin() {
+		return authz.ErrForbidden
+	}
+
+	switch action {
+	case ActionHide, ActionRestore, ActionDelete:
+	default:
+		return ErrInvalidAction
+	}
+
+	adminID := actor.UserID
+	return s.repo.Moderate(ctx, &ModerationAction{
+		ReviewID: reviewID,
+		AdminID:  &adminID,
+		Action:   action,
+		Reason:   reason,
+	})
+}
+
+func (s *service) GetModerationHistory(ctx context.Context, reviewID int) ([]ModerationAction, error) {
+	return s.repo.GetModerationHistory(ctx, reviewID)
+}
+
+// sitterReview loads a visible review written about actor.
+func (s *service) sitterReview(ctx context.Context, actor authz.Actor, reviewID int) (*models.Review, error) {
+	review, err := s.repo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.Hidden {
+		return nil, ErrReviewNotFound
+	}
+
+	if actor.UserID <= 0 || review.SitterID != actor.UserID {
+		return nil, fmt.Errorf("review is about another nanny: %w", authz.ErrForbidden)
+	}
+
+	return review, nil
+}
@@ -1,20 +1,105 @@
 package reviews
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is raised by reviews_booking_unique.
+const uniqueViolation = "23505"
+
+var (
+	ErrReviewNotFound  = apperr.NotFound("review_not_found", "review not found")
+	ErrBookingNotFound = apperr.NotFound("booking_not_found", "booking not found")
+	ErrReviewExists    = apperr.Conflict("review_exists", "review for this booking already exists")
+	ErrReplyExists     = apperr.Conflict("reply_exists", "the review already has a reply")
+	ErrAlreadyFlagged  = apperr.Conflict("review_already_flagged", "the review is already waiting for moderation")
 )
 
 type Repository interface {
-	Create(review *models.Review) (int, error)
-	GetByID(reviewID int) (*models.Review, error)
-	GetBySitterID(sitterID int) ([]models.Review, error)
-	GetByBookingID(bookingID int) (*models.Review, error)
-	Update(review *models.Review) error
-	Delete(reviewID int) error
-	GetSitterRating(sitterID int) (float64, int, error)
+	Create(ctx context.Context, review *models.Review) (int, error)
+	GetByID(ctx context.Context, reviewID int) (*models.Review, error)
+	GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Review], error)
+	GetByBookingID(ctx context.Context, bookingID int) (*models.Review, error)
+	Update(ctx context.Context, review *models.Review) error
+	Delete(ctx context.Context, reviewID int) error
+	GetSitterRating(ctx context.Context, sitterID int) (float64, int, error)
+	GetRatingSummary(ctx context.Context, sitterID int, since time.Time) (*RatingSummary, error)
+	GetBooking(ctx context.Context, bookingID int) (*models.Booking, error)
+	BookingPaid(ctx context.Context, bookingID int) (bool, error)
+
+	SetReply(ctx context.Context, reviewID int, reply string) error
+	Flag(ctx context.Context, reviewID int, reason string) error
+	GetFlagged(ctx context.Context, q listing.Query) (*listing.Page[FlaggedReview], error)
+	Moderate(ctx context.Context, action *ModerationAction) error
+	GetModerationHistory(ctx context.Context, reviewID int) ([]ModerationAction, error)
+}
+
+// ListSpec is how a sitter's review list can be sorted and filtered.
+var ListSpec = listing.Spec[models.Review]{
+	IDColumn: "review_id",
+	ID:       func(r models.Review) int { return r.ReviewID },
+	Sorts: map[string]listing.Sort[models.Review]{
+		"created_at": {Column: "created_at", Value: func(r models.Review) interface{} { return r.CreatedAt }},
+		"rating":     {Column: "rating", Value: func(r models.Review) interface{} { return r.Rating }},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]listing.Filter{
+		"rating":     {Column: "rating", Type: listing.Int},
+		"created_at": {Column: "created_at", Type: listing.Time},
+	},
+}
+
+// QueueSpec is how the moderation queue can be sorted; oldest flags first
+// by default.
+var QueueSpec = listing.Spec[FlaggedReview]{
+	IDColumn: "review_id",
+	ID:       func(r FlaggedReview) int { return r.ReviewID },
+	Sorts: map[string]listing.Sort[FlaggedReview]{
+		"flagged_at": {Column: "flagged_at", Value: func(r FlaggedReview) interface{} { return r.FlaggedAt }},
+	},
+	DefaultSort: "flagged_at",
+	Filters: map[string]listing.Filter{
+		"sitter_id": {Column: "sitter_id", Type: listing.Int},
+	},
+}
+
+// reviewColumns is selected by every query that scans with scanReview.
+const reviewColumns = `review_id, booking_id, owner_id, sitter_id, rating, comment, created_at,
+	COALESCE(reply, ''), replied_at, hidden_at IS NOT NULL,
+	punctuality, communication, pet_care, verified`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReview(row scanner, review *models.Review, extra ...interface{}) error {
+	return row.Scan(append([]interface{}{
+		&review.ReviewID,
+		&review.BookingID,
+		&review.OwnerID,
+		&review.SitterID,
+		&review.Rating,
+		&review.Comment,
+		&review.CreatedAt,
+		&review.Reply,
+		&review.RepliedAt,
+		&review.Hidden,
+		&review.Punctuality,
+		&review.Communication,
+		&review.PetCare,
+		&review.Verified,
+	}, extra...)...)
 }
 
 type repository struct {
@@ -25,14 +110,23 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Create(review *models.Review) (int, error) {
+func (r *repository) Create(ctx context.Context, review *models.Review) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	var reviewID int
-	err := r.db.QueryRow(`
-		INSERT INTO reviews (booking_id, owner_id, sitter_id, rating, comment)
-		VALUES ($1, $2, $3, $4, $5)
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO reviews (booking_id, owner_id, sitter_id, rating, punctuality, communication, pet_care, comment, verified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING review_id
-	`, review.BookingID, review.OwnerID, review.SitterID, review.Rating, review.Comment).Scan(&reviewID)
+	`, review.BookingID, review.OwnerID, review.SitterID, review.Rating,
+		review.Punctuality, review.Communication, review.PetCare, review.Comment, review.Verified,
+	).Scan(&reviewID)
 
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return 0, ErrReviewExists
+	}
 	if err != nil {
 		return 0, fmt.Errorf("could not create a review: %w", err)
 	}
@@ -40,24 +134,19 @@ func (r *repository) Create(review *models.Review) (int, error) {
 	return reviewID, nil
 }
 
-func (r *repository) GetByID(reviewID int) (*models.Review, error) {
+func (r *repository) GetByID(ctx context.Context, reviewID int) (*models.Review, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	review := &models.Review{}
-	err := r.db.QueryRow(`
-		SELECT review_id, booking_id, owner_id, sitter_id, rating, comment, created_at
+	err := scanReview(r.db.QueryRowContext(ctx, `
+		SELECT `+reviewColumns+`
 		FROM reviews
 		WHERE review_id = $1
-	`, reviewID).Scan(
-		&review.ReviewID,
-		&review.BookingID,
-		&review.OwnerID,
-		&review.SitterID,
-		&review.Rating,
-		&review.Comment,
-		&review.CreatedAt,
-	)
+	`, reviewID), review)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("review not found")
+		return nil, ErrReviewNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting review: %w", err)
@@ -66,13 +155,17 @@ func (r *repository) GetByID(reviewID int) (*models.Review, error) {
 	return review, nil
 }
 
-func (r *repository) GetBySitterID(sitterID int) ([]models.Review, error) {
-	rows, err := r.db.Query(`
-		SELECT review_id, booking_id, owner_id, sitter_id, rating, comment, created_at
+// GetBySitterID lists the sitter's visible reviews.
+func (r *repository) GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Review], error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query, args := q.Apply(`
+		SELECT `+reviewColumns+`
 		FROM reviews
-		WHERE sitter_id = $1
-		ORDER BY created_at DESC
-	`, sitterID)
+		WHERE sitter_id = $1 AND hidden_at IS NULL`, []interface{}{sitterID})
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting review: %w", err)
@@ -82,42 +175,31 @@ func (r *repository) GetBySitterID(sitterID int) ([]models.Review, error) {
 	var reviews []models.Review
 	for rows.Next() {
 		var review models.Review
-		err := rows.Scan(
-			&review.ReviewID,
-			&review.BookingID,
-			&review.OwnerID,
-			&review.SitterID,
-			&review.Rating,
-			&review.Comment,
-			&review.CreatedAt,
-		)
-		if err != nil {
+		if err := scanReview(rows, &review); err != nil {
 			return nil, fmt.Errorf("error scanning review: %w", err)
 		}
 		reviews = append(reviews, review)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting review: %w", err)
+	}
 
-	return reviews, nil
+	return ListSpec.Page(reviews, q), nil
 }
 
-func (r *repository) GetByBookingID(bookingID int) (*models.Review, error) {
+func (r *repository) GetByBookingID(ctx context.Context, bookingID int) (*models.Review, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	review := &models.Review{}
-	err := r.db.QueryRow(`
-		SELECT review_id, booking_id, owner_id, sitter_id, rating, comment, created_at
+	err := scanReview(r.db.QueryRowContext(ctx, `
+		SELECT `+reviewColumns+`
 		FROM reviews
 		WHERE booking_id = $1
-	`, bookingID).Scan(
-		&review.ReviewID,
-		&review.BookingID,
-		&review.OwnerID,
-		&review.SitterID,
-		&review.Rating,
-		&review.Comment,
-		&review.CreatedAt,
-	)
+	`, bookingID), review)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("review not found")
+		return nil, ErrReviewNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting review: %w", err)
@@ -126,12 +208,15 @@ func (r *repository) GetByBookingID(bookingID int) (*models.Review, error) {
 	return review, nil
 }
 
-func (r *repository) Update(review *models.Review) error {
-	_, err := r.db.Exec(`
+func (r *repository) Update(ctx context.Context, review *models.Review) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE reviews
-		SET rating = $1, comment = $2
-		WHERE review_id = $3
-	`, review.Rating, review.Comment, review.ReviewID)
+		SET rating = $1, punctuality = $2, communication = $3, pet_care = $4, comment = $5
+		WHERE review_id = $6
+	`, review.Rating, review.Punctuality, review.Communication, review.PetCare, review.Comment, review.ReviewID)
 
 	if err != nil {
 		return fmt.Errorf("could not update review: %w", err)
@@ -140,22 +225,29 @@ func (r *repository) Update(review *models.Review) error {
 	return nil
 }
 
-func (r *repository) Delete(reviewID int) error {
-	_, err := r.db.Exec(`DELETE FROM reviews WHERE review_id = $1`, reviewID)
+func (r *repository) Delete(ctx context.Context, reviewID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM reviews WHERE review_id = $1`, reviewID)
 	if err != nil {
 		return fmt.Errorf("could not delete a review: %w", err)
 	}
 	return nil
 }
 
-func (r *repository) GetSitterRating(sitterID int) (float64, int, error) {
+// GetSitterRating averages the sitter's visible reviews.
+func (r *repository) GetSitterRating(ctx context.Context, sitterID int) (float64, int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	var avgRating sql.NullFloat64
 	var count int
 
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT AVG(rating), COUNT(*)
 		FROM reviews
-		WHERE sitter_id = $1
+		WHERE sitter_id = $1 AND hidden_at IS NULL
 	`, sitterID).Scan(&avgRating, &count)
 
 	if err != nil {
@@ -168,3 +260,304 @@ func (r *repository) GetSitterRating(sitterID int) (float64, int, error) {
 
 	return avgRating.Float64, count, nil
 }
+
+// GetRatingSummary reads the star counts, the per service type averages
+// and the score and dimension totals kept in sitter_stats. Reviews posted
+// at or after since make up the recent average.
+func (r *repository) GetRatingSummary(ctx context.Context, sitterID int, since time.Time) (*RatingSummary, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	summary := &RatingSummary{
+		SitterID:      sitterID,
+		Histogram:     map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+		Recent:        PeriodRating{Since: since},
+		ByServiceType: []ServiceTypeRating{},
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rating, COUNT(*), COUNT(*) FILTER (WHERE created_at >= $2)
+		FROM reviews
+		WHERE sitter_id = $1 AND hidden_at IS NULL
+		GROUP BY rating
+	`, sitterID, since)
+	if err != nil {
+		return nil, fmt.Errorf("error counting ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var total, recentTotal int
+	for rows.Next() {
+		var rating, count, recent int
+		if err := rows.Scan(&rating, &count, &recent); err != nil {
+			return nil, fmt.Errorf("error scanning rating count: %w", err)
+		}
+		summary.Histogram[rating] = count
+		summary.ReviewCount += count
+		summary.Recent.ReviewCount += recent
+		total += rating * count
+		recentTotal += rating * recent
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error counting ratings: %w", err)
+	}
+
+	if summary.ReviewCount > 0 {
+		summary.AverageRating = float64(total) / float64(summary.ReviewCount)
+	}
+	if summary.Recent.ReviewCount > 0 {
+		summary.Recent.AverageRating = float64(recentTotal) / float64(summary.Recent.ReviewCount)
+	}
+
+	typeRows, err := r.db.QueryContext(ctx, `
+		SELECT s.type, AVG(r.rating)::float8, COUNT(*)
+		FROM reviews r
+		JOIN bookings b ON b.booking_id = r.booking_id
+		JOIN services s ON s.service_id = b.service_id
+		WHERE r.sitter_id = $1 AND r.hidden_at IS NULL
+		GROUP BY s.type
+		ORDER BY s.type
+	`, sitterID)
+	if err != nil {
+		return nil, fmt.Errorf("error averaging ratings by service type: %w", err)
+	}
+	defer typeRows.Close()
+
+	for typeRows.Next() {
+		var rating ServiceTypeRating
+		if err := typeRows.Scan(&rating.ServiceType, &rating.AverageRating, &rating.ReviewCount); err != nil {
+			return nil, fmt.Errorf("error scanning service type rating: %w", err)
+		}
+		summary.ByServiceType = append(summary.ByServiceType, rating)
+	}
+	if err := typeRows.Err(); err != nil {
+		return nil, fmt.Errorf("error averaging ratings by service type: %w", err)
+	}
+
+	// A sitter nobody has reviewed yet has no stats row and sits at the prior.
+	var punctuality, communication, petCare [2]int
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(ss.score, sitter_score(0, 0)), COALESCE(ss.verified_count, 0),
+			COALESCE(ss.punctuality_sum, 0), COALESCE(ss.punctuality_count, 0),
+			COALESCE(ss.communication_sum, 0), COALESCE(ss.communication_count, 0),
+			COALESCE(ss.pet_care_sum, 0), COALESCE(ss.pet_care_count, 0)
+		FROM (SELECT $1::int AS sitter_id) q
+		LEFT JOIN sitter_stats ss ON ss.sitter_id = q.sitter_id
+	`, sitterID).Scan(
+		&summary.Score,
+		&summary.VerifiedCount,
+		&punctuality[0], &punctuality[1],
+		&communication[0], &communication[1],
+		&petCare[0], &petCare[1],
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting rating score: %w", err)
+	}
+
+	summary.Dimensions = DimensionRatings{
+		Punctuality:   dimension(punctuality[0], punctuality[1]),
+		Communication: dimension(communication[0], communication[1]),
+		PetCare:       dimension(petCare[0], petCare[1]),
+	}
+
+	return summary, nil
+}
+
+// BookingPaid reports whether the booking has a payment that was not
+// refunded or declined.
+func (r *repository) BookingPaid(ctx context.Context, bookingID int) (bool, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var paid bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM payments WHERE booking_id = $1 AND status = 'paid')
+	`, bookingID).Scan(&paid)
+	if err != nil {
+		return false, fmt.Errorf("error checking booking payment: %w", err)
+	}
+
+	return paid, nil
+}
+
+// GetBooking loads the booking a review is written for.
+func (r *repository) GetBooking(ctx context.Context, bookingID int) (*models.Booking, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	booking := &models.Booking{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT booking_id, owner_id, sitter_id, pet_id, service_id, start_time, end_time, status
+		FROM bookings
+		WHERE booking_id = $1
+	`, bookingID).Scan(
+		&booking.BookingID,
+		&booking.OwnerID,
+		&booking.SitterID,
+		&booking.PetID,
+		&booking.ServiceID,
+		&booking.StartTime,
+		&booking.EndTime,
+		&booking.Status,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting booking: %w", err)
+	}
+
+	return booking, nil
+}
+
+// SetReply stores the sitter's reply. ErrReplyExists if there already is
+// one.
+func (r *repository) SetReply(ctx context.Context, reviewID int, reply string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reviews
+		SET reply = $2, replied_at = NOW()
+		WHERE review_id = $1 AND reply IS NULL
+	`, reviewID, reply)
+	if err != nil {
+		return fmt.Errorf("could not save the reply: %w", err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrReplyExists
+	}
+	return nil
+}
+
+// Flag puts the review into the moderation queue. ErrAlreadyFlagged if it
+// is already there or hidden.
+func (r *repository) Flag(ctx context.Context, reviewID int, reason string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reviews
+		SET flag_reason = $2, flagged_at = NOW()
+		WHERE review_id = $1 AND flagged_at IS NULL AND hidden_at IS NULL
+	`, reviewID, reason)
+	if err != nil {
+		return fmt.Errorf("could not flag the review: %w", err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrAlreadyFlagged
+	}
+	return nil
+}
+
+// GetFlagged lists the moderation queue.
+func (r *repository) GetFlagged(ctx context.Context, q listing.Query) (*listing.Page[FlaggedReview], error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query, args := q.Apply(`
+		SELECT `+reviewColumns+`, flag_reason, flagged_at
+		FROM reviews
+		WHERE flagged_at IS NOT NULL`, nil)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting flagged reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var flagged []FlaggedReview
+	for rows.Next() {
+		var f FlaggedReview
+		if err := scanReview(rows, &f.Review, &f.FlagReason, &f.FlaggedAt); err != nil {
+			return nil, fmt.Errorf("error scanning review: %w", err)
+		}
+		flagged = append(flagged, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting flagged reviews: %w", err)
+	}
+
+	return QueueSpec.Page(flagged, q), nil
+}
+
+// Moderate applies an admin decision and records it in one transaction.
+// Any decision takes the review out of the queue.
+func (r *repository) Moderate(ctx context.Context, action *ModerationAction) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var statement string
+	switch action.Action {
+	case ActionHide:
+		statement = `UPDATE reviews
+			SET hidden_at = COALESCE(hidden_at, NOW()), flag_reason = NULL, flagged_at = NULL
+			WHERE review_id = $1`
+	case ActionRestore:
+		statement = `UPDATE reviews
+			SET hidden_at = NULL, flag_reason = NULL, flagged_at = NULL
+			WHERE review_id = $1`
+	case ActionDelete:
+		statement = `DELETE FROM reviews WHERE review_id = $1`
+	default:
+		return ErrInvalidAction
+	}
+
+	return database.WithTx(ctx, r.db, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		res, err := conn.ExecContext(ctx, statement, action.ReviewID)
+		if err != nil {
+			return fmt.Errorf("could not moderate the review: %w", err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return ErrReviewNotFound
+		}
+
+		_, err = conn.ExecContext(ctx, `
+			INSERT INTO review_moderation_actions (review_id, admin_id, action, reason)
+			VALUES ($1, $2, $3, $4)
+		`, action.ReviewID, action.AdminID, action.Action, action.Reason)
+		if err != nil {
+			return fmt.Errorf("could not record the moderation: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *repository) GetModerationHistory(ctx context.Context, reviewID int) ([]ModerationAction, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT action_id, review_id, admin_id, action, reason, created_at
+		FROM review_moderation_actions
+		WHERE review_id = $1
+		ORDER BY action_id
+	`, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting moderation history: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []ModerationAction{}
+	for rows.Next() {
+		var action ModerationAction
+		var adminID sql.NullInt64
+
+		if err := rows.Scan(&action.ActionID, &action.ReviewID, &adminID, &action.Action, &action.Reason, &action.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning moderation action: %w", err)
+		}
+
+		if adminID.Valid {
+			id := int(adminID.Int64)
+			action.AdminID = &id
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, rows.Err()
+}
@@ -1,13 +1,17 @@
 package reviews
 
 import (
+	"context"
 	"database/sql"
+	"net/url"
 	"testing"
 	"time"
 
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,12 +27,15 @@ func TestCreate_Success(t *testing.T) {
 
 	repo := &repository{db: db}
 
+	petCare := 5
 	review := &models.Review{
-		BookingID: 1,
-		OwnerID:   2,
-		SitterID:  3,
-		Rating:    5,
-		Comment:   "Great service",
+		BookingID:    1,
+		OwnerID:      2,
+		SitterID:     3,
+		Rating:       5,
+		ReviewScores: models.ReviewScores{PetCare: &petCare},
+		Comment:      "Great service",
+		Verified:     true,
 	}
 
 	mock.ExpectQuery(`INSERT INTO reviews`).
@@ -37,11 +44,15 @@ func TestCreate_Success(t *testing.T) {
 			review.OwnerID,
 			review.SitterID,
 			review.Rating,
+			nil,
+			nil,
+			5,
 			review.Comment,
+			true,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"review_id"}).AddRow(10))
 
-	id, err := repo.Create(review)
+	id, err := repo.Create(context.Background(), review)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 10, id)
@@ -70,6 +81,13 @@ func TestGetByID_Success(t *testing.T) {
 		"rating",
 		"comment",
 		"created_at",
+		"reply",
+		"replied_at",
+		"hidden",
+		"punctuality",
+		"communication",
+		"pet_care",
+		"verified",
 	}).AddRow(
 		10,
 		1,
@@ -78,17 +96,29 @@ func TestGetByID_Success(t *testing.T) {
 		5,
 		"Great service",
 		now,
+		"",
+		nil,
+		false,
+		4,
+		nil,
+		5,
+		true,
 	)
 
 	mock.ExpectQuery(`FROM reviews WHERE review_id = \$1`).
 		WithArgs(10).
 		WillReturnRows(rows)
 
-	review, err := repo.GetByID(10)
+	review, err := repo.GetByID(context.Background(), 10)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, review)
 	assert.Equal(t, 5, review.Rating)
+	if assert.NotNil(t, review.Punctuality) {
+		assert.Equal(t, 4, *review.Punctuality)
+	}
+	assert.Nil(t, review.Communication)
+	assert.True(t, review.Verified)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -108,7 +138,7 @@ func TestGetByID_NotFound(t *testing.T) {
 		WithArgs(999).
 		WillReturnError(sql.ErrNoRows)
 
-	review, err := repo.GetByID(999)
+	review, err := repo.GetByID(context.Background(), 999)
 
 	assert.Error(t, err)
 	assert.Nil(t, review)
@@ -137,18 +167,27 @@ func TestGetBySitterID_Success(t *testing.T) {
 		"rating",
 		"comment",
 		"created_at",
+		"reply",
+		"replied_at",
+		"hidden",
+		"punctuality",
+		"communication",
+		"pet_care",
+		"verified",
 	}).
-		AddRow(1, 10, 2, 5, 4, "Good", now).
-		AddRow(2, 11, 3, 5, 5, "Excellent", now.Add(time.Minute))
+		AddRow(1, 10, 2, 5, 4, "Good", now, "", nil, false, nil, nil, nil, false).
+		AddRow(2, 11, 3, 5, 5, "Excellent", now.Add(time.Minute), "", nil, false, 5, 5, 5, true)
+
+	q, _ := ListSpec.Parse(url.Values{"sort": {"-rating"}, "rating": {"4,5"}})
 
-	mock.ExpectQuery(`FROM reviews WHERE sitter_id = \$1`).
-		WithArgs(5).
+	mock.ExpectQuery(`FROM reviews WHERE sitter_id = \$1 AND hidden_at IS NULL AND rating = ANY\(\$2\) ORDER BY rating DESC, review_id DESC LIMIT \$3`).
+		WithArgs(5, pq.Array([]int64{4, 5}), listing.DefaultLimit+1).
 		WillReturnRows(rows)
 
-	reviews, err := repo.GetBySitterID(5)
+	page, err := repo.GetBySitterID(context.Background(), 5, q)
 
 	assert.NoError(t, err)
-	assert.Len(t, reviews, 2)
+	assert.Len(t, page.Items, 2)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -174,6 +213,13 @@ func TestGetByBookingID_Success(t *testing.T) {
 		"rating",
 		"comment",
 		"created_at",
+		"reply",
+		"replied_at",
+		"hidden",
+		"punctuality",
+		"communication",
+		"pet_care",
+		"verified",
 	}).AddRow(
 		10,
 		1,
@@ -182,17 +228,25 @@ func TestGetByBookingID_Success(t *testing.T) {
 		5,
 		"Nice",
 		now,
+		"Thank you!",
+		now,
+		false,
+		nil,
+		nil,
+		nil,
+		false,
 	)
 
 	mock.ExpectQuery(`FROM reviews WHERE booking_id = \$1`).
 		WithArgs(1).
 		WillReturnRows(rows)
 
-	review, err := repo.GetByBookingID(1)
+	review, err := repo.GetByBookingID(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, review)
 	assert.Equal(t, "Nice", review.Comment)
+	assert.Equal(t, "Thank you!", review.Reply)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -217,12 +271,15 @@ func TestUpdate_Success(t *testing.T) {
 	mock.ExpectExec(`UPDATE reviews`).
 		WithArgs(
 			review.Rating,
+			nil,
+			nil,
+			nil,
 			review.Comment,
 			review.ReviewID,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Update(review)
+	err = repo.Update(context.Background(), review)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -244,7 +301,7 @@ func TestDelete_Success(t *testing.T) {
 		WithArgs(10).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Delete(10)
+	err = repo.Delete(context.Background(), 10)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -271,7 +328,7 @@ func TestRepository_GetSitterRating_Success(t *testing.T) {
 		WithArgs(3).
 		WillReturnRows(rows)
 
-	avg, count, err := repo.GetSitterRating(3)
+	avg, count, err := repo.GetSitterRating(context.Background(), 3)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 4.5, avg)
@@ -300,10 +357,150 @@ func TestGetSitterRating_NoReviews(t *testing.T) {
 		WithArgs(3).
 		WillReturnRows(rows)
 
-	avg, count, err := repo.GetSitterRating(3)
+	avg, count, err := repo.GetSitterRating(context.Background(), 3)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 0.0, avg)
 	assert.Equal(t, 0, count)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetRatingSummary_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	since := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT rating, COUNT\(\*\), COUNT\(\*\) FILTER \(WHERE created_at >= \$2\)`).
+		WithArgs(3, since).
+		WillReturnRows(sqlmock.NewRows([]string{"rating", "count", "recent"}).
+			AddRow(5, 3, 1).
+			AddRow(2, 1, 1))
+	mock.ExpectQuery(`JOIN services s ON s.service_id = b.service_id`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "avg", "count"}).
+			AddRow("boarding", 2.0, 1).
+			AddRow("walking", 5.0, 3))
+	mock.ExpectQuery(`LEFT JOIN sitter_stats ss ON ss.sitter_id = q.sitter_id`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"score", "verified_count",
+			"punctuality_sum", "punctuality_count",
+			"communication_sum", "communication_count",
+			"pet_care_sum", "pet_care_count",
+		}).AddRow(4.1, 3, 9, 2, 0, 0, 5, 1))
+
+	summary, err := repo.GetRatingSummary(context.Background(), 3, since)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, summary.ReviewCount)
+	assert.Equal(t, 4.25, summary.AverageRating)
+	assert.Equal(t, map[int]int{1: 0, 2: 1, 3: 0, 4: 0, 5: 3}, summary.Histogram)
+	assert.Equal(t, PeriodRating{Since: since, AverageRating: 3.5, ReviewCount: 2}, summary.Recent)
+	assert.Equal(t, []ServiceTypeRating{
+		{ServiceType: "boarding", AverageRating: 2, ReviewCount: 1},
+		{ServiceType: "walking", AverageRating: 5, ReviewCount: 3},
+	}, summary.ByServiceType)
+	assert.Equal(t, 4.1, summary.Score)
+	assert.Equal(t, 3, summary.VerifiedCount)
+	assert.Equal(t, DimensionRatings{
+		Punctuality: DimensionRating{AverageRating: 4.5, ReviewCount: 2},
+		PetCare:     DimensionRating{AverageRating: 5, ReviewCount: 1},
+	}, summary.Dimensions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreate_DuplicateBooking(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectQuery(`INSERT INTO reviews`).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "reviews_booking_unique"})
+
+	_, err = repo.Create(context.Background(), &models.Review{BookingID: 1, OwnerID: 2, SitterID: 3, Rating: 5})
+
+	assert.ErrorIs(t, err, ErrReviewExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBooking_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectQuery(`FROM bookings`).
+		WithArgs(99).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetBooking(context.Background(), 99)
+
+	assert.ErrorIs(t, err, ErrBookingNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetReply_AlreadyReplied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+
+	mock.ExpectExec(`UPDATE reviews SET reply = \$2, replied_at = NOW\(\) WHERE review_id = \$1 AND reply IS NULL`).
+		WithArgs(1, "Thanks").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.SetReply(context.Background(), 1, "Thanks")
+
+	assert.ErrorIs(t, err, ErrReplyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestModerate_HideRecordsAction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	adminID := 5
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE reviews SET hidden_at = COALESCE\(hidden_at, NOW\(\)\)`).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO review_moderation_actions`).
+		WithArgs(7, &adminID, ActionHide, "abusive").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.Moderate(context.Background(), &ModerationAction{ReviewID: 7, AdminID: &adminID, Action: ActionHide, Reason: "abusive"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestModerate_MissingReviewRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &repository{db: db}
+	adminID := 5
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM reviews`).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.Moderate(context.Background(), &ModerationAction{ReviewID: 7, AdminID: &adminID, Action: ActionDelete, Reason: "spam"})
+
+	assert.ErrorIs(t, err, ErrReviewNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,48 +1,110 @@
 package reviews
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"nanny-backend/internal/bookings"
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
+	"nanny-backend/pkg/config"
 )
 
 type Service interface {
-	CreateReview(bookingID, ownerID, sitterID, rating int, comment string) (int, error)
-	GetReview(reviewID int) (*models.Review, error)
-	GetSitterReviews(sitterID int) ([]models.Review, error)
-	GetBookingReview(bookingID int) (*models.Review, error)
-	UpdateReview(reviewID, rating int, comment string) error
-	DeleteReview(reviewID int) error
-	GetSitterRating(sitterID int) (float64, int, error)
+	CreateReview(ctx context.Context, actor authz.Actor, bookingID, sitterID, rating int, scores models.ReviewScores, comment string) (int, error)
+	GetReview(ctx context.Context, reviewID int) (*models.Review, error)
+	GetSitterReviews(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Review], error)
+	GetBookingReview(ctx context.Context, bookingID int) (*models.Review, error)
+	UpdateReview(ctx context.Context, actor authz.Actor, reviewID, rating int, scores models.ReviewScores, comment string) error
+	DeleteReview(ctx context.Context, actor authz.Actor, reviewID int) error
+	GetSitterRating(ctx context.Context, sitterID int) (float64, int, error)
+	GetRatingSummary(ctx context.Context, sitterID int) (*RatingSummary, error)
+
+	ReplyToReview(ctx context.Context, actor authz.Actor, reviewID int, reply string) error
+	FlagReview(ctx context.Context, actor authz.Actor, reviewID int, reason string) error
+	GetModerationQueue(ctx context.Context, q listing.Query) (*listing.Page[FlaggedReview], error)
+	ModerateReview(ctx context.Context, actor authz.Actor, reviewID int, action, reason string) error
+	GetModerationHistory(ctx context.Context, reviewID int) ([]ModerationAction, error)
 }
 
+var (
+	ErrInvalidRating       = apperr.Validation("invalid_rating", "rating must be from 1 to 5")
+	ErrInvalidScore        = apperr.Validation("invalid_score", "scores must be from 1 to 5")
+	ErrSitterMismatch      = apperr.Validation("sitter_mismatch", "the booking is with another nanny")
+	ErrBookingNotCompleted = apperr.Conflict("booking_not_completed", "only completed bookings can be reviewed")
+	ErrReviewLocked        = apperr.Conflict("review_locked", "the review can no longer be changed")
+)
+
 type service struct {
-	repo Repository
+	repo         Repository
+	editWindow   time.Duration
+	recentPeriod time.Duration
 }
 
 func NewService(repo Repository) Service {
-	return &service{repo: repo}
+	cfg := config.Load().Reviews
+
+	return &service{
+		repo:         repo,
+		editWindow:   cfg.EditWindow,
+		recentPeriod: cfg.RecentPeriod,
+	}
 }
 
-func (s *service) CreateReview(bookingID, ownerID, sitterID, rating int, comment string) (int, error) {
-	if rating < 1 || rating > 5 {
-		return 0, fmt.Errorf("rating must be from 1 to 5")
+func (s *service) CreateReview(ctx context.Context, actor authz.Actor, bookingID, sitterID, rating int, scores models.ReviewScores, comment string) (int, error) {
+	if actor.UserID <= 0 {
+		return 0, authz.ErrForbidden
+	}
+
+	if !validScore(&rating) {
+		return 0, ErrInvalidRating
+	}
+
+	if !validScores(scores) {
+		return 0, ErrInvalidScore
+	}
+
+	booking, err := s.repo.GetBooking(ctx, bookingID)
+	if err != nil {
+		return 0, err
+	}
+
+	if booking.OwnerID != actor.UserID {
+		return 0, fmt.Errorf("booking belongs to another owner: %w", authz.ErrForbidden)
+	}
+
+	if booking.SitterID != sitterID {
+		return 0, ErrSitterMismatch
 	}
 
-	existing, _ := s.repo.GetByBookingID(bookingID)
-	if existing != nil {
-		return 0, fmt.Errorf("revuew for this already exists")
+	if booking.Status != bookings.StatusCompleted {
+		return 0, ErrBookingNotCompleted
 	}
 
+	paid, err := s.repo.BookingPaid(ctx, bookingID)
+	if err != nil {
+		return 0, err
+	}
+
+	// A second review for the booking is refused by the unique constraint.
 	review := &models.Review{
-		BookingID: bookingID,
-		OwnerID:   ownerID,
-		SitterID:  sitterID,
-		Rating:    rating,
-		Comment:   comment,
+		BookingID:    bookingID,
+		OwnerID:      actor.UserID,
+		SitterID:     sitterID,
+		Rating:       rating,
+		ReviewScores: scores,
+		Comment:      comment,
+		Verified:     paid,
 	}
 
-	reviewID, err := s.repo.Create(review)
+	reviewID, err := s.repo.Create(ctx, review)
+	if errors.Is(err, ErrReviewExists) {
+		return 0, err
+	}
 	if err != nil {
 		return 0, fmt.Errorf("error creatung review: %w", err)
 	}
@@ -50,38 +112,90 @@ func (s *service) CreateReview(bookingID, ownerID, sitterID, rating int, comment
 	return reviewID, nil
 }
 
-func (s *service) GetReview(reviewID int) (*models.Review, error) {
-	return s.repo.GetByID(reviewID)
+func (s *service) GetReview(ctx context.Context, reviewID int) (*models.Review, error) {
+	return visible(s.repo.GetByID(ctx, reviewID))
 }
 
-func (s *service) GetSitterReviews(sitterID int) ([]models.Review, error) {
-	return s.repo.GetBySitterID(sitterID)
+func (s *service) GetSitterReviews(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Review], error) {
+	return s.repo.GetBySitterID(ctx, sitterID, q)
 }
 
-func (s *service) GetBookingReview(bookingID int) (*models.Review, error) {
-	return s.repo.GetByBookingID(bookingID)
+func (s *service) GetBookingReview(ctx context.Context, bookingID int) (*models.Review, error) {
+	return visible(s.repo.GetByBookingID(ctx, bookingID))
 }
 
-func (s *service) UpdateReview(reviewID, rating int, comment string) error {
-	if rating < 1 || rating > 5 {
-		return fmt.Errorf("rating must be from 1 to 5")
+func (s *service) UpdateReview(ctx context.Context, actor authz.Actor, reviewID, rating int, scores models.ReviewScores, comment string) error {
+	if !validScore(&rating) {
+		return ErrInvalidRating
 	}
 
-	review, err := s.repo.GetByID(reviewID)
+	if !validScores(scores) {
+		return ErrInvalidScore
+	}
+
+	review, err := s.repo.GetByID(ctx, reviewID)
 	if err != nil {
 		return err
 	}
 
+	if !actor.CanActAs(review.OwnerID) {
+		return fmt.Errorf("review belongs to another owner: %w", authz.ErrForbidden)
+	}
+
+	if s.locked(review) {
+		return ErrReviewLocked
+	}
+
 	review.Rating = rating
+	review.ReviewScores = scores
 	review.Comment = comment
 
-	return s.repo.Update(review)
+	return s.repo.Update(ctx, review)
+}
+
+func (s *service) DeleteReview(ctx context.Context, actor authz.Actor, reviewID int) error {
+	review, err := s.repo.GetByID(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+
+	if !actor.CanActAs(review.OwnerID) {
+		return fmt.Errorf("review belongs to another owner: %w", authz.ErrForbidden)
+	}
+
+	// Admins may still take a locked review down.
+	if s.locked(review) && !actor.IsAdmin() {
+		return ErrReviewLocked
+	}
+
+	return s.repo.Delete(ctx, reviewID)
+}
+
+func (s *service) GetSitterRating(ctx context.Context, sitterID int) (float64, int, error) {
+	return s.repo.GetSitterRating(ctx, sitterID)
+}
+
+// locked reports whether the review's edit window has passed.
+func (s *service) locked(review *models.Review) bool {
+	return time.Since(review.CreatedAt) > s.editWindow
 }
 
-func (s *service) DeleteReview(reviewID int) error {
-	return s.repo.Delete(reviewID)
+// validScore accepts a missing score or one from 1 to 5.
+func validScore(score *int) bool {
+	return score == nil || (*score >= 1 && *score <= 5)
 }
 
-func (s *service) GetSitterRating(sitterID int) (float64, int, error) {
-	return s.repo.GetSitterRating(sitterID)
+func validScores(scores models.ReviewScores) bool {
+	return validScore(scores.Punctuality) && validScore(scores.Communication) && validScore(scores.PetCare)
+}
+
+// visible treats hidden reviews as missing on public reads.
+func visible(review *models.Review, err error) (*models.Review, error) {
+	if err != nil {
+		return nil, err
+	}
+	if review.Hidden {
+		return nil, ErrReviewNotFound
+	}
+	return review, nil
 }
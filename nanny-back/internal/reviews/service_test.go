@@ -1,13 +1,15 @@
 package reviews
 
 import (
-	"errors"
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 )
 
@@ -15,12 +17,12 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) Create(review *models.Review) (int, error) {
+func (m *MockRepository) Create(ctx context.Context, review *models.Review) (int, error) {
 	args := m.Called(review)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockRepository) GetByID(reviewID int) (*models.Review, error) {
+func (m *MockRepository) GetByID(ctx context.Context, reviewID int) (*models.Review, error) {
 	args := m.Called(reviewID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -28,12 +30,15 @@ func (m *MockRepository) GetByID(reviewID int) (*models.Review, error) {
 	return args.Get(0).(*models.Review), args.Error(1)
 }
 
-func (m *MockRepository) GetBySitterID(sitterID int) ([]models.Review, error) {
-	args := m.Called(sitterID)
-	return args.Get(0).([]models.Review), args.Error(1)
+func (m *MockRepository) GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Review], error) {
+	args := m.Called(sitterID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[models.Review]), args.Error(1)
 }
 
-func (m *MockRepository) GetByBookingID(bookingID int) (*models.Review, error) {
+func (m *MockRepository) GetByBookingID(ctx context.Context, bookingID int) (*models.Review, error) {
 	args := m.Called(bookingID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -41,38 +46,104 @@ func (m *MockRepository) GetByBookingID(bookingID int) (*models.Review, error) {
 	return args.Get(0).(*models.Review), args.Error(1)
 }
 
-func (m *MockRepository) Update(review *models.Review) error {
+func (m *MockRepository) Update(ctx context.Context, review *models.Review) error {
 	args := m.Called(review)
 	return args.Error(0)
 }
 
-func (m *MockRepository) Delete(reviewID int) error {
+func (m *MockRepository) Delete(ctx context.Context, reviewID int) error {
 	args := m.Called(reviewID)
 	return args.Error(0)
 }
 
-func (m *MockRepository) GetSitterRating(sitterID int) (float64, int, error) {
+func (m *MockRepository) GetSitterRating(ctx context.Context, sitterID int) (float64, int, error) {
 	args := m.Called(sitterID)
 	return args.Get(0).(float64), args.Int(1), args.Error(2)
 }
 
+func (m *MockRepository) GetRatingSummary(ctx context.Context, sitterID int, since time.Time) (*RatingSummary, error) {
+	args := m.Called(sitterID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RatingSummary), args.Error(1)
+}
+
+func (m *MockRepository) BookingPaid(ctx context.Context, bookingID int) (bool, error) {
+	args := m.Called(bookingID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetBooking(ctx context.Context, bookingID int) (*models.Booking, error) {
+	args := m.Called(bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Booking), args.Error(1)
+}
+
+func (m *MockRepository) SetReply(ctx context.Context, reviewID int, reply string) error {
+	args := m.Called(reviewID, reply)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Flag(ctx context.Context, reviewID int, reason string) error {
+	args := m.Called(reviewID, reason)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetFlagged(ctx context.Context, q listing.Query) (*listing.Page[FlaggedReview], error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listing.Page[FlaggedReview]), args.Error(1)
+}
+
+func (m *MockRepository) Moderate(ctx context.Context, action *ModerationAction) error {
+	args := m.Called(action)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetModerationHistory(ctx context.Context, reviewID int) ([]ModerationAction, error) {
+	args := m.Called(reviewID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ModerationAction), args.Error(1)
+}
+
+var owner = authz.Actor{UserID: 2, Role: authz.RoleOwner}
+
+func completedBooking() *models.Booking {
+	return &models.Booking{BookingID: 1, OwnerID: 2, SitterID: 3, Status: "completed"}
+}
+
 func TestCreateReview_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
 	mockRepo.
-		On("GetByBookingID", 1).
-		Return(nil, errors.New("not found"))
+		On("GetBooking", 1).
+		Return(completedBooking(), nil)
 
 	mockRepo.
-		On("Create", mock.Anything).
+		On("BookingPaid", 1).
+		Return(true, nil)
+
+	punctuality := 4
+	mockRepo.
+		On("Create", mock.MatchedBy(func(review *models.Review) bool {
+			return review.Verified && review.Punctuality != nil && *review.Punctuality == 4 && review.PetCare == nil
+		})).
 		Return(10, nil)
 
-	reviewID, err := service.CreateReview(
+	reviewID, err := service.CreateReview(context.Background(),
+		owner,
 		1,
-		2,
 		3,
 		5,
+		models.ReviewScores{Punctuality: &punctuality},
 		"Great service",
 	)
 
@@ -85,11 +156,12 @@ func TestCreateReview_InvalidRating(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
-	reviewID, err := service.CreateReview(
+	reviewID, err := service.CreateReview(context.Background(),
+		owner,
 		1,
-		2,
 		3,
 		6, // invalid rating
+		models.ReviewScores{},
 		"Bad",
 	)
 
@@ -97,31 +169,88 @@ func TestCreateReview_InvalidRating(t *testing.T) {
 	assert.Equal(t, 0, reviewID)
 }
 
+func TestCreateReview_InvalidScore(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	communication := 0
+	_, err := service.CreateReview(context.Background(), owner, 1, 3, 5, models.ReviewScores{Communication: &communication}, "Bad")
+
+	assert.ErrorIs(t, err, ErrInvalidScore)
+	mockRepo.AssertNotCalled(t, "GetBooking", mock.Anything)
+}
+
 func TestCreateReview_AlreadyExists(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
-	existing := &models.Review{
-		ReviewID:  1,
-		BookingID: 1,
-	}
+	mockRepo.
+		On("GetBooking", 1).
+		Return(completedBooking(), nil)
 
 	mockRepo.
-		On("GetByBookingID", 1).
-		Return(existing, nil)
+		On("BookingPaid", 1).
+		Return(false, nil)
 
-	reviewID, err := service.CreateReview(
+	mockRepo.
+		On("Create", mock.Anything).
+		Return(0, ErrReviewExists)
+
+	reviewID, err := service.CreateReview(context.Background(),
+		owner,
 		1,
-		2,
 		3,
 		5,
+		models.ReviewScores{},
 		"Duplicate",
 	)
 
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrReviewExists)
 	assert.Equal(t, 0, reviewID)
 }
 
+func TestCreateReview_BookingRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		booking  *models.Booking
+		sitterID int
+		want     error
+	}{
+		{"not completed", &models.Booking{BookingID: 1, OwnerID: 2, SitterID: 3, Status: "confirmed"}, 3, ErrBookingNotCompleted},
+		{"another owner", &models.Booking{BookingID: 1, OwnerID: 9, SitterID: 3, Status: "completed"}, 3, authz.ErrForbidden},
+		{"another sitter", completedBooking(), 4, ErrSitterMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := NewService(mockRepo)
+
+			mockRepo.
+				On("GetBooking", 1).
+				Return(tt.booking, nil)
+
+			_, err := service.CreateReview(context.Background(), owner, 1, tt.sitterID, 5, models.ReviewScores{}, "Nice")
+
+			assert.ErrorIs(t, err, tt.want)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestCreateReview_BookingNotFound(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.
+		On("GetBooking", 1).
+		Return(nil, ErrBookingNotFound)
+
+	_, err := service.CreateReview(context.Background(), owner, 1, 3, 5, models.ReviewScores{}, "Nice")
+
+	assert.ErrorIs(t, err, ErrBookingNotFound)
+}
+
 func TestGetReview_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
@@ -140,7 +269,7 @@ func TestGetReview_Success(t *testing.T) {
 		On("GetByID", 1).
 		Return(expected, nil)
 
-	review, err := service.GetReview(1)
+	review, err := service.GetReview(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, review)
@@ -151,20 +280,20 @@ func TestGetSitterReviews_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
+	q, _ := ListSpec.Parse(nil)
 	expected := []models.Review{
 		{ReviewID: 1, SitterID: 3, Rating: 5},
 		{ReviewID: 2, SitterID: 3, Rating: 4},
 	}
 
 	mockRepo.
-		On("GetBySitterID", 3).
-		Return(expected, nil)
+		On("GetBySitterID", 3, q).
+		Return(ListSpec.Page(expected, q), nil)
 
-	reviews, err := service.GetSitterReviews(3)
+	page, err := service.GetSitterReviews(context.Background(), 3, q)
 
 	assert.NoError(t, err)
-	assert.Len(t, reviews, 2)
-	assert.Equal(t, expected, reviews)
+	assert.Equal(t, expected, page.Items)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -181,7 +310,7 @@ func TestGetBookingReview_Success(t *testing.T) {
 		On("GetByBookingID", 5).
 		Return(expected, nil)
 
-	review, err := service.GetBookingReview(5)
+	review, err := service.GetBookingReview(context.Background(), 5)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, review)
@@ -193,9 +322,11 @@ func TestUpdateReview_Success(t *testing.T) {
 	service := NewService(mockRepo)
 
 	existing := &models.Review{
-		ReviewID: 1,
-		Rating:   3,
-		Comment:  "Ok",
+		ReviewID:  1,
+		OwnerID:   2,
+		Rating:    3,
+		Comment:   "Ok",
+		CreatedAt: time.Now().Add(-time.Hour),
 	}
 
 	mockRepo.
@@ -206,17 +337,31 @@ func TestUpdateReview_Success(t *testing.T) {
 		On("Update", mock.Anything).
 		Return(nil)
 
-	err := service.UpdateReview(1, 5, "Excellent")
+	err := service.UpdateReview(context.Background(), owner, 1, 5, models.ReviewScores{}, "Excellent")
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUpdateReview_Locked(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Review{ReviewID: 1, OwnerID: 2, CreatedAt: time.Now().Add(-30 * 24 * time.Hour)}, nil)
+
+	err := service.UpdateReview(context.Background(), owner, 1, 5, models.ReviewScores{}, "Changed my mind")
+
+	assert.ErrorIs(t, err, ErrReviewLocked)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
 func TestUpdateReview_InvalidRating(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
-	err := service.UpdateReview(1, 0, "Bad")
+	err := service.UpdateReview(context.Background(), owner, 1, 0, models.ReviewScores{}, "Bad")
 
 	assert.Error(t, err)
 }
@@ -225,16 +370,52 @@ func TestDeleteReview_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Review{ReviewID: 1, OwnerID: 2, CreatedAt: time.Now()}, nil)
 	mockRepo.
 		On("Delete", 1).
 		Return(nil)
 
-	err := service.DeleteReview(1)
+	err := service.DeleteReview(context.Background(), owner, 1)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestDeleteReview_LockedStillAllowedForAdmin(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	old := &models.Review{ReviewID: 1, OwnerID: 2, CreatedAt: time.Now().Add(-30 * 24 * time.Hour)}
+	mockRepo.
+		On("GetByID", 1).
+		Return(old, nil)
+	mockRepo.
+		On("Delete", 1).
+		Return(nil)
+
+	assert.ErrorIs(t, service.DeleteReview(context.Background(), owner, 1), ErrReviewLocked)
+
+	admin := authz.Actor{UserID: 5, Role: authz.RoleAdmin}
+	assert.NoError(t, service.DeleteReview(context.Background(), admin, 1))
+	mockRepo.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestDeleteReview_OtherOwner(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Review{ReviewID: 1, OwnerID: 9}, nil)
+
+	err := service.DeleteReview(context.Background(), owner, 1)
+
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+	mockRepo.AssertNotCalled(t, "Delete", 1)
+}
+
 func TestGetSitterRating_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
@@ -243,10 +424,95 @@ func TestGetSitterRating_Success(t *testing.T) {
 		On("GetSitterRating", 3).
 		Return(4.5, 10, nil)
 
-	rating, count, err := service.GetSitterRating(3)
+	rating, count, err := service.GetSitterRating(context.Background(), 3)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 4.5, rating)
 	assert.Equal(t, 10, count)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetRatingSummary_LooksBackRecentPeriod(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	summary := &RatingSummary{SitterID: 3, AverageRating: 4.5, ReviewCount: 10}
+	mockRepo.
+		On("GetRatingSummary", 3, mock.MatchedBy(func(since time.Time) bool {
+			return time.Since(since).Round(time.Hour) == 90*24*time.Hour
+		})).
+		Return(summary, nil)
+
+	got, err := service.GetRatingSummary(context.Background(), 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, summary, got)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReplyToReview_OnlyTheReviewedSitter(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Review{ReviewID: 1, OwnerID: 2, SitterID: 3}, nil)
+	mockRepo.
+		On("SetReply", 1, "Thank you!").
+		Return(nil)
+
+	other := authz.Actor{UserID: 4, Role: authz.RoleSitter}
+	err := service.ReplyToReview(context.Background(), other, 1, "Thank you!")
+	assert.ErrorIs(t, err, authz.ErrForbidden)
+
+	sitter := authz.Actor{UserID: 3, Role: authz.RoleSitter}
+	err = service.ReplyToReview(context.Background(), sitter, 1, "Thank you!")
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "SetReply", 1)
+}
+
+func TestFlagReview_HiddenReview(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Review{ReviewID: 1, SitterID: 3, Hidden: true}, nil)
+
+	err := service.FlagReview(context.Background(), authz.Actor{UserID: 3, Role: authz.RoleSitter}, 1, "Insulting")
+
+	assert.ErrorIs(t, err, ErrReviewNotFound)
+	mockRepo.AssertNotCalled(t, "Flag", mock.Anything, mock.Anything)
+}
+
+func TestGetReview_HiddenIsNotFound(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.
+		On("GetByID", 1).
+		Return(&models.Review{ReviewID: 1, Hidden: true}, nil)
+
+	review, err := service.GetReview(context.Background(), 1)
+
+	assert.Nil(t, review)
+	assert.ErrorIs(t, err, ErrReviewNotFound)
+}
+
+func TestModerateReview(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	admin := authz.Actor{UserID: 5, Role: authz.RoleAdmin}
+
+	mockRepo.
+		On("Moderate", mock.MatchedBy(func(a *ModerationAction) bool {
+			return a.ReviewID == 1 && *a.AdminID == 5 && a.Action == ActionHide && a.Reason == "abusive"
+		})).
+		Return(nil)
+
+	assert.ErrorIs(t, service.ModerateReview(context.Background(), owner, 1, ActionHide, "abusive"), authz.ErrForbidden)
+	assert.ErrorIs(t, service.ModerateReview(context.Background(), admin, 1, "ban", "abusive"), ErrInvalidAction)
+	assert.NoError(t, service.ModerateReview(context.Background(), admin, 1, ActionHide, "abusive"))
+	mockRepo.AssertNumberOfCalls(t, "Moderate", 1)
+}
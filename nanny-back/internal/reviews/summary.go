@@ -0,0 +1,61 @@
+package reviews
+
+import (
+	"context"
+	"time"
+)
+
+// RatingSummary breaks a sitter's visible reviews down. Score is the
+// Bayesian average search ranks sitters by; unlike AverageRating it only
+// drifts away from the prior as reviews pile up.
+type RatingSummary struct {
+	SitterID      int     `json:"sitter_id"`
+	AverageRating float64 `json:"average_rating"`
+	ReviewCount   int     `json:"review_count"`
+	VerifiedCount int     `json:"verified_count"`
+	Score         float64 `json:"score"`
+	// Histogram counts reviews per star, with every star from 1 to 5 present.
+	Histogram     map[int]int         `json:"histogram"`
+	Dimensions    DimensionRatings    `json:"dimensions"`
+	Recent        PeriodRating        `json:"recent"`
+	ByServiceType []ServiceTypeRating `json:"by_service_type"`
+}
+
+// DimensionRatings average each of models.ReviewScores over the reviews
+// that filled it in.
+type DimensionRatings struct {
+	Punctuality   DimensionRating `json:"punctuality"`
+	Communication DimensionRating `json:"communication"`
+	PetCare       DimensionRating `json:"pet_care"`
+}
+
+type DimensionRating struct {
+	AverageRating float64 `json:"average_rating"`
+	ReviewCount   int     `json:"review_count"`
+}
+
+// PeriodRating is the average of the reviews posted since Since.
+type PeriodRating struct {
+	Since         time.Time `json:"since"`
+	AverageRating float64   `json:"average_rating"`
+	ReviewCount   int       `json:"review_count"`
+}
+
+// ServiceTypeRating is the average of the reviews for bookings of one
+// service type.
+type ServiceTypeRating struct {
+	ServiceType   string  `json:"service_type"`
+	AverageRating float64 `json:"average_rating"`
+	ReviewCount   int     `json:"review_count"`
+}
+
+func dimension(sum, count int) DimensionRating {
+	if count == 0 {
+		return DimensionRating{}
+	}
+	return DimensionRating{AverageRating: float64(sum) / float64(count), ReviewCount: count}
+}
+
+func (s *service) GetRatingSummary(ctx context.Context, sitterID int) (*RatingSummary, error) {
+	return s.repo.GetRatingSummary(ctx, sitterID, time.Now().Add(-s.recentPeriod))
+}
@@ -1,27 +1,58 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/database"
+	"nanny-backend/internal/common/geo"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
+
+	"github.com/lib/pq"
 )
 
 type Repository interface {
-	Create(service *models.Service) (int, error)
-	GetByID(serviceID int) (*models.Service, error)
-	GetBySitterID(sitterID int) ([]models.Service, error)
-	Update(service *models.Service) error
-	Delete(serviceID int) error
-	SearchServices(serviceType, location string) ([]ServiceWithSitter, error)
+	Create(ctx context.Context, service *models.Service) (int, error)
+	GetByID(ctx context.Context, serviceID int) (*models.Service, error)
+	GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Service], error)
+	Update(ctx context.Context, service *models.Service) error
+	Delete(ctx context.Context, serviceID int) error
+	SearchServices(ctx context.Context, filter SearchFilter, after *Cursor) ([]ServiceWithSitter, int, error)
 }
 
 type ServiceWithSitter struct {
 	models.Service
 	SitterName   string  `json:"sitter_name"`
 	SitterRating float64 `json:"sitter_rating"`
+	ReviewCount  int     `json:"review_count"`
+	// RatingScore is the Bayesian score the rating sort orders by.
+	RatingScore     float64  `json:"rating_score"`
+	ExperienceYears int      `json:"experience_years"`
+	Location        string   `json:"location"`
+	ServiceRadiusKm float64  `json:"service_radius_km"`
+	DistanceKm      *float64 `json:"distance_km,omitempty"`
+}
+
+// ListSpec is how a sitter's service list can be sorted and filtered.
+var ListSpec = listing.Spec[models.Service]{
+	IDColumn: "service_id",
+	ID:       func(s models.Service) int { return s.ServiceID },
+	Sorts: map[string]listing.Sort[models.Service]{
+		"service_id": {Column: "service_id", Value: func(s models.Service) interface{} { return s.ServiceID }},
+		"price":      {Column: "price_per_hour", Value: func(s models.Service) interface{} { return s.PricePerHour }},
+	},
+	DefaultSort: "service_id",
+	Filters: map[string]listing.Filter{
+		"type": {Column: "type", Type: listing.Text},
+	},
 }
 
+var ErrServiceNotFound = apperr.NotFound("service_not_found", "service not found")
+
 type repository struct {
 	db *sql.DB
 }
@@ -30,13 +61,16 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Create(service *models.Service) (int, error) {
+func (r *repository) Create(ctx context.Context, service *models.Service) (int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	var serviceID int
-	err := r.db.QueryRow(`
-		INSERT INTO services (sitter_id, type, price_per_hour, description)
-		VALUES ($1, $2, $3, $4)
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO services (sitter_id, type, price_per_hour, description, pet_types)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING service_id
-	`, service.SitterID, service.Type, service.PricePerHour, service.Description).Scan(&serviceID)
+	`, service.SitterID, service.Type, service.PricePerHour, service.Description, pq.Array(service.PetTypes)).Scan(&serviceID)
 
 	if err != nil {
 		return 0, fmt.Errorf("coould not создать serviceу: %w", err)
@@ -45,10 +79,13 @@ func (r *repository) Create(service *models.Service) (int, error) {
 	return serviceID, nil
 }
 
-func (r *repository) GetByID(serviceID int) (*models.Service, error) {
+func (r *repository) GetByID(ctx context.Context, serviceID int) (*models.Service, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
 	service := &models.Service{}
-	err := r.db.QueryRow(`
-		SELECT service_id, sitter_id, type, price_per_hour, description
+	err := r.db.QueryRowContext(ctx, `
+		SELECT service_id, sitter_id, type, price_per_hour, description, pet_types
 		FROM services
 		WHERE service_id = $1
 	`, serviceID).Scan(
@@ -57,10 +94,11 @@ func (r *repository) GetByID(serviceID int) (*models.Service, error) {
 		&service.Type,
 		&service.PricePerHour,
 		&service.Description,
+		(*pq.StringArray)(&service.PetTypes),
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("service not found")
+		return nil, ErrServiceNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting service: %w", err)
@@ -69,12 +107,16 @@ func (r *repository) GetByID(serviceID int) (*models.Service, error) {
 	return service, nil
 }
 
-func (r *repository) GetBySitterID(sitterID int) ([]models.Service, error) {
-	rows, err := r.db.Query(`
-		SELECT service_id, sitter_id, type, price_per_hour, description
+func (r *repository) GetBySitterID(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Service], error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query, args := q.Apply(`
+		SELECT service_id, sitter_id, type, price_per_hour, description, pet_types
 		FROM services
-		WHERE sitter_id = $1
-	`, sitterID)
+		WHERE sitter_id = $1`, []interface{}{sitterID})
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting service: %w", err)
@@ -90,22 +132,29 @@ func (r *repository) GetBySitterID(sitterID int) ([]models.Service, error) {
 			&service.Type,
 			&service.PricePerHour,
 			&service.Description,
+			(*pq.StringArray)(&service.PetTypes),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning service: %w", err)
 		}
 		services = append(services, service)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting service: %w", err)
+	}
 
-	return services, nil
+	return ListSpec.Page(services, q), nil
 }
 
-func (r *repository) Update(service *models.Service) error {
-	_, err := r.db.Exec(`
+func (r *repository) Update(ctx context.Context, service *models.Service) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE services
-		SET type = $1, price_per_hour = $2, description = $3
-		WHERE service_id = $4
-	`, service.Type, service.PricePerHour, service.Description, service.ServiceID)
+		SET type = $1, price_per_hour = $2, description = $3, pet_types = $4
+		WHERE service_id = $5
+	`, service.Type, service.PricePerHour, service.Description, pq.Array(service.PetTypes), service.ServiceID)
 
 	if err != nil {
 		return fmt.Errorf("coould not update service: %w", err)
@@ -114,66 +163,168 @@ func (r *repository) Update(service *models.Service) error {
 	return nil
 }
 
-func (r *repository) Delete(serviceID int) error {
-	_, err := r.db.Exec(`DELETE FROM services WHERE service_id = $1`, serviceID)
+func (r *repository) Delete(ctx context.Context, serviceID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM services WHERE service_id = $1`, serviceID)
 	if err != nil {
 		return fmt.Errorf("coould not delete service: %w", err)
 	}
 	return nil
 }
 
-func (r *repository) SearchServices(serviceType, location string) ([]ServiceWithSitter, error) {
-	query := `
-		SELECT 
-			s.service_id, s.sitter_id, s.type, s.price_per_hour, s.description,
-			u.full_name as sitter_name,
-			COALESCE(AVG(r.rating), 0) as sitter_rating
-		FROM services s
-		JOIN sitters st ON s.sitter_id = st.sitter_id
-		JOIN users u ON st.sitter_id = u.user_id
-		LEFT JOIN reviews r ON st.sitter_id = r.sitter_id
-		WHERE st.status = 'approved'
-	`
+// SearchServices returns up to filter.Limit+1 rows after the cursor (the
+// extra row tells the caller there is another page) and the total number
+// of matches. Ratings come from sitter_stats, which the reviews trigger
+// keeps current, so no review rows are aggregated here.
+func (r *repository) SearchServices(ctx context.Context, filter SearchFilter, after *Cursor) ([]ServiceWithSitter, int, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
 
 	args := []interface{}{}
-	argCount := 1
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	distance := "NULL::float8"
+	if filter.Latitude != nil {
+		lat, lng := arg(*filter.Latitude), arg(*filter.Longitude)
+		distance = fmt.Sprintf(`(%[3]g * 2 * ASIN(LEAST(1, SQRT(
+				POWER(SIN(RADIANS(st.latitude - %[1]s) / 2), 2) +
+				COS(RADIANS(%[1]s)) * COS(RADIANS(st.latitude)) * POWER(SIN(RADIANS(st.longitude - %[2]s) / 2), 2)))))::float8`,
+			lat, lng, geo.EarthRadiusKm)
+	}
+
+	// Suspended, banned and deleted sitters can't take new bookings.
+	conditions := []string{
+		"st.status = 'approved'",
+		"u.deleted_at IS NULL AND u.banned_at IS NULL AND (u.suspended_until IS NULL OR u.suspended_until <= NOW())",
+	}
+
+	if filter.Type != "" {
+		conditions = append(conditions, "s.type = "+arg(filter.Type))
+	}
+	if filter.Location != "" {
+		conditions = append(conditions, "st.location ILIKE "+arg("%"+filter.Location+"%"))
+	}
+	if filter.MinPrice > 0 {
+		conditions = append(conditions, "s.price_per_hour >= "+arg(filter.MinPrice))
+	}
+	if filter.MaxPrice > 0 {
+		conditions = append(conditions, "s.price_per_hour <= "+arg(filter.MaxPrice))
+	}
+	if filter.PetType != "" {
+		conditions = append(conditions, arg(filter.PetType)+" = ANY(s.pet_types)")
+	}
+	if filter.Date != "" {
+		date := arg(filter.Date)
+		// Without weekly slots a sitter works every day, as in availability.
+		conditions = append(conditions, fmt.Sprintf(`(NOT EXISTS (
+				SELECT 1 FROM availability_slots a WHERE a.sitter_id = st.sitter_id)
+			OR EXISTS (
+				SELECT 1 FROM availability_slots a
+				WHERE a.sitter_id = st.sitter_id AND a.weekday = EXTRACT(DOW FROM %[1]s::date)))
+			AND NOT EXISTS (
+				SELECT 1 FROM availability_blackouts b
+				WHERE b.sitter_id = st.sitter_id AND %[1]s::date BETWEEN b.start_date AND b.end_date)`, date))
+	}
 
-	if serviceType != "" {
-		query += fmt.Sprintf(" AND s.type = $%d", argCount)
-		args = append(args, serviceType)
-		argCount++
+	outer := []string{"TRUE"}
+	if filter.MinRating > 0 {
+		outer = append(outer, "sitter_rating >= "+arg(filter.MinRating))
+	}
+	if filter.Latitude != nil {
+		// Also drops sitters without coordinates: their distance is NULL.
+		outer = append(outer, "distance_km <= service_radius_km")
 	}
+	if filter.RadiusKm > 0 {
+		outer = append(outer, "distance_km <= "+arg(filter.RadiusKm))
+	}
+
+	base := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT
+				s.service_id, s.sitter_id, s.type, s.price_per_hour, s.description, s.pet_types,
+				u.full_name AS sitter_name, st.location, st.experience_years, st.service_radius_km,
+				COALESCE(ss.rating_sum::float8 / NULLIF(ss.review_count, 0), 0) AS sitter_rating,
+				COALESCE(ss.review_count, 0) AS review_count,
+				COALESCE(ss.score, sitter_score(0, 0)) AS rating_score,
+				%s AS distance_km
+			FROM services s
+			JOIN sitters st ON s.sitter_id = st.sitter_id
+			JOIN users u ON st.sitter_id = u.user_id
+			LEFT JOIN sitter_stats ss ON ss.sitter_id = st.sitter_id
+			WHERE %s
+		) results
+		WHERE %s`, distance, strings.Join(conditions, " AND "), strings.Join(outer, " AND "))
 
-	if location != "" {
-		query += fmt.Sprintf(" AND st.location ILIKE $%d", argCount)
-		args = append(args, "%"+location+"%")
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM (`+base+`) matches`, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting services: %w", err)
 	}
 
-	query += " GROUP BY s.service_id, u.full_name ORDER BY sitter_rating DESC"
+	column, direction, comparison := sortColumns(filter.Sort)
 
-	rows, err := r.db.Query(query, args...)
+	query := base
+	if after != nil {
+		value, id := arg(after.Value), arg(after.ServiceID)
+		query += fmt.Sprintf(" AND (%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND service_id > %[4]s))", column, comparison, value, id)
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, service_id ASC LIMIT %s", column, direction, arg(filter.Limit+1))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error searching service: %w", err)
+		return nil, 0, fmt.Errorf("error searching service: %w", err)
 	}
 	defer rows.Close()
 
-	var services []ServiceWithSitter
+	services := []ServiceWithSitter{}
 	for rows.Next() {
 		var service ServiceWithSitter
+		var distance sql.NullFloat64
 		err := rows.Scan(
 			&service.ServiceID,
 			&service.SitterID,
 			&service.Type,
 			&service.PricePerHour,
 			&service.Description,
+			(*pq.StringArray)(&service.PetTypes),
 			&service.SitterName,
+			&service.Location,
+			&service.ExperienceYears,
+			&service.ServiceRadiusKm,
 			&service.SitterRating,
+			&service.ReviewCount,
+			&service.RatingScore,
+			&distance,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning service: %w", err)
+			return nil, 0, fmt.Errorf("error scanning service: %w", err)
+		}
+		if distance.Valid {
+			service.DistanceKm = &distance.Float64
 		}
 		services = append(services, service)
 	}
 
-	return services, nil
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error searching service: %w", err)
+	}
+
+	return services, total, nil
+}
+
+// sortColumns maps a sort name to its column, direction and the
+// comparison that selects rows after the cursor.
+func sortColumns(sort string) (column, direction, comparison string) {
+	switch sort {
+	case SortPrice:
+		return "price_per_hour", "ASC", ">"
+	case SortDistance:
+		return "distance_km", "ASC", ">"
+	default:
+		return "rating_score", "DESC", "<"
+	}
 }
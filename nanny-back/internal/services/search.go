@@ -0,0 +1,160 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"nanny-backend/internal/common/apperr"
+)
+
+const (
+	SortRating   = "rating"
+	SortPrice    = "price"
+	SortDistance = "distance"
+
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+var ErrInvalidSearch = apperr.Validation("invalid_search", "invalid search")
+
+// SearchFilter holds the /api/services/search parameters. Zero values
+// mean "no filter"; Latitude and Longitude are pointers because 0 is a
+// valid coordinate.
+type SearchFilter struct {
+	Type      string
+	Location  string
+	MinPrice  float64
+	MaxPrice  float64
+	MinRating float64
+	PetType   string
+	// Date is "YYYY-MM-DD": only sitters who work that weekday and are
+	// not on a blackout that day are returned.
+	Date string
+	// Latitude/Longitude is where the pet is looked after. Only sitters
+	// whose service radius reaches it are returned.
+	Latitude  *float64
+	Longitude *float64
+	// HomeOwnerID searches around this owner's saved home instead of
+	// Latitude/Longitude.
+	HomeOwnerID int
+	RadiusKm    float64
+	Sort        string
+	Cursor      string
+	Limit       int
+}
+
+// SearchResult is one page of results. Total counts every match, not
+// only this page; NextCursor is empty on the last page.
+type SearchResult struct {
+	Services   []ServiceWithSitter `json:"services"`
+	Total      int                 `json:"total"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// Cursor is the sort key of the last row of a page. Rows are ordered by
+// the sort value and then by service ID, so the pair is unique.
+type Cursor struct {
+	Sort      string  `json:"s"`
+	Value     float64 `json:"v"`
+	ServiceID int     `json:"id"`
+}
+
+func encodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s, sort string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidSearch)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ServiceID <= 0 {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidSearch)
+	}
+
+	if c.Sort != sort {
+		return nil, fmt.Errorf("%w: cursor belongs to a search with another sort order", ErrInvalidSearch)
+	}
+
+	return &c, nil
+}
+
+// normalize fills defaults and rejects contradictory parameters.
+func (f *SearchFilter) normalize() error {
+	if f.Sort == "" {
+		f.Sort = SortRating
+	}
+
+	switch f.Sort {
+	case SortRating, SortPrice, SortDistance:
+	default:
+		return fmt.Errorf("%w: sort must be one of rating, price, distance", ErrInvalidSearch)
+	}
+
+	if f.Limit <= 0 {
+		f.Limit = DefaultSearchLimit
+	}
+	if f.Limit > MaxSearchLimit {
+		f.Limit = MaxSearchLimit
+	}
+
+	if f.MinPrice < 0 || f.MaxPrice < 0 {
+		return fmt.Errorf("%w: price cannot be negative", ErrInvalidSearch)
+	}
+	if f.MaxPrice > 0 && f.MinPrice > f.MaxPrice {
+		return fmt.Errorf("%w: min_price is above max_price", ErrInvalidSearch)
+	}
+
+	if f.MinRating < 0 || f.MinRating > 5 {
+		return fmt.Errorf("%w: min_rating must be between 0 and 5", ErrInvalidSearch)
+	}
+
+	if f.PetType != "" && !validPetTypes[f.PetType] {
+		return fmt.Errorf("%w: pet_type must be one of cat, dog, rodent", ErrInvalidSearch)
+	}
+
+	if f.Date != "" {
+		if _, err := time.Parse("2006-01-02", f.Date); err != nil {
+			return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidSearch)
+		}
+	}
+
+	if (f.Latitude == nil) != (f.Longitude == nil) {
+		return fmt.Errorf("%w: lat and lng go together", ErrInvalidSearch)
+	}
+
+	if f.Latitude != nil {
+		if *f.Latitude < -90 || *f.Latitude > 90 || *f.Longitude < -180 || *f.Longitude > 180 {
+			return fmt.Errorf("%w: coordinates out of range", ErrInvalidSearch)
+		}
+	} else if f.RadiusKm > 0 || f.Sort == SortDistance {
+		return fmt.Errorf("%w: radius and distance sort need lat and lng", ErrInvalidSearch)
+	}
+
+	if f.RadiusKm < 0 {
+		return fmt.Errorf("%w: radius_km cannot be negative", ErrInvalidSearch)
+	}
+
+	return nil
+}
+
+// sortValue is what the cursor stores for the given row.
+func sortValue(sort string, s ServiceWithSitter) float64 {
+	switch sort {
+	case SortPrice:
+		return s.PricePerHour
+	case SortDistance:
+		if s.DistanceKm != nil {
+			return *s.DistanceKm
+		}
+		return 0
+	default:
+		return s.RatingScore
+	}
+}
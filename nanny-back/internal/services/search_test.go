@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nanny-backend/internal/common/geo"
+	"nanny-backend/internal/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// searchRepository only implements SearchServices; the other methods
+// panic through the nil embedded interface.
+type searchRepository struct {
+	Repository
+	rows   []ServiceWithSitter
+	total  int
+	filter SearchFilter
+	after  *Cursor
+}
+
+func (r *searchRepository) SearchServices(ctx context.Context, filter SearchFilter, after *Cursor) ([]ServiceWithSitter, int, error) {
+	r.filter, r.after = filter, after
+	return r.rows, r.total, nil
+}
+
+func TestSearchServices_NextCursor(t *testing.T) {
+	repo := &searchRepository{total: 5, rows: []ServiceWithSitter{
+		{Service: models.Service{ServiceID: 1, PricePerHour: 1000}},
+		{Service: models.Service{ServiceID: 2, PricePerHour: 1500}},
+		{Service: models.Service{ServiceID: 3, PricePerHour: 2000}},
+	}}
+	svc := NewService(repo, nil)
+
+	page, err := svc.SearchServices(context.Background(), SearchFilter{Sort: SortPrice, Limit: 2})
+
+	require.NoError(t, err)
+	assert.Len(t, page.Services, 2)
+	assert.Equal(t, 5, page.Total)
+	require.NotEmpty(t, page.NextCursor)
+
+	_, err = svc.SearchServices(context.Background(), SearchFilter{Sort: SortPrice, Limit: 2, Cursor: page.NextCursor})
+
+	require.NoError(t, err)
+	assert.Equal(t, &Cursor{Sort: SortPrice, Value: 1500, ServiceID: 2}, repo.after)
+}
+
+func TestSearchServices_LastPage(t *testing.T) {
+	repo := &searchRepository{total: 1, rows: []ServiceWithSitter{{Service: models.Service{ServiceID: 1}}}}
+
+	page, err := NewService(repo, nil).SearchServices(context.Background(), SearchFilter{})
+
+	require.NoError(t, err)
+	assert.Empty(t, page.NextCursor)
+	assert.Equal(t, SortRating, repo.filter.Sort)
+	assert.Equal(t, DefaultSearchLimit, repo.filter.Limit)
+}
+
+func TestSearchServices_InvalidFilters(t *testing.T) {
+	lat := 43.2
+	price := encodeCursor(Cursor{Sort: SortPrice, Value: 1, ServiceID: 1})
+
+	cases := map[string]SearchFilter{
+		"unknown sort":            {Sort: "name"},
+		"distance without coords": {Sort: SortDistance},
+		"radius without coords":   {RadiusKm: 5},
+		"lat without lng":         {Latitude: &lat},
+		"min above max price":     {MinPrice: 3000, MaxPrice: 1000},
+		"rating out of range":     {MinRating: 6},
+		"unknown pet type":        {PetType: "fish"},
+		"bad date":                {Date: "19.10.2026"},
+		"cursor of another sort":  {Cursor: price},
+		"garbage cursor":          {Cursor: "%%%"},
+	}
+
+	for name, filter := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewService(&searchRepository{}, nil).SearchServices(context.Background(), filter)
+			assert.ErrorIs(t, err, ErrInvalidSearch)
+		})
+	}
+}
+
+func TestHandler_SearchServices_InvalidParams(t *testing.T) {
+	handler := NewHandler(NewService(&searchRepository{}, nil))
+
+	for _, query := range []string{
+		"min_price=cheap", "sort=distance", "lat=43.2&lng=abc",
+		"lat=NaN&lng=76.9", "lat=43.2&lng=Inf", "max_price=-Inf", "radius_km=nan",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/services/search?"+query, nil)
+		rec := httptest.NewRecorder()
+
+		handler.SearchServices(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+type fakeHomes map[int]geo.Point
+
+func (h fakeHomes) HomePoint(ctx context.Context, ownerID int) (*geo.Point, error) {
+	if point, ok := h[ownerID]; ok {
+		return &point, nil
+	}
+	return nil, nil
+}
+
+func TestSearchServices_NearHome(t *testing.T) {
+	repo := &searchRepository{}
+	svc := NewService(repo, fakeHomes{1: {Latitude: 43.24, Longitude: 76.89}})
+
+	_, err := svc.SearchServices(context.Background(), SearchFilter{HomeOwnerID: 1, Sort: SortDistance})
+
+	require.NoError(t, err)
+	require.NotNil(t, repo.filter.Latitude)
+	assert.Equal(t, 43.24, *repo.filter.Latitude)
+	assert.Equal(t, 76.89, *repo.filter.Longitude)
+
+	_, err = svc.SearchServices(context.Background(), SearchFilter{HomeOwnerID: 2})
+	assert.ErrorIs(t, err, ErrInvalidSearch)
+}
+
+func TestHandler_SearchServices_NearHomeNeedsLogin(t *testing.T) {
+	handler := NewHandler(NewService(&searchRepository{}, fakeHomes{}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/services/search?near=home", nil)
+	rec := httptest.NewRecorder()
+
+	handler.SearchServices(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
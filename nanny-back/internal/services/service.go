@@ -1,74 +1,119 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 
+	"nanny-backend/internal/audit"
+	"nanny-backend/internal/common/apperr"
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/geo"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/listing"
+	"nanny-backend/internal/common/middleware"
 	"nanny-backend/internal/common/models"
-
-	"github.com/gorilla/mux"
 )
 
 type Service interface {
-	CreateService(sitterID int, serviceType string, pricePerHour float64, description string) (int, error)
-	GetService(serviceID int) (*models.Service, error)
-	GetSitterServices(sitterID int) ([]models.Service, error)
-	UpdateService(serviceID int, serviceType string, pricePerHour float64, description string) error
-	DeleteService(serviceID int) error
-	SearchServices(serviceType, location string) ([]ServiceWithSitter, error)
+	CreateService(ctx context.Context, actor authz.Actor, serviceType string, pricePerHour float64, description string, petTypes []string) (int, error)
+	GetService(ctx context.Context, serviceID int) (*models.Service, error)
+	GetSitterServices(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Service], error)
+	UpdateService(ctx context.Context, actor authz.Actor, serviceID int, serviceType string, pricePerHour float64, description string, petTypes []string) error
+	DeleteService(ctx context.Context, actor authz.Actor, serviceID int) error
+	SearchServices(ctx context.Context, filter SearchFilter) (*SearchResult, error)
+}
+
+var validPetTypes = map[string]bool{"cat": true, "dog": true, "rodent": true}
+
+var (
+	ErrInvalidServiceType = apperr.Validation("invalid_service_type", "incorrect type of service. Allowed: walking, boarding, home-care")
+	ErrInvalidPrice       = apperr.Validation("invalid_price", "price must be more than 0")
+	ErrInvalidPetType     = apperr.Validation("invalid_pet_type", "incorrect pet type. Allowed: cat, dog, rodent")
+	ErrLoginRequired      = apperr.Unauthorized("login_required", "log in to search near your home")
+)
+
+// HomeLocator is implemented by the locations module.
+type HomeLocator interface {
+	HomePoint(ctx context.Context, ownerID int) (*geo.Point, error)
 }
 
 type service struct {
-	repo Repository
+	repo  Repository
+	homes HomeLocator
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, homes HomeLocator) Service {
+	return &service{repo: repo, homes: homes}
 }
 
-func (s *service) CreateService(sitterID int, serviceType string, pricePerHour float64, description string) (int, error) {
+func (s *service) CreateService(ctx context.Context, actor authz.Actor, serviceType string, pricePerHour float64, description string, petTypes []string) (int, error) {
+	if actor.UserID <= 0 {
+		return 0, authz.ErrForbidden
+	}
+
 	validTypes := map[string]bool{"walking": true, "boarding": true, "home-care": true}
 	if !validTypes[serviceType] {
-		return 0, fmt.Errorf("incorrect type of service. Allowed: walking, boarding, home-care")
+		return 0, ErrInvalidServiceType
 	}
 
 	if pricePerHour <= 0 {
-		return 0, fmt.Errorf("price must be more than 0")
+		return 0, ErrInvalidPrice
+	}
+
+	petTypes, err := normalizePetTypes(petTypes)
+	if err != nil {
+		return 0, err
 	}
 
 	srv := &models.Service{
-		SitterID:     sitterID,
+		SitterID:     actor.UserID,
 		Type:         serviceType,
 		PricePerHour: pricePerHour,
 		Description:  description,
+		PetTypes:     petTypes,
 	}
 
-	serviceID, err := s.repo.Create(srv)
+	serviceID, err := s.repo.Create(ctx, srv)
 	if err != nil {
 		return 0, fmt.Errorf("error creating service: %w", err)
 	}
 
+	srv.ServiceID = serviceID
+	audit.Describe(ctx, "service.create", "service", serviceID, nil, srv)
+
 	return serviceID, nil
 }
 
-func (s *service) GetService(serviceID int) (*models.Service, error) {
-	return s.repo.GetByID(serviceID)
+func (s *service) GetService(ctx context.Context, serviceID int) (*models.Service, error) {
+	return s.repo.GetByID(ctx, serviceID)
 }
 
-func (s *service) GetSitterServices(sitterID int) ([]models.Service, error) {
-	return s.repo.GetBySitterID(sitterID)
+func (s *service) GetSitterServices(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Service], error) {
+	return s.repo.GetBySitterID(ctx, sitterID, q)
 }
 
-func (s *service) UpdateService(serviceID int, serviceType string, pricePerHour float64, description string) error {
+func (s *service) UpdateService(ctx context.Context, actor authz.Actor, serviceID int, serviceType string, pricePerHour float64, description string, petTypes []string) error {
 	validTypes := map[string]bool{"walking": true, "boarding": true, "home-care": true}
 	if !validTypes[serviceType] {
-		return fmt.Errorf("incorrect type of service. Allowed: walking, boarding, home-care")
+		return ErrInvalidServiceType
 	}
 
 	if pricePerHour <= 0 {
-		return fmt.Errorf("price must be more than 0")
+		return ErrInvalidPrice
+	}
+
+	petTypes, err := normalizePetTypes(petTypes)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.checkOwnership(ctx, actor, serviceID)
+	if err != nil {
+		return err
 	}
 
 	srv := &models.Service{
@@ -76,17 +121,114 @@ func (s *service) UpdateService(serviceID int, serviceType string, pricePerHour
 		Type:         serviceType,
 		PricePerHour: pricePerHour,
 		Description:  description,
+		PetTypes:     petTypes,
 	}
 
-	return s.repo.Update(srv)
+	if err := s.repo.Update(ctx, srv); err != nil {
+		return err
+	}
+
+	srv.SitterID = current.SitterID
+	audit.Describe(ctx, "service.update", "service", serviceID, current, srv)
+	return nil
+}
+
+func (s *service) DeleteService(ctx context.Context, actor authz.Actor, serviceID int) error {
+	current, err := s.checkOwnership(ctx, actor, serviceID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, serviceID); err != nil {
+		return err
+	}
+
+	audit.Describe(ctx, "service.delete", "service", serviceID, current, nil)
+	return nil
 }
 
-func (s *service) DeleteService(serviceID int) error {
-	return s.repo.Delete(serviceID)
+// checkOwnership loads the service if actor may change it.
+func (s *service) checkOwnership(ctx context.Context, actor authz.Actor, serviceID int) (*models.Service, error) {
+	srv, err := s.repo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !actor.CanActAs(srv.SitterID) {
+		return nil, fmt.Errorf("service belongs to another nanny: %w", authz.ErrForbidden)
+	}
+
+	return srv, nil
+}
+
+// normalizePetTypes defaults to every pet type and drops duplicates.
+func normalizePetTypes(petTypes []string) ([]string, error) {
+	if len(petTypes) == 0 {
+		return []string{"cat", "dog", "rodent"}, nil
+	}
+
+	seen := map[string]bool{}
+	result := make([]string, 0, len(petTypes))
+	for _, petType := range petTypes {
+		if !validPetTypes[petType] {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPetType, petType)
+		}
+		if !seen[petType] {
+			seen[petType] = true
+			result = append(result, petType)
+		}
+	}
+
+	return result, nil
 }
 
-func (s *service) SearchServices(serviceType, location string) ([]ServiceWithSitter, error) {
-	return s.repo.SearchServices(serviceType, location)
+func (s *service) SearchServices(ctx context.Context, filter SearchFilter) (*SearchResult, error) {
+	if filter.HomeOwnerID > 0 {
+		if filter.Latitude != nil || filter.Longitude != nil {
+			return nil, fmt.Errorf("%w: near=home cannot be combined with lat and lng", ErrInvalidSearch)
+		}
+
+		home, err := s.homes.HomePoint(ctx, filter.HomeOwnerID)
+		if err != nil {
+			return nil, err
+		}
+		if home == nil {
+			return nil, fmt.Errorf("%w: set your home address first", ErrInvalidSearch)
+		}
+
+		filter.Latitude, filter.Longitude = &home.Latitude, &home.Longitude
+	}
+
+	if err := filter.normalize(); err != nil {
+		return nil, err
+	}
+
+	var after *Cursor
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor, filter.Sort)
+		if err != nil {
+			return nil, err
+		}
+		after = cursor
+	}
+
+	services, total, err := s.repo.SearchServices(ctx, filter, after)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{Services: services, Total: total}
+	if len(services) > filter.Limit {
+		result.Services = services[:filter.Limit]
+		last := result.Services[filter.Limit-1]
+		result.NextCursor = encodeCursor(Cursor{
+			Sort:      filter.Sort,
+			Value:     sortValue(filter.Sort, last),
+			ServiceID: last.ServiceID,
+		})
+	}
+
+	return result, nil
 }
 
 type Handler struct {
@@ -98,134 +240,197 @@ func NewHandler(service Service) *Handler {
 }
 
 type CreateServiceRequest struct {
-	SitterID     int     `json:"sitter_id"`
-	Type         string  `json:"type"`
-	PricePerHour float64 `json:"price_per_hour"`
-	Description  string  `json:"description,omitempty"`
+	Type         string   `json:"type"`
+	PricePerHour float64  `json:"price_per_hour"`
+	Description  string   `json:"description,omitempty"`
+	PetTypes     []string `json:"pet_types,omitempty"`
 }
 
 type UpdateServiceRequest struct {
-	Type         string  `json:"type"`
-	PricePerHour float64 `json:"price_per_hour"`
-	Description  string  `json:"description,omitempty"`
+	Type         string   `json:"type"`
+	PricePerHour float64  `json:"price_per_hour"`
+	Description  string   `json:"description,omitempty"`
+	PetTypes     []string `json:"pet_types,omitempty"`
 }
 
 func (h *Handler) CreateService(w http.ResponseWriter, r *http.Request) {
 	var req CreateServiceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
+		httpx.Error(w, r, httpx.ErrMalformedBody)
 		return
 	}
 
-	serviceID, err := h.service.CreateService(req.SitterID, req.Type, req.PricePerHour, req.Description)
+	actor := middleware.ActorFromContext(r.Context())
+
+	serviceID, err := h.service.CreateService(r.Context(), actor, req.Type, req.PricePerHour, req.Description, req.PetTypes)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+	httpx.JSON(w, http.StatusCreated, map[string]interface{}{
 		"message":    "service created succesfully",
 		"service_id": serviceID,
 	})
 }
 
 func (h *Handler) GetService(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	serviceID, err := strconv.Atoi(vars["id"])
+	serviceID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID service")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	service, err := h.service.GetService(serviceID)
+	service, err := h.service.GetService(r.Context(), serviceID)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, service)
+	httpx.JSON(w, http.StatusOK, service)
 }
 
 func (h *Handler) GetSitterServices(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	sitterID, err := strconv.Atoi(vars["sitter_id"])
+	sitterID, err := httpx.PathID(r, "sitter_id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID nanny")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	services, err := h.service.GetSitterServices(sitterID)
+	q, err := ListSpec.Parse(r.URL.Query())
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, services)
+	page, err := h.service.GetSitterServices(r.Context(), sitterID, q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, page)
 }
 
 func (h *Handler) UpdateService(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	serviceID, err := strconv.Atoi(vars["id"])
+	serviceID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID service")
+		httpx.Error(w, r, err)
 		return
 	}
 
 	var req UpdateServiceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect data")
+		httpx.Error(w, r, httpx.ErrMalformedBody)
 		return
 	}
 
-	err = h.service.UpdateService(serviceID, req.Type, req.PricePerHour, req.Description)
+	actor := middleware.ActorFromContext(r.Context())
+
+	err = h.service.UpdateService(r.Context(), actor, serviceID, req.Type, req.PricePerHour, req.Description, req.PetTypes)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
+	httpx.JSON(w, http.StatusOK, map[string]string{
 		"message": "service updated succesfully",
 	})
 }
 
 func (h *Handler) DeleteService(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	serviceID, err := strconv.Atoi(vars["id"])
+	serviceID, err := httpx.PathID(r, "id")
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "incorrect ID service")
+		httpx.Error(w, r, err)
 		return
 	}
 
-	err = h.service.DeleteService(serviceID)
+	actor := middleware.ActorFromContext(r.Context())
+
+	err = h.service.DeleteService(r.Context(), actor, serviceID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		httpx.Error(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
+	httpx.JSON(w, http.StatusOK, map[string]string{
 		"message": "service deleted succesfully",
 	})
 }
 
+// SearchServices handles GET /api/services/search. See SearchFilter for
+// the parameters; numbers that do not parse are rejected with 400.
 func (h *Handler) SearchServices(w http.ResponseWriter, r *http.Request) {
-	serviceType := r.URL.Query().Get("type")
-	location := r.URL.Query().Get("location")
+	query := r.URL.Query()
+	filter := SearchFilter{
+		Type:     query.Get("type"),
+		Location: query.Get("location"),
+		PetType:  query.Get("pet_type"),
+		Date:     query.Get("date"),
+		Sort:     query.Get("sort"),
+		Cursor:   query.Get("cursor"),
+	}
 
-	services, err := h.service.SearchServices(serviceType, location)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	switch query.Get("near") {
+	case "":
+	case "home":
+		actor := middleware.ActorFromContext(r.Context())
+		if actor.UserID <= 0 {
+			httpx.Error(w, r, ErrLoginRequired)
+			return
+		}
+		filter.HomeOwnerID = actor.UserID
+	default:
+		httpx.Error(w, r, fmt.Errorf("%w: near must be home", ErrInvalidSearch))
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, services)
-}
+	var parseErr error
+	number := func(name string) float64 {
+		value := query.Get(name)
+		if value == "" {
+			return 0
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		// ParseFloat takes "NaN" and "Inf", which no range check catches.
+		if (err != nil || math.IsNaN(f) || math.IsInf(f, 0)) && parseErr == nil {
+			parseErr = fmt.Errorf("%w: %s must be a number", ErrInvalidSearch, name)
+		}
+		return f
+	}
+	coordinate := func(name string) *float64 {
+		if query.Get(name) == "" {
+			return nil
+		}
+		f := number(name)
+		return &f
+	}
 
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
-}
+	filter.MinPrice = number("min_price")
+	filter.MaxPrice = number("max_price")
+	filter.MinRating = number("min_rating")
+	filter.RadiusKm = number("radius_km")
+	filter.Latitude = coordinate("lat")
+	filter.Longitude = coordinate("lng")
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil && parseErr == nil {
+			parseErr = fmt.Errorf("%w: limit must be a number", ErrInvalidSearch)
+		}
+		filter.Limit = n
+	}
+
+	if parseErr != nil {
+		httpx.Error(w, r, parseErr)
+		return
+	}
+
+	result, err := h.service.SearchServices(r.Context(), filter)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
 
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(payload)
+	httpx.JSON(w, http.StatusOK, result)
 }
@@ -1,13 +1,19 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"testing"
 
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateServiceRepository(t *testing.T) {
@@ -29,13 +35,14 @@ func TestCreateServiceRepository(t *testing.T) {
 		Type:         "walking",
 		PricePerHour: 2500,
 		Description:  "Dog walking",
+		PetTypes:     []string{"dog"},
 	}
 
 	mock.ExpectQuery("INSERT INTO services").
-		WithArgs(srv.SitterID, srv.Type, srv.PricePerHour, srv.Description).
+		WithArgs(srv.SitterID, srv.Type, srv.PricePerHour, srv.Description, pq.Array(srv.PetTypes)).
 		WillReturnRows(sqlmock.NewRows([]string{"service_id"}).AddRow(1))
 
-	id, err := repo.Create(srv)
+	id, err := repo.Create(context.Background(), srv)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -62,14 +69,14 @@ func TestGetByIDRepository(t *testing.T) {
 
 	repo := NewRepository(db)
 
-	rows := sqlmock.NewRows([]string{"service_id", "sitter_id", "type", "price_per_hour", "description"}).
-		AddRow(1, 2, "walking", 2500.0, "Dog walking")
+	rows := sqlmock.NewRows([]string{"service_id", "sitter_id", "type", "price_per_hour", "description", "pet_types"}).
+		AddRow(1, 2, "walking", 2500.0, "Dog walking", "{dog}")
 
 	mock.ExpectQuery("SELECT (.+) FROM services WHERE service_id").
 		WithArgs(1).
 		WillReturnRows(rows)
 
-	srv, err := repo.GetByID(1)
+	srv, err := repo.GetByID(context.Background(), 1)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -100,7 +107,7 @@ func TestGetByIDNotFound(t *testing.T) {
 		WithArgs(999).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err = repo.GetByID(999)
+	_, err = repo.GetByID(context.Background(), 999)
 	if err == nil {
 		t.Error("expected error for non-existent service")
 	}
@@ -124,20 +131,22 @@ func TestGetBySitterIDRepository(t *testing.T) {
 
 	repo := NewRepository(db)
 
-	rows := sqlmock.NewRows([]string{"service_id", "sitter_id", "type", "price_per_hour", "description"}).
-		AddRow(1, 2, "walking", 2500.0, "Dog walking").
-		AddRow(2, 2, "boarding", 5000.0, "Pet boarding")
+	rows := sqlmock.NewRows([]string{"service_id", "sitter_id", "type", "price_per_hour", "description", "pet_types"}).
+		AddRow(1, 2, "walking", 2500.0, "Dog walking", "{dog}").
+		AddRow(2, 2, "boarding", 5000.0, "Pet boarding", "{cat,dog,rodent}")
 
-	mock.ExpectQuery("SELECT (.+) FROM services WHERE sitter_id").
-		WithArgs(2).
+	q, _ := ListSpec.Parse(nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM services WHERE sitter_id (.+) ORDER BY service_id ASC").
+		WithArgs(2, listing.DefaultLimit+1).
 		WillReturnRows(rows)
 
-	services, err := repo.GetBySitterID(2)
+	page, err := repo.GetBySitterID(context.Background(), 2, q)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	if len(services) != 2 {
-		t.Errorf("expected 2 services, got %d", len(services))
+	if len(page.Items) != 2 {
+		t.Errorf("expected 2 services, got %d", len(page.Items))
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -168,10 +177,10 @@ func TestUpdateRepository(t *testing.T) {
 	}
 
 	mock.ExpectExec("UPDATE services SET").
-		WithArgs(srv.Type, srv.PricePerHour, srv.Description, srv.ServiceID).
+		WithArgs(srv.Type, srv.PricePerHour, srv.Description, pq.Array(srv.PetTypes), srv.ServiceID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Update(srv)
+	err = repo.Update(context.Background(), srv)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -199,7 +208,7 @@ func TestDeleteRepository(t *testing.T) {
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Delete(1)
+	err = repo.Delete(context.Background(), 1)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -209,75 +218,78 @@ func TestDeleteRepository(t *testing.T) {
 	}
 }
 
+var searchColumns = []string{
+	"service_id", "sitter_id", "type", "price_per_hour", "description", "pet_types",
+	"sitter_name", "location", "experience_years", "service_radius_km", "sitter_rating", "review_count", "rating_score", "distance_km",
+}
+
 func TestSearchServicesRepository(t *testing.T) {
 	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
-	}
-	defer func(db *sql.DB) {
-		err := db.Close()
-		if err != nil {
-
-		}
-	}(db)
+	require.NoError(t, err)
+	defer db.Close()
 
 	repo := NewRepository(db)
 
-	rows := sqlmock.NewRows([]string{
-		"service_id", "sitter_id", "type", "price_per_hour", "description",
-		"full_name", "rating",
-	}).AddRow(1, 2, "walking", 2500.0, "Dog walking", "John Doe", 4.5)
-
-	mock.ExpectQuery("SELECT (.+) FROM services").
-		WillReturnRows(rows)
-
-	services, err := repo.SearchServices("", "")
-	if err != nil {
-		t.Errorf("unexpected error: %v", err)
-	}
-	if len(services) == 0 {
-		t.Error("expected at least one service")
-	}
-
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("unmet expectations: %v", err)
-	}
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`(?s)LEFT JOIN sitter_stats ss.*ORDER BY rating_score DESC, service_id ASC LIMIT \$1`).
+		WithArgs(21).
+		WillReturnRows(sqlmock.NewRows(searchColumns).
+			AddRow(1, 2, "walking", 2500.0, "Dog walking", "{dog}", "John Doe", "Almaty", 3, 10.0, 4.5, 12, 4.4, nil))
+
+	services, total, err := repo.SearchServices(context.Background(), SearchFilter{Sort: SortRating, Limit: 20}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, services, 1)
+	assert.Equal(t, 12, services[0].ReviewCount)
+	assert.Equal(t, 4.4, services[0].RatingScore)
+	assert.Equal(t, 3, services[0].ExperienceYears)
+	assert.Equal(t, []string{"dog"}, services[0].PetTypes)
+	assert.Nil(t, services[0].DistanceKm)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestSearchWithFilters(t *testing.T) {
 	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
-	}
-	defer func(db *sql.DB) {
-		err := db.Close()
-		if err != nil {
-
-		}
-	}(db)
+	require.NoError(t, err)
+	defer db.Close()
 
 	repo := NewRepository(db)
 
-	rows := sqlmock.NewRows([]string{
-		"service_id", "sitter_id", "type", "price_per_hour", "description",
-		"full_name", "rating",
-	}).AddRow(1, 2, "walking", 2500.0, "Dog walking", "John Doe", 4.5)
-
-	mock.ExpectQuery("SELECT (.+) FROM services").
-		WithArgs("walking", "%Almaty%").
-		WillReturnRows(rows)
-
-	services, err := repo.SearchServices("walking", "Almaty")
-	if err != nil {
-		t.Errorf("unexpected error: %v", err)
-	}
-	if len(services) != 1 {
-		t.Errorf("expected 1 service, got %d", len(services))
-	}
-
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("unmet expectations: %v", err)
-	}
+	lat, lng := 43.24, 76.89
+	filter := SearchFilter{
+		Type:      "walking",
+		Location:  "Almaty",
+		MinPrice:  1000,
+		MaxPrice:  3000,
+		MinRating: 4,
+		PetType:   "dog",
+		Date:      "2026-10-19",
+		Latitude:  &lat,
+		Longitude: &lng,
+		RadiusKm:  5,
+		Sort:      SortDistance,
+		Limit:     10,
+	}
+	filterArgs := []driver.Value{lat, lng, "walking", "%Almaty%", 1000.0, 3000.0, "dog", "2026-10-19", 4.0, 5.0}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM`).
+		WithArgs(filterArgs...).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+	mock.ExpectQuery(`(?s)availability_blackouts.*distance_km <= service_radius_km AND distance_km <= \$10 AND \(distance_km > \$11 OR \(distance_km = \$11 AND service_id > \$12\)\) ORDER BY distance_km ASC`).
+		WithArgs(append(filterArgs, 1.5, 3, 11)...).
+		WillReturnRows(sqlmock.NewRows(searchColumns).
+			AddRow(4, 2, "walking", 2500.0, "Dog walking", "{cat,dog}", "John Doe", "Almaty", 3, 10.0, 4.5, 12, 4.4, 2.25))
+
+	services, total, err := repo.SearchServices(context.Background(), filter, &Cursor{Sort: SortDistance, Value: 1.5, ServiceID: 3})
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, total)
+	require.Len(t, services, 1)
+	require.NotNil(t, services[0].DistanceKm)
+	assert.Equal(t, 2.25, *services[0].DistanceKm)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestRepositoryErrors(t *testing.T) {
@@ -299,7 +311,7 @@ func TestRepositoryErrors(t *testing.T) {
 		mock.ExpectQuery("INSERT INTO services").
 			WillReturnError(errors.New("db error"))
 
-		_, err := repo.Create(srv)
+		_, err := repo.Create(context.Background(), srv)
 		if err == nil {
 			t.Error("expected error")
 		}
@@ -310,7 +322,7 @@ func TestRepositoryErrors(t *testing.T) {
 		mock.ExpectExec("UPDATE services").
 			WillReturnError(errors.New("db error"))
 
-		err := repo.Update(srv)
+		err := repo.Update(context.Background(), srv)
 		if err == nil {
 			t.Error("expected error")
 		}
@@ -320,7 +332,7 @@ func TestRepositoryErrors(t *testing.T) {
 		mock.ExpectExec("DELETE FROM services").
 			WillReturnError(errors.New("db error"))
 
-		err := repo.Delete(1)
+		err := repo.Delete(context.Background(), 1)
 		if err == nil {
 			t.Error("expected error")
 		}
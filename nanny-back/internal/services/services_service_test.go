@@ -2,12 +2,16 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"nanny-backend/internal/common/authz"
+	"nanny-backend/internal/common/httpx"
+	"nanny-backend/internal/common/listing"
 	"nanny-backend/internal/common/models"
 
 	"github.com/gorilla/mux"
@@ -15,66 +19,65 @@ import (
 )
 
 type mockServiceForHandler struct {
-	createServiceFunc     func(int, string, float64, string) (int, error)
+	createServiceFunc     func(authz.Actor, string, float64, string, []string) (int, error)
 	getServiceFunc        func(int) (*models.Service, error)
-	getSitterServicesFunc func(int) ([]models.Service, error)
-	updateServiceFunc     func(int, string, float64, string) error
-	deleteServiceFunc     func(int) error
-	searchServicesFunc    func(string, string) ([]ServiceWithSitter, error)
+	getSitterServicesFunc func(int, listing.Query) (*listing.Page[models.Service], error)
+	updateServiceFunc     func(authz.Actor, int, string, float64, string, []string) error
+	deleteServiceFunc     func(authz.Actor, int) error
+	searchServicesFunc    func(SearchFilter) (*SearchResult, error)
 }
 
-func (m *mockServiceForHandler) CreateService(sitterID int, serviceType string, pricePerHour float64, description string) (int, error) {
+func (m *mockServiceForHandler) CreateService(ctx context.Context, actor authz.Actor, serviceType string, pricePerHour float64, description string, petTypes []string) (int, error) {
 	if m.createServiceFunc != nil {
-		return m.createServiceFunc(sitterID, serviceType, pricePerHour, description)
+		return m.createServiceFunc(actor, serviceType, pricePerHour, description, petTypes)
 	}
 	return 1, nil
 }
 
-func (m *mockServiceForHandler) GetService(serviceID int) (*models.Service, error) {
+func (m *mockServiceForHandler) GetService(ctx context.Context, serviceID int) (*models.Service, error) {
 	if m.getServiceFunc != nil {
 		return m.getServiceFunc(serviceID)
 	}
 	return &models.Service{ServiceID: serviceID}, nil
 }
 
-func (m *mockServiceForHandler) GetSitterServices(sitterID int) ([]models.Service, error) {
+func (m *mockServiceForHandler) GetSitterServices(ctx context.Context, sitterID int, q listing.Query) (*listing.Page[models.Service], error) {
 	if m.getSitterServicesFunc != nil {
-		return m.getSitterServicesFunc(sitterID)
+		return m.getSitterServicesFunc(sitterID, q)
 	}
-	return []models.Service{{ServiceID: 1}}, nil
+	return ListSpec.Page([]models.Service{{ServiceID: 1}}, q), nil
 }
 
-func (m *mockServiceForHandler) UpdateService(serviceID int, serviceType string, pricePerHour float64, description string) error {
+func (m *mockServiceForHandler) UpdateService(ctx context.Context, actor authz.Actor, serviceID int, serviceType string, pricePerHour float64, description string, petTypes []string) error {
 	if m.updateServiceFunc != nil {
-		return m.updateServiceFunc(serviceID, serviceType, pricePerHour, description)
+		return m.updateServiceFunc(actor, serviceID, serviceType, pricePerHour, description, petTypes)
 	}
 	return nil
 }
 
-func (m *mockServiceForHandler) DeleteService(serviceID int) error {
+func (m *mockServiceForHandler) DeleteService(ctx context.Context, actor authz.Actor, serviceID int) error {
 	if m.deleteServiceFunc != nil {
-		return m.deleteServiceFunc(serviceID)
+		return m.deleteServiceFunc(actor, serviceID)
 	}
 	return nil
 }
 
-func (m *mockServiceForHandler) SearchServices(serviceType, location string) ([]ServiceWithSitter, error) {
+func (m *mockServiceForHandler) SearchServices(ctx context.Context, filter SearchFilter) (*SearchResult, error) {
 	if m.searchServicesFunc != nil {
-		return m.searchServicesFunc(serviceType, location)
+		return m.searchServicesFunc(filter)
 	}
-	return []ServiceWithSitter{{Service: models.Service{ServiceID: 1}}}, nil
+	return &SearchResult{Services: []ServiceWithSitter{{Service: models.Service{ServiceID: 1}}}, Total: 1}, nil
 }
 
 func TestHandler_CreateService_Success(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		createServiceFunc: func(sitterID int, serviceType string, pricePerHour float64, description string) (int, error) {
+		createServiceFunc: func(actor authz.Actor, serviceType string, pricePerHour float64, description string, petTypes []string) (int, error) {
 			return 123, nil
 		},
 	}
 	handler := NewHandler(mockSvc)
 
 	reqBody := CreateServiceRequest{
-		SitterID:     1,
 		Type:         "walking",
 		PricePerHour: 2500,
 		Description:  "Dog walking service",
@@ -109,21 +112,20 @@ func TestHandler_CreateService_InvalidBody(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect data")
+	assert.Equal(t, "malformed_body", resp.Code)
 }
 
 func TestHandler_CreateService_ServiceError(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		createServiceFunc: func(sitterID int, serviceType string, pricePerHour float64, description string) (int, error) {
-			return 0, errors.New("incorrect type service")
+		createServiceFunc: func(actor authz.Actor, serviceType string, pricePerHour float64, description string, petTypes []string) (int, error) {
+			return 0, ErrInvalidServiceType
 		},
 	}
 	handler := NewHandler(mockSvc)
 
 	reqBody := CreateServiceRequest{
-		SitterID:     1,
 		Type:         "invalid",
 		PricePerHour: 2500,
 		Description:  "Test",
@@ -138,9 +140,9 @@ func TestHandler_CreateService_ServiceError(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect type service")
+	assert.Equal(t, "invalid_service_type", resp.Code)
 }
 
 func TestHandler_GetService_Success(t *testing.T) {
@@ -183,15 +185,15 @@ func TestHandler_GetService_InvalidID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect ID service")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_GetService_NotFound(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
 		getServiceFunc: func(serviceID int) (*models.Service, error) {
-			return nil, errors.New("servie not found")
+			return nil, ErrServiceNotFound
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -204,18 +206,18 @@ func TestHandler_GetService_NotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "servie not found")
+	assert.Equal(t, "service_not_found", resp.Code)
 }
 
 func TestHandler_GetSitterServices_Success(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		getSitterServicesFunc: func(sitterID int) ([]models.Service, error) {
-			return []models.Service{
+		getSitterServicesFunc: func(sitterID int, q listing.Query) (*listing.Page[models.Service], error) {
+			return ListSpec.Page([]models.Service{
 				{ServiceID: 1, SitterID: sitterID, Type: "walking", PricePerHour: 2500},
 				{ServiceID: 2, SitterID: sitterID, Type: "boarding", PricePerHour: 5000},
-			}, nil
+			}, q), nil
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -228,11 +230,11 @@ func TestHandler_GetSitterServices_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	var resp []models.Service
+	var resp listing.Page[models.Service]
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Equal(t, 2, len(resp))
-	assert.Equal(t, "walking", resp[0].Type)
-	assert.Equal(t, "boarding", resp[1].Type)
+	assert.Equal(t, 2, len(resp.Items))
+	assert.Equal(t, "walking", resp.Items[0].Type)
+	assert.Equal(t, "boarding", resp.Items[1].Type)
 }
 
 func TestHandler_GetSitterServices_InvalidID(t *testing.T) {
@@ -247,14 +249,14 @@ func TestHandler_GetSitterServices_InvalidID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect ID nanny")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_GetSitterServices_Error(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		getSitterServicesFunc: func(sitterID int) ([]models.Service, error) {
+		getSitterServicesFunc: func(sitterID int, q listing.Query) (*listing.Page[models.Service], error) {
 			return nil, errors.New("database error")
 		},
 	}
@@ -268,14 +270,15 @@ func TestHandler_GetSitterServices_Error(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "database error")
+	assert.Equal(t, "internal_error", resp.Code)
+	assert.NotContains(t, rec.Body.String(), "database error")
 }
 
 func TestHandler_UpdateService_Success(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		updateServiceFunc: func(serviceID int, serviceType string, pricePerHour float64, description string) error {
+		updateServiceFunc: func(actor authz.Actor, serviceID int, serviceType string, pricePerHour float64, description string, petTypes []string) error {
 			return nil
 		},
 	}
@@ -320,9 +323,9 @@ func TestHandler_UpdateService_InvalidID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect ID service")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_UpdateService_InvalidBody(t *testing.T) {
@@ -337,15 +340,15 @@ func TestHandler_UpdateService_InvalidBody(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect data")
+	assert.Equal(t, "malformed_body", resp.Code)
 }
 
 func TestHandler_UpdateService_ServiceError(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		updateServiceFunc: func(serviceID int, serviceType string, pricePerHour float64, description string) error {
-			return errors.New("incorrect type service")
+		updateServiceFunc: func(actor authz.Actor, serviceID int, serviceType string, pricePerHour float64, description string, petTypes []string) error {
+			return ErrInvalidServiceType
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -365,14 +368,14 @@ func TestHandler_UpdateService_ServiceError(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect type service")
+	assert.Equal(t, "invalid_service_type", resp.Code)
 }
 
 func TestHandler_DeleteService_Success(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		deleteServiceFunc: func(serviceID int) error {
+		deleteServiceFunc: func(actor authz.Actor, serviceID int) error {
 			return nil
 		},
 	}
@@ -403,15 +406,15 @@ func TestHandler_DeleteService_InvalidID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "incorrect ID service")
+	assert.Equal(t, "invalid_id", resp.Code)
 }
 
 func TestHandler_DeleteService_Error(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		deleteServiceFunc: func(serviceID int) error {
-			return errors.New("service not found")
+		deleteServiceFunc: func(actor authz.Actor, serviceID int) error {
+			return ErrServiceNotFound
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -422,28 +425,28 @@ func TestHandler_DeleteService_Error(t *testing.T) {
 
 	handler.DeleteService(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "service not found")
+	assert.Equal(t, "service_not_found", resp.Code)
 }
 
 func TestHandler_SearchServices_Success(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		searchServicesFunc: func(serviceType, location string) ([]ServiceWithSitter, error) {
-			return []ServiceWithSitter{
+		searchServicesFunc: func(filter SearchFilter) (*SearchResult, error) {
+			return &SearchResult{Services: []ServiceWithSitter{
 				{
-					Service:      models.Service{ServiceID: 1, Type: serviceType, PricePerHour: 2500},
+					Service:      models.Service{ServiceID: 1, Type: filter.Type, PricePerHour: 2500},
 					SitterName:   "John Doe",
 					SitterRating: 4.5,
 				},
 				{
-					Service:      models.Service{ServiceID: 2, Type: serviceType, PricePerHour: 3000},
+					Service:      models.Service{ServiceID: 2, Type: filter.Type, PricePerHour: 3000},
 					SitterName:   "Jane Smith",
 					SitterRating: 4.8,
 				},
-			}, nil
+			}, Total: 2}, nil
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -455,19 +458,20 @@ func TestHandler_SearchServices_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	var resp []ServiceWithSitter
+	var resp SearchResult
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Equal(t, 2, len(resp))
-	assert.Equal(t, "John Doe", resp[0].SitterName)
-	assert.Equal(t, 4.5, resp[0].SitterRating)
+	assert.Equal(t, 2, len(resp.Services))
+	assert.Equal(t, 2, resp.Total)
+	assert.Equal(t, "John Doe", resp.Services[0].SitterName)
+	assert.Equal(t, 4.5, resp.Services[0].SitterRating)
 }
 
 func TestHandler_SearchServices_NoFilters(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		searchServicesFunc: func(serviceType, location string) ([]ServiceWithSitter, error) {
-			return []ServiceWithSitter{
+		searchServicesFunc: func(filter SearchFilter) (*SearchResult, error) {
+			return &SearchResult{Services: []ServiceWithSitter{
 				{Service: models.Service{ServiceID: 1}},
-			}, nil
+			}, Total: 1}, nil
 		},
 	}
 	handler := NewHandler(mockSvc)
@@ -479,14 +483,14 @@ func TestHandler_SearchServices_NoFilters(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	var resp []ServiceWithSitter
+	var resp SearchResult
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.GreaterOrEqual(t, len(resp), 0)
+	assert.GreaterOrEqual(t, len(resp.Services), 0)
 }
 
 func TestHandler_SearchServices_Error(t *testing.T) {
 	mockSvc := &mockServiceForHandler{
-		searchServicesFunc: func(serviceType, location string) ([]ServiceWithSitter, error) {
+		searchServicesFunc: func(filter SearchFilter) (*SearchResult, error) {
 			return nil, errors.New("database error")
 		},
 	}
@@ -499,9 +503,27 @@ func TestHandler_SearchServices_Error(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 
-	var resp map[string]string
+	var resp httpx.Problem
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Contains(t, resp["error"], "database error")
+	assert.Equal(t, "internal_error", resp.Code)
+	assert.NotContains(t, rec.Body.String(), "database error")
+}
+
+func TestHandler_DeleteService_Forbidden(t *testing.T) {
+	mockSvc := &mockServiceForHandler{
+		deleteServiceFunc: func(actor authz.Actor, serviceID int) error {
+			return authz.ErrForbidden
+		},
+	}
+	handler := NewHandler(mockSvc)
+
+	req := httptest.NewRequest(http.MethodDelete, "/services/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rec := httptest.NewRecorder()
+
+	handler.DeleteService(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
 }
 
 func TestNewHandler(t *testing.T) {
@@ -1,3 +1 @@
-DELETE FROM sitters;
-DELETE FROM pets;
-DELETE FROM users;
+-- Nothing to undo: see the up migration.
@@ -0,0 +1,13 @@
+CREATE TABLE sessions (
+                          session_id VARCHAR(64) PRIMARY KEY,
+                          user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+                          refresh_token_hash VARCHAR(64) NOT NULL UNIQUE,
+                          previous_token_hash VARCHAR(64),
+                          expires_at TIMESTAMP NOT NULL,
+                          created_at TIMESTAMP DEFAULT NOW(),
+                          last_used_at TIMESTAMP DEFAULT NOW(),
+                          revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_user ON sessions(user_id);
+CREATE INDEX idx_sessions_previous_hash ON sessions(previous_token_hash);
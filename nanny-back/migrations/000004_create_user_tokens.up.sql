@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+CREATE TABLE user_tokens (
+                             token_hash VARCHAR(64) PRIMARY KEY,
+                             user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+                             purpose VARCHAR(20) CHECK (purpose IN ('password_reset', 'email_verification')) NOT NULL,
+                             expires_at TIMESTAMP NOT NULL,
+                             used_at TIMESTAMP,
+                             created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_tokens_user ON user_tokens(user_id, purpose);
@@ -0,0 +1,10 @@
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+
+ALTER TABLE bookings
+    ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN end_time TYPE TIMESTAMP USING end_time AT TIME ZONE 'UTC';
+
+DROP TABLE IF EXISTS availability_blackouts;
+DROP TABLE IF EXISTS availability_slots;
+
+ALTER TABLE sitters DROP COLUMN IF EXISTS time_zone;
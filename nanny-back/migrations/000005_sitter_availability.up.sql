@@ -0,0 +1,58 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE sitters ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'Asia/Almaty';
+
+-- Weekly recurring working hours in the sitter's local time.
+-- weekday follows Go/JS: 0 = Sunday ... 6 = Saturday; end_time may be 24:00.
+CREATE TABLE availability_slots (
+                                    slot_id SERIAL PRIMARY KEY,
+                                    sitter_id INT NOT NULL REFERENCES sitters(sitter_id) ON DELETE CASCADE,
+                                    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+                                    start_time TIME NOT NULL,
+                                    end_time TIME NOT NULL,
+                                    CHECK (start_time < end_time)
+);
+
+CREATE INDEX idx_availability_slots_sitter ON availability_slots(sitter_id);
+
+-- Whole days off (vacations, sick days), inclusive, in the sitter's local time.
+CREATE TABLE availability_blackouts (
+                                        blackout_id SERIAL PRIMARY KEY,
+                                        sitter_id INT NOT NULL REFERENCES sitters(sitter_id) ON DELETE CASCADE,
+                                        start_date DATE NOT NULL,
+                                        end_date DATE NOT NULL,
+                                        reason TEXT,
+                                        CHECK (start_date <= end_date)
+);
+
+CREATE INDEX idx_availability_blackouts_sitter ON availability_blackouts(sitter_id, end_date);
+
+ALTER TABLE bookings
+    ALTER COLUMN start_time TYPE TIMESTAMPTZ USING start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN end_time TYPE TIMESTAMPTZ USING end_time AT TIME ZONE 'UTC';
+
+-- Existing overlaps would make the constraint fail. Which booking to
+-- cancel is a decision for a person, so stop and name them instead.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('%s/%s', o.booking_id, b.booking_id), ', ' ORDER BY o.booking_id, b.booking_id)
+    INTO conflicts
+    FROM bookings o
+    JOIN bookings b
+      ON b.sitter_id = o.sitter_id
+     AND b.booking_id > o.booking_id
+     AND tstzrange(o.start_time, o.end_time, '[)') && tstzrange(b.start_time, b.end_time, '[)')
+    WHERE o.status IN ('pending', 'confirmed')
+      AND b.status IN ('pending', 'confirmed');
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'overlapping active bookings of the same sitter (booking_id pairs): %', conflicts
+            USING HINT = 'Cancel one booking of each pair, run "migrate force 4" and then "migrate up".';
+    END IF;
+END $$;
+
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (sitter_id WITH =, tstzrange(start_time, end_time, '[)') WITH &&)
+    WHERE (status IN ('pending', 'confirmed'));
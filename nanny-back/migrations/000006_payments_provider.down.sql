@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_payments_booking;
+DROP INDEX IF EXISTS idx_payments_one_paid_per_booking;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS refunded_at,
+    DROP COLUMN IF EXISTS provider_ref;
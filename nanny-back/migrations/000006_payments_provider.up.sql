@@ -0,0 +1,8 @@
+ALTER TABLE payments
+    ADD COLUMN provider_ref VARCHAR(100),
+    ADD COLUMN refunded_at TIMESTAMP;
+
+-- At most one live charge per booking; refunded and failed attempts stay
+-- in history.
+CREATE UNIQUE INDEX idx_payments_one_paid_per_booking ON payments(booking_id) WHERE status = 'paid';
+CREATE INDEX idx_payments_booking ON payments(booking_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_chat;
+CREATE INDEX idx_messages_chat ON messages(chat_id);
+
+DROP INDEX IF EXISTS idx_chats_booking;
+CREATE INDEX idx_chats_booking ON chats(booking_id);
@@ -0,0 +1,24 @@
+-- One chat per booking. Keep the oldest chat if duplicates slipped in and
+-- move their messages over before enforcing it.
+UPDATE messages m
+SET chat_id = keep.chat_id
+FROM chats c
+JOIN (SELECT booking_id, MIN(chat_id) AS chat_id FROM chats GROUP BY booking_id) keep
+    ON keep.booking_id = c.booking_id
+WHERE m.chat_id = c.chat_id AND c.chat_id <> keep.chat_id;
+
+DELETE FROM chats c
+USING chats older
+WHERE c.booking_id = older.booking_id AND c.chat_id > older.chat_id;
+
+DROP INDEX IF EXISTS idx_chats_booking;
+CREATE UNIQUE INDEX idx_chats_booking ON chats(booking_id);
+
+-- Chats of existing bookings.
+INSERT INTO chats (booking_id)
+SELECT b.booking_id FROM bookings b
+WHERE NOT EXISTS (SELECT 1 FROM chats c WHERE c.booking_id = b.booking_id);
+
+-- Pagination walks message_id within a chat.
+DROP INDEX IF EXISTS idx_messages_chat;
+CREATE INDEX idx_messages_chat ON messages(chat_id, message_id);
@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS booking_events;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+
+UPDATE bookings SET status = 'cancelled'
+WHERE status IN ('cancelled_by_owner', 'cancelled_by_sitter', 'expired', 'no_show');
+UPDATE bookings SET status = 'confirmed' WHERE status = 'in_progress';
+
+ALTER TABLE bookings ALTER COLUMN status TYPE VARCHAR(15);
+
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending', 'confirmed', 'cancelled', 'completed'));
+
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (sitter_id WITH =, tstzrange(start_time, end_time, '[)') WITH &&)
+    WHERE (status IN ('pending', 'confirmed'));
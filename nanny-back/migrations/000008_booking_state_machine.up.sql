@@ -0,0 +1,35 @@
+-- Statuses of the booking state machine (internal/bookings/state.go).
+-- 'cancelled' stays valid for rows written before cancellations recorded
+-- who cancelled.
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+
+ALTER TABLE bookings ALTER COLUMN status TYPE VARCHAR(20);
+
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check CHECK (status IN (
+    'pending', 'confirmed', 'in_progress', 'completed',
+    'cancelled_by_owner', 'cancelled_by_sitter', 'expired', 'no_show',
+    'cancelled'
+));
+
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (sitter_id WITH =, tstzrange(start_time, end_time, '[)') WITH &&)
+    WHERE (status IN ('pending', 'confirmed', 'in_progress'));
+
+CREATE TABLE booking_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    booking_id INT NOT NULL REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_id INT REFERENCES users(user_id) ON DELETE SET NULL,
+    actor_role VARCHAR(10) NOT NULL CHECK (actor_role IN ('owner', 'sitter', 'admin', 'system')),
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_booking_events_booking ON booking_events(booking_id, event_id);
+
+-- Existing bookings start their history at the status they are in now.
+INSERT INTO booking_events (booking_id, from_status, to_status, actor_role, reason)
+SELECT booking_id, NULL, status, 'system', 'imported'
+FROM bookings;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_availability_slots_weekday;
+DROP INDEX IF EXISTS idx_services_type_price;
+
+ALTER TABLE sitters
+    DROP CONSTRAINT IF EXISTS sitters_coordinates_check,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+
+ALTER TABLE services
+    DROP CONSTRAINT IF EXISTS services_pet_types_check,
+    DROP COLUMN IF EXISTS pet_types;
@@ -0,0 +1,18 @@
+-- Which pets a service accepts; every existing service keeps accepting all.
+ALTER TABLE services
+    ADD COLUMN pet_types VARCHAR(20)[] NOT NULL DEFAULT ARRAY['cat', 'dog', 'rodent']::VARCHAR(20)[],
+    ADD CONSTRAINT services_pet_types_check
+        CHECK (cardinality(pet_types) > 0 AND pet_types <@ ARRAY['cat', 'dog', 'rodent']::VARCHAR(20)[]);
+
+-- Coordinates for radius search; both set or both empty.
+ALTER TABLE sitters
+    ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT sitters_coordinates_check CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+-- City centres for the cities already in use.
+UPDATE sitters SET latitude = 43.2389, longitude = 76.8897 WHERE location ILIKE 'almaty';
+UPDATE sitters SET latitude = 51.1605, longitude = 71.4704 WHERE location ILIKE 'astana';
+
+CREATE INDEX idx_services_type_price ON services(type, price_per_hour);
+CREATE INDEX idx_availability_slots_weekday ON availability_slots(weekday, sitter_id);
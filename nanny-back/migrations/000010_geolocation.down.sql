@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS owner_locations;
+
+ALTER TABLE sitters
+    DROP COLUMN IF EXISTS service_radius_km,
+    DROP COLUMN IF EXISTS address_line;
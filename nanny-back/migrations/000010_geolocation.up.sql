@@ -0,0 +1,15 @@
+-- A sitter's address is address_line + location (the city) + coordinates.
+ALTER TABLE sitters
+    ADD COLUMN address_line VARCHAR(200),
+    ADD COLUMN service_radius_km DOUBLE PRECISION NOT NULL DEFAULT 10
+        CHECK (service_radius_km > 0 AND service_radius_km <= 200);
+
+-- Where the owner's pets live; bookings are matched against it.
+CREATE TABLE owner_locations (
+                                 owner_id INT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+                                 address_line VARCHAR(200),
+                                 city VARCHAR(100) NOT NULL,
+                                 latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+                                 longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+                                 updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+ALTER TABLE reviews
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_booking_unique;
+CREATE INDEX IF NOT EXISTS idx_reviews_booking ON reviews(booking_id);
+
+INSERT INTO reviews (review_id, booking_id, owner_id, sitter_id, rating, comment, created_at)
+SELECT review_id, booking_id, owner_id, sitter_id, rating, comment, created_at FROM review_duplicates;
+
+DROP TABLE review_duplicates;
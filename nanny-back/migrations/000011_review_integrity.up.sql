@@ -0,0 +1,24 @@
+-- One review per booking. Existing duplicates would make the constraint
+-- fail: the earliest review of a booking stays, later ones are moved to
+-- review_duplicates, unchanged, for an admin to look at.
+CREATE TABLE review_duplicates (
+    LIKE reviews,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+WITH moved AS (
+    DELETE FROM reviews r
+    USING reviews earlier
+    WHERE r.booking_id = earlier.booking_id
+      AND r.review_id > earlier.review_id
+    RETURNING r.review_id, r.booking_id, r.owner_id, r.sitter_id, r.rating, r.comment, r.created_at
+)
+INSERT INTO review_duplicates (review_id, booking_id, owner_id, sitter_id, rating, comment, created_at)
+SELECT review_id, booking_id, owner_id, sitter_id, rating, comment, created_at FROM moved;
+
+DROP INDEX IF EXISTS idx_reviews_booking;
+ALTER TABLE reviews ADD CONSTRAINT reviews_booking_unique UNIQUE (booking_id);
+
+-- The edit window is measured from created_at.
+ALTER TABLE reviews
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS review_moderation_actions;
+
+DROP INDEX IF EXISTS idx_reviews_flagged;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS hidden_at,
+    DROP COLUMN IF EXISTS flagged_at,
+    DROP COLUMN IF EXISTS flag_reason,
+    DROP COLUMN IF EXISTS replied_at,
+    DROP COLUMN IF EXISTS reply;
@@ -0,0 +1,24 @@
+-- The sitter's public answer, one per review.
+ALTER TABLE reviews
+    ADD COLUMN reply TEXT,
+    ADD COLUMN replied_at TIMESTAMPTZ,
+    -- Set when the sitter reports the review, cleared once an admin decides.
+    ADD COLUMN flag_reason TEXT,
+    ADD COLUMN flagged_at TIMESTAMPTZ,
+    -- Hidden reviews are kept but neither shown nor counted in ratings.
+    ADD COLUMN hidden_at TIMESTAMPTZ;
+
+CREATE INDEX idx_reviews_flagged ON reviews(flagged_at) WHERE flagged_at IS NOT NULL;
+
+-- What admins did to a review and why. review_id has no foreign key so the
+-- record of a deletion outlives the review.
+CREATE TABLE review_moderation_actions (
+    action_id BIGSERIAL PRIMARY KEY,
+    review_id INT NOT NULL,
+    admin_id INT REFERENCES users(user_id) ON DELETE SET NULL,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('hide', 'restore', 'delete')),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_review_moderation_review ON review_moderation_actions(review_id, action_id);
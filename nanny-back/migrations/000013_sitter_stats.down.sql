@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS reviews_sitter_stats ON reviews;
+DROP FUNCTION IF EXISTS sitter_stats_apply();
+
+DROP TABLE IF EXISTS sitter_stats;
+DROP FUNCTION IF EXISTS sitter_score(BIGINT, BIGINT);
@@ -0,0 +1,51 @@
+-- sitter_score ranks sitters by a Bayesian average: every sitter starts
+-- with 5 imaginary 4.0-star reviews, so a handful of perfect ratings cannot
+-- outrank a long record of good ones. Change the prior here only.
+CREATE FUNCTION sitter_score(review_count BIGINT, rating_sum BIGINT) RETURNS DOUBLE PRECISION
+    LANGUAGE SQL IMMUTABLE
+    AS $$ SELECT (rating_sum + 5 * 4.0)::float8 / (review_count + 5) $$;
+
+-- Running totals of each sitter's visible reviews, kept up to date by the
+-- trigger below so search can order by score without aggregating reviews.
+CREATE TABLE sitter_stats (
+    sitter_id INT PRIMARY KEY REFERENCES sitters(sitter_id) ON DELETE CASCADE,
+    review_count BIGINT NOT NULL DEFAULT 0,
+    rating_sum BIGINT NOT NULL DEFAULT 0,
+    score DOUBLE PRECISION GENERATED ALWAYS AS (sitter_score(review_count, rating_sum)) STORED,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sitter_stats_score ON sitter_stats(score DESC);
+
+INSERT INTO sitter_stats (sitter_id, review_count, rating_sum)
+SELECT sitter_id, COUNT(*), SUM(rating)
+FROM reviews
+WHERE sitter_id IS NOT NULL AND hidden_at IS NULL
+GROUP BY sitter_id;
+
+-- Takes the old row out of the totals and adds the new one, so hiding,
+-- restoring, editing and deleting a review all move the score.
+CREATE FUNCTION sitter_stats_apply() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.sitter_id IS NOT NULL AND OLD.hidden_at IS NULL THEN
+        UPDATE sitter_stats
+        SET review_count = review_count - 1, rating_sum = rating_sum - OLD.rating, updated_at = NOW()
+        WHERE sitter_id = OLD.sitter_id;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.sitter_id IS NOT NULL AND NEW.hidden_at IS NULL THEN
+        INSERT INTO sitter_stats (sitter_id, review_count, rating_sum)
+        VALUES (NEW.sitter_id, 1, NEW.rating)
+        ON CONFLICT (sitter_id) DO UPDATE
+        SET review_count = sitter_stats.review_count + 1,
+            rating_sum = sitter_stats.rating_sum + EXCLUDED.rating_sum,
+            updated_at = NOW();
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reviews_sitter_stats
+    AFTER INSERT OR DELETE OR UPDATE OF sitter_id, rating, hidden_at ON reviews
+    FOR EACH ROW EXECUTE FUNCTION sitter_stats_apply();
@@ -0,0 +1,41 @@
+DROP TRIGGER IF EXISTS reviews_sitter_stats ON reviews;
+
+CREATE OR REPLACE FUNCTION sitter_stats_apply() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.sitter_id IS NOT NULL AND OLD.hidden_at IS NULL THEN
+        UPDATE sitter_stats
+        SET review_count = review_count - 1, rating_sum = rating_sum - OLD.rating, updated_at = NOW()
+        WHERE sitter_id = OLD.sitter_id;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.sitter_id IS NOT NULL AND NEW.hidden_at IS NULL THEN
+        INSERT INTO sitter_stats (sitter_id, review_count, rating_sum)
+        VALUES (NEW.sitter_id, 1, NEW.rating)
+        ON CONFLICT (sitter_id) DO UPDATE
+        SET review_count = sitter_stats.review_count + 1,
+            rating_sum = sitter_stats.rating_sum + EXCLUDED.rating_sum,
+            updated_at = NOW();
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reviews_sitter_stats
+    AFTER INSERT OR DELETE OR UPDATE OF sitter_id, rating, hidden_at ON reviews
+    FOR EACH ROW EXECUTE FUNCTION sitter_stats_apply();
+
+ALTER TABLE sitter_stats
+    DROP COLUMN IF EXISTS pet_care_sum,
+    DROP COLUMN IF EXISTS pet_care_count,
+    DROP COLUMN IF EXISTS communication_sum,
+    DROP COLUMN IF EXISTS communication_count,
+    DROP COLUMN IF EXISTS punctuality_sum,
+    DROP COLUMN IF EXISTS punctuality_count,
+    DROP COLUMN IF EXISTS verified_count;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS verified,
+    DROP COLUMN IF EXISTS pet_care,
+    DROP COLUMN IF EXISTS communication,
+    DROP COLUMN IF EXISTS punctuality;
@@ -0,0 +1,84 @@
+-- Optional scores for the parts of a stay owners rate separately, and
+-- whether the review comes from a completed booking that was paid for.
+ALTER TABLE reviews
+    ADD COLUMN punctuality SMALLINT CHECK (punctuality BETWEEN 1 AND 5),
+    ADD COLUMN communication SMALLINT CHECK (communication BETWEEN 1 AND 5),
+    ADD COLUMN pet_care SMALLINT CHECK (pet_care BETWEEN 1 AND 5),
+    ADD COLUMN verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE reviews r
+SET verified = TRUE
+FROM bookings b
+WHERE b.booking_id = r.booking_id
+  AND b.status = 'completed'
+  AND EXISTS (SELECT 1 FROM payments p WHERE p.booking_id = b.booking_id AND p.status = 'paid');
+
+-- Each dimension is optional, so it keeps its own count.
+ALTER TABLE sitter_stats
+    ADD COLUMN verified_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN punctuality_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN punctuality_sum BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN communication_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN communication_sum BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN pet_care_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN pet_care_sum BIGINT NOT NULL DEFAULT 0;
+
+UPDATE sitter_stats ss
+SET verified_count = agg.verified_count
+FROM (
+    SELECT sitter_id, COUNT(*) FILTER (WHERE verified) AS verified_count
+    FROM reviews
+    WHERE hidden_at IS NULL
+    GROUP BY sitter_id
+) agg
+WHERE agg.sitter_id = ss.sitter_id;
+
+CREATE OR REPLACE FUNCTION sitter_stats_apply() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.sitter_id IS NOT NULL AND OLD.hidden_at IS NULL THEN
+        UPDATE sitter_stats
+        SET review_count = review_count - 1,
+            rating_sum = rating_sum - OLD.rating,
+            verified_count = verified_count - OLD.verified::int,
+            punctuality_count = punctuality_count - (OLD.punctuality IS NOT NULL)::int,
+            punctuality_sum = punctuality_sum - COALESCE(OLD.punctuality, 0),
+            communication_count = communication_count - (OLD.communication IS NOT NULL)::int,
+            communication_sum = communication_sum - COALESCE(OLD.communication, 0),
+            pet_care_count = pet_care_count - (OLD.pet_care IS NOT NULL)::int,
+            pet_care_sum = pet_care_sum - COALESCE(OLD.pet_care, 0),
+            updated_at = NOW()
+        WHERE sitter_id = OLD.sitter_id;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.sitter_id IS NOT NULL AND NEW.hidden_at IS NULL THEN
+        INSERT INTO sitter_stats (
+            sitter_id, review_count, rating_sum, verified_count,
+            punctuality_count, punctuality_sum, communication_count, communication_sum,
+            pet_care_count, pet_care_sum)
+        VALUES (
+            NEW.sitter_id, 1, NEW.rating, NEW.verified::int,
+            (NEW.punctuality IS NOT NULL)::int, COALESCE(NEW.punctuality, 0),
+            (NEW.communication IS NOT NULL)::int, COALESCE(NEW.communication, 0),
+            (NEW.pet_care IS NOT NULL)::int, COALESCE(NEW.pet_care, 0))
+        ON CONFLICT (sitter_id) DO UPDATE
+        SET review_count = sitter_stats.review_count + 1,
+            rating_sum = sitter_stats.rating_sum + EXCLUDED.rating_sum,
+            verified_count = sitter_stats.verified_count + EXCLUDED.verified_count,
+            punctuality_count = sitter_stats.punctuality_count + EXCLUDED.punctuality_count,
+            punctuality_sum = sitter_stats.punctuality_sum + EXCLUDED.punctuality_sum,
+            communication_count = sitter_stats.communication_count + EXCLUDED.communication_count,
+            communication_sum = sitter_stats.communication_sum + EXCLUDED.communication_sum,
+            pet_care_count = sitter_stats.pet_care_count + EXCLUDED.pet_care_count,
+            pet_care_sum = sitter_stats.pet_care_sum + EXCLUDED.pet_care_sum,
+            updated_at = NOW();
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER reviews_sitter_stats ON reviews;
+
+CREATE TRIGGER reviews_sitter_stats
+    AFTER INSERT OR DELETE OR UPDATE OF sitter_id, rating, hidden_at, verified, punctuality, communication, pet_care ON reviews
+    FOR EACH ROW EXECUTE FUNCTION sitter_stats_apply();
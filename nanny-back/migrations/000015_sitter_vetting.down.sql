@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS sitter_status_history;
+DROP TABLE IF EXISTS sitter_notes;
+DROP TABLE IF EXISTS sitter_documents;
+
+UPDATE sitters SET status = 'pending' WHERE status = 'changes_requested';
+
+ALTER TABLE sitters DROP CONSTRAINT IF EXISTS sitters_status_check;
+ALTER TABLE sitters ALTER COLUMN status TYPE VARCHAR(10);
+ALTER TABLE sitters ADD CONSTRAINT sitters_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected'));
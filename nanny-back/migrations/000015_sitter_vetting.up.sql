@@ -0,0 +1,47 @@
+-- changes_requested sends an application back to the sitter, who can
+-- resubmit it to pending.
+ALTER TABLE sitters ALTER COLUMN status TYPE VARCHAR(20);
+ALTER TABLE sitters DROP CONSTRAINT IF EXISTS sitters_status_check;
+ALTER TABLE sitters ADD CONSTRAINT sitters_status_check
+    CHECK (status IN ('pending', 'changes_requested', 'approved', 'rejected'));
+
+-- Files live in the upload store under storage_key; this is what we know
+-- about them.
+CREATE TABLE sitter_documents (
+    document_id SERIAL PRIMARY KEY,
+    sitter_id INT NOT NULL REFERENCES sitters(sitter_id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('certificate', 'id')),
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sitter_documents_sitter ON sitter_documents(sitter_id, document_id);
+
+-- Internal notes admins leave while vetting; sitters never see them.
+CREATE TABLE sitter_notes (
+    note_id SERIAL PRIMARY KEY,
+    sitter_id INT NOT NULL REFERENCES sitters(sitter_id) ON DELETE CASCADE,
+    admin_id INT REFERENCES users(user_id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sitter_notes_sitter ON sitter_notes(sitter_id, note_id);
+
+-- Every status change of an application, with who made it and why.
+CREATE TABLE sitter_status_history (
+    history_id BIGSERIAL PRIMARY KEY,
+    sitter_id INT NOT NULL REFERENCES sitters(sitter_id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    decision VARCHAR(20) NOT NULL
+        CHECK (decision IN ('approve', 'reject', 'request_changes', 'resubmit')),
+    reason TEXT NOT NULL DEFAULT '',
+    actor_id INT REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sitter_status_history_sitter ON sitter_status_history(sitter_id, history_id);
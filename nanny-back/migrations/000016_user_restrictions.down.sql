@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP COLUMN deleted_at,
+    DROP COLUMN restriction_reason,
+    DROP COLUMN banned_at,
+    DROP COLUMN suspended_until;
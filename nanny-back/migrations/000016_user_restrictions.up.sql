@@ -0,0 +1,8 @@
+-- A suspended user is locked out until suspended_until, a banned one for
+-- good; restriction_reason says why. Deleting a user keeps the row with
+-- the personal data wiped, so bookings, payments and reviews stay intact.
+ALTER TABLE users
+    ADD COLUMN suspended_until TIMESTAMPTZ,
+    ADD COLUMN banned_at TIMESTAMPTZ,
+    ADD COLUMN restriction_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN deleted_at TIMESTAMPTZ;
@@ -0,0 +1,35 @@
+-- Who changed what, one row per state-changing request. actor_id and
+-- entity_id have no foreign keys so entries outlive what they point at.
+CREATE TABLE audit_log (
+    entry_id BIGSERIAL PRIMARY KEY,
+    actor_id INT,
+    actor_role VARCHAR(20) NOT NULL DEFAULT '',
+    action VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL DEFAULT '',
+    entity_id INT,
+    -- Only the fields the action changed, as they were and as they became.
+    before JSONB,
+    after JSONB,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status INT NOT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, entry_id);
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id, entry_id);
+CREATE INDEX idx_audit_log_created ON audit_log(created_at);
+
+-- The log is append-only: rows can be added but never changed or removed.
+CREATE FUNCTION audit_log_append_only() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$ BEGIN RAISE EXCEPTION 'audit_log is append-only'; END $$;
+
+CREATE TRIGGER audit_log_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,6 @@
+-- The admin behind an impersonation token; actor_id is the user being
+-- impersonated. NULL for everything else.
+ALTER TABLE audit_log ADD COLUMN impersonator_id INT;
+
+CREATE INDEX idx_audit_log_impersonator ON audit_log(impersonator_id, entry_id)
+    WHERE impersonator_id IS NOT NULL;
@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migrations so the API binary can apply
+// them itself. Files follow golang-migrate naming:
+// NNNNNN_description.up.sql / NNNNNN_description.down.sql.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
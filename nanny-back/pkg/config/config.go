@@ -3,11 +3,20 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
 	Database  DatabaseConfig
 	Server    ServerConfig
+	Auth      AuthConfig
+	Mail      MailConfig
+	Payments  PaymentsConfig
+	Geo       GeoConfig
+	Reviews   ReviewsConfig
+	Storage   StorageConfig
+	Admin     AdminConfig
 	JWTSecret string
 }
 
@@ -18,12 +27,75 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// AutoMigrate applies pending migrations when the API starts.
+	AutoMigrate bool
+	// QueryTimeout bounds every single query; the request context still
+	// cancels it earlier when the client goes away.
+	QueryTimeout time.Duration
 }
 
 type ServerConfig struct {
 	Port string
 }
 
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	Issuer          string
+	KeyID           string
+	PrivateKeyFile  string
+	PreviousKeys    string
+
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	ImpersonationTTL     time.Duration
+}
+
+type MailConfig struct {
+	Driver       string
+	From         string
+	File         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	// AppBaseURL is where links in emails point to (the frontend).
+	AppBaseURL string
+}
+
+type PaymentsConfig struct {
+	// Provider selects the payment gateway; only "fake" is built in.
+	Provider string
+}
+
+type GeoConfig struct {
+	// Geocoder selects the implementation; only "static" is built in.
+	Geocoder string
+	// PlacesFile is an optional CSV of extra places for the static
+	// geocoder.
+	PlacesFile string
+}
+
+type ReviewsConfig struct {
+	// EditWindow is how long after posting an owner may still change or
+	// delete a review.
+	EditWindow time.Duration
+	// RecentPeriod is how far back the "recent" average of a rating
+	// summary looks.
+	RecentPeriod time.Duration
+}
+
+type StorageConfig struct {
+	// Dir is where uploaded files such as sitter documents are kept.
+	Dir string
+}
+
+type AdminConfig struct {
+	// StatsCacheTTL is how long computed dashboard stats are served before
+	// they are queried again.
+	StatsCacheTTL time.Duration
+}
+
 func Load() *Config {
 	return &Config{
 		Database: DatabaseConfig{
@@ -33,10 +105,52 @@ func Load() *Config {
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			DBName:   getEnv("DB_NAME", "nanny_db"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			AutoMigrate:  getBool("DB_AUTO_MIGRATE", true),
+			QueryTimeout: getDuration("DB_QUERY_TIMEOUT", 5*time.Second),
 		},
 		Server: ServerConfig{
 			Port: getEnv("SERVER_PORT", "8080"),
 		},
+		Auth: AuthConfig{
+			AccessTokenTTL:  getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			Issuer:          getEnv("JWT_ISSUER", "nanny-backend"),
+			KeyID:           getEnv("JWT_KEY_ID", "v1"),
+			PrivateKeyFile:  getEnv("JWT_PRIVATE_KEY_FILE", ""),
+			PreviousKeys:    getEnv("JWT_PREVIOUS_KEYS", ""),
+
+			PasswordResetTTL:     getDuration("PASSWORD_RESET_TTL", time.Hour),
+			EmailVerificationTTL: getDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+			ImpersonationTTL:     getDuration("IMPERSONATION_TTL", 15*time.Minute),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "no-reply@nanny.local"),
+			File:         getEnv("MAIL_FILE", "mail.log"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUser:     getEnv("SMTP_USER", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			AppBaseURL:   getEnv("APP_BASE_URL", "http://localhost:8080"),
+		},
+		Payments: PaymentsConfig{
+			Provider: getEnv("PAYMENT_PROVIDER", "fake"),
+		},
+		Geo: GeoConfig{
+			Geocoder:   getEnv("GEOCODER", "static"),
+			PlacesFile: getEnv("GEOCODER_FILE", ""),
+		},
+		Reviews: ReviewsConfig{
+			EditWindow:   getDuration("REVIEW_EDIT_WINDOW", 7*24*time.Hour),
+			RecentPeriod: getDuration("REVIEW_RECENT_PERIOD", 90*24*time.Hour),
+		},
+		Storage: StorageConfig{
+			Dir: getEnv("UPLOAD_DIR", "uploads"),
+		},
+		Admin: AdminConfig{
+			StatsCacheTTL: getDuration("ADMIN_STATS_CACHE_TTL", 5*time.Minute),
+		},
 		JWTSecret: getEnv("JWT_SECRET", "dev_secret"),
 	}
 }
@@ -59,3 +173,29 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return defaultValue
+	}
+	return d
+}
+
+func getBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -12,8 +13,26 @@ import (
 
 var validate *validator.Validate
 
+// FieldError is one broken rule. Field is the JSON name of the field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Errors is returned by Validate when data breaks one or more rules.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, f := range e {
+		messages = append(messages, f.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
 func init() {
 	validate = validator.New()
+	validate.RegisterTagNameFunc(jsonName)
 
 	if err := validate.RegisterValidation("phone_kz", validateKazakhPhone); err != nil {
 		log.Fatal("Failed to register phone_kz validator:", err)
@@ -42,18 +61,24 @@ func Validate(data interface{}) error {
 }
 
 func formatValidationErrors(errors validator.ValidationErrors) error {
-	var messages []string
-
+	fields := make(Errors, 0, len(errors))
 	for _, err := range errors {
-		message := getErrorMessage(err)
-		messages = append(messages, message)
+		fields = append(fields, FieldError{Field: err.Field(), Message: getErrorMessage(err)})
 	}
+	return fields
+}
 
-	return fmt.Errorf("%s", strings.Join(messages, "; "))
+// jsonName reports fields by their JSON name, falling back to the Go name.
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
 }
 
 func getErrorMessage(err validator.FieldError) string {
-	field := getFieldName(err.Field())
+	field := getFieldName(err.StructField())
 
 	switch err.Tag() {
 	case "required":
@@ -77,7 +102,7 @@ func getErrorMessage(err validator.FieldError) string {
 	case "pet_type":
 		return fmt.Sprintf("%s must be one of: dog, cat, bird, rat, raptile, other", field)
 	case "booking_status":
-		return fmt.Sprintf("%s must be one of: pending, confirmed, cancelled, completed", field)
+		return fmt.Sprintf("%s must be one of: pending, confirmed, in_progress, completed, cancelled_by_owner, cancelled_by_sitter, expired, no_show", field)
 	case "user_role":
 		return fmt.Sprintf("%s must be one of: owner, sitter, admin", field)
 	default:
@@ -138,7 +163,10 @@ func validatePetType(fl validator.FieldLevel) bool {
 
 func validateBookingStatus(fl validator.FieldLevel) bool {
 	status := strings.ToLower(fl.Field().String())
-	validStatuses := []string{"pending", "confirmed", "cancelled", "completed"}
+	validStatuses := []string{
+		"pending", "confirmed", "in_progress", "completed",
+		"cancelled_by_owner", "cancelled_by_sitter", "expired", "no_show",
+	}
 
 	for _, valid := range validStatuses {
 		if status == valid {
@@ -0,0 +1,67 @@
+-- Optional demo data for local development. Not applied automatically:
+-- run it once after the migrations, e.g.
+--   psql "$DATABASE_URL" -f scripts/demo_data.sql
+
+-- Demo users, a demo admin account among them: never load this
+-- into a production database.
+INSERT INTO users (full_name, email, phone, password_hash, role) VALUES
+('Aruzhan Akhmetova', 'aruzhan@example.com', '+77010000001', 'hash1', 'owner'),
+('Nazerke Alpyssova', 'nazerke@example.com', '+77010000002', 'hash2', 'sitter'),
+('Anara Armankyzy', 'anara@example.com', '+77010000003', 'hash3', 'owner'),
+('Meyrim Sultan', 'meyrim@example.com', '+77010000004', 'hash4', 'sitter'),
+('Admin User', 'admin@nanny.kz', '+77010000005', 'hash5', 'admin')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO pets (owner_id, name, type, age, notes) VALUES
+(1, 'Mila', 'cat', 2, 'Very calm and fluffy'),
+(3, 'Bobby', 'dog', 4, 'Needs daily walk'),
+(1, 'Luna', 'rodent', 1, 'Hamster'),
+(3, 'Sharik', 'dog', 3, 'Friendly with kids'),
+(1, 'Simba', 'cat', 5, 'Prefers dry food')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO sitters (sitter_id, experience_years, certificates, preferences, location, status) VALUES
+(2, 3, 'Pet Care Certificate 2022', 'Loves dogs and cats', 'Almaty', 'approved'),
+(4, 5, 'Veterinary Basics 2021', 'Can handle rodents', 'Astana', 'approved')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO services (sitter_id, type, price_per_hour, description) VALUES
+    (2, 'walking', 2500.00, '1-hour walk with your dog in the park'),
+    (2, 'home-care', 4000.00, 'Visits home twice a day to feed your pet'),
+    (4, 'boarding', 7000.00, 'Pet stays at sitter''s place overnight'),
+    (4, 'walking', 2000.00, 'Evening walks near the river'),
+    (2, 'boarding', 8000.00, 'Comfortable stay for cats and small dogs')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO bookings (owner_id, sitter_id, pet_id, service_id, start_time, end_time, status) VALUES
+    (1, 2, 1, 1, '2025-10-15 10:00', '2025-10-15 11:00', 'completed'),
+    (3, 4, 2, 3, '2025-10-16 09:00', '2025-10-16 18:00', 'completed'),
+    (1, 2, 5, 5, '2025-10-20 08:00', '2025-10-21 08:00', 'pending'),
+    (3, 4, 4, 4, '2025-10-22 18:00', '2025-10-22 19:00', 'cancelled'),
+    (1, 2, 3, 2, '2025-10-25 09:00', '2025-10-25 10:00', 'confirmed')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO payments (booking_id, amount, method, status) VALUES
+    (1, 2500.00, 'card', 'paid'),
+    (2, 7000.00, 'card', 'paid'),
+    (3, 8000.00, 'card', 'failed'),
+    (4, 2000.00, 'cash', 'refunded'),
+    (5, 4000.00, 'card', 'paid')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reviews (booking_id, owner_id, sitter_id, rating, comment) VALUES
+    (1, 1, 2, 5, 'Great experience, sitter was kind!'),
+    (2, 3, 4, 4, 'Dog came home happy.')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO chats (booking_id) VALUES
+    (1), (2), (3), (4), (5)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO messages (chat_id, sender_id, content) VALUES
+    (1, 1, 'Hello, is the time okay for tomorrow?'),
+    (1, 2, 'Yes, I''ll be there at 10.'),
+    (2, 3, 'Can you take Bobby at 9am?'),
+    (2, 4, 'Sure, no problem.'),
+    (3, 1, 'Please send photo updates during boarding.')
+ON CONFLICT DO NOTHING;
@@ -13,10 +13,28 @@ if (!authData || !authData.token || !authData.user || authData.user.role !== 'ad
     window.location.href = 'login.html';
 }
 
-const token = authData.token;
+let token = authData.token;
 const user = authData.user;
 
-async function authFetch(url, options = {}) {
+async function refreshSession() {
+    if (!authData.refresh_token) return false;
+
+    const res = await fetch(`${API_URL}/api/auth/refresh`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: authData.refresh_token })
+    });
+    if (!res.ok) return false;
+
+    const result = await res.json();
+    authData.token = result.token;
+    authData.refresh_token = result.refresh_token;
+    token = result.token;
+    localStorage.setItem('auth', JSON.stringify(authData));
+    return true;
+}
+
+async function authFetch(url, options = {}, retried = false) {
     const headers = options.headers || {};
     headers['Authorization'] = `Bearer ${token}`;
     if (!headers['Content-Type'] && options.method && options.method !== 'GET') {
@@ -27,6 +45,10 @@ async function authFetch(url, options = {}) {
 
     const res = await fetch(fullUrl, { ...options, headers });
 
+    if (res.status === 401 && !retried && await refreshSession()) {
+        return authFetch(url, options, true);
+    }
+
     if (res.status === 401) {
         alert('Сессия истекла. Войдите снова.');
         logout();
@@ -36,6 +58,19 @@ async function authFetch(url, options = {}) {
     return res;
 }
 
+// List endpoints return {items, next_cursor}; follow the cursor to the end.
+async function fetchAllItems(url) {
+    const items = [];
+    let cursor = '';
+    do {
+        const sep = url.includes('?') ? '&' : '?';
+        const page = await (await authFetch(`${url}${sep}limit=100${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`)).json();
+        items.push(...(page.items || []));
+        cursor = page.next_cursor;
+    } while (cursor);
+    return items;
+}
+
 document.querySelectorAll('.sidebar-menu a').forEach(link => {
     link.addEventListener('click', (e) => {
         e.preventDefault();
@@ -57,20 +92,19 @@ function loadTabData(tab) {
         case 'pending': loadPendingSitters(); break;
         case 'users':   loadUsers(); break;
         case 'sitters': loadSitters(); break;
+        case 'audit':   loadAudit(); break;
     }
 }
 
 async function loadOverview() {
     try {
-        const usersRes = await authFetch('/api/admin/users');
-        const users = await usersRes.json();
+        const users = await fetchAllItems('/api/admin/users');
         document.getElementById('totalUsers').textContent = users.length;
 
         const sitters = users.filter(u => u.role === 'sitter');
         document.getElementById('totalSitters').textContent = sitters.length;
 
-        const pendingRes = await authFetch('/api/admin/sitters/pending');
-        const pending = await pendingRes.json();
+        const pending = await fetchAllItems('/api/admin/sitters/pending');
         document.getElementById('pendingCount').textContent = pending.length;
 
         document.getElementById('approvedCount').textContent = sitters.length - pending.length;
@@ -102,15 +136,109 @@ async function loadOverview() {
                 </tbody>
             </table>
         `;
+
+        await loadStats();
     } catch (err) {
         console.error('Ошибка загрузки обзора:', err);
     }
 }
 
+async function loadStats() {
+    const res = await authFetch('/api/admin/stats');
+    if (!res.ok) return;
+    const stats = await res.json();
+
+    const totals = stats.booking_totals;
+    document.getElementById('grossBookingValue').textContent = Math.round(totals.gross_booking_value).toLocaleString('ru-RU');
+    document.getElementById('bookingCount').textContent = totals.bookings;
+    document.getElementById('cancellationRate').textContent = Math.round(totals.cancellation_rate * 100) + '%';
+
+    document.getElementById('statsTables').innerHTML = `
+        <h3>Топ нянь по выручке</h3>
+        <table>
+            <thead><tr><th>Няня</th><th>Оплат</th><th>Выручка, ₸</th></tr></thead>
+            <tbody>
+                ${stats.top_sitters_by_revenue.map(s => `
+                    <tr><td>${s.full_name}</td><td>${s.payments}</td><td>${s.revenue.toLocaleString('ru-RU')}</td></tr>
+                `).join('') || '<tr><td colspan="3">Нет данных</td></tr>'}
+            </tbody>
+        </table>
+        <h3>Топ нянь по рейтингу</h3>
+        <table>
+            <thead><tr><th>Няня</th><th>Рейтинг</th><th>Отзывов</th></tr></thead>
+            <tbody>
+                ${stats.top_sitters_by_rating.map(s => `
+                    <tr><td>${s.full_name}</td><td>${renderStars(s.average_rating)} ${s.average_rating.toFixed(1)}</td><td>${s.review_count}</td></tr>
+                `).join('') || '<tr><td colspan="3">Нет данных</td></tr>'}
+            </tbody>
+        </table>
+        <h3>Популярные услуги по городам</h3>
+        <table>
+            <thead><tr><th>Город</th><th>Услуга</th><th>Бронирований</th></tr></thead>
+            <tbody>
+                ${stats.busiest_services.map(c => `
+                    <tr><td>${c.city || '—'}</td><td>${getServiceTypeName(c.service_type)}</td><td>${c.bookings}</td></tr>
+                `).join('') || '<tr><td colspan="3">Нет данных</td></tr>'}
+            </tbody>
+        </table>
+    `;
+}
+
+let auditEntries = [];
+let auditCursor = '';
+
+async function loadAudit(more = false) {
+    try {
+        if (!more) {
+            auditEntries = [];
+            auditCursor = '';
+        }
+
+        const url = '/api/admin/audit?limit=50' + (auditCursor ? '&cursor=' + encodeURIComponent(auditCursor) : '');
+        const res = await authFetch(url);
+        if (!res.ok) throw new Error(`HTTP ${res.status}`);
+        const page = await res.json();
+
+        auditEntries = auditEntries.concat(page.items);
+        auditCursor = page.next_cursor || '';
+        document.getElementById('auditMore').style.display = auditCursor ? 'inline-block' : 'none';
+
+        document.getElementById('auditList').innerHTML = `
+            <table>
+                <thead>
+                    <tr>
+                        <th>Время</th>
+                        <th>Кто</th>
+                        <th>Действие</th>
+                        <th>Объект</th>
+                        <th>Изменения</th>
+                        <th>Статус</th>
+                        <th>IP</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    ${auditEntries.map(e => `
+                        <tr>
+                            <td>${new Date(e.created_at).toLocaleString('ru-RU')}</td>
+                            <td>${e.actor_id ? `#${e.actor_id} <span class="badge badge-${e.actor_role}">${e.actor_role}</span>` : '—'}</td>
+                            <td>${e.action}</td>
+                            <td>${e.entity_type ? `${e.entity_type}${e.entity_id ? ' #' + e.entity_id : ''}` : '—'}</td>
+                            <td>${e.before || e.after ? `<code>${JSON.stringify(e.before ?? null)} → ${JSON.stringify(e.after ?? null)}</code>` : ''}</td>
+                            <td>${e.status}</td>
+                            <td>${e.ip}</td>
+                        </tr>
+                    `).join('')}
+                </tbody>
+            </table>
+        `;
+    } catch (err) {
+        console.error('Ошибка загрузки журнала:', err);
+    }
+}
+
 async function loadPendingSitters() {
     try {
-        const res = await authFetch('/api/admin/sitters/pending');
-        const sitters = await res.json();
+        const sitters = await fetchAllItems('/api/admin/sitters/pending');
 
         const div = document.getElementById('pendingSitters');
 
@@ -136,6 +264,7 @@ async function loadPendingSitters() {
 
                 <div class="actions" style="margin-top: 10px;">
                     <button class="btn btn-success" onclick="approveSitter(${s.sitter_id})">Одобрить</button>
+                    <button class="btn btn-secondary" onclick="requestSitterChanges(${s.sitter_id})">Запросить изменения</button>
                     <button class="btn btn-danger" onclick="rejectSitter(${s.sitter_id})">Отклонить</button>
                     <button class="btn btn-secondary" onclick="showSitterDetails(${s.sitter_id})">Подробнее</button>
                 </div>
@@ -149,8 +278,7 @@ async function loadPendingSitters() {
 
 async function loadUsers() {
     try {
-        const res = await authFetch('/api/admin/users');
-        const users = await res.json();
+        const users = await fetchAllItems('/api/admin/users');
 
         const div = document.getElementById('usersList');
 
@@ -164,6 +292,7 @@ async function loadUsers() {
                         <th>Телефон</th>
                         <th>Роль</th>
                         <th>Дата регистрации</th>
+                        <th>Статус</th>
                         <th>Действия</th>
                     </tr>
                 </thead>
@@ -176,9 +305,15 @@ async function loadUsers() {
                             <td>${u.phone}</td>
                             <td>${u.role}</td>
                             <td>${new Date(u.created_at).toLocaleDateString('ru-RU')}</td>
+                            <td>${accountStatus(u)}</td>
                             <td>
-                                ${u.role !== 'admin'
-            ? `<button class="btn btn-danger btn-sm" onclick="deleteUser(${u.user_id}, '${u.full_name}')">Удалить</button>`
+                                ${u.role !== 'admin' && !u.deleted_at
+            ? `${u.banned_at || isSuspended(u)
+                ? `<button class="btn btn-secondary btn-sm" onclick="reinstateUser(${u.user_id})">Восстановить</button>`
+                : `<button class="btn btn-secondary btn-sm" onclick="suspendUser(${u.user_id})">Заблокировать</button>
+                   <button class="btn btn-danger btn-sm" onclick="banUser(${u.user_id})">Забанить</button>`}
+               <button class="btn btn-secondary btn-sm" onclick="impersonateUser(${u.user_id})">Войти как</button>
+               <button class="btn btn-danger btn-sm" onclick="deleteUser(${u.user_id}, '${u.full_name}')">Удалить</button>`
             : '-'}
                             </td>
                         </tr>
@@ -193,8 +328,7 @@ async function loadUsers() {
 
 async function loadSitters() {
     try {
-        const usersRes = await authFetch('/api/admin/users');
-        const users = await usersRes.json();
+        const users = await fetchAllItems('/api/admin/users');
 
         const sitters = users.filter(u => u.role === 'sitter');
 
@@ -258,11 +392,9 @@ async function showSitterDetails(id) {
         const dRes = await authFetch(`/api/admin/sitters/${id}`);
         const details = await dRes.json();
 
-        const reviewsRes = await authFetch(`/api/sitters/${id}/reviews`);
-        const reviews = await reviewsRes.json();
+        const reviews = await fetchAllItems(`/api/sitters/${id}/reviews`);
 
-        const servicesRes = await authFetch(`/api/sitters/${id}/services`);
-        const services = await servicesRes.json();
+        const services = await fetchAllItems(`/api/sitters/${id}/services`);
 
         const content = document.getElementById('sitterDetailsContent');
 
@@ -273,6 +405,28 @@ async function showSitterDetails(id) {
             <p><strong>Опыт:</strong> ${details.experience_years} лет</p>
             <p><strong>Статус:</strong> ${details.status}</p>
 
+            <h3>Документы</h3>
+            ${details.documents.length
+            ? details.documents.map(d => `
+                    <p>${d.kind === 'id' ? 'Удостоверение' : 'Сертификат'}:
+                        <a href="#" onclick="openDocument(${id}, ${d.document_id}); return false;">${d.file_name}</a></p>
+                `).join('')
+            : 'Документы не загружены'}
+
+            <h3>История решений</h3>
+            ${details.status_history.length
+            ? details.status_history.map(h => `
+                    <p>${new Date(h.created_at).toLocaleString()} — ${h.decision}${h.reason ? `: ${h.reason}` : ''}</p>
+                `).join('')
+            : 'Решений ещё не было'}
+
+            <h3>Заметки</h3>
+            ${details.notes.map(n => `
+                    <p>${new Date(n.created_at).toLocaleString()} — ${n.body}</p>
+                `).join('')}
+            <textarea id="sitterNoteBody" rows="2" style="width: 100%;"></textarea>
+            <button class="btn btn-secondary" onclick="addSitterNote(${id})">Добавить заметку</button>
+
             <h3>Услуги</h3>
             ${services.length
             ? services.map(s => `<p>${getServiceTypeName(s.type)} — ${s.price_per_hour} ₸</p>`).join('')
@@ -296,25 +450,149 @@ async function showSitterDetails(id) {
     }
 }
 
-async function approveSitter(id) {
-    if (!confirm('Одобрить няню?')) return;
+// decideSitter sends an admin decision; the reason is shown to the sitter
+// and is required for everything except approval.
+async function decideSitter(id, action, reason) {
     try {
-        await authFetch(`/api/admin/sitters/${id}/approve`, { method: 'POST' });
+        const res = await authFetch(`/api/admin/sitters/${id}/${action}`, {
+            method: 'POST',
+            body: JSON.stringify({ reason })
+        });
+        if (!res.ok) {
+            const err = await res.json().catch(() => ({}));
+            alert('Ошибка: ' + (err.detail || `код ${res.status}`));
+            return;
+        }
         loadPendingSitters();
         loadOverview();
     } catch (err) {
-        console.error('Ошибка одобрения няни', err);
+        console.error('Ошибка решения по няне', err);
     }
 }
 
+async function approveSitter(id) {
+    if (!confirm('Одобрить няню?')) return;
+    await decideSitter(id, 'approve', '');
+}
+
 async function rejectSitter(id) {
-    if (!confirm('Отклонить няню?')) return;
+    const reason = prompt('Причина отказа:');
+    if (!reason) return;
+    await decideSitter(id, 'reject', reason);
+}
+
+async function requestSitterChanges(id) {
+    const reason = prompt('Что нужно исправить?');
+    if (!reason) return;
+    await decideSitter(id, 'request-changes', reason);
+}
+
+async function addSitterNote(id) {
+    const body = document.getElementById('sitterNoteBody').value.trim();
+    if (!body) return;
     try {
-        await authFetch(`/api/admin/sitters/${id}/reject`, { method: 'POST' });
-        loadPendingSitters();
-        loadOverview();
+        await authFetch(`/api/admin/sitters/${id}/notes`, {
+            method: 'POST',
+            body: JSON.stringify({ body })
+        });
+        showSitterDetails(id);
+    } catch (err) {
+        console.error('Ошибка добавления заметки', err);
+    }
+}
+
+// Documents need the Authorization header, so they are fetched and opened
+// as a blob rather than linked directly.
+async function openDocument(sitterId, documentId) {
+    try {
+        const res = await authFetch(`/api/sitters/${sitterId}/documents/${documentId}/file`);
+        if (!res.ok) return;
+        const url = URL.createObjectURL(await res.blob());
+        window.open(url, '_blank');
     } catch (err) {
-        console.error('Ошибка отклонения няни', err);
+        console.error('Ошибка открытия документа', err);
+    }
+}
+
+function isSuspended(u) {
+    return u.suspended_until && new Date(u.suspended_until) > new Date();
+}
+
+function accountStatus(u) {
+    if (u.deleted_at) return 'Удалён';
+    if (u.banned_at) return `Забанен: ${u.restriction_reason}`;
+    if (isSuspended(u)) {
+        return `Заблокирован до ${new Date(u.suspended_until).toLocaleDateString('ru-RU')}: ${u.restriction_reason}`;
+    }
+    return 'Активен';
+}
+
+async function restrictUser(id, action, body) {
+    try {
+        const res = await authFetch(`/api/admin/users/${id}/${action}`, {
+            method: 'POST',
+            body: JSON.stringify(body)
+        });
+        if (!res.ok) {
+            const err = await res.json().catch(() => ({}));
+            alert('Ошибка: ' + (err.detail || `код ${res.status}`));
+            return;
+        }
+        loadUsers();
+    } catch (err) {
+        console.error('Ошибка изменения статуса пользователя', err);
+    }
+}
+
+async function suspendUser(id) {
+    const days = parseInt(prompt('На сколько дней заблокировать?', '7'), 10);
+    if (!days || days <= 0) return;
+    const reason = prompt('Причина блокировки:');
+    if (!reason) return;
+    const until = new Date(Date.now() + days * 24 * 60 * 60 * 1000).toISOString();
+    await restrictUser(id, 'suspend', { until, reason });
+}
+
+async function banUser(id) {
+    const reason = prompt('Причина бана:');
+    if (!reason) return;
+    await restrictUser(id, 'ban', { reason });
+}
+
+async function reinstateUser(id) {
+    if (!confirm('Снять ограничения с пользователя?')) return;
+    await restrictUser(id, 'reinstate', {});
+}
+
+// The user's dashboard opens in a new tab that keeps the impersonation
+// token to itself; the tab is opened before the request so popup blockers
+// let it through.
+async function impersonateUser(id) {
+    const tab = window.open('', '_blank');
+    try {
+        const res = await authFetch(`/api/admin/users/${id}/impersonate`, { method: 'POST' });
+        const result = await res.json().catch(() => ({}));
+        if (!res.ok) {
+            tab.close();
+            alert('Ошибка: ' + (result.detail || `код ${res.status}`));
+            return;
+        }
+
+        const login = {
+            token: result.token,
+            impersonated_by: result.impersonated_by,
+            user: {
+                id: result.user_id,
+                role: result.role,
+                email: result.email,
+                full_name: result.full_name
+            }
+        };
+        const page = result.role === 'sitter' ? 'sitter-dashboard.html' : 'dashboard.html';
+        tab.location.href = `${page}#impersonate=${encodeURIComponent(JSON.stringify(login))}`;
+    } catch (err) {
+        tab.close();
+        console.error('Ошибка входа от имени пользователя', err);
     }
 }
 
@@ -347,6 +625,13 @@ function closeModal(id) {
 }
 
 function logout() {
+    if (authData && authData.refresh_token) {
+        fetch(`${API_URL}/api/auth/logout`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ refresh_token: authData.refresh_token })
+        }).catch(() => {});
+    }
     localStorage.removeItem('auth');
     localStorage.removeItem('user');
     localStorage.removeItem('token');
@@ -1,6 +1,15 @@
 const API_URL = 'http://localhost:8080';
 
-let authData = JSON.parse(localStorage.getItem('auth') || 'null');
+// "Войти как" in the admin dashboard opens this page with an
+// impersonation login in the URL fragment. It is kept for this tab only,
+// so the admin's own session in localStorage is left alone.
+if (location.hash.startsWith('#impersonate=')) {
+    sessionStorage.setItem('impersonation', decodeURIComponent(location.hash.slice('#impersonate='.length)));
+    history.replaceState(null, '', location.pathname);
+}
+
+const impersonation = JSON.parse(sessionStorage.getItem('impersonation') || 'null');
+let authData = impersonation || JSON.parse(localStorage.getItem('auth') || 'null');
 
 if (!authData && localStorage.getItem('token') && localStorage.getItem('user')) {
     authData = {
@@ -13,10 +22,28 @@ if (!authData || !authData.token || !authData.user || authData.user.role !== 'ow
     window.location.href = 'login.html';
 }
 
-const token = authData.token;
+let token = authData.token;
 const user = authData.user;
 
-async function authFetch(url, options = {}) {
+async function refreshSession() {
+    if (!authData.refresh_token) return false;
+
+    const res = await fetch(`${API_URL}/api/auth/refresh`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: authData.refresh_token })
+    });
+    if (!res.ok) return false;
+
+    const result = await res.json();
+    authData.token = result.token;
+    authData.refresh_token = result.refresh_token;
+    token = result.token;
+    localStorage.setItem('auth', JSON.stringify(authData));
+    return true;
+}
+
+async function authFetch(url, options = {}, retried = false) {
     const headers = options.headers || {};
     headers['Authorization'] = `Bearer ${token}`;
     if (!headers['Content-Type'] && options.body && !(options.body instanceof FormData)) {
@@ -27,6 +54,10 @@ async function authFetch(url, options = {}) {
 
     const res = await fetch(fullUrl, { ...options, headers });
 
+    if (res.status === 401 && !retried && await refreshSession()) {
+        return authFetch(url, options, true);
+    }
+
     if (res.status === 401) {
         alert('Сессия истекла. Войдите снова.');
         logout();
@@ -36,8 +67,28 @@ async function authFetch(url, options = {}) {
     return res;
 }
 
+// List endpoints return {items, next_cursor}; follow the cursor to the end.
+async function fetchAllItems(url) {
+    const items = [];
+    let cursor = '';
+    do {
+        const sep = url.includes('?') ? '&' : '?';
+        const page = await (await authFetch(`${url}${sep}limit=100${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`)).json();
+        items.push(...(page.items || []));
+        cursor = page.next_cursor;
+    } while (cursor);
+    return items;
+}
+
 document.getElementById('userEmail').textContent = user.email;
 
+if (impersonation) {
+    const banner = document.createElement('div');
+    banner.className = 'impersonation-banner';
+    banner.textContent = `Просмотр от имени ${user.full_name || user.email} (администратор #${impersonation.impersonated_by}). Изменения недоступны: доступен только просмотр.`;
+    document.body.prepend(banner);
+}
+
 document.querySelectorAll('.sidebar-menu a').forEach(link => {
     link.addEventListener('click', (e) => {
         e.preventDefault();
@@ -72,14 +123,10 @@ function loadTabData(tab) {
 
 async function loadOverview() {
     try {
-        const petsRes = await authFetch(`/api/owners/${user.id}/pets`);
-        if (!petsRes) return;
-        const pets = await petsRes.json();
+        const pets = await fetchAllItems(`/api/owners/${user.id}/pets`);
         document.getElementById('petsCount').textContent = pets.length || 0;
 
-        const bookingsRes = await authFetch(`/api/owners/${user.id}/bookings`);
-        if (!bookingsRes) return;
-        const bookings = await bookingsRes.json();
+        const bookings = await fetchAllItems(`/api/owners/${user.id}/bookings`);
         document.getElementById('bookingsCount').textContent = bookings.length || 0;
 
         const recentDiv = document.getElementById('recentBookings');
@@ -119,10 +166,7 @@ async function loadPets() {
     const petsDiv = document.getElementById('petsList');
 
     try {
-        const res = await authFetch(`/api/owners/${user.id}/pets`);
-        if (!res) return;
-
-        const pets = await res.json();
+        const pets = await fetchAllItems(`/api/owners/${user.id}/pets`);
 
         if (!Array.isArray(pets) || pets.length === 0) {
             petsDiv.innerHTML = '<div class="empty-state"><h3>У вас пока нет питомцев</h3><p>Добавьте первого питомца!</p></div>';
@@ -163,9 +207,7 @@ async function loadPets() {
 
 async function loadBookings() {
     try {
-        const res = await authFetch(`/api/owners/${user.id}/bookings`);
-        if (!res) return;
-        const bookings = await res.json();
+        const bookings = await fetchAllItems(`/api/owners/${user.id}/bookings`);
 
         const bookingsDiv = document.getElementById('bookingsList');
 
@@ -227,9 +269,10 @@ async function searchServices() {
 
         const res = await authFetch(`/api/services/search?${params.toString()}`);
         if (!res) return;
-        const services = await res.json();
+        const data = await res.json();
+        const services = data.services;
 
-        console.log('services search result:', services);
+        console.log('services search result:', data);
 
         const resultsDiv = document.getElementById('searchResults');
 
@@ -243,8 +286,9 @@ async function searchServices() {
                 <h3>${s.sitter_name}</h3>
                 <div class="rating">
                     ${renderStars(s.sitter_rating || 0)}
-                    <span>(${(s.sitter_rating || 0).toFixed(1)})</span>
+                    <span>(${(s.sitter_rating || 0).toFixed(1)}, отзывов: ${s.review_count || 0})</span>
                 </div>
+                <p><strong>Опыт:</strong> ${s.experience_years || 0} лет, ${s.location || ''}</p>
                 <p><strong>Услуга:</strong> ${getServiceTypeName(s.type)}</p>
                 <p><strong>Цена:</strong> ${s.price_per_hour} ₸/час</p>
                 <p>${s.description || ''}</p>
@@ -261,6 +305,37 @@ async function searchServices() {
     }
 }
 
+// Optional per-dimension scores; reviews are sent with whichever are picked.
+const REVIEW_SCORES = [
+    ['punctuality', 'Пунктуальность'],
+    ['communication', 'Общение'],
+    ['pet_care', 'Уход за питомцем']
+];
+
+// The owner's loaded reviews by ID, so editing keeps their scores.
+let myReviews = {};
+
+function scoreFields(prefix, review = {}) {
+    return REVIEW_SCORES.map(([key, label]) => `
+        <div class="form-group">
+            <label>${label}:</label>
+            <select id="${prefix}_${key}">
+                <option value="">-- Не оценивать --</option>
+                ${[5, 4, 3, 2, 1].map(v => `<option value="${v}" ${review[key] === v ? 'selected' : ''}>${'⭐'.repeat(v)}</option>`).join('')}
+            </select>
+        </div>
+    `).join('');
+}
+
+function readScores(prefix) {
+    const scores = {};
+    REVIEW_SCORES.forEach(([key]) => {
+        const value = parseInt(document.getElementById(`${prefix}_${key}`).value);
+        if (value) scores[key] = value;
+    });
+    return scores;
+}
+
 async function loadReviews() {
     const user = authData?.user;
     const container = document.getElementById('reviewsList');
@@ -283,7 +358,7 @@ async function loadReviews() {
     }
 
     try {
-        const bookingsRes = await authFetch(`/api/owners/${user.id}/bookings`);
+        const bookingsRes = await authFetch(`/api/owners/${user.id}/bookings?limit=100`);
 
         if (!bookingsRes) {
             throw new Error('Сервер не вернул ответ по бронированиям (bookingsRes = null)');
@@ -305,7 +380,7 @@ async function loadReviews() {
 
         let bookings;
         try {
-            bookings = await bookingsRes.json();
+            bookings = (await bookingsRes.json()).items;
         } catch (jsonErr) {
             console.error('loadReviews: не удалось распарсить JSON бронирований:', jsonErr);
             throw new Error('Некорректный JSON от сервера при загрузке бронирований');
@@ -364,6 +439,7 @@ async function loadReviews() {
             .map(r => r.value);
 
         console.log('loadReviews: итоговый список reviews =', reviews);
+        myReviews = Object.fromEntries(reviews.map(r => [r.review_id, r]));
 
         if (reviews.length === 0) {
             renderEmptyReviews(container);
@@ -380,8 +456,13 @@ async function loadReviews() {
                                 ${'⭐'.repeat(review.rating)}${'☆'.repeat(5 - review.rating)}
                             </span>
                             <span style="color: #666;">(${review.rating}/5)</span>
+                            ${review.verified ? '<span style="color: #27ae60; font-weight: bold;">✔ Подтверждённое бронирование</span>' : ''}
                         </div>
 
+                        ${REVIEW_SCORES.filter(([key]) => review[key]).map(([key, label]) => `
+                            <p><strong>${label}:</strong> ${review[key]}/5</p>
+                        `).join('')}
+
                         <p><strong>Бронирование:</strong> #${review.booking_id}</p>
                         <p><strong>Няня:</strong> ID ${review.sitter_id}</p>
                         <p><strong>Дата отзыва:</strong> ${new Date(review.created_at).toLocaleDateString('ru-RU')}</p>
@@ -457,6 +538,7 @@ async function editReview(reviewId, currentRating, currentComment) {
                     <label>Комментарий:</label>
                     <textarea id="editReviewComment" rows="4" placeholder="Расскажите о вашем опыте...">${currentComment}</textarea>
                 </div>
+                ${scoreFields('editReview', myReviews[reviewId])}
                 <div style="display: flex; gap: 10px; justify-content: flex-end;">
                     <button type="button" onclick="this.closest('.modal').remove()" class="btn btn-secondary">
                         Отмена
@@ -482,6 +564,7 @@ async function editReview(reviewId, currentRating, currentComment) {
                 headers: { 'Content-Type': 'application/json' },
                 body: JSON.stringify({
                     rating: newRating,
+                    ...readScores('editReview'),
                     comment: newComment
                 })
             });
@@ -492,7 +575,7 @@ async function editReview(reviewId, currentRating, currentComment) {
                 loadReviews();
             } else {
                 const err = await res.json().catch(() => ({}));
-                alert('❌ Ошибка: ' + (err.error || `код ${res.status}`));
+                alert('❌ Ошибка: ' + (err.detail || `код ${res.status}`));
             }
         } catch (err) {
             console.error('Ошибка обновления отзыва:', err);
@@ -516,7 +599,7 @@ async function deleteReview(reviewId) {
             loadReviews();
         } else {
             const err = await res.json().catch(() => ({}));
-            alert('❌ Ошибка удаления: ' + (err.error || `код ${res.status}`));
+            alert('❌ Ошибка удаления: ' + (err.detail || `код ${res.status}`));
         }
     } catch (err) {
         console.error('Ошибка удаления отзыва:', err);
@@ -552,6 +635,7 @@ function showReviewModal(bookingId, sitterId) {
                               placeholder="Что вам понравилось или не понравилось?&#10;Как няня обращалась с питомцем?&#10;Рекомендуете ли вы эту няню другим?"></textarea>
                     <small style="color: #666;">Комментарий необязателен, но будет полезен другим владельцам</small>
                 </div>
+                ${scoreFields('review')}
                 <div style="display: flex; gap: 10px; justify-content: flex-end;">
                     <button type="button" onclick="this.closest('.modal').remove()" class="btn btn-secondary">
                         Отмена
@@ -573,7 +657,6 @@ function showReviewModal(bookingId, sitterId) {
 }
 
 async function submitReview(bookingId, sitterId) {
-    const user = JSON.parse(localStorage.getItem('user'));
     const rating = parseInt(document.getElementById('reviewRating').value);
     const comment = document.getElementById('reviewComment').value.trim();
 
@@ -591,6 +674,7 @@ async function submitReview(bookingId, sitterId) {
                 owner_id: user.id,
                 sitter_id: sitterId,
                 rating: rating,
+                ...readScores('review'),
                 comment: comment
             })
         });
@@ -604,7 +688,7 @@ async function submitReview(bookingId, sitterId) {
             loadReviews();
         } else {
             const err = await res.json().catch(() => ({}));
-            alert('❌ Ошибка: ' + (err.error || `код ${res.status}`));
+            alert('❌ Ошибка: ' + (err.detail || `код ${res.status}`));
         }
     } catch (err) {
         console.error('Ошибка отправки отзыва:', err);
@@ -627,9 +711,7 @@ function closeModal(modalId) {
 
 async function loadPetsForBooking() {
     try {
-        const res = await authFetch(`/api/owners/${user.id}/pets`);
-        if (!res) return;
-        const pets = await res.json();
+        const pets = await fetchAllItems(`/api/owners/${user.id}/pets`);
 
         const select = document.getElementById('bookingPetSelect');
         select.innerHTML = pets.map(pet =>
@@ -664,7 +746,7 @@ document.getElementById('addPetForm').addEventListener('submit', async (e) => {
             loadOverview();
         } else if (res) {
             const err = await res.json();
-            alert('❌ ' + err.error);
+            alert('❌ ' + err.detail);
         }
     } catch (err) {
         alert('Ошибка соединения');
@@ -698,7 +780,7 @@ document.getElementById('createBookingForm').addEventListener('submit', async (e
             loadOverview();
         } else if (res) {
             const err = await res.json();
-            alert('❌ ' + err.error);
+            alert('❌ ' + err.detail);
         }
     } catch (err) {
         alert('Ошибка соединения');
@@ -762,6 +844,19 @@ function renderStars(rating) {
 }
 
 function logout() {
+    if (impersonation) {
+        sessionStorage.removeItem('impersonation');
+        window.close();
+        window.location.href = 'admin-dashboard.html';
+        return;
+    }
+    if (authData && authData.refresh_token) {
+        fetch(`${API_URL}/api/auth/logout`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ refresh_token: authData.refresh_token })
+        }).catch(() => {});
+    }
     localStorage.removeItem('auth');
     localStorage.removeItem('user');
     localStorage.removeItem('token');
@@ -34,13 +34,14 @@ document.addEventListener('DOMContentLoaded', () => {
             if (!res.ok) {
                 if (errorBox) {
                     errorBox.style.display = 'block';
-                    errorBox.innerText = result.error || 'Ошибка входа';
+                    errorBox.innerText = result.detail || 'Ошибка входа';
                 }
                 return;
             }
 
             const authData = {
                 token: result.token,
+                refresh_token: result.refresh_token,
                 user: {
                     id: result.user_id,
                     role: result.role,
@@ -0,0 +1,58 @@
+document.addEventListener('DOMContentLoaded', () => {
+    const BASE_URL = "http://localhost:8080";
+
+    const forgotForm = document.getElementById('forgotForm');
+    const resetForm = document.getElementById('resetForm');
+    const errorBox = document.getElementById('error-box');
+    const resetToken = new URLSearchParams(window.location.search).get('token');
+
+    function showMessage(text) {
+        errorBox.style.display = 'block';
+        errorBox.innerText = text;
+    }
+
+    errorBox.style.display = 'none';
+
+    if (resetToken) {
+        forgotForm.style.display = 'none';
+        resetForm.style.display = 'block';
+    }
+
+    async function post(path, body) {
+        const res = await fetch(`${BASE_URL}${path}`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(body)
+        });
+        const result = await res.json();
+        return { ok: res.ok, result };
+    }
+
+    forgotForm.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        const data = Object.fromEntries(new FormData(forgotForm));
+
+        try {
+            const { ok, result } = await post('/api/auth/password/forgot', data);
+            showMessage(ok ? 'Если аккаунт существует, мы отправили ссылку на почту' : (result.detail || 'Ошибка'));
+        } catch (err) {
+            showMessage('Ошибка соединения с сервером');
+        }
+    });
+
+    resetForm.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        const data = Object.fromEntries(new FormData(resetForm));
+
+        try {
+            const { ok, result } = await post('/api/auth/password/reset', { token: resetToken, password: data.password });
+            if (!ok) {
+                showMessage(result.detail || 'Ссылка недействительна или устарела');
+                return;
+            }
+            window.location.href = 'login.html';
+        } catch (err) {
+            showMessage('Ошибка соединения с сервером');
+        }
+    });
+});
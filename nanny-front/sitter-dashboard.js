@@ -1,6 +1,15 @@
 const API_URL = 'http://localhost:8080';
 
-let authData = JSON.parse(localStorage.getItem('auth') || 'null');
+// "Войти как" in the admin dashboard opens this page with an
+// impersonation login in the URL fragment. It is kept for this tab only,
+// so the admin's own session in localStorage is left alone.
+if (location.hash.startsWith('#impersonate=')) {
+    sessionStorage.setItem('impersonation', decodeURIComponent(location.hash.slice('#impersonate='.length)));
+    history.replaceState(null, '', location.pathname);
+}
+
+const impersonation = JSON.parse(sessionStorage.getItem('impersonation') || 'null');
+let authData = impersonation || JSON.parse(localStorage.getItem('auth') || 'null');
 
 if (!authData && localStorage.getItem('token') && localStorage.getItem('user')) {
     authData = {
@@ -13,12 +22,37 @@ if (!authData || !authData.token || !authData.user || authData.user.role !== 'si
     window.location.href = 'login.html';
 }
 
-const token = authData.token;
+let token = authData.token;
 const user = authData.user;
 
 document.getElementById('userEmail').textContent = user.email;
 
-async function authFetch(url, options = {}) {
+if (impersonation) {
+    const banner = document.createElement('div');
+    banner.className = 'impersonation-banner';
+    banner.textContent = `Просмотр от имени ${user.full_name || user.email} (администратор #${impersonation.impersonated_by}). Изменения недоступны: доступен только просмотр.`;
+    document.body.prepend(banner);
+}
+
+async function refreshSession() {
+    if (!authData.refresh_token) return false;
+
+    const res = await fetch(`${API_URL}/api/auth/refresh`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: authData.refresh_token })
+    });
+    if (!res.ok) return false;
+
+    const result = await res.json();
+    authData.token = result.token;
+    authData.refresh_token = result.refresh_token;
+    token = result.token;
+    localStorage.setItem('auth', JSON.stringify(authData));
+    return true;
+}
+
+async function authFetch(url, options = {}, retried = false) {
     const headers = options.headers || {};
     headers['Authorization'] = `Bearer ${token}`;
     if (!headers['Content-Type'] && options.body && !(options.body instanceof FormData)) {
@@ -32,6 +66,10 @@ async function authFetch(url, options = {}) {
         headers
     });
 
+    if (response.status === 401 && !retried && await refreshSession()) {
+        return authFetch(url, options, true);
+    }
+
     if (response.status === 401) {
         alert('Сессия истекла. Войдите снова.');
         logout();
@@ -41,6 +79,19 @@ async function authFetch(url, options = {}) {
     return response;
 }
 
+// List endpoints return {items, next_cursor}; follow the cursor to the end.
+async function fetchAllItems(url) {
+    const items = [];
+    let cursor = '';
+    do {
+        const sep = url.includes('?') ? '&' : '?';
+        const page = await (await authFetch(`${url}${sep}limit=100${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`)).json();
+        items.push(...(page.items || []));
+        cursor = page.next_cursor;
+    } while (cursor);
+    return items;
+}
+
 document.querySelectorAll('.sidebar-menu a').forEach(link => {
     link.addEventListener('click', (e) => {
         e.preventDefault();
@@ -68,14 +119,10 @@ function loadTabData(tab) {
 
 async function loadOverview() {
     try {
-        const servicesRes = await authFetch(`/api/sitters/${user.id}/services`);
-        if (!servicesRes) return;
-        const services = await servicesRes.json();
+        const services = await fetchAllItems(`/api/sitters/${user.id}/services`);
         document.getElementById('servicesCount').textContent = services.length || 0;
 
-        const bookingsRes = await authFetch(`/api/sitters/${user.id}/bookings`);
-        if (!bookingsRes) return;
-        const bookings = await bookingsRes.json();
+        const bookings = await fetchAllItems(`/api/sitters/${user.id}/bookings`);
         document.getElementById('bookingsCount').textContent = bookings.length || 0;
 
         const ratingRes = await authFetch(`/api/sitters/${user.id}/rating`);
@@ -125,20 +172,40 @@ async function loadOverview() {
     }
 }
 
+const DECISION_NAMES = {
+    approve: 'Одобрено',
+    reject: 'Отклонено',
+    request_changes: 'Запрошены изменения',
+    resubmit: 'Отправлено повторно'
+};
+
+// The current status is the last entry of the history; a sitter nobody
+// has decided on yet is pending.
+async function loadStatusHistory() {
+    const res = await authFetch(`/api/sitters/${user.id}/status-history`);
+    if (!res || !res.ok) return [];
+    return await res.json();
+}
+
 async function checkAccountStatus() {
     try {
-        const res = await authFetch(`/api/admin/sitters/${user.id}`);
-        if (!res) return;
-        const details = await res.json();
+        const history = await loadStatusHistory();
+        const last = history[history.length - 1];
+        const status = last ? last.to_status : 'pending';
+        const reason = last && last.reason ? `<p>Причина: ${last.reason}</p>` : '';
 
         const badge = document.getElementById('statusBadge');
 
-        if (details.status === 'pending') {
+        if (status === 'pending') {
             badge.innerHTML = '<span class="badge badge-pending">⏳ На модерации</span>';
-        } else if (details.status === 'approved') {
+        } else if (status === 'approved') {
             badge.innerHTML = '<span class="badge badge-approved">✅ Одобрен</span>';
-        } else if (details.status === 'rejected') {
-            badge.innerHTML = '<span class="badge badge-rejected">❌ Отклонён</span>';
+        } else if (status === 'changes_requested') {
+            badge.innerHTML = `<span class="badge badge-changes_requested">✏️ Нужны изменения</span>${reason}
+                <button class="btn btn-primary" onclick="resubmitApplication()">Отправить повторно</button>`;
+        } else if (status === 'rejected') {
+            badge.innerHTML = `<span class="badge badge-rejected">❌ Отклонён</span>${reason}
+                <button class="btn btn-primary" onclick="resubmitApplication()">Подать заново</button>`;
         }
 
     } catch (err) {
@@ -146,11 +213,20 @@ async function checkAccountStatus() {
     }
 }
 
+async function resubmitApplication() {
+    const res = await authFetch(`/api/sitters/${user.id}/resubmit`, { method: 'POST' });
+    if (!res) return;
+    if (!res.ok) {
+        const err = await res.json().catch(() => ({}));
+        alert('Ошибка: ' + (err.detail || `код ${res.status}`));
+        return;
+    }
+    checkAccountStatus();
+}
+
 async function loadBookings() {
     try {
-        const res = await authFetch(`/api/sitters/${user.id}/bookings`);
-        if (!res) return;
-        const bookings = await res.json();
+        const bookings = await fetchAllItems(`/api/sitters/${user.id}/bookings`);
 
         const pending = bookings.filter(b => b.status === 'pending');
         const pendingDiv = document.getElementById('pendingBookings');
@@ -224,8 +300,10 @@ function renderAllBookings(list) {
                         <td><span class="badge badge-${b.status}">${b.status}</span></td>
                         <td>
                             ${b.status === 'confirmed'
-        ? `<button class="btn btn-success btn-sm" onclick="completeBooking(${b.booking_id})">Завершить</button>`
-        : '-'}
+        ? `<button class="btn btn-primary btn-sm" onclick="startBooking(${b.booking_id})">Начать</button>`
+        : b.status === 'in_progress'
+            ? `<button class="btn btn-success btn-sm" onclick="completeBooking(${b.booking_id})">Завершить</button>`
+            : '-'}
                         </td>
                     </tr>
                 `).join('')}
@@ -236,9 +314,7 @@ function renderAllBookings(list) {
 
 async function loadServices() {
     try {
-        const res = await authFetch(`/api/sitters/${user.id}/services`);
-        if (!res) return;
-        const services = await res.json();
+        const services = await fetchAllItems(`/api/sitters/${user.id}/services`);
 
         const div = document.getElementById('servicesList');
 
@@ -297,7 +373,7 @@ document.getElementById('addServiceForm').addEventListener('submit', async (e) =
             loadOverview();
         } else if (res) {
             const err = await res.json().catch(() => ({}));
-            alert('Ошибка: ' + (err.error || `код ${res.status}`));
+            alert('Ошибка: ' + (err.detail || `код ${res.status}`));
         }
     } catch {
         alert('Ошибка соединения');
@@ -306,14 +382,13 @@ document.getElementById('addServiceForm').addEventListener('submit', async (e) =
 
 async function loadReviews() {
     try {
-        const [reviewsRes, ratingRes] = await Promise.all([
-            authFetch(`/api/sitters/${user.id}/reviews`),
+        const [reviews, ratingRes] = await Promise.all([
+            fetchAllItems(`/api/sitters/${user.id}/reviews`),
             authFetch(`/api/sitters/${user.id}/rating`)
         ]);
 
-        if (!reviewsRes || !ratingRes) return;
+        if (!ratingRes) return;
 
-        const reviews = await reviewsRes.json();
         const rating = await ratingRes.json();
 
         document.getElementById('avgRating').textContent = rating.average_rating.toFixed(1);
@@ -359,8 +434,57 @@ async function loadProfile() {
     } catch (err) {
         console.error('Ошибка загрузки профиля:', err);
     }
+
+    loadDocuments();
+
+    try {
+        const history = await loadStatusHistory();
+        document.getElementById('statusHistory').innerHTML = history.length
+            ? history.map(h => `
+                <p>${new Date(h.created_at).toLocaleString()} — ${DECISION_NAMES[h.decision] || h.decision}${h.reason ? `: ${h.reason}` : ''}</p>
+            `).join('')
+            : '<p>Заявка ещё не рассматривалась</p>';
+    } catch (err) {
+        console.error('Ошибка загрузки истории заявки:', err);
+    }
 }
 
+async function loadDocuments() {
+    try {
+        const res = await authFetch(`/api/sitters/${user.id}/documents`);
+        if (!res) return;
+        const docs = await res.json();
+
+        document.getElementById('documentsList').innerHTML = docs.length
+            ? docs.map(d => `
+                <p>${d.kind === 'id' ? 'Удостоверение' : 'Сертификат'}: ${d.file_name}
+                    (${Math.ceil(d.size_bytes / 1024)} КБ, ${new Date(d.uploaded_at).toLocaleDateString()})</p>
+            `).join('')
+            : '<p>Документы не загружены</p>';
+    } catch (err) {
+        console.error('Ошибка загрузки документов:', err);
+    }
+}
+
+document.getElementById('uploadDocumentForm').addEventListener('submit', async (e) => {
+    e.preventDefault();
+
+    const res = await authFetch(`/api/sitters/${user.id}/documents`, {
+        method: 'POST',
+        body: new FormData(e.target)
+    });
+    if (!res) return;
+
+    if (!res.ok) {
+        const err = await res.json().catch(() => ({}));
+        alert('Ошибка: ' + (err.detail || `код ${res.status}`));
+        return;
+    }
+
+    e.target.reset();
+    loadDocuments();
+});
+
 async function confirmBooking(id) {
     const res = await authFetch(`/api/bookings/${id}/confirm`, { method: 'POST' });
     if (res && res.ok) {
@@ -378,6 +502,13 @@ async function rejectBooking(id) {
     }
 }
 
+async function startBooking(id) {
+    const res = await authFetch(`/api/bookings/${id}/start`, { method: 'POST' });
+    if (res && res.ok) {
+        loadBookings();
+    }
+}
+
 async function completeBooking(id) {
     if (!confirm('Завершить?')) return;
     const res = await authFetch(`/api/bookings/${id}/complete`, { method: 'POST' });
@@ -413,6 +544,19 @@ function closeModal(id) {
 }
 
 function logout() {
+    if (impersonation) {
+        sessionStorage.removeItem('impersonation');
+        window.close();
+        window.location.href = 'admin-dashboard.html';
+        return;
+    }
+    if (authData && authData.refresh_token) {
+        fetch(`${API_URL}/api/auth/logout`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ refresh_token: authData.refresh_token })
+        }).catch(() => {});
+    }
     localStorage.removeItem('auth');
     localStorage.removeItem('user');
     localStorage.removeItem('token');